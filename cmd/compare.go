@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/zing22845/go-frm-parser/frm"
+	"github.com/zing22845/go-frm-parser/frm/ddl"
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+// runCompare compares a .frm with the CREATE TABLE statement in a SQL file,
+// it exits with 1 when they differ
+func runCompare(args []string) int {
+	flags := flag.NewFlagSet("compare", flag.ExitOnError)
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "usage: compare <frm> <sql file>")
+		return 2
+	}
+	result, err := frm.ParseFile(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 2
+	}
+	mt, ok := result.(*table.MySQLTable)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: %s is not a table\n", flags.Arg(0))
+		return 2
+	}
+	sql, err := os.ReadFile(flags.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 2
+	}
+	diffs, err := ddl.Compare(mt, string(sql))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 2
+	}
+	for _, diff := range diffs {
+		fmt.Println(diff.String())
+	}
+	if len(diffs) != 0 {
+		return 1
+	}
+	return 0
+}
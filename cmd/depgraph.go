@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/zing22845/go-frm-parser/frm/depgraph"
+)
+
+// runViewDeps prints the restore order of the views of a datadir, it exits
+// with 1 when views are in cycles or reference missing objects
+func runViewDeps(args []string) int {
+	flags := flag.NewFlagSet("viewdeps", flag.ExitOnError)
+	format := flags.String("format", "text", "output format: text or json")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: viewdeps [-format text|json] <datadir>")
+		return 2
+	}
+	g, errs, err := depgraph.Build(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 2
+	}
+	report := depgraph.NewReport(flags.Arg(0), g, errs)
+	switch *format {
+	case "json":
+		err = report.WriteJSON(os.Stdout)
+	case "text":
+		fmt.Print(report.String())
+	default:
+		err = fmt.Errorf("unknown output format %q", *format)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 2
+	}
+	if report.HasProblems() {
+		return 1
+	}
+	return 0
+}
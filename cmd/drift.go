@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/zing22845/go-frm-parser/frm/drift"
+)
+
+// runDrift reports the schema drift between two datadirs or backup
+// archives, it exits with 1 when they differ
+func runDrift(args []string) int {
+	flags := flag.NewFlagSet("drift", flag.ExitOnError)
+	format := flags.String("format", "markdown", "output format: markdown or json")
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "usage: drift [-format markdown|json] <old> <new>")
+		return 2
+	}
+	old, err := drift.Load(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 2
+	}
+	new, err := drift.Load(flags.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 2
+	}
+	report := drift.Compare(old, new)
+	switch *format {
+	case "json":
+		err = report.WriteJSON(os.Stdout)
+	case "markdown":
+		fmt.Print(report.Markdown())
+	default:
+		err = fmt.Errorf("unknown output format %q", *format)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 2
+	}
+	if len(report.Changes) != 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/zing22845/go-frm-parser/frm/inventory"
+)
+
+// runInventory reports the counts and statistics of the tables and views
+// of a datadir
+func runInventory(args []string) int {
+	flags := flag.NewFlagSet("inventory", flag.ExitOnError)
+	format := flags.String("format", "text", "output format: text or json")
+	top := flags.Int("top", inventory.DEFAULT_TOP, "number of widest and largest-key tables listed")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: inventory [-format text|json] [-top n] <datadir>")
+		return 2
+	}
+	report, err := inventory.Build(flags.Arg(0), *top)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 2
+	}
+	switch *format {
+	case "json":
+		err = report.WriteJSON(os.Stdout)
+	case "text":
+		fmt.Print(report.String())
+	default:
+		err = fmt.Errorf("unknown output format %q", *format)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 2
+	}
+	return 0
+}
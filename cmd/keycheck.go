@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/zing22845/go-frm-parser/frm"
+	"github.com/zing22845/go-frm-parser/frm/keycheck"
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+// runKeyCheck reports the duplicate and redundant indexes of the tables of
+// the files and directories, it exits with 1 when there are findings
+func runKeyCheck(args []string) int {
+	flags := flag.NewFlagSet("dupkeys", flag.ExitOnError)
+	format := flags.String("format", "text", "output format: text or json")
+	flags.Parse(args)
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: unknown output format %q\n", *format)
+		return 2
+	}
+
+	findings := []*keycheck.Finding{}
+	status := 0
+	for _, path := range tableFiles(flags.Args()) {
+		schema, err := frm.ParseFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			status = 2
+			continue
+		}
+		mt, ok := schema.(*table.MySQLTable)
+		if !ok {
+			continue
+		}
+		findings = append(findings, keycheck.Check(mt)...)
+	}
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(findings); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return 2
+		}
+	} else {
+		for _, finding := range findings {
+			fmt.Printf("%s\n\n", finding)
+		}
+	}
+	if status == 0 && len(findings) != 0 {
+		status = 1
+	}
+	return status
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zing22845/go-frm-parser/frm"
+	"github.com/zing22845/go-frm-parser/frm/lint"
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+// runLint lints the tables of the files and directories, it exits with 1
+// when there are findings
+func runLint(args []string) int {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	format := flags.String("format", lint.FORMAT_TEXT, "output format: text, json or sarif")
+	enable := flags.String("enable", "", "comma separated rules to run, all if empty")
+	disable := flags.String("disable", "", "comma separated rules to skip")
+	maxVarchar := flags.Int("max-varchar", lint.DEFAULT_MAX_VARCHAR_LENGTH, "longest VARCHAR accepted, in characters")
+	flags.Parse(args)
+
+	rules := lint.DefaultRules()
+	for i, rule := range rules {
+		if rule.ID == lint.RULE_OVERSIZED_VARCHAR {
+			rules[i] = lint.OversizedVarchar(*maxVarchar)
+		}
+	}
+	linter := lint.NewLinter(rules...)
+	if *enable != "" {
+		linter.EnableOnly(splitList(*enable)...)
+	}
+	linter.Disable(splitList(*disable)...)
+	for _, id := range append(splitList(*enable), splitList(*disable)...) {
+		if linter.Rule(id) == nil {
+			fmt.Fprintln(os.Stderr, "Error: unknown rule", id)
+			return 2
+		}
+	}
+
+	var findings []*lint.Finding
+	status := 0
+	for _, path := range tableFiles(flags.Args()) {
+		schema, err := frm.ParseFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			status = 2
+			continue
+		}
+		mt, ok := schema.(*table.MySQLTable)
+		if !ok {
+			continue
+		}
+		findings = append(findings, linter.Lint(path, mt)...)
+	}
+	lint.Sort(findings)
+	err := linter.Write(os.Stdout, *format, findings)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 2
+	}
+	if status == 0 && len(findings) != 0 {
+		status = 1
+	}
+	return status
+}
+
+// tableFiles expands the directories of paths to the .frm and .sdi files
+// under them
+func tableFiles(paths []string) (files []string) {
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			switch strings.ToLower(filepath.Ext(p)) {
+			case ".frm", ".sdi":
+				files = append(files, p)
+			}
+			return nil
+		})
+	}
+	return files
+}
+
+func splitList(s string) (items []string) {
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -1,25 +1,42 @@
 package main
 
 import (
-	"bytes"
+	"flag"
 	"fmt"
 	"os"
 
 	"github.com/zing22845/go-frm-parser/frm"
+	"github.com/zing22845/go-frm-parser/frm/ibd"
 )
 
 func main() {
-	path := os.Args[1]
-	file, err := os.ReadFile(path)
-	if err != nil {
-		fmt.Println("Error:", err)
-		return
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "rowsize":
+			os.Exit(runRowSize(os.Args[2:]))
+		case "lint":
+			os.Exit(runLint(os.Args[2:]))
+		case "upgrade":
+			os.Exit(runUpgrade(os.Args[2:]))
+		case "manifest":
+			os.Exit(runManifest(os.Args[2:]))
+		case "drift":
+			os.Exit(runDrift(os.Args[2:]))
+		case "compare":
+			os.Exit(runCompare(os.Args[2:]))
+		case "dupkeys":
+			os.Exit(runKeyCheck(os.Args[2:]))
+		case "inventory":
+			os.Exit(runInventory(os.Args[2:]))
+		case "viewdeps":
+			os.Exit(runViewDeps(os.Args[2:]))
+		case "viewmd5":
+			os.Exit(runViewMD5(os.Args[2:]))
+		}
 	}
-
-	buf := bytes.NewBuffer(file)
-
+	path := os.Args[1]
 	// read and parse frm file
-	result, err := frm.ParseBuffer(path, buf)
+	result, err := frm.ParseFile(path)
 	if err != nil {
 		fmt.Println("Error:", err)
 		return
@@ -27,3 +44,28 @@ func main() {
 	fmt.Printf("====WITHOUT HEADER:\n%s", result.String())
 	fmt.Printf("\n====WITH HEADER:\n%s", result.StringWithHeader())
 }
+
+// runRowSize checks the row size of the tables, it exits with 1 when one is
+// too large
+func runRowSize(args []string) int {
+	opts := &ibd.RowSizeOptions{}
+	flags := flag.NewFlagSet("rowsize", flag.ExitOnError)
+	pageSize := flags.Uint("page-size", ibd.UNIV_PAGE_SIZE_DEF, "innodb_page_size")
+	flags.StringVar(&opts.DefaultRowFormat, "default-row-format", "DYNAMIC", "innodb_default_row_format")
+	flags.Parse(args)
+	opts.PageSize = uint32(*pageSize)
+	status := 0
+	for _, path := range flags.Args() {
+		report, err := frm.CheckRowSize(path, opts)
+		if err != nil {
+			fmt.Println("Error:", err)
+			status = 2
+			continue
+		}
+		fmt.Println(report.String())
+		if report.TooLarge() && status == 0 {
+			status = 1
+		}
+	}
+	return status
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zing22845/go-frm-parser/frm/manifest"
+)
+
+// runManifest prints the schema manifest of a datadir or of a .sql file
+// of CREATE statements, or with -diff compares two datadirs, .sql files or
+// JSON manifests, exiting with 1 when they differ
+func runManifest(args []string) int {
+	flags := flag.NewFlagSet("manifest", flag.ExitOnError)
+	format := flags.String("format", "text", "output format: text or json")
+	canonical := flags.Bool("canonical", false, "include the canonical forms in the JSON output")
+	diff := flags.Bool("diff", false, "compare two datadirs, .sql files or JSON manifests")
+	sqlOptions := &manifest.SQLOptions{}
+	flags.StringVar(&sqlOptions.Schema, "schema", "", "schema of the .sql statements before any USE statement")
+	flags.BoolVar(&sqlOptions.SkipForeignKeys, "skip-foreign-keys", false,
+		"leave the foreign keys of .sql files out, the .frm files of MySQL 5.x don't record them")
+	flags.Parse(args)
+	if *diff {
+		if flags.NArg() != 2 {
+			fmt.Fprintln(os.Stderr, "usage: manifest -diff <old> <new>")
+			return 2
+		}
+		return diffManifests(flags.Arg(0), flags.Arg(1), sqlOptions)
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: manifest [-format text|json] [-canonical] <datadir>|<file.sql>")
+		return 2
+	}
+	m, err := buildManifest(flags.Arg(0), sqlOptions)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 2
+	}
+	for _, message := range m.Errors {
+		fmt.Fprintln(os.Stderr, "Error:", message)
+	}
+	switch *format {
+	case "json":
+		err = m.WriteJSON(os.Stdout, *canonical)
+	case "text":
+		err = m.WriteText(os.Stdout)
+	default:
+		err = fmt.Errorf("unknown output format %q", *format)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 2
+	}
+	return 0
+}
+
+func diffManifests(oldPath, newPath string, sqlOptions *manifest.SQLOptions) int {
+	old, err := loadManifest(oldPath, sqlOptions)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 2
+	}
+	new, err := loadManifest(newPath, sqlOptions)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 2
+	}
+	diffs := manifest.Compare(old, new)
+	for _, d := range diffs {
+		fmt.Printf("%-8s %s `%s`.`%s`\n", d.Status, d.Kind, d.Schema, d.Name)
+	}
+	if len(diffs) != 0 {
+		return 1
+	}
+	return 0
+}
+
+// buildManifest builds the manifest of a datadir or of a .sql file
+func buildManifest(path string, sqlOptions *manifest.SQLOptions) (*manifest.Manifest, error) {
+	if !strings.EqualFold(filepath.Ext(path), ".sql") {
+		return manifest.Build(path)
+	}
+	sql, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return manifest.BuildFromSQL(path, string(sql), sqlOptions)
+}
+
+// loadManifest builds the manifest of a datadir or of a .sql file, or
+// reads a JSON manifest
+func loadManifest(path string, sqlOptions *manifest.SQLOptions) (*manifest.Manifest, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() || strings.EqualFold(filepath.Ext(path), ".sql") {
+		return buildManifest(path, sqlOptions)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return manifest.ReadJSON(file)
+}
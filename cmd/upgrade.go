@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/zing22845/go-frm-parser/frm/upgrade"
+)
+
+// runUpgrade checks a 5.7 datadir for the upgrade to 8.0, it exits with 1
+// when errors were found
+func runUpgrade(args []string) int {
+	flags := flag.NewFlagSet("upgrade", flag.ExitOnError)
+	format := flags.String("format", "text", "output format: text or json")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: upgrade [-format text|json] <datadir>")
+		return 2
+	}
+	report, err := upgrade.CheckDatadir(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 2
+	}
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	case "text":
+		fmt.Print(report.String())
+	default:
+		err = fmt.Errorf("unknown output format %q", *format)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 2
+	}
+	if !report.Ready() {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/zing22845/go-frm-parser/frm"
+	"github.com/zing22845/go-frm-parser/frm/view"
+)
+
+// runViewMD5 checks the md5 of the view files, it exits with 1 when one
+// doesn't match its query, with -mode repair the md5 is rewritten
+func runViewMD5(args []string) int {
+	flags := flag.NewFlagSet("viewmd5", flag.ExitOnError)
+	mode := flags.String("mode", "warn", "on mismatch: warn, strict or repair")
+	flags.Parse(args)
+	opts := &view.ParseOptions{}
+	switch *mode {
+	case "warn":
+		opts.MD5Mode = view.MD5_WARN
+	case "strict":
+		opts.MD5Mode = view.MD5_STRICT
+	case "repair":
+		opts.MD5Mode = view.MD5_REPAIR
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown mode %q\n", *mode)
+		return 2
+	}
+
+	status := 0
+	for _, path := range tableFiles(flags.Args()) {
+		schema, err := frm.ParseFileWithOptions(path, opts)
+		switch {
+		case errors.Is(err, view.ErrMD5Mismatch):
+			fmt.Printf("%s: %v\n", path, err)
+			status = max(status, 1)
+			continue
+		case err != nil:
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			status = 2
+			continue
+		}
+		v, ok := schema.(*view.MySQLView)
+		if !ok {
+			continue
+		}
+		for _, warning := range v.Warnings {
+			fmt.Printf("%s: %s\n", path, warning)
+		}
+		if len(v.Warnings) != 0 {
+			status = max(status, 1)
+		}
+	}
+	return status
+}
@@ -0,0 +1,209 @@
+package archive
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zing22845/go-frm-parser/frm/sdi"
+	"github.com/zing22845/go-frm-parser/frm/table"
+	"github.com/zing22845/go-frm-parser/frm/utils"
+)
+
+// .ARZ header layout, azio.h
+const (
+	AZ_MAGIC              = 0xfe
+	ARZ_VERSION           = 3
+	AZHEADER_SIZE         = 29
+	AZMETA_BUFFER_SIZE    = 4*8 + 4*4 + 1
+	AZ_MAGIC_POS          = 0
+	AZ_VERSION_POS        = 1
+	AZ_MINOR_VERSION_POS  = 2
+	AZ_BLOCK_POS          = 3
+	AZ_STRATEGY_POS       = 4
+	AZ_FRM_POS            = 5
+	AZ_FRM_LENGTH_POS     = 9
+	AZ_META_POS           = 13
+	AZ_META_LENGTH_POS    = 17
+	AZ_START_POS          = 21
+	AZ_ROW_POS            = 29
+	AZ_FLUSH_POS          = 37
+	AZ_CHECK_POS          = 45
+	AZ_AUTOINCREMENT_POS  = 53
+	AZ_LONGEST_POS        = 61
+	AZ_SHORTEST_POS       = 65
+	AZ_COMMENT_POS        = 69
+	AZ_COMMENT_LENGTH_POS = 73
+	AZ_DIRTY_POS          = 77
+)
+
+// Header is the header of an ARCHIVE engine .ARZ data file
+type Header struct {
+	Version       uint8
+	MinorVersion  uint8
+	BlockSize     uint32
+	FRMStart      uint32
+	FRMLength     uint32
+	CommentStart  uint32
+	CommentLength uint32
+	// Start is the offset of the compressed rows
+	Start         uint64
+	Rows          uint64
+	ForcedFlushes uint64
+	CheckPoint    uint64
+	// AutoIncrement is the last used value, the next one is AutoIncrement+1
+	AutoIncrement uint64
+	LongestRow    uint32
+	ShortestRow   uint32
+	// Dirty is set if the file was not closed cleanly
+	Dirty bool
+}
+
+// ReadHeader reads the header of a .ARZ file, only the version 3 format
+// of MySQL 5.1 and later embeds the .frm
+func ReadHeader(r io.ReaderAt) (*Header, error) {
+	buf := make([]byte, AZHEADER_SIZE+AZMETA_BUFFER_SIZE)
+	_, err := r.ReadAt(buf, 0)
+	if err != nil {
+		return nil, fmt.Errorf("read ARZ header failed: %w", err)
+	}
+	if buf[AZ_MAGIC_POS] != AZ_MAGIC {
+		return nil, fmt.Errorf("invalid ARZ magic 0x%02x", buf[AZ_MAGIC_POS])
+	}
+	if buf[AZ_VERSION_POS] != ARZ_VERSION {
+		return nil, fmt.Errorf("unsupported ARZ version %d", buf[AZ_VERSION_POS])
+	}
+	h := &Header{
+		Version:       buf[AZ_VERSION_POS],
+		MinorVersion:  buf[AZ_MINOR_VERSION_POS],
+		BlockSize:     uint32(buf[AZ_BLOCK_POS]) * 1024,
+		FRMStart:      binary.LittleEndian.Uint32(buf[AZ_FRM_POS:]),
+		FRMLength:     binary.LittleEndian.Uint32(buf[AZ_FRM_LENGTH_POS:]),
+		CommentStart:  binary.LittleEndian.Uint32(buf[AZ_COMMENT_POS:]),
+		CommentLength: binary.LittleEndian.Uint32(buf[AZ_COMMENT_LENGTH_POS:]),
+		Start:         binary.LittleEndian.Uint64(buf[AZ_START_POS:]),
+		Rows:          binary.LittleEndian.Uint64(buf[AZ_ROW_POS:]),
+		ForcedFlushes: binary.LittleEndian.Uint64(buf[AZ_FLUSH_POS:]),
+		CheckPoint:    binary.LittleEndian.Uint64(buf[AZ_CHECK_POS:]),
+		AutoIncrement: binary.LittleEndian.Uint64(buf[AZ_AUTOINCREMENT_POS:]),
+		LongestRow:    binary.LittleEndian.Uint32(buf[AZ_LONGEST_POS:]),
+		ShortestRow:   binary.LittleEndian.Uint32(buf[AZ_SHORTEST_POS:]),
+		Dirty:         buf[AZ_DIRTY_POS] != 0,
+	}
+	return h, nil
+}
+
+// ReadFRM reads the table definition embedded in the .ARZ file,
+// the .frm image, or the SDI JSON for MySQL 8.0
+func (h *Header) ReadFRM(r io.ReaderAt) ([]byte, error) {
+	if h.FRMLength == 0 {
+		return nil, fmt.Errorf("no table definition embedded in the ARZ file")
+	}
+	data := make([]byte, h.FRMLength)
+	_, err := r.ReadAt(data, int64(h.FRMStart))
+	if err != nil {
+		return nil, fmt.Errorf("read embedded frm failed: %w", err)
+	}
+	return data, nil
+}
+
+// ReadComment reads the table comment kept in the .ARZ file
+func (h *Header) ReadComment(r io.ReaderAt) (string, error) {
+	if h.CommentLength == 0 {
+		return "", nil
+	}
+	data := make([]byte, h.CommentLength)
+	_, err := r.ReadAt(data, int64(h.CommentStart))
+	if err != nil {
+		return "", fmt.Errorf("read comment failed: %w", err)
+	}
+	return string(data), nil
+}
+
+// Apply sets the AUTO_INCREMENT option and the row count of mt from the
+// headers of its .ARZ files, one per partition for partitioned tables
+func Apply(mt *table.MySQLTable, headers ...*Header) {
+	if len(headers) == 0 {
+		return
+	}
+	stats := &table.Statistics{}
+	var autoIncrement uint64
+	for _, h := range headers {
+		stats.Rows += h.Rows
+		if h.AutoIncrement > autoIncrement {
+			autoIncrement = h.AutoIncrement
+		}
+	}
+	mt.Statistics = stats
+	mt.Options.AutoIncrement = autoIncrement + 1
+}
+
+// ResolveHeader reads the .ARZ files of the table, or of all its
+// (sub)partitions, in dir and applies them to mt. Missing files are
+// skipped, found is false if there was none
+func ResolveHeader(dir string, mt *table.MySQLTable) (found bool, err error) {
+	stems := []string{utils.EncodeMySQLObject2File(mt.Name)}
+	if mt.Options.Partitioning != nil {
+		stems = mt.Options.Partitioning.FileNames(mt.Name)
+	}
+	headers := make([]*Header, 0, len(stems))
+	for _, stem := range stems {
+		path := filepath.Join(dir, stem+".ARZ")
+		file, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		h, err := ReadHeader(file)
+		file.Close()
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", path, err)
+		}
+		headers = append(headers, h)
+	}
+	if len(headers) == 0 {
+		return false, nil
+	}
+	Apply(mt, headers...)
+	return true, nil
+}
+
+// Parse rebuilds the table from the definition embedded in the .ARZ file
+// at path, the table name is taken from the file name
+func Parse(path string, r io.ReaderAt) (*table.MySQLTable, *Header, error) {
+	h, err := ReadHeader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	data, err := h.ReadFRM(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	var mt *table.MySQLTable
+	if len(data) > 0 && data[0] == '{' {
+		mt, err = sdi.Decode(path, data)
+	} else {
+		frmPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".frm"
+		mt, err = table.Parse(frmPath, data)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	Apply(mt, h)
+	return mt, h, nil
+}
+
+// ParseFile parses the .ARZ file at path
+func ParseFile(path string) (*table.MySQLTable, *Header, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	return Parse(path, file)
+}
@@ -0,0 +1,160 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+// newARZ returns a version 3 .ARZ file embedding frm and comment, the
+// compressed rows are left out
+func newARZ(frm []byte, comment string, rows, autoIncrement uint64) []byte {
+	buf := make([]byte, AZHEADER_SIZE+AZMETA_BUFFER_SIZE)
+	buf[AZ_MAGIC_POS] = AZ_MAGIC
+	buf[AZ_VERSION_POS] = ARZ_VERSION
+	buf[AZ_MINOR_VERSION_POS] = 3
+	buf[AZ_BLOCK_POS] = 16
+	binary.LittleEndian.PutUint32(buf[AZ_FRM_POS:], uint32(len(buf)))
+	binary.LittleEndian.PutUint32(buf[AZ_FRM_LENGTH_POS:], uint32(len(frm)))
+	binary.LittleEndian.PutUint32(buf[AZ_COMMENT_POS:], uint32(len(buf)+len(frm)))
+	binary.LittleEndian.PutUint32(buf[AZ_COMMENT_LENGTH_POS:], uint32(len(comment)))
+	binary.LittleEndian.PutUint64(buf[AZ_START_POS:], uint64(len(buf)+len(frm)+len(comment)))
+	binary.LittleEndian.PutUint64(buf[AZ_ROW_POS:], rows)
+	binary.LittleEndian.PutUint64(buf[AZ_AUTOINCREMENT_POS:], autoIncrement)
+	binary.LittleEndian.PutUint32(buf[AZ_LONGEST_POS:], 40)
+	binary.LittleEndian.PutUint32(buf[AZ_SHORTEST_POS:], 12)
+	buf[AZ_DIRTY_POS] = 1
+	buf = append(buf, frm...)
+	return append(buf, comment...)
+}
+
+func readFRM(t *testing.T) []byte {
+	t.Helper()
+	data, err := os.ReadFile("../../test_frms/table_simple.frm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestReadHeader(t *testing.T) {
+	frm := readFRM(t)
+	arz := newARZ(frm, "archived orders", 42, 99)
+	r := bytes.NewReader(arz)
+	h, err := ReadHeader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Header{
+		Version:       3,
+		MinorVersion:  3,
+		BlockSize:     16 * 1024,
+		FRMStart:      AZHEADER_SIZE + AZMETA_BUFFER_SIZE,
+		FRMLength:     uint32(len(frm)),
+		CommentStart:  AZHEADER_SIZE + AZMETA_BUFFER_SIZE + uint32(len(frm)),
+		CommentLength: 15,
+		Start:         uint64(len(arz)),
+		Rows:          42,
+		AutoIncrement: 99,
+		LongestRow:    40,
+		ShortestRow:   12,
+		Dirty:         true,
+	}
+	if *h != want {
+		t.Errorf("got %+v, want %+v", *h, want)
+	}
+	data, err := h.ReadFRM(r)
+	if err != nil || !bytes.Equal(data, frm) {
+		t.Errorf("embedded frm of %d bytes, %v", len(data), err)
+	}
+	comment, err := h.ReadComment(r)
+	if err != nil || comment != "archived orders" {
+		t.Errorf("comment %q, %v", comment, err)
+	}
+}
+
+func TestReadHeaderErrors(t *testing.T) {
+	badMagic := newARZ(nil, "", 0, 0)
+	badMagic[AZ_MAGIC_POS] = 0x1f
+	version1 := newARZ(nil, "", 0, 0)
+	version1[AZ_VERSION_POS] = 1
+	for _, c := range []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"magic", badMagic, "invalid ARZ magic 0x1f"},
+		{"version", version1, "unsupported ARZ version 1"},
+		{"truncated", newARZ(nil, "", 0, 0)[:AZHEADER_SIZE], "read ARZ header failed"},
+	} {
+		_, err := ReadHeader(bytes.NewReader(c.data))
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: error %v, want %q", c.name, err, c.want)
+		}
+	}
+
+	// a header without an embedded definition, or with a truncated one
+	h, err := ReadHeader(bytes.NewReader(newARZ(nil, "", 0, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = h.ReadFRM(bytes.NewReader(nil)); err == nil {
+		t.Error("read a definition from a header without one")
+	}
+	h.FRMLength = 100
+	if _, err = h.ReadFRM(bytes.NewReader(newARZ(nil, "", 0, 0))); err == nil {
+		t.Error("read a truncated definition")
+	}
+}
+
+func TestParse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "t1.ARZ")
+	if err := os.WriteFile(path, newARZ(readFRM(t), "", 42, 99), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mt, h, err := ParseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mt.Name != "t1" || h.Rows != 42 {
+		t.Errorf("table %s, rows %d", mt.Name, h.Rows)
+	}
+	if mt.Options.AutoIncrement != 100 || mt.Statistics == nil || mt.Statistics.Rows != 42 {
+		t.Errorf("AUTO_INCREMENT %d, statistics %+v", mt.Options.AutoIncrement, mt.Statistics)
+	}
+}
+
+func TestResolveHeader(t *testing.T) {
+	dir := t.TempDir()
+	mt := &table.MySQLTable{Name: "t1", Options: &table.Options{
+		Partitions: " PARTITION BY HASH (id)\nPARTITIONS 2",
+	}}
+	if err := mt.Options.DecodePartitioning(); err != nil {
+		t.Fatal(err)
+	}
+	found, err := ResolveHeader(dir, mt)
+	if found || err != nil {
+		t.Fatalf("no .ARZ files: found %v, %v", found, err)
+	}
+	// every partition has its own .ARZ file
+	for stem, arz := range map[string][]byte{
+		"t1#P#p0": newARZ(nil, "", 10, 7),
+		"t1#P#p1": newARZ(nil, "", 5, 12),
+	} {
+		if err := os.WriteFile(filepath.Join(dir, stem+".ARZ"), arz, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	found, err = ResolveHeader(dir, mt)
+	if !found || err != nil {
+		t.Fatalf("found %v, %v", found, err)
+	}
+	if mt.Options.AutoIncrement != 13 || mt.Statistics.Rows != 15 {
+		t.Errorf("AUTO_INCREMENT %d, rows %d", mt.Options.AutoIncrement, mt.Statistics.Rows)
+	}
+}
@@ -0,0 +1,138 @@
+package frm
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zing22845/go-frm-parser/frm/utils"
+)
+
+// Datadir is the listing of a MySQL datadir
+type Datadir struct {
+	Path string
+	// Files are the files at the top of the datadir
+	Files []fs.DirEntry
+	// Schemas are the database directories
+	Schemas []*SchemaDir
+}
+
+// SchemaDir is the database directory of a schema
+type SchemaDir struct {
+	// Schema is the schema name decoded from the directory name
+	Schema string
+	Path   string
+	// Files are the files of the directory but the temporary ones
+	Files []fs.DirEntry
+	// Temporary are the #sql files left by interrupted DDL statements,
+	// they aren't definitions of the schema
+	Temporary []fs.DirEntry
+}
+
+// DecodeSchemaName is the schema name of a database directory, the name
+// is kept as is when it isn't in the MySQL file name encoding
+func DecodeSchemaName(dirName string) string {
+	schema, err := utils.DecodeMySQLFile2Object(dirName)
+	if err != nil {
+		return dirName
+	}
+	return schema
+}
+
+// IsTemporary reports whether the file is a temporary #sql file
+func IsTemporary(name string) bool {
+	return strings.HasPrefix(name, "#sql")
+}
+
+// IsSchemaFile reports whether the file is a db.opt or defines a schema
+// object, directories without one are not schemas
+func IsSchemaFile(name string) bool {
+	if IsTemporary(name) {
+		return false
+	}
+	if name == "db.opt" {
+		return true
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".frm", ".trg", ".sdi":
+		return true
+	}
+	return false
+}
+
+// IsSchema reports whether the directory holds a db.opt or a definition,
+// directories such as #innodb_temp or lost+found are not schemas
+func (sd *SchemaDir) IsSchema() bool {
+	for _, file := range sd.Files {
+		if IsSchemaFile(file.Name()) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadDatadir lists the database directories of the datadir and their files
+func ReadDatadir(datadir string) (*Datadir, error) {
+	entries, err := os.ReadDir(datadir)
+	if err != nil {
+		return nil, err
+	}
+	d := &Datadir{Path: datadir}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			d.Files = append(d.Files, entry)
+			continue
+		}
+		sd := &SchemaDir{
+			Schema: DecodeSchemaName(entry.Name()),
+			Path:   filepath.Join(datadir, entry.Name()),
+		}
+		files, err := os.ReadDir(sd.Path)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			switch {
+			case file.IsDir():
+			case IsTemporary(file.Name()):
+				sd.Temporary = append(sd.Temporary, file)
+			default:
+				sd.Files = append(sd.Files, file)
+			}
+		}
+		d.Schemas = append(d.Schemas, sd)
+	}
+	return d, nil
+}
+
+// Walk parses the .frm files of the database directory in name order, fn
+// gets the error of a file that doesn't parse and stops the walk by
+// returning an error
+func (sd *SchemaDir) Walk(fn func(path string, s MySQLSchema, err error) error) error {
+	for _, file := range sd.Files {
+		if !strings.EqualFold(filepath.Ext(file.Name()), ".frm") {
+			continue
+		}
+		path := filepath.Join(sd.Path, file.Name())
+		s, err := ParseFile(path)
+		if err = fn(path, s, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Walk parses the .frm files of every database directory, fn gets the
+// schema directory of each
+func (d *Datadir) Walk(fn func(sd *SchemaDir, path string, s MySQLSchema, err error) error) error {
+	for _, sd := range d.Schemas {
+		err := sd.Walk(func(path string, s MySQLSchema, err error) error {
+			return fn(sd, path, s, err)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
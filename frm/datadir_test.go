@@ -0,0 +1,78 @@
+package frm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadDatadir(t *testing.T) {
+	datadir := t.TempDir()
+	simple, err := os.ReadFile("../test_frms/table_simple.frm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{
+		"ibdata1":              nil,
+		"my@002ddb/t1.frm":     simple,
+		"my@002ddb/t1.ibd":     nil,
+		"my@002ddb/#sql-1.frm": simple,
+		"my@002ddb/broken.frm": []byte("garbage!!"),
+		"my@002ddb/db.opt":     nil,
+		"plain/sub/nested.frm": simple,
+		"bad@zzzzname/db.opt":  nil,
+	}
+	for name, data := range files {
+		path := filepath.Join(datadir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d, err := ReadDatadir(datadir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Files) != 1 || d.Files[0].Name() != "ibdata1" {
+		t.Errorf("top files %v", d.Files)
+	}
+	var schemas []string
+	for _, sd := range d.Schemas {
+		schemas = append(schemas, sd.Schema)
+	}
+	if len(schemas) != 3 || schemas[0] != "bad@zzzzname" || schemas[1] != "my-db" || schemas[2] != "plain" {
+		t.Fatalf("schemas %q", schemas)
+	}
+	// plain holds a nested directory only
+	if !d.Schemas[0].IsSchema() || !d.Schemas[1].IsSchema() || d.Schemas[2].IsSchema() {
+		t.Errorf("schema directories %v, %v, %v", d.Schemas[0].IsSchema(), d.Schemas[1].IsSchema(), d.Schemas[2].IsSchema())
+	}
+	sd := d.Schemas[1]
+	if len(sd.Temporary) != 1 || sd.Temporary[0].Name() != "#sql-1.frm" {
+		t.Errorf("temporary files %v", sd.Temporary)
+	}
+	if len(sd.Files) != 4 {
+		t.Errorf("files %v", sd.Files)
+	}
+
+	parsed := make(map[string]error)
+	err = d.Walk(func(sd *SchemaDir, path string, s MySQLSchema, err error) error {
+		parsed[sd.Schema+"/"+filepath.Base(path)] = err
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != 2 {
+		t.Fatalf("walked %v", parsed)
+	}
+	if err := parsed["my-db/t1.frm"]; err != nil {
+		t.Errorf("t1.frm: %v", err)
+	}
+	if parsed["my-db/broken.frm"] == nil {
+		t.Error("broken.frm parsed")
+	}
+}
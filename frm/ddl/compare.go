@@ -0,0 +1,255 @@
+package ddl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/tidb/pkg/parser"
+	"github.com/pingcap/tidb/pkg/parser/ast"
+	_ "github.com/pingcap/tidb/pkg/parser/test_driver"
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+// ObjectType is the kind of table element a difference is about
+type ObjectType string
+
+const (
+	OBJECT_TABLE       ObjectType = "table"
+	OBJECT_COLUMN      ObjectType = "column"
+	OBJECT_KEY         ObjectType = "key"
+	OBJECT_FOREIGN_KEY ObjectType = "foreign key"
+	OBJECT_OPTION      ObjectType = "option"
+)
+
+// Difference is one real difference between the .frm and the SQL, FRM or
+// SQL is empty when the element exists only on the other side
+type Difference struct {
+	Object    ObjectType `json:"object"`
+	Name      string     `json:"name"`
+	Attribute string     `json:"attribute,omitempty"`
+	FRM       string     `json:"frm"`
+	SQL       string     `json:"sql"`
+}
+
+func (d *Difference) String() string {
+	subject := string(d.Object)
+	if d.Name != "" {
+		subject += fmt.Sprintf(" `%s`", d.Name)
+	}
+	switch {
+	case d.Attribute == "" && d.SQL == "":
+		return fmt.Sprintf("%s only in .frm: %s", subject, d.FRM)
+	case d.Attribute == "" && d.FRM == "":
+		return fmt.Sprintf("%s only in SQL: %s", subject, d.SQL)
+	}
+	return fmt.Sprintf("%s %s differs: .frm %s, SQL %s", subject, d.Attribute, display(d.FRM), display(d.SQL))
+}
+
+func display(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
+
+// Compare parses the CREATE TABLE statement in sql with the TiDB parser and
+// compares it with the table parsed from the .frm. Formatting, implicit
+// defaults, charset aliases and integer display widths are not reported, a
+// nil slice means the definitions match
+func Compare(mt *table.MySQLTable, sql string) ([]*Difference, error) {
+	stmt, err := parseCreateTable(mt.Name, sql)
+	if err != nil {
+		return nil, err
+	}
+	sqlDef, err := fromSQL(stmt)
+	if err != nil {
+		return nil, err
+	}
+	return compareTables(fromFRM(mt), sqlDef), nil
+}
+
+// parseCreateTable returns the CREATE TABLE statement of the table in sql,
+// or the only one if none is named so
+func parseCreateTable(name, sql string) (*ast.CreateTableStmt, error) {
+	stmts, _, err := parser.New().Parse(sql, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("parse SQL: %w", err)
+	}
+	var creates []*ast.CreateTableStmt
+	for _, stmt := range stmts {
+		if create, ok := stmt.(*ast.CreateTableStmt); ok {
+			if strings.EqualFold(create.Table.Name.O, name) {
+				return create, nil
+			}
+			creates = append(creates, create)
+		}
+	}
+	switch len(creates) {
+	case 0:
+		return nil, fmt.Errorf("no CREATE TABLE statement in SQL")
+	case 1:
+		return creates[0], nil
+	}
+	return nil, fmt.Errorf("no CREATE TABLE statement for table %s in SQL", name)
+}
+
+func compareTables(frm, sql *tableDef) (diffs []*Difference) {
+	diffs = append(diffs, compareColumns(frm, sql)...)
+	diffs = append(diffs, compareKeys(frm, sql)...)
+	if frm.foreignKeys != nil {
+		diffs = append(diffs, compareForeignKeys(frm, sql)...)
+	}
+	diffs = append(diffs, compareOptions(frm, sql)...)
+	return diffs
+}
+
+func compareColumns(frm, sql *tableDef) (diffs []*Difference) {
+	var frmOrder, sqlOrder []string
+	for _, fc := range frm.columns {
+		sc := sql.column(fc.name)
+		if sc == nil {
+			diffs = append(diffs, &Difference{Object: OBJECT_COLUMN, Name: fc.name, FRM: fc.typ})
+			continue
+		}
+		frmOrder = append(frmOrder, fc.name)
+		diffs = append(diffs, compareColumn(fc, sc)...)
+	}
+	for _, sc := range sql.columns {
+		if frm.column(sc.name) == nil {
+			diffs = append(diffs, &Difference{Object: OBJECT_COLUMN, Name: sc.name, SQL: sc.typ})
+			continue
+		}
+		sqlOrder = append(sqlOrder, frm.column(sc.name).name)
+	}
+	if strings.Join(frmOrder, ",") != strings.Join(sqlOrder, ",") {
+		diffs = append(diffs, &Difference{
+			Object:    OBJECT_TABLE,
+			Name:      frm.name,
+			Attribute: "column order",
+			FRM:       strings.Join(frmOrder, ", "),
+			SQL:       strings.Join(sqlOrder, ", "),
+		})
+	}
+	return diffs
+}
+
+func compareColumn(frm, sql *columnDef) (diffs []*Difference) {
+	differ := func(attribute, frmValue, sqlValue string) {
+		if frmValue != sqlValue {
+			diffs = append(diffs, &Difference{
+				Object: OBJECT_COLUMN, Name: frm.name, Attribute: attribute, FRM: frmValue, SQL: sqlValue,
+			})
+		}
+	}
+	differ("type", frm.typ, sql.typ)
+	if frm.collation != "" && sql.collation != "" {
+		differ("collation", frm.collation, sql.collation)
+	}
+	if sql.nullableKnown {
+		differ("nullability", nullability(frm.nullable), nullability(sql.nullable))
+	}
+	frmDefault, sqlDefault := frm.defaultValue, sql.defaultValue
+	if frmDefault == "" && frm.nullable {
+		// the .frm records no default for nullable BLOB columns
+		frmDefault = "NULL"
+	}
+	if sqlDefault == "" {
+		if sql.nullable && sql.nullableKnown || !sql.nullableKnown && frm.nullable {
+			// nullable columns default to NULL
+			sqlDefault = "NULL"
+		} else if frm.implicitDefault() {
+			sqlDefault = frmDefault
+		}
+	}
+	differ("default", frmDefault, sqlDefault)
+	sqlOnUpdate := sql.onUpdate
+	if sqlOnUpdate == "" && sql.defaultValue == "" && frm.isTimestamp() {
+		// the implicit ON UPDATE of the first TIMESTAMP
+		sqlOnUpdate = frm.onUpdate
+	}
+	differ("on update", frm.onUpdate, sqlOnUpdate)
+	differ("auto_increment", fmt.Sprint(frm.autoIncrement), fmt.Sprint(sql.autoIncrement))
+	differ("comment", frm.comment, sql.comment)
+	return diffs
+}
+
+func nullability(nullable bool) string {
+	if nullable {
+		return "NULL"
+	}
+	return "NOT NULL"
+}
+
+func compareKeys(frm, sql *tableDef) (diffs []*Difference) {
+	for _, fk := range frm.keys {
+		sk := sql.key(fk.name)
+		if sk == nil {
+			if !sql.implicitKey(fk) {
+				diffs = append(diffs, &Difference{Object: OBJECT_KEY, Name: fk.name, FRM: fk.String()})
+			}
+			continue
+		}
+		differ := func(attribute, frmValue, sqlValue string) {
+			if frmValue != sqlValue {
+				diffs = append(diffs, &Difference{
+					Object: OBJECT_KEY, Name: fk.name, Attribute: attribute, FRM: frmValue, SQL: sqlValue,
+				})
+			}
+		}
+		differ("kind", fk.kind, sk.kind)
+		differ("columns", strings.Join(fk.parts, ","), strings.Join(sk.parts, ","))
+		differ("comment", fk.comment, sk.comment)
+	}
+	for _, sk := range sql.keys {
+		if frm.key(sk.name) == nil {
+			diffs = append(diffs, &Difference{Object: OBJECT_KEY, Name: sk.name, SQL: sk.String()})
+		}
+	}
+	return diffs
+}
+
+func compareForeignKeys(frm, sql *tableDef) (diffs []*Difference) {
+	for name, definition := range frm.foreignKeys {
+		switch other, ok := sql.foreignKeys[name]; {
+		case !ok:
+			diffs = append(diffs, &Difference{Object: OBJECT_FOREIGN_KEY, Name: name, FRM: definition})
+		case other != definition:
+			diffs = append(diffs, &Difference{
+				Object: OBJECT_FOREIGN_KEY, Name: name, Attribute: "definition", FRM: definition, SQL: other,
+			})
+		}
+	}
+	for name, definition := range sql.foreignKeys {
+		if _, ok := frm.foreignKeys[name]; !ok {
+			diffs = append(diffs, &Difference{Object: OBJECT_FOREIGN_KEY, Name: name, SQL: definition})
+		}
+	}
+	sortDifferences(diffs)
+	return diffs
+}
+
+// compareOptions compares the table options the SQL states, the others
+// take server defaults which can't be known
+func compareOptions(frm, sql *tableDef) (diffs []*Difference) {
+	for _, name := range optionNames {
+		value, ok := sql.options[name]
+		if !ok {
+			continue
+		}
+		if frm.options[name] != value {
+			diffs = append(diffs, &Difference{
+				Object: OBJECT_OPTION, Name: name, Attribute: "value", FRM: frm.options[name], SQL: value,
+			})
+		}
+	}
+	if frm.partitions != sql.partitions {
+		diffs = append(diffs, &Difference{
+			Object:    OBJECT_TABLE,
+			Name:      frm.name,
+			Attribute: "partitioning",
+			FRM:       frm.partitions,
+			SQL:       sql.partitions,
+		})
+	}
+	return diffs
+}
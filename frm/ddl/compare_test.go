@@ -0,0 +1,109 @@
+package ddl
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	const hash4 = " PARTITION BY HASH (user_id)\nPARTITIONS 4"
+	const range2 = " PARTITION BY RANGE (user_id)\n(PARTITION p0 VALUES LESS THAN (10) ENGINE = InnoDB,\n" +
+		" PARTITION p1 VALUES LESS THAN MAXVALUE ENGINE = InnoDB)"
+	for _, c := range []struct {
+		name       string
+		partitions string
+		sql        string
+		// want are the object, name and attribute of the differences
+		want [][3]string
+	}{
+		{
+			name: "same",
+			sql: "CREATE TABLE `table_key_using_btree` (\n  `session_id` char(32) NOT NULL,\n" +
+				"  `user_id` int(11) NOT NULL,\n  KEY `user_idx` (`user_id`) USING BTREE\n) ENGINE=InnoDB DEFAULT CHARSET=utf8;",
+		},
+		{
+			name: "formatting, aliases and implicit defaults",
+			sql: "create table table_key_using_btree (session_id CHAR(32) not null, user_id INT not null, " +
+				"index user_idx (user_id)) engine=innodb default charset=utf8mb3",
+		},
+		{
+			name: "column type, nullability and missing key",
+			sql: "CREATE TABLE table_key_using_btree (session_id varchar(32) NOT NULL, user_id int) " +
+				"ENGINE=InnoDB DEFAULT CHARSET=utf8",
+			want: [][3]string{
+				{"column", "session_id", "type"},
+				{"column", "user_id", "nullability"},
+				{"column", "user_id", "default"},
+				{"key", "user_idx", ""},
+			},
+		},
+		{
+			name: "extra column and option",
+			sql: "CREATE TABLE table_key_using_btree (session_id char(32) NOT NULL, user_id int NOT NULL, " +
+				"x int, KEY user_idx (user_id)) ENGINE=MyISAM DEFAULT CHARSET=utf8",
+			want: [][3]string{
+				{"column", "x", ""},
+				{"option", "ENGINE", "value"},
+			},
+		},
+		{
+			name:       "same partitioning",
+			partitions: hash4,
+			sql: "CREATE TABLE table_key_using_btree (session_id char(32) NOT NULL, user_id int NOT NULL, " +
+				"KEY user_idx (user_id)) ENGINE=InnoDB DEFAULT CHARSET=utf8 /*!50100 PARTITION BY HASH(`user_id`) PARTITIONS 4 */",
+		},
+		{
+			name:       "partition count",
+			partitions: hash4,
+			sql: "CREATE TABLE table_key_using_btree (session_id char(32) NOT NULL, user_id int NOT NULL, " +
+				"KEY user_idx (user_id)) ENGINE=InnoDB DEFAULT CHARSET=utf8 PARTITION BY HASH (user_id) PARTITIONS 8",
+			want: [][3]string{{"table", "table_key_using_btree", "partitioning"}},
+		},
+		{
+			name:       "partition method",
+			partitions: hash4,
+			sql: "CREATE TABLE table_key_using_btree (session_id char(32) NOT NULL, user_id int NOT NULL, " +
+				"KEY user_idx (user_id)) ENGINE=InnoDB DEFAULT CHARSET=utf8 PARTITION BY KEY (user_id) PARTITIONS 4",
+			want: [][3]string{{"table", "table_key_using_btree", "partitioning"}},
+		},
+		{
+			name:       "partition definitions without engines",
+			partitions: range2,
+			sql: "CREATE TABLE table_key_using_btree (session_id char(32) NOT NULL, user_id int NOT NULL, " +
+				"KEY user_idx (user_id)) ENGINE=InnoDB DEFAULT CHARSET=utf8 PARTITION BY RANGE (user_id) " +
+				"(PARTITION p0 VALUES LESS THAN (10), PARTITION p1 VALUES LESS THAN (MAXVALUE))",
+		},
+		{
+			name:       "partition bound",
+			partitions: range2,
+			sql: "CREATE TABLE table_key_using_btree (session_id char(32) NOT NULL, user_id int NOT NULL, " +
+				"KEY user_idx (user_id)) ENGINE=InnoDB DEFAULT CHARSET=utf8 PARTITION BY RANGE (user_id) " +
+				"(PARTITION p0 VALUES LESS THAN (20), PARTITION p1 VALUES LESS THAN (MAXVALUE))",
+			want: [][3]string{{"table", "table_key_using_btree", "partitioning"}},
+		},
+		{
+			name:       "partitioning only in the .frm",
+			partitions: range2,
+			sql: "CREATE TABLE table_key_using_btree (session_id char(32) NOT NULL, user_id int NOT NULL, " +
+				"KEY user_idx (user_id)) ENGINE=InnoDB DEFAULT CHARSET=utf8",
+			want: [][3]string{{"table", "table_key_using_btree", "partitioning"}},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			diffs, err := Compare(parseFRM(t, "table_key_using_btree", c.partitions), c.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got [][3]string
+			for _, d := range diffs {
+				got = append(got, [3]string{string(d.Object), d.Name, d.Attribute})
+			}
+			if len(got) != len(c.want) {
+				t.Fatalf("got %v, want %v", diffs, c.want)
+
+			}
+			for i := range got {
+				if got[i] != c.want[i] {
+					t.Errorf("difference %d: got %v (%s), want %v", i, got[i], diffs[i], c.want[i])
+				}
+			}
+		})
+	}
+}
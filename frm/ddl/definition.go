@@ -0,0 +1,428 @@
+package ddl
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/tidb/pkg/parser/ast"
+	"github.com/pingcap/tidb/pkg/parser/mysql"
+	"github.com/zing22845/go-frm-parser/frm/table"
+	"github.com/zing22845/go-frm-parser/frm/utils"
+)
+
+// typeNoiseRegex matches what the .frm type name carries besides the type
+// itself
+var typeNoiseRegex = regexp.MustCompile(` (CHARACTER SET|COLLATE) \S+| NOT NULL| AUTO_INCREMENT| NULL`)
+
+// optionNames are the table options compared, in report order
+var optionNames = []string{
+	"ENGINE", "DEFAULT CHARSET", "COLLATE", "ROW_FORMAT", "KEY_BLOCK_SIZE",
+	"COMMENT", "MIN_ROWS", "MAX_ROWS", "AVG_ROW_LENGTH",
+}
+
+// tableDef is the part of a table definition both sides can state, in a
+// normal form
+type tableDef struct {
+	name    string
+	columns []*columnDef
+	keys    []*keyDef
+	// foreignKeys maps the lowercase constraint name to its definition,
+	// nil when the side doesn't record them
+	foreignKeys map[string]string
+	// fkColumns are the column lists of the foreign keys
+	fkColumns [][]string
+	options   map[string]string
+	// partitions is the normal form of the partition clause, empty if
+	// the table isn't partitioned
+	partitions string
+}
+
+func (t *tableDef) column(name string) *columnDef {
+	for _, c := range t.columns {
+		if strings.EqualFold(c.name, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+func (t *tableDef) key(name string) *keyDef {
+	for _, k := range t.keys {
+		if strings.EqualFold(k.name, name) {
+			return k
+		}
+	}
+	return nil
+}
+
+// inPrimaryKey reports whether the lowercase column name is a part of
+// the primary key
+func (t *tableDef) inPrimaryKey(name string) bool {
+	if pk := t.key("PRIMARY"); pk != nil {
+		for _, part := range pk.parts {
+			if strings.HasPrefix(part, "`"+name+"`") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// implicitKey reports whether the key is one the server adds for a foreign
+// key of the table that has no index of its own
+func (t *tableDef) implicitKey(k *keyDef) bool {
+	if k.kind != "INDEX" {
+		return false
+	}
+	if _, ok := t.foreignKeys[strings.ToLower(k.name)]; ok {
+		return true
+	}
+	for _, columns := range t.fkColumns {
+		if strings.Join(columns, ",") == strings.Join(k.parts, ",") {
+			return true
+		}
+	}
+	return false
+}
+
+// columnDef is a column in normal form, defaultValue and onUpdate are
+// empty if the column has none
+type columnDef struct {
+	name string
+	typ  string
+	// collation is charset/collation of text columns, empty if not known
+	collation string
+	nullable  bool
+	// nullableKnown is false when the SQL leaves the nullability of a
+	// TIMESTAMP to explicit_defaults_for_timestamp
+	nullableKnown bool
+	defaultValue  string
+	onUpdate      string
+	autoIncrement bool
+	comment       string
+}
+
+func (c *columnDef) isTimestamp() bool {
+	return strings.HasPrefix(c.typ, "timestamp")
+}
+
+// implicitDefault reports whether the default is one the server gives a
+// NOT NULL column which states none
+func (c *columnDef) implicitDefault() bool {
+	if c.isTimestamp() && strings.HasPrefix(c.defaultValue, "CURRENT_TIMESTAMP") {
+		return true
+	}
+	if !strings.HasPrefix(c.defaultValue, "'") {
+		return false
+	}
+	value := strings.Trim(c.defaultValue, "'")
+	switch {
+	case value == "":
+		return true
+	case strings.HasPrefix(c.typ, "enum("):
+		return strings.HasPrefix(c.typ, "enum("+table.QuoteString(value))
+	}
+	return strings.Trim(value, "0-: .") == ""
+}
+
+// keyDef is an index in normal form, parts are the lowercase column names
+// with their prefix length and direction
+type keyDef struct {
+	name    string
+	kind    string
+	parts   []string
+	comment string
+}
+
+func (k *keyDef) String() string {
+	return fmt.Sprintf("%s (%s)", k.kind, strings.Join(k.parts, ","))
+}
+
+// fromFRM returns the normal form of the table parsed from the .frm
+func fromFRM(mt *table.MySQLTable) *tableDef {
+	t := &tableDef{name: mt.Name, options: make(map[string]string)}
+	for _, c := range mt.Columns.Items {
+		t.columns = append(t.columns, frmColumn(c))
+	}
+	for _, k := range mt.Keys.Items {
+		// the dump leaves out keys without a name or parts as well
+		if k.String() == "" {
+			continue
+		}
+		t.keys = append(t.keys, frmKey(k))
+	}
+	if mt.ForeignKeys != nil {
+		t.foreignKeys = make(map[string]string)
+		for _, fk := range mt.ForeignKeys {
+			t.foreignKeys[strings.ToLower(fk.Name)] = foreignKeyString(
+				fk.Columns, fk.ReferencedTable, fk.ReferencedColumns, fk.OnDelete, fk.OnUpdate)
+			columns := make([]string, len(fk.Columns))
+			for i, column := range fk.Columns {
+				columns[i] = strings.ToLower(column)
+			}
+			t.fkColumns = append(t.fkColumns, columns)
+		}
+	}
+	o := mt.Options
+	t.options["ENGINE"] = strings.ToUpper(o.Engine)
+	if o.Collation != nil {
+		t.options["DEFAULT CHARSET"], t.options["COLLATE"] = table.CanonicalCharset(o.Collation)
+	}
+	t.options["ROW_FORMAT"] = rowFormatName(o.RowFormat)
+	t.options["KEY_BLOCK_SIZE"] = fmt.Sprint(o.KeyBlockSize)
+	t.options["COMMENT"] = o.Comment
+	t.options["MIN_ROWS"] = fmt.Sprint(o.MinRows)
+	t.options["MAX_ROWS"] = fmt.Sprint(o.MaxRows)
+	t.options["AVG_ROW_LENGTH"] = fmt.Sprint(o.AvgRowLength)
+	if o.Partitions != "" {
+		t.partitions = partitionString(o.PartitionClause())
+	}
+	return t
+}
+
+// tableOptionNames are the compared options a table option of the
+// statement states
+var tableOptionNames = map[ast.TableOptionType][]string{
+	ast.TableOptionEngine:       {"ENGINE"},
+	ast.TableOptionCharset:      {"DEFAULT CHARSET", "COLLATE"},
+	ast.TableOptionCollate:      {"DEFAULT CHARSET", "COLLATE"},
+	ast.TableOptionRowFormat:    {"ROW_FORMAT"},
+	ast.TableOptionKeyBlockSize: {"KEY_BLOCK_SIZE"},
+	ast.TableOptionComment:      {"COMMENT"},
+	ast.TableOptionMinRows:      {"MIN_ROWS"},
+	ast.TableOptionMaxRows:      {"MAX_ROWS"},
+	ast.TableOptionAvgRowLength: {"AVG_ROW_LENGTH"},
+}
+
+// fromSQL returns the normal form of the CREATE TABLE statement, mapped
+// into the .frm model by NewMySQLTable. Only the table options the
+// statement states are kept, and the nullability of a TIMESTAMP column
+// which states none is unknown as it depends on
+// explicit_defaults_for_timestamp
+func fromSQL(stmt *ast.CreateTableStmt) (*tableDef, error) {
+	mt, err := NewMySQLTable(stmt)
+	if err != nil {
+		return nil, err
+	}
+	t := fromFRM(mt)
+	stated := make(map[string]bool)
+	for _, option := range stmt.Options {
+		for _, name := range tableOptionNames[option.Tp] {
+			stated[name] = true
+		}
+	}
+	for name := range t.options {
+		if !stated[name] {
+			delete(t.options, name)
+		}
+	}
+	for _, col := range stmt.Cols {
+		if col.Tp.GetType() != mysql.TypeTimestamp {
+			continue
+		}
+		known := false
+		for _, option := range col.Options {
+			switch option.Tp {
+			case ast.ColumnOptionNotNull, ast.ColumnOptionNull, ast.ColumnOptionPrimaryKey:
+				known = true
+			}
+		}
+		if !known && !t.inPrimaryKey(col.Name.Name.L) {
+			t.column(col.Name.Name.O).nullableKnown = false
+		}
+	}
+	return t, nil
+}
+
+// partitionString is the normal form of a partition clause, the engine of
+// the partitions is the one of the table and compared as a table option
+func partitionString(clause string) string {
+	p, err := table.ParsePartitioning(clause)
+	if err != nil {
+		return table.NormalizePartitions(clause)
+	}
+	for _, def := range p.Definitions {
+		def.Engine = ""
+		for _, sub := range def.SubPartitions {
+			sub.Engine = ""
+		}
+	}
+	return utils.CollapseWhitespace(p.String())
+}
+
+func rowFormatName(rowFormat table.RowType) string {
+	if rowFormat == table.RT_DEFAULT {
+		return "DEFAULT"
+	}
+	return rowFormat.String()
+}
+
+func frmColumn(c *table.Column) *columnDef {
+	cd := &columnDef{
+		name:          c.Name,
+		typ:           normalizeType(frmType(c)),
+		nullable:      c.Flags.HasFlag(table.FF_MAYBE_NULL),
+		nullableKnown: true,
+		autoIncrement: c.Utype == table.UT_NEXT_NUMBER,
+		comment:       c.Comment,
+	}
+	if c.Collation != nil && c.Collation.CharsetName != "binary" && table.HasCharset(c.TypeCode) {
+		cd.collation = collationString(c.Collation)
+	}
+	defaultValue := c.Default
+	if i := strings.LastIndex(defaultValue, " ON UPDATE "); i >= 0 && isTemporal(cd.typ) {
+		cd.onUpdate = normalizeDefault(cd.typ, defaultValue[i+len(" ON UPDATE "):])
+		defaultValue = defaultValue[:i]
+	}
+	cd.defaultValue = normalizeDefault(cd.typ, defaultValue)
+	return cd
+}
+
+// frmType is the type of the column as SQL spells it, the .frm type name
+// drops the precision of FLOAT(M,D) and DOUBLE(M,D)
+func frmType(c *table.Column) string {
+	name, _ := c.TypeCode.Name()
+	switch c.TypeCode {
+	case table.MT_ENUM, table.MT_SET:
+		return name + labelList(c.LabelStrs)
+	case table.MT_FLOAT, table.MT_DOUBLE:
+		if c.Scale != table.FF_MAX_DEC {
+			name += fmt.Sprintf("(%d,%d)", c.Length, c.Scale)
+		}
+		return name + numericAttributes(!c.Flags.HasFlag(table.FF_DECIMAL), c.Flags.HasFlag(table.FF_ZEROFILL))
+	}
+	typ := typeNoiseRegex.ReplaceAllString(c.TypeName, "")
+	// the .frm parser doesn't decode generation expressions
+	typ, _, _ = strings.Cut(typ, " GENERATED ALWAYS AS ")
+	return typ
+}
+
+func frmKey(k *table.Key) *keyDef {
+	kd := &keyDef{name: k.Name, comment: k.Comment}
+	switch {
+	case k.Name == "PRIMARY":
+		kd.kind = "PRIMARY"
+	case k.IsUnique:
+		kd.kind = "UNIQUE"
+	case k.IndexType == "FULLTEXT" || k.IndexType == "SPATIAL":
+		kd.kind = k.IndexType
+	default:
+		kd.kind = "INDEX"
+	}
+	for _, part := range k.Parts {
+		kd.parts = append(kd.parts, strings.ToLower(utils.CollapseWhitespace(k.FormatKeyPart(part))))
+	}
+	return kd
+}
+
+func isTemporal(typ string) bool {
+	return strings.HasPrefix(typ, "timestamp") || strings.HasPrefix(typ, "datetime")
+}
+
+func isNumeric(typ string) bool {
+	for _, prefix := range []string{"tinyint", "smallint", "mediumint", "int", "bigint",
+		"decimal", "float", "double", "year", "bit"} {
+		if strings.HasPrefix(typ, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func collationString(c *table.Collation) string {
+	charsetName, collationName := table.CanonicalCharset(c)
+	return charsetName + "/" + collationName
+}
+
+// normalizeType drops the display width of integers which aren't
+// ZEROFILL and the (4) of YEAR
+func normalizeType(typ string) string {
+	typ = strings.TrimSpace(typ)
+	if !strings.HasSuffix(typ, " zerofill") {
+		if match := utils.DisplayWidthRegex.FindStringSubmatch(typ); match != nil {
+			typ = match[1] + typ[len(match[0]):]
+		}
+	}
+	if strings.HasPrefix(typ, "year(") {
+		typ = "year"
+	}
+	return typ
+}
+
+// normalizeDefault returns the default value in a form which doesn't
+// depend on quoting, number formatting or the CURRENT_TIMESTAMP synonym
+// used: NULL, CURRENT_TIMESTAMP[(fsp)], a quoted literal or an expression
+// in parentheses
+func normalizeDefault(typ, value string) string {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "":
+		return ""
+	case strings.EqualFold(value, "NULL"):
+		return "NULL"
+	}
+	if match := currentTimestampRegex.FindStringSubmatch(value); match != nil {
+		if match[3] == "" || match[3] == "0" {
+			return "CURRENT_TIMESTAMP"
+		}
+		return "CURRENT_TIMESTAMP(" + match[3] + ")"
+	}
+	if strings.HasPrefix(value, "(") {
+		return "(" + utils.CollapseWhitespace(strings.TrimSuffix(strings.TrimPrefix(value, "("), ")")) + ")"
+	}
+	if strings.HasPrefix(value, "b'") || strings.HasPrefix(value, "B'") {
+		n, ok := new(big.Int).SetString(strings.Trim(value[1:], "'"), 2)
+		if ok {
+			return "'" + n.String() + "'"
+		}
+	}
+	return literal(typ, strings.TrimSuffix(strings.TrimPrefix(value, "'"), "'"))
+}
+
+// literal quotes the default literal, numbers are written in lowest terms
+// and zero fractions of temporal values are dropped
+func literal(typ, value string) string {
+	switch {
+	case isNumeric(typ):
+		if r, ok := new(big.Rat).SetString(value); ok {
+			value = r.FloatString(30)
+			if strings.Contains(value, ".") {
+				value = strings.TrimSuffix(strings.TrimRight(value, "0"), ".")
+			}
+			if value == "-0" {
+				value = "0"
+			}
+		}
+	case isTemporal(typ) || strings.HasPrefix(typ, "time"):
+		if isTemporal(typ) && len(value) == len("2006-01-02") {
+			value += " 00:00:00"
+		}
+		if i := strings.LastIndex(value, "."); i >= 0 && strings.Trim(value[i+1:], "0") == "" {
+			value = value[:i]
+		}
+	}
+	return table.QuoteString(value)
+}
+
+func foreignKeyString(columns []string, referencedTable string, referencedColumns []string, onDelete, onUpdate string) string {
+	definition := fmt.Sprintf("(%s) REFERENCES %s (%s)",
+		strings.ToLower(strings.Join(columns, ",")), strings.ToLower(referencedTable),
+		strings.ToLower(strings.Join(referencedColumns, ",")))
+	// RESTRICT and NO ACTION are the same in MySQL and the default
+	for _, action := range []struct{ name, value string }{{"ON DELETE", onDelete}, {"ON UPDATE", onUpdate}} {
+		value := strings.ToUpper(action.value)
+		if value != "" && value != "RESTRICT" && value != "NO ACTION" {
+			definition += " " + action.name + " " + value
+		}
+	}
+	return definition
+}
+
+func sortDifferences(diffs []*Difference) {
+	sort.SliceStable(diffs, func(i, j int) bool {
+		return diffs[i].Name < diffs[j].Name
+	})
+}
@@ -0,0 +1,738 @@
+package ddl
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/tidb/pkg/parser"
+	"github.com/pingcap/tidb/pkg/parser/ast"
+	pmodel "github.com/pingcap/tidb/pkg/parser/model"
+	"github.com/pingcap/tidb/pkg/parser/mysql"
+	"github.com/pingcap/tidb/pkg/parser/opcode"
+	"github.com/pingcap/tidb/pkg/parser/test_driver"
+	"github.com/zing22845/go-frm-parser/frm/table"
+	"github.com/zing22845/go-frm-parser/frm/view"
+)
+
+// Definition is a table or a view defined by a CREATE statement
+type Definition struct {
+	Schema string
+	Table  *table.MySQLTable
+	View   *view.MySQLView
+}
+
+// ParseDefinitions parses the CREATE TABLE and CREATE VIEW statements of
+// sql, such as the output of SHOW CREATE or mysqldump --no-data, into the
+// models the .frm parsers build, so they have the same canonical forms.
+// USE statements set the schema of the statements which don't name one,
+// schema is the one before any. A later statement for an object replaces
+// the earlier one, as the view of a dump replaces its placeholder table
+func ParseDefinitions(schema, sql string) ([]*Definition, error) {
+	stmts, _, err := parser.New().Parse(sql, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("parse SQL: %w", err)
+	}
+	var definitions []*Definition
+	index := make(map[string]int)
+	add := func(d *Definition, name string) {
+		key := d.Schema + "." + strings.ToLower(name)
+		if i, ok := index[key]; ok {
+			definitions[i] = d
+			return
+		}
+		index[key] = len(definitions)
+		definitions = append(definitions, d)
+	}
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *ast.UseStmt:
+			schema = s.DBName
+		case *ast.CreateTableStmt:
+			mt, err := NewMySQLTable(s)
+			if err != nil {
+				return nil, fmt.Errorf("table %s: %w", s.Table.Name.O, err)
+			}
+			add(&Definition{Schema: schemaOf(s.Table, schema), Table: mt}, mt.Name)
+		case *ast.CreateViewStmt:
+			v, err := NewMySQLView(s)
+			if err != nil {
+				return nil, fmt.Errorf("view %s: %w", s.ViewName.Name.O, err)
+			}
+			add(&Definition{Schema: schemaOf(s.ViewName, schema), View: v}, v.Name)
+		}
+	}
+	return definitions, nil
+}
+
+func schemaOf(name *ast.TableName, schema string) string {
+	if name.Schema.O != "" {
+		return name.Schema.O
+	}
+	return schema
+}
+
+// sqlTable maps a CREATE TABLE statement into a table.MySQLTable
+type sqlTable struct {
+	stmt    *ast.CreateTableStmt
+	mt      *table.MySQLTable
+	columns map[string]*table.Column
+	// primaryKey are the lowercase names of the columns of the primary key
+	primaryKey map[string]bool
+}
+
+// NewMySQLTable maps a CREATE TABLE statement into the same model
+// table.Parse builds from a .frm, so it renders the same way
+func NewMySQLTable(stmt *ast.CreateTableStmt) (*table.MySQLTable, error) {
+	t := &sqlTable{
+		stmt:       stmt,
+		mt:         &table.MySQLTable{Name: stmt.Table.Name.O},
+		columns:    make(map[string]*table.Column),
+		primaryKey: make(map[string]bool),
+	}
+	err := t.decodeOptions()
+	if err != nil {
+		return nil, err
+	}
+	err = t.decodeColumns()
+	if err != nil {
+		return nil, err
+	}
+	err = t.decodeKeys()
+	if err != nil {
+		return nil, err
+	}
+	t.decodeForeignKeys()
+	return t.mt, nil
+}
+
+func (t *sqlTable) decodeOptions() error {
+	o := &table.Options{}
+	var charsetName, collationName string
+	for _, option := range t.stmt.Options {
+		switch option.Tp {
+		case ast.TableOptionEngine:
+			o.Engine = option.StrValue
+		case ast.TableOptionCharset:
+			charsetName = option.StrValue
+		case ast.TableOptionCollate:
+			collationName = option.StrValue
+		case ast.TableOptionRowFormat:
+			o.RowFormat = rowType(rowFormats[option.UintValue])
+		case ast.TableOptionKeyBlockSize:
+			o.KeyBlockSize = uint16(option.UintValue)
+		case ast.TableOptionComment:
+			o.Comment = option.StrValue
+		case ast.TableOptionMinRows:
+			o.MinRows = uint32(option.UintValue)
+		case ast.TableOptionMaxRows:
+			o.MaxRows = uint32(option.UintValue)
+		case ast.TableOptionAvgRowLength:
+			o.AvgRowLength = uint32(option.UintValue)
+		case ast.TableOptionAutoIncrement:
+			o.AutoIncrement = option.UintValue
+		case ast.TableOptionConnection:
+			o.Connection = option.StrValue
+		case ast.TableOptionDataDirectory:
+			o.DataDirectory = option.StrValue
+		case ast.TableOptionIndexDirectory:
+			o.IndexDirectory = option.StrValue
+		case ast.TableOptionInsertMethod:
+			o.InsertMethod = strings.ToUpper(option.StrValue)
+		case ast.TableOptionUnion:
+			for _, name := range option.TableNames {
+				o.Union = append(o.Union, &table.MergeChild{Schema: name.Schema.O, Name: name.Name.O})
+			}
+		}
+	}
+	collation, err := resolveCollation(charsetName, collationName)
+	if err != nil {
+		return err
+	}
+	o.Collation = collation
+	t.mt.Collation = collation
+	if t.stmt.Partition != nil {
+		clause, err := restore(t.stmt.Partition)
+		if err != nil {
+			return err
+		}
+		o.Partitions = " " + clause
+		// the clause is kept as is when the model can't be parsed
+		_ = o.DecodePartitioning()
+	}
+	t.mt.Options = o
+	return nil
+}
+
+// rowType is the row type of a ROW_FORMAT name
+func rowType(name string) table.RowType {
+	for rt := table.RT_FIXED; rt <= table.RT_COMPACT; rt++ {
+		if rt.String() == name {
+			return rt
+		}
+	}
+	return table.RT_DEFAULT
+}
+
+func (t *sqlTable) decodeColumns() error {
+	for _, constraint := range t.stmt.Constraints {
+		if constraint.Tp == ast.ConstraintPrimaryKey {
+			for _, part := range constraint.Keys {
+				if part.Column != nil {
+					t.primaryKey[part.Column.Name.L] = true
+				}
+			}
+		}
+	}
+	cs := &table.Columns{}
+	combined := make([]string, 0, len(t.stmt.Cols))
+	for _, col := range t.stmt.Cols {
+		c, err := t.decodeColumn(len(cs.Items), col)
+		if err != nil {
+			return fmt.Errorf("column %s: %w", col.Name.Name.O, err)
+		}
+		if c.Flags.HasFlag(table.FF_MAYBE_NULL) {
+			cs.NullCount++
+		}
+		cs.Items = append(cs.Items, c)
+		t.columns[col.Name.Name.L] = c
+		combined = append(combined, "  "+c.String())
+	}
+	cs.Count = uint16(len(cs.Items))
+	cs.Combined = strings.Join(combined, ",\n")
+	t.mt.Columns = cs
+	return nil
+}
+
+func (t *sqlTable) decodeColumn(number int, col *ast.ColumnDef) (*table.Column, error) {
+	ft := col.Tp
+	typeCode := table.MySQLType(ft.GetType())
+	c := &table.Column{
+		Name:           col.Name.Name.O,
+		Number:         number,
+		TypeCode:       typeCode,
+		TableCollation: t.mt.Collation,
+	}
+	unsigned := mysql.HasUnsignedFlag(ft.GetFlag())
+	zerofill := mysql.HasZerofillFlag(ft.GetFlag())
+	if !unsigned && !zerofill {
+		c.Flags |= table.FF_DECIMAL
+	}
+	if zerofill {
+		c.Flags |= table.FF_ZEROFILL
+	}
+	nullable := !t.primaryKey[col.Name.Name.L]
+	explicitNull := false
+	collationName := ft.GetCollate()
+	var defaultExpr, onUpdateExpr, generatedExpr ast.ExprNode
+	stored := false
+	for _, option := range col.Options {
+		switch option.Tp {
+		case ast.ColumnOptionNotNull, ast.ColumnOptionPrimaryKey:
+			nullable = false
+		case ast.ColumnOptionNull:
+			nullable, explicitNull = true, true
+		case ast.ColumnOptionAutoIncrement:
+			c.Utype = table.UT_NEXT_NUMBER
+		case ast.ColumnOptionDefaultValue:
+			defaultExpr = option.Expr
+		case ast.ColumnOptionOnUpdate:
+			onUpdateExpr = option.Expr
+		case ast.ColumnOptionComment:
+			if value, ok := option.Expr.(*test_driver.ValueExpr); ok {
+				c.Comment = value.GetString()
+			}
+		case ast.ColumnOptionCollate:
+			collationName = option.StrValue
+		case ast.ColumnOptionGenerated:
+			generatedExpr, stored = option.Expr, option.Stored
+		}
+	}
+	if typeCode == table.MT_TIMESTAMP && !explicitNull {
+		// TIMESTAMP columns are NOT NULL unless stated otherwise before
+		// explicit_defaults_for_timestamp
+		nullable = false
+	}
+	if nullable {
+		c.Flags |= table.FF_MAYBE_NULL
+	}
+	if isBlob(typeCode) && c.Utype != table.UT_NEXT_NUMBER {
+		c.Utype = table.UT_BLOB_FIELD
+	}
+
+	c.TypeName = sqlType(ft)
+	switch {
+	case typeCode == table.MT_TINY && ft.GetFlen() == 1 && !zerofill:
+		// tinyint(1) keeps its display width in the canonical form
+		c.TypeName = "tinyint(1)" + numericAttributes(unsigned, false)
+	case typeCode == table.MT_ENUM || typeCode == table.MT_SET:
+		c.LabelStrs = ft.GetElems()
+		c.TypeName = strings.ToLower(strings.TrimSuffix(c.TypeName, labelList(c.LabelStrs))) +
+			fmt.Sprintf("('%s')", strings.Join(c.LabelStrs, "','"))
+	case typeCode == table.MT_BIT:
+		c.Length = uint16(max(ft.GetFlen(), 1))
+	case typeCode == table.MT_NEWDECIMAL:
+		c.Scale = table.FieldFlag(max(ft.GetDecimal(), 0))
+	case typeCode == table.MT_FLOAT || typeCode == table.MT_DOUBLE:
+		c.Scale = table.FF_MAX_DEC
+		if ft.GetFlen() > 0 && ft.GetDecimal() >= 0 {
+			c.Length, c.Scale = uint16(ft.GetFlen()), table.FieldFlag(ft.GetDecimal())
+		}
+	}
+	if table.HasCharset(typeCode) && ft.GetCharset() != "binary" {
+		collation, err := resolveCollation(ft.GetCharset(), collationName)
+		if err != nil {
+			return nil, err
+		}
+		if collation == nil {
+			collation = t.mt.Collation
+		}
+		c.Collation = collation
+		if collation != nil {
+			if collation != t.mt.Collation {
+				c.TypeName += " CHARACTER SET " + collation.CharsetName
+			}
+			if !collation.IsDefault {
+				c.TypeName += " COLLATE " + collation.Name
+			}
+		}
+	}
+	if c.Collation == nil {
+		c.Collation, _ = table.GetCollationByName("binary")
+	}
+	if flen := ft.GetFlen(); flen > 0 && table.HasCharset(typeCode) {
+		// the length of text columns is in bytes
+		c.Length = uint16(flen * max(c.Collation.Maxlen, 1))
+	}
+	if generatedExpr != nil {
+		expr, err := restore(generatedExpr)
+		if err != nil {
+			return nil, err
+		}
+		storage := "VIRTUAL"
+		if stored {
+			storage = "STORED"
+		}
+		c.TypeName += fmt.Sprintf(" GENERATED ALWAYS AS (%s) %s", expr, storage)
+	}
+	if !nullable {
+		c.TypeName += " NOT NULL"
+	} else if typeCode == table.MT_TIMESTAMP {
+		c.TypeName += " NULL"
+	}
+	if c.Utype == table.UT_NEXT_NUMBER {
+		c.TypeName += " AUTO_INCREMENT"
+	}
+
+	var err error
+	c.Default, err = columnDefault(c, defaultExpr, generatedExpr != nil)
+	if err != nil {
+		return nil, err
+	}
+	if onUpdateExpr != nil && c.Default != "" {
+		onUpdate, err := literalDefault(c, onUpdateExpr)
+		if err != nil {
+			return nil, err
+		}
+		c.Default += " ON UPDATE " + onUpdate
+	}
+	return c, nil
+}
+
+func isBlob(typeCode table.MySQLType) bool {
+	switch typeCode {
+	case table.MT_TINY_BLOB, table.MT_MEDIUM_BLOB, table.MT_LONG_BLOB, table.MT_BLOB,
+		table.MT_JSON, table.MT_GEOMETRY:
+		return true
+	}
+	return false
+}
+
+// columnDefault is the default as the .frm parser renders it, NULL for
+// nullable columns without one and none for NOT NULL ones
+func columnDefault(c *table.Column, expr ast.ExprNode, generated bool) (string, error) {
+	switch {
+	case c.Utype == table.UT_NEXT_NUMBER || generated:
+		return "", nil
+	case c.TypeCode == table.MT_JSON:
+		// the .frm parser renders JSON columns with DEFAULT NULL
+		return "NULL", nil
+	case expr == nil && c.Flags.HasFlag(table.FF_MAYBE_NULL) && c.Utype != table.UT_BLOB_FIELD:
+		return "NULL", nil
+	case expr == nil:
+		return "", nil
+	}
+	value, err := literalDefault(c, expr)
+	if err != nil {
+		return "", err
+	}
+	if value == "NULL" && c.Utype == table.UT_BLOB_FIELD {
+		return "", nil
+	}
+	return value, nil
+}
+
+// literalDefault renders a DEFAULT or ON UPDATE value as the .frm parser
+// does, values quoted, CURRENT_TIMESTAMP with its precision and other
+// expressions in parentheses
+func literalDefault(c *table.Column, expr ast.ExprNode) (string, error) {
+	switch e := expr.(type) {
+	case *test_driver.ValueExpr:
+		switch e.Kind() {
+		case test_driver.KindNull:
+			return "NULL", nil
+		case test_driver.KindString, test_driver.KindBytes:
+			value := e.GetString()
+			if c.TypeCode == table.MT_STRING || c.TypeCode == table.MT_VAR_STRING || c.TypeCode == table.MT_VARCHAR {
+				value = strings.TrimRight(value, " ")
+			}
+			return "'" + value + "'", nil
+		case test_driver.KindBinaryLiteral, test_driver.KindMysqlBit:
+			number := new(big.Int).SetBytes(e.GetBytes())
+			if c.TypeCode == table.MT_BIT {
+				return "b'" + number.Text(2) + "'", nil
+			}
+			return "'" + number.String() + "'", nil
+		case test_driver.KindFloat32, test_driver.KindFloat64:
+			return "'" + strconv.FormatFloat(e.GetFloat64(), 'g', 6, 64) + "'", nil
+		}
+		value, err := restore(e)
+		if err != nil {
+			return "", err
+		}
+		if c.TypeCode == table.MT_NEWDECIMAL {
+			// decimals are zero filled to their scale
+			integer, fraction, _ := strings.Cut(value, ".")
+			if c.Scale > 0 {
+				integer += "." + (fraction + strings.Repeat("0", int(c.Scale)))[:c.Scale]
+			}
+			value = integer
+		}
+		return "'" + value + "'", nil
+	case *ast.UnaryOperationExpr:
+		if value, ok := e.V.(*test_driver.ValueExpr); ok && e.Op == opcode.Minus {
+			number, err := literalDefault(c, value)
+			if err != nil {
+				return "", err
+			}
+			return "'-" + strings.Trim(number, "'") + "'", nil
+		}
+	case *ast.FuncCallExpr:
+		if currentTimestampRegex.MatchString(e.FnName.L) {
+			if len(e.Args) == 1 {
+				fsp, err := restore(e.Args[0])
+				if err != nil {
+					return "", err
+				}
+				if fsp != "0" {
+					return "CURRENT_TIMESTAMP(" + fsp + ")", nil
+				}
+			}
+			return "CURRENT_TIMESTAMP", nil
+		}
+	}
+	value, err := restore(expr)
+	if err != nil {
+		return "", err
+	}
+	return "(" + value + ")", nil
+}
+
+func (t *sqlTable) decodeKeys() error {
+	ks := &table.Keys{}
+	var constraints []*ast.Constraint
+	// keys stated in the column definitions come first
+	for _, col := range t.stmt.Cols {
+		part := []*ast.IndexPartSpecification{{Column: col.Name}}
+		for _, option := range col.Options {
+			switch option.Tp {
+			case ast.ColumnOptionPrimaryKey:
+				constraints = append(constraints, &ast.Constraint{Tp: ast.ConstraintPrimaryKey, Keys: part})
+			case ast.ColumnOptionUniqKey:
+				constraints = append(constraints, &ast.Constraint{Tp: ast.ConstraintUniqKey, Keys: part})
+			}
+		}
+	}
+	for _, constraint := range t.stmt.Constraints {
+		switch constraint.Tp {
+		case ast.ConstraintForeignKey, ast.ConstraintCheck:
+			continue
+		}
+		constraints = append(constraints, constraint)
+	}
+	for i, constraint := range constraints {
+		k, err := t.decodeKey(ks, constraint)
+		if err != nil {
+			return err
+		}
+		k.PartsCount = uint8(i)
+		ks.Items = append(ks.Items, k)
+		ks.Names = append(ks.Names, k.Name)
+	}
+	sortKeys(ks.Items)
+	combined := make([]string, 0, len(ks.Items))
+	for _, k := range ks.Items {
+		k.PartsCount = uint8(len(k.Parts))
+		combined = append(combined, "  "+k.String())
+	}
+	ks.Count = uint8(len(ks.Items))
+	ks.Combined = strings.Join(combined, ",\n")
+	t.mt.Keys = ks
+	return nil
+}
+
+func (t *sqlTable) decodeKey(ks *table.Keys, constraint *ast.Constraint) (*table.Key, error) {
+	k := &table.Key{
+		Name:      constraint.Name,
+		IndexType: "BTREE",
+		Keys:      ks,
+		Columns:   t.mt.Columns,
+	}
+	switch constraint.Tp {
+	case ast.ConstraintPrimaryKey:
+		k.Name, k.IsUnique = "PRIMARY", true
+	case ast.ConstraintUniq, ast.ConstraintUniqKey, ast.ConstraintUniqIndex:
+		k.IsUnique = true
+	case ast.ConstraintFulltext:
+		k.IndexType = "FULLTEXT"
+	}
+	if option := constraint.Option; option != nil {
+		k.Comment = option.Comment
+		k.BlockSize = uint16(option.KeyBlockSize)
+		k.Parser = option.ParserName.O
+		k.Invisible = option.Visibility == ast.IndexVisibilityInvisible
+		switch option.Tp {
+		case pmodel.IndexTypeBtree:
+			k.Algorithm = table.HA_KEY_ALG_BTREE
+		case pmodel.IndexTypeHash:
+			k.Algorithm = table.HA_KEY_ALG_HASH
+			k.IndexType = "HASH"
+		case pmodel.IndexTypeRtree:
+			k.IndexType = "SPATIAL"
+		}
+	}
+	for _, spec := range constraint.Keys {
+		part := &table.KeyPart{Descending: spec.Desc}
+		if spec.Expr != nil {
+			expr, err := restore(spec.Expr)
+			if err != nil {
+				return nil, err
+			}
+			part.Expression = expr
+			part.Column = &table.Column{}
+			k.Parts = append(k.Parts, part)
+			continue
+		}
+		c, ok := t.columns[spec.Column.Name.L]
+		if !ok {
+			return nil, fmt.Errorf("key %s: unknown column %s", k.Name, spec.Column.Name.O)
+		}
+		part.Column = c
+		part.Length = c.Length
+		if spec.Length > 0 {
+			part.Length = uint16(spec.Length * max(c.Collation.Maxlen, 1))
+		}
+		k.Parts = append(k.Parts, part)
+	}
+	if k.Name == "" && len(constraint.Keys) != 0 && constraint.Keys[0].Column != nil {
+		// unnamed keys are named after their first column
+		base := constraint.Keys[0].Column.Name.O
+		k.Name = base
+		for i := 2; containsFold(ks.Names, k.Name) || strings.EqualFold(k.Name, "PRIMARY"); i++ {
+			k.Name = fmt.Sprintf("%s_%d", base, i)
+		}
+	}
+	if k.Name == "PRIMARY" && containsFold(ks.Names, "PRIMARY") {
+		return nil, fmt.Errorf("multiple primary keys defined")
+	}
+	return k, nil
+}
+
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortKeys orders the keys as sort_keys of the server does when it writes
+// the .frm: unique keys first, NOT NULL ones before the others with the
+// primary key leading them and keys without prefix parts before the
+// others, FULLTEXT keys last and otherwise the statement order, which
+// PartsCount holds meanwhile
+func sortKeys(keys []*table.Key) {
+	hasNull := func(k *table.Key) bool {
+		for _, part := range k.Parts {
+			if part.Column.Flags.HasFlag(table.FF_MAYBE_NULL) {
+				return true
+			}
+		}
+		return false
+	}
+	hasPrefix := func(k *table.Key) bool {
+		for _, part := range k.Parts {
+			if part.Expression == "" && part.Length != part.Column.Length {
+				return true
+			}
+		}
+		return false
+	}
+	less := func(a, b *table.Key) (less, decided bool) {
+		if a.IsUnique != b.IsUnique {
+			return a.IsUnique, true
+		}
+		if a.IsUnique {
+			if hasNull(a) != hasNull(b) {
+				return !hasNull(a), true
+			}
+			if a.Name == "PRIMARY" || b.Name == "PRIMARY" {
+				return a.Name == "PRIMARY", true
+			}
+			if hasPrefix(a) != hasPrefix(b) {
+				return !hasPrefix(a), true
+			}
+		}
+		if (a.IndexType == "FULLTEXT") != (b.IndexType == "FULLTEXT") {
+			return b.IndexType == "FULLTEXT", true
+		}
+		return false, false
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		if less, decided := less(keys[i], keys[j]); decided {
+			return less
+		}
+		return keys[i].PartsCount < keys[j].PartsCount
+	})
+}
+
+func (t *sqlTable) decodeForeignKeys() {
+	var constraints []*ast.Constraint
+	for _, col := range t.stmt.Cols {
+		for _, option := range col.Options {
+			if option.Tp == ast.ColumnOptionReference {
+				constraints = append(constraints, &ast.Constraint{
+					Tp:    ast.ConstraintForeignKey,
+					Keys:  []*ast.IndexPartSpecification{{Column: col.Name}},
+					Refer: option.Refer,
+				})
+			}
+		}
+	}
+	for _, constraint := range t.stmt.Constraints {
+		if constraint.Tp == ast.ConstraintForeignKey {
+			constraints = append(constraints, constraint)
+		}
+	}
+	names := make(map[string]bool)
+	for _, constraint := range constraints {
+		names[strings.ToLower(constraint.Name)] = true
+	}
+	n := 0
+	for _, constraint := range constraints {
+		refer := constraint.Refer
+		fk := &table.ForeignKey{
+			Name:            constraint.Name,
+			ReferencedTable: refer.Table.Name.O,
+			OnDelete:        referOption(refer.OnDelete.ReferOpt),
+			OnUpdate:        referOption(refer.OnUpdate.ReferOpt),
+		}
+		if schema := refer.Table.Schema.O; schema != "" && schema != t.stmt.Table.Schema.O {
+			fk.ReferencedSchema = schema
+		}
+		for fk.Name == "" {
+			// unnamed foreign keys are named <table>_ibfk_<n> as InnoDB does
+			n++
+			if name := fmt.Sprintf("%s_ibfk_%d", t.mt.Name, n); !names[strings.ToLower(name)] {
+				fk.Name = name
+			}
+		}
+		for _, part := range constraint.Keys {
+			fk.Columns = append(fk.Columns, part.Column.Name.O)
+		}
+		for _, part := range refer.IndexPartSpecifications {
+			fk.ReferencedColumns = append(fk.ReferencedColumns, part.Column.Name.O)
+		}
+		t.mt.ForeignKeys = append(t.mt.ForeignKeys, fk)
+	}
+}
+
+// referOption is the rule of ON DELETE or ON UPDATE, empty for RESTRICT
+// and NO ACTION which SHOW CREATE TABLE leaves out as sdi does
+func referOption(option pmodel.ReferOptionType) string {
+	switch option {
+	case pmodel.ReferOptionCascade, pmodel.ReferOptionSetNull, pmodel.ReferOptionSetDefault:
+		return option.String()
+	}
+	return ""
+}
+
+// checkOptionRegex matches the end of a CREATE VIEW statement after the
+// query, with the check option and the version comment mysqldump puts it in
+var checkOptionRegex = regexp.MustCompile(
+	`(?is)\s*(\*/\s*/\*!\d*\s*)?(WITH\s+(LOCAL|CASCADED)\s+CHECK\s+OPTION\s*)?(\*/)?\s*;?\s*$`)
+
+// NewMySQLView maps a CREATE VIEW statement into the same model
+// view.Parse builds from a .frm, the body is the query as the statement
+// writes it, as SHOW CREATE VIEW writes the one the server stores
+func NewMySQLView(stmt *ast.CreateViewStmt) (*view.MySQLView, error) {
+	v := &view.MySQLView{
+		Name:      stmt.ViewName.Name.O,
+		Algorithm: view.Undefined,
+		SUID:      view.Definer,
+	}
+	switch stmt.Algorithm {
+	case pmodel.AlgorithmMerge:
+		v.Algorithm = view.Merge
+	case pmodel.AlgorithmTemptable:
+		v.Algorithm = view.TmpTable
+	}
+	if stmt.Security == pmodel.SecurityInvoker {
+		v.SUID = view.Invoker
+	}
+	if stmt.Definer != nil && !stmt.Definer.CurrentUser {
+		v.Definer = view.MySQLDefiner{User: stmt.Definer.Username, Host: stmt.Definer.Hostname}
+	}
+
+	// the text of the query runs to the end of the input when no check
+	// option ends it, so it is cut at the end of the statement
+	text, query := stmt.Text(), stmt.Select.Text()
+	start := -1
+	for i := range text {
+		if strings.HasPrefix(text[i:], query) || strings.HasPrefix(query, text[i:]) {
+			start = i
+			break
+		}
+	}
+	if query == "" || start < 0 {
+		return nil, fmt.Errorf("query of the view not found in the statement")
+	}
+	body := text[start:]
+	if len(body) > len(query) {
+		body = body[:len(query)]
+	}
+	end := checkOptionRegex.FindStringSubmatchIndex(text[start:])
+	if end[0] < len(body) {
+		body = body[:end[0]]
+	}
+	switch strings.ToUpper(subMatch(text[start:], end, 3)) {
+	case "LOCAL":
+		v.CheckOption = view.Local
+	case "CASCADED":
+		v.CheckOption = view.Cascaded
+	}
+	v.Body = body
+	v.Source = body
+	return v, nil
+}
+
+func subMatch(s string, match []int, group int) string {
+	if match[2*group] < 0 {
+		return ""
+	}
+	return s[match[2*group]:match[2*group+1]]
+}
@@ -0,0 +1,153 @@
+package ddl
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+	"github.com/zing22845/go-frm-parser/frm/view"
+)
+
+// parseFRM parses a table of test_frms, with the partition clause if any
+func parseFRM(t *testing.T, name, partitions string) *table.MySQLTable {
+	t.Helper()
+	data, err := os.ReadFile("../../test_frms/" + name + ".frm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mt, err := table.Parse(name+".frm", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if partitions != "" {
+		mt.Options.Partitions = partitions
+		if err := mt.Options.DecodePartitioning(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return mt
+}
+
+// parseDefinition parses sql which defines a single object
+func parseDefinition(t *testing.T, sql string) *Definition {
+	t.Helper()
+	definitions, err := ParseDefinitions("db", sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(definitions) != 1 {
+		t.Fatalf("got %d definitions, want 1", len(definitions))
+	}
+	return definitions[0]
+}
+
+func TestNewMySQLTableRoundTrip(t *testing.T) {
+	for _, name := range []string{"table_simple", "table_key_using_btree"} {
+		mt := parseFRM(t, name, "")
+		d := parseDefinition(t, mt.String())
+		if got, want := d.Table.Canonical(), mt.Canonical(); got != want {
+			t.Errorf("%s: got\n%s\nwant\n%s", name, got, want)
+		}
+	}
+}
+
+func TestNewMySQLViewRoundTrip(t *testing.T) {
+	data, err := os.ReadFile("../../test_frms/view_md5_success.frm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := view.Parse("view_md5_success.frm", string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sql := range []string{v.String(), v.StringWithHeader() + ";"} {
+		d := parseDefinition(t, sql)
+		if got, want := d.View.Canonical(), v.Canonical(); got != want {
+			t.Errorf("got\n%s\nwant\n%s", got, want)
+		}
+	}
+}
+
+func TestParseDefinitions(t *testing.T) {
+	const dump = "USE `shop`;\n" +
+		"DROP TABLE IF EXISTS `v`;\n" +
+		"/*!50001 CREATE VIEW `v` AS SELECT \n 1 AS `id`*/;\n" +
+		"CREATE TABLE `t` (\n  `id` int(11) NOT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;\n" +
+		"/*!50001 DROP VIEW IF EXISTS `v`*/;\n" +
+		"/*!50001 CREATE ALGORITHM=MERGE */\n" +
+		"/*!50013 DEFINER=`root`@`localhost` SQL SECURITY INVOKER */\n" +
+		"/*!50001 VIEW `v` AS select `t`.`id` AS `id` from `t` where (`t`.`id` > 1) */\n" +
+		"/*!50002 WITH CASCADED CHECK OPTION */;\n" +
+		"CREATE TABLE `other`.`u` (`id` int);\n"
+	definitions, err := ParseDefinitions("", dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range definitions {
+		if d.View != nil {
+			got = append(got, d.Schema+"."+d.View.Name+" "+d.View.String())
+			continue
+		}
+		got = append(got, d.Schema+"."+d.Table.Name)
+	}
+	want := []string{
+		"shop.v CREATE ALGORITHM=MERGE DEFINER=`root`@`localhost` SQL SECURITY INVOKER VIEW `v` AS " +
+			"select `t`.`id` AS `id` from `t` where (`t`.`id` > 1) WITH CASCADED CHECK OPTION;\n",
+		"shop.t",
+		"other.u",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestNewMySQLTable(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{
+			name: "columns",
+			sql: "CREATE TABLE t (id bigint unsigned NOT NULL AUTO_INCREMENT, flag tinyint(1) DEFAULT '0', " +
+				"price decimal(10,2) NOT NULL DEFAULT 1.5, e enum('a','b') CHARACTER SET latin1 DEFAULT 'a', " +
+				"ts timestamp DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP, body text, " +
+				"b bit(3) DEFAULT b'101', PRIMARY KEY (id)) COLLATE=utf8mb4_general_ci COMMENT='x' DEFAULT CHARSET=utf8mb4 ENGINE=InnoDB\n",
+			want: "CREATE TABLE `t` (\n" +
+				"  `id` bigint unsigned NOT NULL AUTO_INCREMENT,\n" +
+				"  `flag` tinyint(1) DEFAULT '0',\n" +
+				"  `price` decimal(10,2) NOT NULL DEFAULT '1.50',\n" +
+				"  `e` enum('a','b') CHARACTER SET latin1 COLLATE latin1_swedish_ci DEFAULT 'a',\n" +
+				"  `ts` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,\n" +
+				"  `body` text CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci,\n" +
+				"  `b` bit(3) DEFAULT b'101',\n" +
+				"  PRIMARY KEY (`id`)\n" +
+				") COLLATE=utf8mb4_general_ci COMMENT='x' DEFAULT CHARSET=utf8mb4 ENGINE=InnoDB\n",
+		},
+		{
+			name: "keys",
+			sql: "CREATE TABLE t (a int, b varchar(20) NOT NULL, c int NOT NULL, KEY (a), FULLTEXT KEY ft (b), " +
+				"UNIQUE KEY ua (a), UNIQUE KEY ub (b(4)), UNIQUE KEY uc (c), KEY (a, c), " +
+				"FOREIGN KEY (c) REFERENCES p (id) ON DELETE CASCADE ON UPDATE RESTRICT) COLLATE=latin1_swedish_ci DEFAULT CHARSET=latin1\n",
+			want: "CREATE TABLE `t` (\n" +
+				"  `a` int DEFAULT NULL,\n" +
+				"  `b` varchar(20) CHARACTER SET latin1 COLLATE latin1_swedish_ci NOT NULL,\n" +
+				"  `c` int NOT NULL,\n" +
+				"  UNIQUE KEY `uc` (`c`),\n" +
+				"  UNIQUE KEY `ub` (`b`(4)),\n" +
+				"  UNIQUE KEY `ua` (`a`),\n" +
+				"  KEY `a` (`a`),\n" +
+				"  KEY `a_2` (`a`,`c`),\n" +
+				"  FULLTEXT KEY `ft` (`b`),\n" +
+				"  CONSTRAINT `t_ibfk_1` FOREIGN KEY (`c`) REFERENCES `p` (`id`) ON DELETE CASCADE\n" +
+				") COLLATE=latin1_swedish_ci DEFAULT CHARSET=latin1\n",
+		},
+	} {
+		d := parseDefinition(t, c.sql)
+		if got := d.Table.Canonical(); got != c.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package ddl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pingcap/tidb/pkg/parser/ast"
+	"github.com/pingcap/tidb/pkg/parser/charset"
+	"github.com/pingcap/tidb/pkg/parser/format"
+	"github.com/pingcap/tidb/pkg/parser/mysql"
+	"github.com/pingcap/tidb/pkg/parser/types"
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+// currentTimestampRegex matches CURRENT_TIMESTAMP and its synonyms
+var currentTimestampRegex = regexp.MustCompile(`(?i)^(current_timestamp|now|localtime|localtimestamp)(\((\d*)\))?$`)
+
+// integerTypes are the names and the default display widths of the
+// unsigned integer types, ZEROFILL implies UNSIGNED
+var integerTypes = map[byte]struct {
+	name  string
+	width int
+}{
+	mysql.TypeTiny:     {"tinyint", 3},
+	mysql.TypeShort:    {"smallint", 5},
+	mysql.TypeInt24:    {"mediumint", 8},
+	mysql.TypeLong:     {"int", 10},
+	mysql.TypeLonglong: {"bigint", 20},
+}
+
+// rowFormats are the ROW_FORMAT names of the parser values
+var rowFormats = map[uint64]string{
+	ast.RowFormatDefault:    "DEFAULT",
+	ast.RowFormatDynamic:    "DYNAMIC",
+	ast.RowFormatFixed:      "FIXED",
+	ast.RowFormatCompressed: "COMPRESSED",
+	ast.RowFormatRedundant:  "REDUNDANT",
+	ast.RowFormatCompact:    "COMPACT",
+}
+
+// resolveCollation returns the collation a charset and collation clause
+// select, nil if both are empty
+func resolveCollation(charsetName, collationName string) (*table.Collation, error) {
+	switch {
+	case collationName != "":
+		return table.GetCollationByName(collationName)
+	case charsetName != "":
+		return table.GetDefaultCollation(charsetName)
+	}
+	return nil, nil
+}
+
+// sqlType is the type of the column as the .frm type name spells it
+func sqlType(ft *types.FieldType) string {
+	flen, decimal := ft.GetFlen(), ft.GetDecimal()
+	unsigned := mysql.HasUnsignedFlag(ft.GetFlag())
+	zerofill := mysql.HasZerofillFlag(ft.GetFlag())
+	binary := ft.GetCharset() == charset.CharsetBin
+	pick := func(text, bin string) string {
+		if binary {
+			return bin
+		}
+		return text
+	}
+	switch tp := ft.GetType(); tp {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong:
+		name := integerTypes[tp].name
+		if zerofill {
+			if flen <= 0 {
+				flen = integerTypes[tp].width
+			}
+			name += fmt.Sprintf("(%d)", flen)
+		}
+		return name + numericAttributes(unsigned, zerofill)
+	case mysql.TypeNewDecimal:
+		if flen <= 0 {
+			flen = 10
+		}
+		if decimal < 0 {
+			decimal = 0
+		}
+		return fmt.Sprintf("decimal(%d,%d)", flen, decimal) + numericAttributes(unsigned, zerofill)
+	case mysql.TypeFloat, mysql.TypeDouble:
+		name := "float"
+		if tp == mysql.TypeDouble {
+			name = "double"
+		}
+		if flen > 0 && decimal >= 0 {
+			name += fmt.Sprintf("(%d,%d)", flen, decimal)
+		}
+		return name + numericAttributes(unsigned, zerofill)
+	case mysql.TypeString:
+		if flen < 0 {
+			flen = 1
+		}
+		return fmt.Sprintf("%s(%d)", pick("char", "binary"), flen)
+	case mysql.TypeVarchar, mysql.TypeVarString:
+		return fmt.Sprintf("%s(%d)", pick("varchar", "varbinary"), flen)
+	case mysql.TypeTinyBlob:
+		return pick("tinytext", "tinyblob")
+	case mysql.TypeBlob:
+		return pick("text", "blob")
+	case mysql.TypeMediumBlob:
+		return pick("mediumtext", "mediumblob")
+	case mysql.TypeLongBlob:
+		return pick("longtext", "longblob")
+	case mysql.TypeEnum:
+		return "enum" + labelList(ft.GetElems())
+	case mysql.TypeSet:
+		return "set" + labelList(ft.GetElems())
+	case mysql.TypeDuration, mysql.TypeDatetime, mysql.TypeTimestamp:
+		name := map[byte]string{
+			mysql.TypeDuration: "time", mysql.TypeDatetime: "datetime", mysql.TypeTimestamp: "timestamp",
+		}[tp]
+		if decimal > 0 {
+			name += fmt.Sprintf("(%d)", decimal)
+		}
+		return name
+	case mysql.TypeDate:
+		return "date"
+	case mysql.TypeYear:
+		return "year"
+	case mysql.TypeBit:
+		if flen < 0 {
+			flen = 1
+		}
+		return fmt.Sprintf("bit(%d)", flen)
+	case mysql.TypeJSON:
+		return "json"
+	}
+	return strings.ToLower(ft.CompactStr())
+}
+
+// restore returns the SQL of the node as the parser writes it back
+func restore(node ast.Node) (string, error) {
+	var sb strings.Builder
+	ctx := format.NewRestoreCtx(format.RestoreStringSingleQuotes|format.RestoreKeyWordUppercase|
+		format.RestoreNameBackQuotes|format.RestoreStringWithoutCharset, &sb)
+	if err := node.Restore(ctx); err != nil {
+		return "", fmt.Errorf("restore %T: %w", node, err)
+	}
+	return sb.String(), nil
+}
+
+func labelList(labels []string) string {
+	quoted := make([]string, len(labels))
+	for i, label := range labels {
+		quoted[i] = table.QuoteString(label)
+	}
+	return "(" + strings.Join(quoted, ",") + ")"
+}
+
+func numericAttributes(unsigned, zerofill bool) string {
+	var attributes string
+	if unsigned || zerofill {
+		attributes += " unsigned"
+	}
+	if zerofill {
+		attributes += " zerofill"
+	}
+	return attributes
+}
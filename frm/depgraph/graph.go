@@ -0,0 +1,400 @@
+package depgraph
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/tidb/pkg/parser"
+	"github.com/pingcap/tidb/pkg/parser/ast"
+	_ "github.com/pingcap/tidb/pkg/parser/test_driver"
+	"github.com/zing22845/go-frm-parser/frm"
+	"github.com/zing22845/go-frm-parser/frm/mysqldb"
+	"github.com/zing22845/go-frm-parser/frm/table"
+	"github.com/zing22845/go-frm-parser/frm/view"
+)
+
+// Kind is the kind of a schema object
+type Kind string
+
+const (
+	KIND_TABLE    Kind = "table"
+	KIND_VIEW     Kind = "view"
+	KIND_FUNCTION Kind = "function"
+	// KIND_MISSING is a referenced object which isn't in the graph
+	KIND_MISSING Kind = "missing"
+	// KIND_EXTERNAL is a referenced object of information_schema or of a
+	// schema which isn't in the graph, it isn't missing from the datadir
+	KIND_EXTERNAL Kind = "external"
+)
+
+// Object is a node of the graph, References are the objects the body of a
+// view uses
+type Object struct {
+	Schema     string       `json:"schema"`
+	Name       string       `json:"name"`
+	Kind       Kind         `json:"kind"`
+	References []*Reference `json:"references,omitempty"`
+	// Error is why the view body could not be parsed
+	Error string `json:"error,omitempty"`
+}
+
+// Key is the schema qualified name of the object
+func (o *Object) Key() string {
+	return objectKey(o.Schema, o.Name)
+}
+
+// Reference is an object used by a view, Kind is KIND_MISSING if the
+// object isn't in the graph but its schema is
+type Reference struct {
+	Schema string `json:"schema"`
+	Name   string `json:"name"`
+	Kind   Kind   `json:"kind"`
+	// function is set for stored function calls
+	function bool
+}
+
+// Key is the schema qualified name of the referenced object
+func (r *Reference) Key() string {
+	return objectKey(r.Schema, r.Name)
+}
+
+// Missing is a reference of a view to an object which isn't in the graph
+type Missing struct {
+	View      *Object    `json:"-"`
+	Reference *Reference `json:"reference"`
+}
+
+// Graph holds the tables, views and stored functions of a datadir and
+// the references of the views
+type Graph struct {
+	Objects map[string]*Object
+	// functions maps the key of stored functions, which share the name
+	// space of tables and views only in the reports
+	functions map[string]*Object
+	// schemas are the schemas of the datadir, objects of other schemas
+	// are external
+	schemas  map[string]bool
+	resolved bool
+}
+
+// NewGraph creates an empty graph
+func NewGraph() *Graph {
+	return &Graph{
+		Objects:   make(map[string]*Object),
+		functions: make(map[string]*Object),
+		schemas:   make(map[string]bool),
+	}
+}
+
+func objectKey(schema, name string) string {
+	return fmt.Sprintf("`%s`.`%s`", strings.ReplaceAll(schema, "`", "``"), strings.ReplaceAll(name, "`", "``"))
+}
+
+// AddSchema adds a schema without objects, references to the objects of
+// schemas which aren't in the graph are external
+func (g *Graph) AddSchema(schema string) {
+	g.schemas[schema] = true
+	g.resolved = false
+}
+
+// AddTable adds a table
+func (g *Graph) AddTable(schema, name string) {
+	g.add(&Object{Schema: schema, Name: name, Kind: KIND_TABLE})
+}
+
+// AddFunction adds a stored function
+func (g *Graph) AddFunction(schema, name string) {
+	o := &Object{Schema: schema, Name: name, Kind: KIND_FUNCTION}
+	g.functions[strings.ToLower(o.Key())] = o
+	g.resolved = false
+}
+
+// AddView adds a view and the objects its body references, names without
+// a schema belong to the schema of the view. A body which can't be parsed
+// is kept in the view's Error and returned
+func (g *Graph) AddView(schema string, v *view.MySQLView) error {
+	o := &Object{Schema: schema, Name: v.Name, Kind: KIND_VIEW}
+	g.add(o)
+	references, err := References(schema, v.Body)
+	if err != nil {
+		o.Error = err.Error()
+		return fmt.Errorf("view %s: %w", o.Key(), err)
+	}
+	o.References = references
+	return nil
+}
+
+func (g *Graph) add(o *Object) {
+	g.Objects[o.Key()] = o
+	g.schemas[o.Schema] = true
+	g.resolved = false
+}
+
+// References parses a view body and returns the tables, views and stored
+// functions it uses, once each in the order of their first use. Builtin
+// functions can't be told from stored functions without a schema, so only
+// schema qualified calls, as the server writes them in view bodies, are
+// references
+func References(schema, body string) ([]*Reference, error) {
+	stmts, _, err := parser.New().Parse(body, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("parse view body: %w", err)
+	}
+	c := &collector{schema: schema, seen: make(map[string]bool), ctes: make(map[string]bool)}
+	for _, stmt := range stmts {
+		stmt.Accept(c)
+	}
+	return c.references, nil
+}
+
+// collector is the ast.Visitor collecting the references of a view body
+type collector struct {
+	schema     string
+	ctes       map[string]bool
+	seen       map[string]bool
+	references []*Reference
+}
+
+func (c *collector) Enter(n ast.Node) (ast.Node, bool) {
+	switch node := n.(type) {
+	case *ast.WithClause:
+		for _, cte := range node.CTEs {
+			c.ctes[cte.Name.L] = true
+		}
+	case *ast.TableName:
+		if node.Schema.O == "" && c.ctes[node.Name.L] {
+			break
+		}
+		c.add(node.Schema.O, node.Name.O, false)
+	case *ast.FuncCallExpr:
+		if node.Schema.O != "" {
+			c.add(node.Schema.O, node.FnName.O, true)
+		}
+	}
+	return n, false
+}
+
+func (c *collector) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
+
+func (c *collector) add(schema, name string, function bool) {
+	if schema == "" {
+		schema = c.schema
+	}
+	r := &Reference{Schema: schema, Name: name, function: function}
+	key := fmt.Sprint(function, r.Key())
+	if c.seen[key] {
+		return
+	}
+	c.seen[key] = true
+	c.references = append(c.references, r)
+}
+
+// resolve sets the kind of the references to the objects of the graph
+func (g *Graph) resolve() {
+	if g.resolved {
+		return
+	}
+	for _, o := range g.Objects {
+		for _, r := range o.References {
+			switch {
+			case r.function && g.functions[strings.ToLower(r.Key())] != nil:
+				r.Kind = KIND_FUNCTION
+			case !r.function && g.Objects[r.Key()] != nil:
+				r.Kind = g.Objects[r.Key()].Kind
+			case strings.EqualFold(r.Schema, "information_schema") || !g.schemas[r.Schema]:
+				r.Kind = KIND_EXTERNAL
+			default:
+				r.Kind = KIND_MISSING
+			}
+		}
+	}
+	g.resolved = true
+}
+
+// Missing returns the references to objects which aren't in the graph,
+// but not the external ones
+func (g *Graph) Missing() (missing []*Missing) {
+	g.resolve()
+	for _, o := range g.sortedObjects() {
+		for _, r := range o.References {
+			if r.Kind == KIND_MISSING {
+				missing = append(missing, &Missing{View: o, Reference: r})
+			}
+		}
+	}
+	return missing
+}
+
+// RestoreOrder returns the views so that each one comes after the views it
+// references, and the cycles of views. The views of a cycle and those
+// depending on one can't be ordered and are left out. Views without
+// dependencies between them keep the order of their names
+func (g *Graph) RestoreOrder() (order []*Object, cycles [][]*Object) {
+	g.resolve()
+	cycles = g.Cycles()
+	inCycle := make(map[*Object]bool)
+	for _, cycle := range cycles {
+		for _, o := range cycle {
+			inCycle[o] = true
+		}
+	}
+	placed := make(map[*Object]bool)
+	blocked := make(map[*Object]bool)
+	var visit func(o *Object) bool
+	// visit places the views o depends on, then o, it returns false if o
+	// is in or depends on a cycle
+	visit = func(o *Object) bool {
+		switch {
+		case placed[o]:
+			return true
+		case blocked[o] || inCycle[o]:
+			return false
+		}
+		ok := true
+		for _, dep := range g.viewDependencies(o) {
+			ok = visit(dep) && ok
+		}
+		if ok {
+			placed[o] = true
+			order = append(order, o)
+		} else {
+			blocked[o] = true
+		}
+		return ok
+	}
+	for _, o := range g.sortedObjects() {
+		if o.Kind == KIND_VIEW {
+			visit(o)
+		}
+	}
+	return order, cycles
+}
+
+// Cycles returns the views which reference each other in a cycle, as the
+// strongly connected components of the graph of views
+func (g *Graph) Cycles() (cycles [][]*Object) {
+	g.resolve()
+	index := make(map[*Object]int)
+	low := make(map[*Object]int)
+	onStack := make(map[*Object]bool)
+	var stack []*Object
+	var strongConnect func(o *Object)
+	strongConnect = func(o *Object) {
+		index[o] = len(index)
+		low[o] = index[o]
+		stack = append(stack, o)
+		onStack[o] = true
+		for _, dep := range g.viewDependencies(o) {
+			if _, visited := index[dep]; !visited {
+				strongConnect(dep)
+				low[o] = min(low[o], low[dep])
+			} else if onStack[dep] {
+				low[o] = min(low[o], index[dep])
+			}
+		}
+		if low[o] != index[o] {
+			return
+		}
+		var component []*Object
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == o {
+				break
+			}
+		}
+		if len(component) > 1 || g.referencesItself(o) {
+			sort.Slice(component, func(i, j int) bool { return component[i].Key() < component[j].Key() })
+			cycles = append(cycles, component)
+		}
+	}
+	for _, o := range g.sortedObjects() {
+		if _, visited := index[o]; !visited && o.Kind == KIND_VIEW {
+			strongConnect(o)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0].Key() < cycles[j][0].Key() })
+	return cycles
+}
+
+func (g *Graph) referencesItself(o *Object) bool {
+	for _, dep := range g.viewDependencies(o) {
+		if dep == o {
+			return true
+		}
+	}
+	return false
+}
+
+// viewDependencies returns the views o references
+func (g *Graph) viewDependencies(o *Object) (deps []*Object) {
+	for _, r := range o.References {
+		if r.Kind == KIND_VIEW {
+			deps = append(deps, g.Objects[r.Key()])
+		}
+	}
+	return deps
+}
+
+func (g *Graph) sortedObjects() []*Object {
+	objects := make([]*Object, 0, len(g.Objects))
+	for _, o := range g.Objects {
+		objects = append(objects, o)
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key() < objects[j].Key() })
+	return objects
+}
+
+// Build parses the .frm files of every schema of the datadir, and the
+// stored functions of mysql.proc if there is one, and returns their graph.
+// The errors of the files and view bodies which can't be parsed are
+// returned along with the graph
+func Build(datadir string) (g *Graph, errs []error, err error) {
+	g = NewGraph()
+	d, err := frm.ReadDatadir(datadir)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, sd := range d.Schemas {
+		if sd.IsSchema() {
+			g.AddSchema(sd.Schema)
+		}
+	}
+	err = d.Walk(func(sd *frm.SchemaDir, path string, parsed frm.MySQLSchema, err error) error {
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		switch o := parsed.(type) {
+		case *table.MySQLTable:
+			g.AddTable(sd.Schema, o.Name)
+		case *view.MySQLView:
+			if err := g.AddView(sd.Schema, o); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := os.Stat(filepath.Join(datadir, "mysql", "proc.frm")); err == nil {
+		routines, err := mysqldb.ReadRoutines(filepath.Join(datadir, "mysql"))
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for _, r := range routines {
+			if r.Type == "FUNCTION" {
+				g.AddFunction(r.Schema, r.Name)
+			}
+		}
+	}
+	return g, errs, nil
+}
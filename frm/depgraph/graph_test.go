@@ -0,0 +1,173 @@
+package depgraph
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/zing22845/go-frm-parser/frm/view"
+)
+
+// keys returns the keys of the objects
+func keys(objects []*Object) []string {
+	var keys []string
+	for _, o := range objects {
+		keys = append(keys, o.Key())
+	}
+	return keys
+}
+
+// addView adds a view of body, which has to parse
+func addView(t *testing.T, g *Graph, schema, name, body string) {
+	t.Helper()
+	if err := g.AddView(schema, &view.MySQLView{Name: name, Body: body}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReferences(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		body string
+		want []string
+	}{
+		{
+			name: "tables",
+			body: "select `a`.`id` AS `id` from (`t1` `a` join `other`.`t2` `b` on((`a`.`id` = `b`.`id`))) " +
+				"where `a`.`id` in (select `t1`.`id` from `t1`)",
+			want: []string{"`shop`.`t1`", "`other`.`t2`"},
+		},
+		{
+			name: "common table expressions",
+			body: "with `recent` as (select `o`.`id` AS `id` from `orders` `o`) " +
+				"select `recent`.`id` AS `id` from `recent` join `shop`.`recent` `r`",
+			want: []string{"`shop`.`orders`", "`shop`.`recent`"},
+		},
+		{
+			name: "function calls",
+			body: "select `shop`.`price`(`t`.`id`) AS `p`,`util`.`price`(1) AS `q`,concat(`t`.`name`,'x') AS `c`," +
+				"`shop`.`price`(2) AS `r` from `price` `t`",
+			want: []string{"function `shop`.`price`", "function `util`.`price`", "`shop`.`price`"},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			references, err := References("shop", c.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, r := range references {
+				if r.function {
+					got = append(got, "function "+r.Key())
+				} else {
+					got = append(got, r.Key())
+				}
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+	if _, err := References("shop", "select from"); err == nil {
+		t.Error("invalid body parsed")
+	}
+}
+
+func TestRestoreOrder(t *testing.T) {
+	g := NewGraph()
+	g.AddTable("shop", "t")
+	addView(t, g, "shop", "v3", "select `v2`.`id` AS `id` from `v2`")
+	addView(t, g, "shop", "v2", "select `v1`.`id` AS `id` from `v1` join `t`")
+	addView(t, g, "shop", "v1", "select `t`.`id` AS `id` from `t`")
+	addView(t, g, "shop", "a", "select 1 AS `x`")
+	// c1 and c2 reference each other, self references itself and d
+	// depends on c2
+	addView(t, g, "shop", "c1", "select `c2`.`id` AS `id` from `c2`")
+	addView(t, g, "shop", "c2", "select `c1`.`id` AS `id` from `c1`")
+	addView(t, g, "shop", "self", "select `self`.`id` AS `id` from `self`")
+	addView(t, g, "shop", "d", "select `c2`.`id` AS `id` from `c2` join `v1`")
+
+	order, cycles := g.RestoreOrder()
+	want := []string{"`shop`.`a`", "`shop`.`v1`", "`shop`.`v2`", "`shop`.`v3`"}
+	if got := keys(order); !reflect.DeepEqual(got, want) {
+		t.Errorf("got order %q, want %q", got, want)
+	}
+	var got [][]string
+	for _, cycle := range cycles {
+		got = append(got, keys(cycle))
+	}
+	wantCycles := [][]string{{"`shop`.`c1`", "`shop`.`c2`"}, {"`shop`.`self`"}}
+	if !reflect.DeepEqual(got, wantCycles) {
+		t.Errorf("got cycles %q, want %q", got, wantCycles)
+	}
+	if got := g.Cycles(); len(got) != 2 {
+		t.Errorf("got %d cycles", len(got))
+	}
+
+	r := NewReport("/var/lib/mysql", g, nil)
+	if !reflect.DeepEqual(r.Blocked, []string{"`shop`.`d`"}) || !r.HasProblems() {
+		t.Errorf("got blocked views %q", r.Blocked)
+	}
+}
+
+func TestMissing(t *testing.T) {
+	g := NewGraph()
+	g.AddSchema("empty")
+	g.AddTable("shop", "t")
+	g.AddFunction("shop", "price")
+	addView(t, g, "shop", "v",
+		"select `shop`.`price`(`t`.`id`) AS `p`,`shop`.`tax`(1) AS `x`,`sys`.`format_bytes`(1) AS `b` "+
+			"from `t` join `gone` join `empty`.`u` join `information_schema`.`TABLES` join `INFORMATION_SCHEMA`.`columns` "+
+			"join `archive`.`t`")
+
+	var got []string
+	for _, m := range g.Missing() {
+		got = append(got, m.View.Key()+" "+m.Reference.Key())
+	}
+	want := []string{"`shop`.`v` `shop`.`tax`", "`shop`.`v` `shop`.`gone`", "`shop`.`v` `empty`.`u`"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got missing %q, want %q", got, want)
+	}
+	var kinds []Kind
+	for _, r := range g.Objects["`shop`.`v`"].References {
+		kinds = append(kinds, r.Kind)
+	}
+	wantKinds := []Kind{
+		KIND_FUNCTION, KIND_MISSING, KIND_EXTERNAL, KIND_TABLE, KIND_MISSING, KIND_MISSING,
+		KIND_EXTERNAL, KIND_EXTERNAL, KIND_EXTERNAL,
+	}
+	if !reflect.DeepEqual(kinds, wantKinds) {
+		t.Errorf("got kinds %q, want %q", kinds, wantKinds)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	datadir := t.TempDir()
+	simple, err := os.ReadFile("../../test_frms/table_simple.frm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string][]byte{
+		"shop/db.opt":             nil,
+		"shop/t.frm":              simple,
+		"empty/db.opt":            nil,
+		"#innodb_temp/temp_1.ibt": nil,
+	} {
+		path := filepath.Join(datadir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g, errs, err := Build(datadir)
+	if err != nil || len(errs) != 0 {
+		t.Fatal(err, errs)
+	}
+	want := map[string]bool{"shop": true, "empty": true}
+	if !reflect.DeepEqual(g.schemas, want) || g.Objects["`shop`.`t`"] == nil {
+		t.Errorf("got schemas %v and objects %v", g.schemas, g.Objects)
+	}
+}
@@ -0,0 +1,115 @@
+package depgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Report is the restore order of the views of a datadir with the problems
+// which prevent restoring them
+type Report struct {
+	Datadir string `json:"datadir"`
+	// Order are the views in restore order
+	Order  []string   `json:"order"`
+	Cycles [][]string `json:"cycles,omitempty"`
+	// Blocked are the views which depend on a cycle
+	Blocked []string        `json:"blocked,omitempty"`
+	Missing []*MissingEntry `json:"missing,omitempty"`
+	Errors  []string        `json:"errors,omitempty"`
+}
+
+// MissingEntry is a Missing of a Report
+type MissingEntry struct {
+	View      string `json:"view"`
+	Reference string `json:"reference"`
+	// Function is set if the missing object is a stored function
+	Function bool `json:"function,omitempty"`
+}
+
+// NewReport orders the views of the graph, errs are the errors of Build
+func NewReport(datadir string, g *Graph, errs []error) *Report {
+	r := &Report{Datadir: datadir, Order: []string{}}
+	order, cycles := g.RestoreOrder()
+	ordered := make(map[*Object]bool)
+	for _, o := range order {
+		r.Order = append(r.Order, o.Key())
+		ordered[o] = true
+	}
+	for _, cycle := range cycles {
+		keys := make([]string, len(cycle))
+		for i, o := range cycle {
+			keys[i] = o.Key()
+			ordered[o] = true
+		}
+		r.Cycles = append(r.Cycles, keys)
+	}
+	for _, o := range g.sortedObjects() {
+		if o.Kind == KIND_VIEW && !ordered[o] {
+			r.Blocked = append(r.Blocked, o.Key())
+		}
+	}
+	for _, m := range g.Missing() {
+		r.Missing = append(r.Missing, &MissingEntry{
+			View:      m.View.Key(),
+			Reference: m.Reference.Key(),
+			Function:  m.Reference.function,
+		})
+	}
+	for _, err := range errs {
+		r.Errors = append(r.Errors, err.Error())
+	}
+	return r
+}
+
+// HasProblems reports whether views are in cycles or reference missing
+// objects
+func (r *Report) HasProblems() bool {
+	return len(r.Cycles) != 0 || len(r.Missing) != 0
+}
+
+// WriteJSON writes the report as an indented JSON document
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+func (r *Report) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Datadir: %s\n", r.Datadir)
+	sb.WriteString("\nRestore order:\n")
+	for i, key := range r.Order {
+		fmt.Fprintf(&sb, "  %d. %s\n", i+1, key)
+	}
+	if len(r.Cycles) != 0 {
+		sb.WriteString("\nCycles:\n")
+		for _, cycle := range r.Cycles {
+			fmt.Fprintf(&sb, "  %s\n", strings.Join(cycle, ", "))
+		}
+	}
+	if len(r.Blocked) != 0 {
+		sb.WriteString("\nDepending on a cycle:\n")
+		for _, key := range r.Blocked {
+			fmt.Fprintf(&sb, "  %s\n", key)
+		}
+	}
+	if len(r.Missing) != 0 {
+		sb.WriteString("\nMissing references:\n")
+		for _, m := range r.Missing {
+			kind := "object"
+			if m.Function {
+				kind = "function"
+			}
+			fmt.Fprintf(&sb, "  %s references missing %s %s\n", m.View, kind, m.Reference)
+		}
+	}
+	if len(r.Errors) != 0 {
+		sb.WriteString("\nErrors:\n")
+		for _, err := range r.Errors {
+			fmt.Fprintf(&sb, "  %s\n", err)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,401 @@
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+	"github.com/zing22845/go-frm-parser/frm/trigger"
+	"github.com/zing22845/go-frm-parser/frm/view"
+)
+
+const (
+	STATUS_ADDED   = "added"
+	STATUS_REMOVED = "removed"
+	STATUS_RENAMED = "renamed"
+	STATUS_CHANGED = "changed"
+)
+
+// Change is a schema, table, view or trigger which differs between the
+// two snapshots
+type Change struct {
+	Kind   string `json:"kind"`
+	Status string `json:"status"`
+	Schema string `json:"schema"`
+	Name   string `json:"name,omitempty"`
+	// OldSchema and OldName are the names before a rename
+	OldSchema string `json:"old_schema,omitempty"`
+	OldName   string `json:"old_name,omitempty"`
+	// Details summarize what changed
+	Details []string `json:"details,omitempty"`
+}
+
+// Object names the changed object
+func (c *Change) Object() string {
+	if c.Kind == KIND_SCHEMA {
+		return fmt.Sprintf("`%s`", c.Schema)
+	}
+	return fmt.Sprintf("`%s`.`%s`", c.Schema, c.Name)
+}
+
+// OldObject names the object before a rename
+func (c *Change) OldObject() string {
+	return fmt.Sprintf("`%s`.`%s`", c.OldSchema, c.OldName)
+}
+
+// Report is the schema drift between two snapshots
+type Report struct {
+	Old       string    `json:"old"`
+	New       string    `json:"new"`
+	Changes   []*Change `json:"changes"`
+	Unchanged int       `json:"unchanged"`
+	Errors    []string  `json:"errors,omitempty"`
+}
+
+// Compare returns the schemas and objects added, removed, renamed or
+// changed from old to new. A removed object with the same structure as
+// an added one of the same kind is reported as renamed
+func Compare(old, new *Snapshot) *Report {
+	r := &Report{Old: old.Source, New: new.Source, Changes: []*Change{}}
+	r.Errors = append(append(r.Errors, old.Errors...), new.Errors...)
+	oldSchemas := make(map[string]bool)
+	for _, schema := range old.Schemas {
+		oldSchemas[schema] = true
+	}
+	newSchemas := make(map[string]bool)
+	for _, schema := range new.Schemas {
+		newSchemas[schema] = true
+		if !oldSchemas[schema] {
+			r.Changes = append(r.Changes, &Change{Kind: KIND_SCHEMA, Status: STATUS_ADDED, Schema: schema})
+		}
+	}
+	for _, schema := range old.Schemas {
+		if !newSchemas[schema] {
+			r.Changes = append(r.Changes, &Change{Kind: KIND_SCHEMA, Status: STATUS_REMOVED, Schema: schema})
+		}
+	}
+
+	oldObjects := make(map[string]*Object)
+	for _, o := range old.Objects {
+		oldObjects[o.Key()] = o
+	}
+	newObjects := make(map[string]*Object)
+	var added []*Object
+	for _, o := range new.Objects {
+		newObjects[o.Key()] = o
+		prev, ok := oldObjects[o.Key()]
+		switch {
+		case !ok:
+			added = append(added, o)
+		case prev.Kind != o.Kind:
+			r.Changes = append(r.Changes, &Change{
+				Kind: o.Kind, Status: STATUS_CHANGED, Schema: o.Schema, Name: o.Name,
+				Details: []string{fmt.Sprintf("%s replaced by a %s", prev.Kind, o.Kind)},
+			})
+		case prev.Fingerprint != o.Fingerprint:
+			r.Changes = append(r.Changes, &Change{
+				Kind: o.Kind, Status: STATUS_CHANGED, Schema: o.Schema, Name: o.Name,
+				Details: objectDetails(prev, o),
+			})
+		default:
+			r.Unchanged++
+		}
+	}
+	var removed []*Object
+	for _, o := range old.Objects {
+		if _, ok := newObjects[o.Key()]; !ok {
+			removed = append(removed, o)
+		}
+	}
+
+	// pair the removed and added objects of the same structure
+	renamedFrom := make(map[*Object]*Object)
+	used := make(map[*Object]bool)
+	for _, o := range added {
+		for _, prev := range removed {
+			if !used[prev] && prev.Kind == o.Kind && prev.Structure == o.Structure {
+				renamedFrom[o] = prev
+				used[prev] = true
+				break
+			}
+		}
+	}
+	for _, o := range added {
+		if prev, ok := renamedFrom[o]; ok {
+			r.Changes = append(r.Changes, &Change{
+				Kind: o.Kind, Status: STATUS_RENAMED, Schema: o.Schema, Name: o.Name,
+				OldSchema: prev.Schema, OldName: prev.Name,
+			})
+			continue
+		}
+		r.Changes = append(r.Changes, &Change{
+			Kind: o.Kind, Status: STATUS_ADDED, Schema: o.Schema, Name: o.Name, Details: summary(o),
+		})
+	}
+	for _, o := range removed {
+		if !used[o] {
+			r.Changes = append(r.Changes, &Change{Kind: o.Kind, Status: STATUS_REMOVED, Schema: o.Schema, Name: o.Name})
+		}
+	}
+	r.sort()
+	return r
+}
+
+var (
+	statusOrder  = map[string]int{STATUS_ADDED: 0, STATUS_REMOVED: 1, STATUS_RENAMED: 2, STATUS_CHANGED: 3}
+	kindOrder    = map[string]int{KIND_SCHEMA: 0, KIND_TABLE: 1, KIND_VIEW: 2, KIND_TRIGGER: 3}
+	statusTitles = map[string]string{STATUS_ADDED: "Added", STATUS_REMOVED: "Removed", STATUS_RENAMED: "Renamed"}
+)
+
+func (r *Report) sort() {
+	sort.SliceStable(r.Changes, func(i, j int) bool {
+		a, b := r.Changes[i], r.Changes[j]
+		if statusOrder[a.Status] != statusOrder[b.Status] {
+			return statusOrder[a.Status] < statusOrder[b.Status]
+		}
+		if kindOrder[a.Kind] != kindOrder[b.Kind] {
+			return kindOrder[a.Kind] < kindOrder[b.Kind]
+		}
+		return a.Object() < b.Object()
+	})
+}
+
+// Count returns the number of changes of the status
+func (r *Report) Count(status string) int {
+	count := 0
+	for _, c := range r.Changes {
+		if c.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// WriteJSON writes the report as JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// Markdown formats the report as a Markdown document
+func (r *Report) Markdown() string {
+	var b strings.Builder
+	b.WriteString("# Schema drift report\n\n")
+	fmt.Fprintf(&b, "- Old: `%s`\n- New: `%s`\n\n", r.Old, r.New)
+	b.WriteString("| Added | Removed | Renamed | Changed | Unchanged |\n")
+	b.WriteString("|------:|--------:|--------:|--------:|----------:|\n")
+	fmt.Fprintf(&b, "| %d | %d | %d | %d | %d |\n", r.Count(STATUS_ADDED), r.Count(STATUS_REMOVED),
+		r.Count(STATUS_RENAMED), r.Count(STATUS_CHANGED), r.Unchanged)
+	for _, status := range []string{STATUS_ADDED, STATUS_REMOVED, STATUS_RENAMED} {
+		if r.Count(status) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", statusTitles[status])
+		if status == STATUS_RENAMED {
+			b.WriteString("| Kind | Old name | New name |\n|------|----------|----------|\n")
+		} else {
+			b.WriteString("| Kind | Name | Summary |\n|------|------|---------|\n")
+		}
+		for _, c := range r.Changes {
+			if c.Status != status {
+				continue
+			}
+			if status == STATUS_RENAMED {
+				fmt.Fprintf(&b, "| %s | %s | %s |\n", c.Kind, c.OldObject(), c.Object())
+			} else {
+				fmt.Fprintf(&b, "| %s | %s | %s |\n", c.Kind, c.Object(), escapeCell(strings.Join(c.Details, "; ")))
+			}
+		}
+	}
+	if r.Count(STATUS_CHANGED) != 0 {
+		b.WriteString("\n## Changed\n")
+		for _, c := range r.Changes {
+			if c.Status != STATUS_CHANGED {
+				continue
+			}
+			fmt.Fprintf(&b, "\n### %s %s\n\n", c.Kind, c.Object())
+			for _, detail := range c.Details {
+				fmt.Fprintf(&b, "- %s\n", detail)
+			}
+		}
+	}
+	if len(r.Errors) != 0 {
+		b.WriteString("\n## Errors\n\n")
+		for _, e := range r.Errors {
+			fmt.Fprintf(&b, "- %s\n", e)
+		}
+	}
+	return b.String()
+}
+
+func escapeCell(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
+// summary describes an added object
+func summary(o *Object) []string {
+	switch {
+	case o.Table != nil:
+		return []string{fmt.Sprintf("%d columns, %d keys, ENGINE=%s",
+			len(o.Table.Columns.Items), len(o.Table.Keys.Items), o.Table.Options.Engine)}
+	case o.Trigger != nil:
+		return []string{fmt.Sprintf("%s %s ON `%s`", o.Trigger.Timing, o.Trigger.Event, o.Trigger.Table)}
+	}
+	return nil
+}
+
+// objectDetails summarizes the changes of an object
+func objectDetails(old, new *Object) []string {
+	switch {
+	case old.Table != nil:
+		return tableDetails(old.Table, new.Table)
+	case old.View != nil:
+		return viewDetails(old.View, new.View)
+	case old.Trigger != nil:
+		return triggerDetails(old.Trigger, new.Trigger)
+	}
+	return nil
+}
+
+// tableDetails lists the columns, keys, foreign keys and options which
+// differ
+func tableDetails(old, new *table.MySQLTable) (details []string) {
+	oldColumns := make(map[string]string)
+	var oldOrder []string
+	for _, c := range old.Columns.Items {
+		oldColumns[c.Name] = c.Canonical()
+		oldOrder = append(oldOrder, c.Name)
+	}
+	newColumns := make(map[string]bool)
+	var newOrder []string
+	for _, c := range new.Columns.Items {
+		newColumns[c.Name] = true
+		canonical := c.Canonical()
+		prev, ok := oldColumns[c.Name]
+		switch {
+		case !ok:
+			details = append(details, fmt.Sprintf("column added: %s", canonical))
+		case prev != canonical:
+			details = append(details, fmt.Sprintf("column changed: %s → %s", prev, canonical))
+		}
+		if ok {
+			newOrder = append(newOrder, c.Name)
+		}
+	}
+	var kept []string
+	for _, name := range oldOrder {
+		if !newColumns[name] {
+			details = append(details, fmt.Sprintf("column removed: `%s`", name))
+		} else {
+			kept = append(kept, name)
+		}
+	}
+	if strings.Join(kept, "\x00") != strings.Join(newOrder, "\x00") {
+		details = append(details, "column order changed")
+	}
+	details = append(details, namedDetails("key", keyDefinitions(old), keyDefinitions(new))...)
+	details = append(details, namedDetails("foreign key", foreignKeyDefinitions(old), foreignKeyDefinitions(new))...)
+	if oldOptions, newOptions := old.Options.Canonical(), new.Options.Canonical(); oldOptions != newOptions {
+		details = append(details, fmt.Sprintf("table options changed: %s → %s", oldOptions, newOptions))
+	}
+	return details
+}
+
+type definition struct {
+	name, sql string
+}
+
+func keyDefinitions(mt *table.MySQLTable) (definitions []definition) {
+	for _, k := range mt.Keys.Items {
+		sql := strings.Replace(k.String(), " USING BTREE", "", 1)
+		if sql != "" {
+			definitions = append(definitions, definition{k.Name, strings.TrimSpace(sql)})
+		}
+	}
+	return definitions
+}
+
+func foreignKeyDefinitions(mt *table.MySQLTable) (definitions []definition) {
+	for _, fk := range mt.ForeignKeys {
+		definitions = append(definitions, definition{fk.Name, fk.String()})
+	}
+	return definitions
+}
+
+// namedDetails compares definitions by name
+func namedDetails(kind string, old, new []definition) (details []string) {
+	oldDefinitions := make(map[string]string)
+	for _, d := range old {
+		oldDefinitions[d.name] = d.sql
+	}
+	newNames := make(map[string]bool)
+	for _, d := range new {
+		newNames[d.name] = true
+		prev, ok := oldDefinitions[d.name]
+		switch {
+		case !ok:
+			details = append(details, fmt.Sprintf("%s added: %s", kind, d.sql))
+		case prev != d.sql:
+			details = append(details, fmt.Sprintf("%s changed: %s → %s", kind, prev, d.sql))
+		}
+	}
+	for _, d := range old {
+		if !newNames[d.name] {
+			details = append(details, fmt.Sprintf("%s removed: `%s`", kind, d.name))
+		}
+	}
+	return details
+}
+
+func viewDetails(old, new *view.MySQLView) (details []string) {
+	if old.Algorithm != new.Algorithm {
+		details = append(details, fmt.Sprintf("ALGORITHM changed: %s → %s", old.Algorithm, new.Algorithm))
+	}
+	if old.Definer != new.Definer {
+		details = append(details, fmt.Sprintf("DEFINER changed: %s → %s", old.Definer.String(), new.Definer.String()))
+	}
+	if old.SUID != new.SUID {
+		details = append(details, fmt.Sprintf("SQL SECURITY changed: %s → %s", old.SUID, new.SUID))
+	}
+	if old.CheckOption != new.CheckOption {
+		details = append(details, fmt.Sprintf("CHECK OPTION changed: %s → %s", old.CheckOption, new.CheckOption))
+	}
+	if bodyChanged(old, new) {
+		details = append(details, "view body changed")
+	}
+	return details
+}
+
+// bodyChanged reports whether the view bodies differ by more than
+// whitespace
+func bodyChanged(old, new *view.MySQLView) bool {
+	a, b := *old, *new
+	a.Algorithm, a.Definer, a.SUID, a.CheckOption = b.Algorithm, b.Definer, b.SUID, b.CheckOption
+	a.Name = b.Name
+	return a.Canonical() != b.Canonical()
+}
+
+func triggerDetails(old, new *trigger.MySQLTrigger) (details []string) {
+	if old.Table != new.Table {
+		details = append(details, fmt.Sprintf("table changed: `%s` → `%s`", old.Table, new.Table))
+	}
+	if old.Timing != new.Timing || old.Event != new.Event {
+		details = append(details, fmt.Sprintf("event changed: %s %s → %s %s", old.Timing, old.Event, new.Timing, new.Event))
+	}
+	if old.Order != new.Order {
+		details = append(details, fmt.Sprintf("order changed: %q → %q", old.Order, new.Order))
+	}
+	if old.Definer != new.Definer {
+		details = append(details, fmt.Sprintf("DEFINER changed: %s → %s", old.Definer, new.Definer))
+	}
+	a, b := *old, *new
+	a.Table, a.Timing, a.Event, a.Order, a.Definer = b.Table, b.Timing, b.Event, b.Order, b.Definer
+	if a.Canonical() != b.Canonical() {
+		details = append(details, "trigger body changed")
+	}
+	return details
+}
@@ -0,0 +1,110 @@
+package drift
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zing22845/go-frm-parser/frm/ddl"
+	"github.com/zing22845/go-frm-parser/frm/trigger"
+)
+
+// newSnapshot builds a snapshot of the schemas from the CREATE statements
+// of sql and the .TRG files of triggers, by table name
+func newSnapshot(t *testing.T, source string, schemas []string, sql string, triggers map[string]string) *Snapshot {
+	t.Helper()
+	s := &Snapshot{Source: source, Schemas: schemas}
+	definitions, err := ddl.ParseDefinitions(schemas[0], sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range definitions {
+		if d.Table != nil {
+			s.add(d.Schema, d.Schema+"/"+d.Table.Name+".frm", d.Table)
+		} else {
+			s.add(d.Schema, d.Schema+"/"+d.View.Name+".frm", d.View)
+		}
+	}
+	for name, data := range triggers {
+		path := schemas[0] + "/" + name + ".TRG"
+		parsed, err := trigger.Parse(path, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tr := range parsed {
+			s.add(schemas[0], path, tr)
+		}
+	}
+	s.sort()
+	return s
+}
+
+// trg is a .TRG file of one trigger
+func trg(statement string) string {
+	return "TYPE=TRIGGERS\ntriggers='" + strings.ReplaceAll(statement, "'", "\\'") + "'\n" +
+		"sql_modes=1436549152\ndefiners='root@localhost'\nclient_cs_names='utf8mb4'\n" +
+		"connection_cl_names='utf8mb4_general_ci'\ndb_cl_names='utf8mb4_general_ci'\n"
+}
+
+func changes(r *Report) (lines []string) {
+	for _, c := range r.Changes {
+		line := fmt.Sprintf("%s %s %s", c.Status, c.Kind, c.Object())
+		if c.Status == STATUS_RENAMED {
+			line += " from " + c.OldObject()
+		}
+		for _, detail := range c.Details {
+			line += "; " + detail
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestCompare(t *testing.T) {
+	old := newSnapshot(t, "old", []string{"shop", "legacy"}, `
+CREATE TABLE orders (id INT PRIMARY KEY, total DECIMAL(10,2)) ENGINE=InnoDB;
+CREATE TABLE items (id INT PRIMARY KEY, sku VARCHAR(20)) ENGINE=InnoDB;
+CREATE TABLE customers (id INT PRIMARY KEY, name VARCHAR(80), email VARCHAR(100)) ENGINE=InnoDB;
+CREATE TABLE audit (id BIGINT, note TEXT) ENGINE=InnoDB;
+CREATE TABLE summary (day DATE, total DECIMAL(12,2)) ENGINE=InnoDB;
+CREATE VIEW v_orders AS SELECT id FROM orders;
+CREATE VIEW v_items AS SELECT id FROM items;
+CREATE TABLE legacy.t1 (a INT, b CHAR(3)) ENGINE=MyISAM;
+`, map[string]string{
+		"orders": trg("CREATE DEFINER=`root`@`localhost` TRIGGER orders_bi BEFORE INSERT ON `orders` FOR EACH ROW SET NEW.total = 0"),
+	})
+	new := newSnapshot(t, "new", []string{"shop", "reporting"}, `
+CREATE TABLE orders (id INT PRIMARY KEY, total DECIMAL(12,2), note VARCHAR(20), KEY k_total (total)) ENGINE=InnoDB;
+CREATE TABLE items (id INT PRIMARY KEY, sku VARCHAR(20)) ENGINE=InnoDB;
+CREATE TABLE clients (id INT PRIMARY KEY, name VARCHAR(80), email VARCHAR(100)) ENGINE=InnoDB;
+CREATE VIEW summary AS SELECT id, total FROM orders;
+CREATE VIEW v_orders AS SELECT id, total FROM orders;
+CREATE TABLE v_items (id INT PRIMARY KEY) ENGINE=InnoDB;
+CREATE TABLE reporting.t1 (a INT, b CHAR(3)) ENGINE=MyISAM;
+CREATE TABLE reporting.daily (day DATE PRIMARY KEY, orders INT) ENGINE=InnoDB;
+`, map[string]string{
+		"orders": trg("CREATE DEFINER=`root`@`localhost` TRIGGER orders_bi BEFORE INSERT ON `orders` FOR EACH ROW SET NEW.total = 1"),
+	})
+	r := Compare(old, new)
+	want := []string{
+		"added schema `reporting`",
+		"added table `reporting`.`daily`; 2 columns, 1 keys, ENGINE=InnoDB",
+		"removed schema `legacy`",
+		"removed table `shop`.`audit`",
+		"renamed table `reporting`.`t1` from `legacy`.`t1`",
+		"renamed table `shop`.`clients` from `shop`.`customers`",
+		"changed table `shop`.`orders`; column changed: `total` decimal(10,2) DEFAULT NULL → `total` decimal(12,2) DEFAULT NULL; " +
+			"column added: `note` varchar(20) DEFAULT NULL; key added: KEY `k_total` (`total`)",
+		"changed table `shop`.`v_items`; view replaced by a table",
+		"changed view `shop`.`summary`; table replaced by a view",
+		"changed view `shop`.`v_orders`; view body changed",
+		"changed trigger `shop`.`orders_bi`; trigger body changed",
+	}
+	if got := changes(r); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if r.Unchanged != 1 || r.Old != "old" || r.New != "new" {
+		t.Errorf("%d unchanged, old %s, new %s", r.Unchanged, r.Old, r.New)
+	}
+}
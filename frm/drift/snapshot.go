@@ -0,0 +1,240 @@
+package drift
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zing22845/go-frm-parser/frm"
+	"github.com/zing22845/go-frm-parser/frm/table"
+	"github.com/zing22845/go-frm-parser/frm/trigger"
+	"github.com/zing22845/go-frm-parser/frm/view"
+)
+
+const (
+	KIND_SCHEMA  = "schema"
+	KIND_TABLE   = "table"
+	KIND_VIEW    = "view"
+	KIND_TRIGGER = "trigger"
+)
+
+// Object is a table, view or trigger of a snapshot
+type Object struct {
+	Schema string
+	Name   string
+	Kind   string
+	File   string
+	// Fingerprint is the SHA-256 of the canonical form, Structure the one
+	// of the canonical form without the name, equal for renamed objects
+	Fingerprint string
+	Structure   string
+	Table       *table.MySQLTable
+	View        *view.MySQLView
+	Trigger     *trigger.MySQLTrigger
+}
+
+// Key identifies the object in a snapshot, tables and views share
+// their namespace
+func (o *Object) Key() string {
+	kind := o.Kind
+	if kind == KIND_VIEW {
+		kind = KIND_TABLE
+	}
+	return fmt.Sprintf("%s `%s`.`%s`", kind, o.Schema, o.Name)
+}
+
+// Snapshot holds the schema objects of a datadir or backup archive
+type Snapshot struct {
+	Source  string
+	Schemas []string
+	Objects []*Object
+	// Errors are the files which could not be parsed
+	Errors []string
+}
+
+// Load reads the snapshot of a datadir, or of a .tar, .tar.gz or .tgz
+// archive of one
+func Load(source string) (*Snapshot, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return LoadDatadir(source)
+	}
+	return LoadArchive(source)
+}
+
+// LoadDatadir reads the .frm, .TRG and .sdi files of the schema
+// directories of a datadir, the directories holding none of them nor a
+// db.opt are not schemas
+func LoadDatadir(datadir string) (*Snapshot, error) {
+	s := &Snapshot{Source: datadir}
+	d, err := frm.ReadDatadir(datadir)
+	if err != nil {
+		return nil, err
+	}
+	for _, sd := range d.Schemas {
+		contents := make(map[string][]byte)
+		for _, file := range sd.Files {
+			if !frm.IsSchemaFile(file.Name()) {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(sd.Path, file.Name()))
+			if err != nil {
+				return nil, err
+			}
+			contents[file.Name()] = data
+		}
+		if len(contents) != 0 {
+			s.addSchema(sd.Path, sd.Schema, contents)
+		}
+	}
+	s.sort()
+	return s, nil
+}
+
+// LoadArchive reads the .frm, .TRG and .sdi files of a tar archive of a
+// datadir, gzip compressed or not, the schema of a file is the directory
+// holding it. Tar archives don't keep empty schemas without a db.opt
+func LoadArchive(archive string) (*Snapshot, error) {
+	file, err := os.Open(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var r io.Reader = file
+	if lower := strings.ToLower(archive); strings.HasSuffix(lower, ".gz") || strings.HasSuffix(lower, ".tgz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", archive, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	s := &Snapshot{Source: archive}
+	schemas := make(map[string]map[string][]byte)
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", archive, err)
+		}
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		dir, base := path.Split(name)
+		dir = strings.TrimSuffix(dir, "/")
+		if !header.FileInfo().Mode().IsRegular() || dir == "" || !frm.IsSchemaFile(base) {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", archive, name, err)
+		}
+		if schemas[dir] == nil {
+			schemas[dir] = make(map[string][]byte)
+		}
+		schemas[dir][base] = data
+	}
+	for dir, contents := range schemas {
+		s.addSchema(archive+":"+dir, frm.DecodeSchemaName(path.Base(dir)), contents)
+	}
+	s.sort()
+	return s, nil
+}
+
+// addSchema parses the files of a schema directory
+func (s *Snapshot) addSchema(dir, schema string, contents map[string][]byte) {
+	s.Schemas = append(s.Schemas, schema)
+	names := make([]string, 0, len(contents))
+	frms := make(map[string]bool)
+	for name := range contents {
+		names = append(names, name)
+		if strings.EqualFold(filepath.Ext(name), ".frm") {
+			frms[strings.TrimSuffix(name, filepath.Ext(name))] = true
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		filePath := dir + "/" + name
+		data := contents[name]
+		ext := strings.ToLower(filepath.Ext(name))
+		if name == "db.opt" {
+			continue
+		}
+		if ext == ".trg" {
+			triggers, err := trigger.Parse(filePath, string(data))
+			if err != nil {
+				s.Errors = append(s.Errors, err.Error())
+				continue
+			}
+			for _, t := range triggers {
+				s.add(schema, filePath, t)
+			}
+			continue
+		}
+		if ext == ".sdi" {
+			stem := strings.TrimSuffix(name, filepath.Ext(name))
+			if i := strings.LastIndex(stem, "_"); i >= 0 && frms[stem[:i]] {
+				continue
+			}
+		}
+		parsed, err := frm.Parse(filePath, bytes.NewReader(data))
+		if err != nil {
+			s.Errors = append(s.Errors, fmt.Sprintf("%s: %v", filePath, err))
+			continue
+		}
+		s.add(schema, filePath, parsed)
+	}
+}
+
+// add adds a parsed table, view or trigger
+func (s *Snapshot) add(schema, file string, parsed interface{}) {
+	o := &Object{Schema: schema, File: file}
+	var canonical, structure string
+	switch t := parsed.(type) {
+	case *table.MySQLTable:
+		o.Kind, o.Name, o.Table = KIND_TABLE, t.Name, t
+		anonymous := *t
+		anonymous.Name = ""
+		canonical, structure = t.Canonical(), anonymous.Canonical()
+	case *view.MySQLView:
+		o.Kind, o.Name, o.View = KIND_VIEW, t.Name, t
+		anonymous := *t
+		anonymous.Name = ""
+		canonical, structure = t.Canonical(), anonymous.Canonical()
+	case *trigger.MySQLTrigger:
+		o.Kind, o.Name, o.Trigger = KIND_TRIGGER, t.Name, t
+		anonymous := *t
+		anonymous.Name = ""
+		canonical, structure = t.Canonical(), anonymous.Canonical()
+	default:
+		return
+	}
+	o.Fingerprint, o.Structure = hash(canonical), hash(structure)
+	s.Objects = append(s.Objects, o)
+}
+
+func (s *Snapshot) sort() {
+	sort.Strings(s.Schemas)
+	sort.SliceStable(s.Objects, func(i, j int) bool {
+		return s.Objects[i].Key() < s.Objects[j].Key()
+	})
+}
+
+func hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,147 @@
+package drift
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// datadirFiles are the files of a datadir by path, the ones starting
+// with @ are copied from test_frms
+var datadirFiles = map[string]string{
+	"ibdata1":                 "",
+	"shop/db.opt":             "default-character-set=utf8mb4\n",
+	"shop/t1.frm":             "@table_simple.frm",
+	"shop/t1.ibd":             "",
+	"shop/v1.frm":             "@view_md5_success.frm",
+	"shop/#sql-1a2b_3.frm":    "@table_simple.frm",
+	"shop/broken.frm":         "not a definition",
+	"empty/db.opt":            "default-character-set=utf8mb4\n",
+	"sales@002d2023/t2.frm":   "@table_simple.frm",
+	"sales@002d2023/t2.MYD":   "",
+	"#innodb_temp/temp_1.ibt": "",
+}
+
+func readFile(t *testing.T, data string) []byte {
+	t.Helper()
+	name, ok := strings.CutPrefix(data, "@")
+	if !ok {
+		return []byte(data)
+	}
+	content, err := os.ReadFile(filepath.Join("../../test_frms", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+func writeDatadir(t *testing.T) string {
+	datadir := t.TempDir()
+	for name, data := range datadirFiles {
+		path := filepath.Join(datadir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, readFile(t, data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return datadir
+}
+
+// writeArchive writes the datadir files to a .tar.gz as tar ./datadir does
+func writeArchive(t *testing.T) string {
+	archive := filepath.Join(t.TempDir(), "backup.tar.gz")
+	file, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	dirs := make(map[string]bool)
+	for name, data := range datadirFiles {
+		if dir := filepath.Dir(name); dir != "." && !dirs[dir] {
+			dirs[dir] = true
+			err = tw.WriteHeader(&tar.Header{Name: "./" + dir + "/", Typeflag: tar.TypeDir, Mode: 0o755})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		content := readFile(t, data)
+		err = tw.WriteHeader(&tar.Header{Name: "./" + name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(content))})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = tw.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return archive
+}
+
+func objects(s *Snapshot) (keys []string) {
+	for _, o := range s.Objects {
+		keys = append(keys, o.Key()+" "+o.Kind)
+	}
+	return keys
+}
+
+func TestLoad(t *testing.T) {
+	datadir, err := Load(writeDatadir(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive, err := Load(writeArchive(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"table `sales-2023`.`t2` table",
+		"table `shop`.`t1` table",
+		"table `shop`.`v1` view",
+	}
+	for _, s := range []*Snapshot{datadir, archive} {
+		// #innodb_temp holds no definitions, it is no schema
+		if !reflect.DeepEqual(s.Schemas, []string{"empty", "sales-2023", "shop"}) {
+			t.Errorf("%s: schemas %q", s.Source, s.Schemas)
+		}
+		if got := objects(s); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %q, want %q", s.Source, got, want)
+		}
+		if len(s.Errors) != 1 || !strings.Contains(s.Errors[0], "shop/broken.frm") {
+			t.Errorf("%s: errors %q", s.Source, s.Errors)
+		}
+	}
+	if !strings.HasSuffix(archive.Objects[1].File, "backup.tar.gz:shop/t1.frm") {
+		t.Errorf("file of an archived table %s", archive.Objects[1].File)
+	}
+	// the fingerprints don't depend on where the files come from
+	r := Compare(datadir, archive)
+	if len(r.Changes) != 0 || r.Unchanged != 3 {
+		t.Errorf("changes %q, %d unchanged", changes(r), r.Unchanged)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("loaded a missing datadir")
+	}
+	path := filepath.Join(t.TempDir(), "backup.tgz")
+	if err := os.WriteFile(path, []byte("not gzip"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "backup.tgz") {
+		t.Errorf("loaded an invalid archive: %v", err)
+	}
+}
@@ -4,7 +4,14 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/zing22845/go-frm-parser/frm/archive"
+	"github.com/zing22845/go-frm-parser/frm/ibd"
+	"github.com/zing22845/go-frm-parser/frm/myisam"
+	"github.com/zing22845/go-frm-parser/frm/sdi"
 	"github.com/zing22845/go-frm-parser/frm/table"
 	"github.com/zing22845/go-frm-parser/frm/view"
 )
@@ -15,18 +22,32 @@ type MySQLSchema interface {
 	GetName() string
 }
 
+// isSDI reports whether header is the beginning of a JSON document, as the
+// <table>_<id>.sdi files MySQL 8.0 writes for MyISAM, CSV and ARCHIVE tables
+func isSDI(header []byte) bool {
+	header = bytes.TrimLeft(header, " \t\r\n")
+	return len(header) > 0 && header[0] == '{'
+}
+
 func ParseBuffer(path string, buf *bytes.Buffer) (MySQLSchema, error) {
 	header := buf.Bytes()[:9]
 	if bytes.Equal(header[:2], []byte{0xfe, 0x01}) {
 		return table.Parse(path, buf.Bytes())
 	} else if string(header) == "TYPE=VIEW" {
 		return view.Parse(path, buf.String())
+	} else if isSDI(header) {
+		return sdi.Decode(path, buf.Bytes())
 	} else {
 		return nil, fmt.Errorf("invalid input format")
 	}
 }
 
 func Parse(path string, r io.Reader) (MySQLSchema, error) {
+	return ParseWithOptions(path, r, nil)
+}
+
+// ParseWithOptions is Parse with the options of view files
+func ParseWithOptions(path string, r io.Reader, opts *view.ParseOptions) (MySQLSchema, error) {
 	// Create a bytes.Buffer to store the entire input
 	var buf bytes.Buffer
 
@@ -57,8 +78,116 @@ func Parse(path string, r io.Reader) (MySQLSchema, error) {
 		if err != nil {
 			return nil, err
 		}
-		return view.Parse(path, buf.String())
+		return view.ParseWithOptions(path, buf.String(), opts)
+	} else if isSDI(header) {
+		// Read the rest of the input and parse it as a MySQL 8.0 SDI
+		_, err = io.Copy(&buf, r)
+		if err != nil {
+			return nil, err
+		}
+		return sdi.Decode(path, buf.Bytes())
 	} else {
 		return nil, fmt.Errorf("invalid input format")
 	}
 }
+
+// ParseFile parses the .frm (or MySQL 8.0 .sdi, or ARCHIVE .ARZ) file at path, for tables the files next to it
+// (.isl, .MYD/.MYI symlinks, .MYI state, .MRG, .ARZ header) are used to fill what the .frm doesn't record
+func ParseFile(path string) (MySQLSchema, error) {
+	return ParseFileWithOptions(path, nil)
+}
+
+// ParseFileWithOptions is ParseFile with the options of view files, with
+// view.MD5_REPAIR the md5 of a view file which doesn't match is rewritten
+func ParseFileWithOptions(path string, opts *view.ParseOptions) (MySQLSchema, error) {
+	if strings.EqualFold(filepath.Ext(path), ".ARZ") {
+		// the table definition embedded in an ARCHIVE data file
+		t, _, err := archive.ParseFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return t, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	schema, err := ParseWithOptions(path, file, opts)
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := schema.(*view.MySQLView); ok && opts != nil && opts.MD5Mode == view.MD5_REPAIR && !v.MD5Matches() {
+		file.Close()
+		repaired, err := view.RepairMD5(path, opts)
+		if err != nil {
+			return nil, err
+		}
+		repaired.Warnings = append(v.Warnings, "md5 repaired")
+		return repaired, nil
+	}
+	if t, ok := schema.(*table.MySQLTable); ok {
+		t.ResolveDataDirectory(filepath.Dir(path))
+		if strings.EqualFold(t.Options.Engine, "MyISAM") {
+			// the .MYI only adds AUTO_INCREMENT and statistics
+			_, err = myisam.ResolveState(filepath.Dir(path), t)
+			if err != nil {
+				t.Warnings = append(t.Warnings, fmt.Sprintf("read .MYI state: %v", err))
+			}
+		}
+		if strings.EqualFold(t.Options.Engine, "ARCHIVE") {
+			// the .ARZ header only adds AUTO_INCREMENT and the row count
+			_, err = archive.ResolveHeader(filepath.Dir(path), t)
+			if err != nil {
+				t.Warnings = append(t.Warnings, fmt.Sprintf("read .ARZ header: %v", err))
+			}
+		}
+		if strings.EqualFold(t.Options.Engine, "MRG_MYISAM") {
+			t.ResolveMergeFile(filepath.Dir(path))
+		}
+	}
+	return schema, nil
+}
+
+// ParseIBD extracts the table definitions stored in the SDI pages of a
+// MySQL 8.0 .ibd file
+func ParseIBD(path string, r io.ReaderAt) ([]MySQLSchema, error) {
+	tables, err := sdi.ParseIBD(path, r)
+	if err != nil {
+		return nil, err
+	}
+	schemas := make([]MySQLSchema, 0, len(tables))
+	for _, t := range tables {
+		schemas = append(schemas, t)
+	}
+	return schemas, nil
+}
+
+// CheckIBD parses the .frm file at path and checks it against the
+// InnoDB tablespace header of its .ibd files
+func CheckIBD(path string) ([]*ibd.Report, error) {
+	schema, err := ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	t, ok := schema.(*table.MySQLTable)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a table", path)
+	}
+	return ibd.CheckTable(t, filepath.Dir(path))
+}
+
+// CheckRowSize parses the .frm (or .sdi) file at path and checks its row
+// size against the limits of the server and of InnoDB
+func CheckRowSize(path string, opts *ibd.RowSizeOptions) (*ibd.RowSizeReport, error) {
+	schema, err := ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	t, ok := schema.(*table.MySQLTable)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a table", path)
+	}
+	return ibd.CheckRowSize(t, opts)
+}
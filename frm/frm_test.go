@@ -0,0 +1,152 @@
+package frm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+	"github.com/zing22845/go-frm-parser/frm/view"
+)
+
+// myisamFRM returns table_simple.frm turned into a MyISAM table
+func myisamFRM(t *testing.T) []byte {
+	data, err := os.ReadFile("../test_frms/table_simple.frm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.Replace(data, []byte("InnoDB"), []byte("MyISAM"), 1)
+}
+
+func TestParseFileWithTruncatedMYI(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "t1.frm")
+	if err := os.WriteFile(path, myisamFRM(t), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "t1.MYI"), []byte("xx"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("a truncated .MYI should not fail parsing: %v", err)
+	}
+	mt := schema.(*table.MySQLTable)
+	if mt.Options.Engine != "MyISAM" {
+		t.Fatalf("engine %s", mt.Options.Engine)
+	}
+	if len(mt.Warnings) != 1 || !strings.Contains(mt.Warnings[0], ".MYI") {
+		t.Errorf("warnings %q", mt.Warnings)
+	}
+	if mt.Statistics != nil {
+		t.Error("statistics set from an unreadable .MYI")
+	}
+}
+
+// archiveFRM returns table_simple.frm turned into an ARCHIVE table, the
+// engine name is one byte longer than InnoDB and takes a byte of the
+// padding after the extra segment
+func archiveFRM(t *testing.T) []byte {
+	data, err := os.ReadFile("../test_frms/table_simple.frm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := bytes.Index(data, []byte("\x06\x00InnoDB"))
+	length := binary.LittleEndian.Uint32(data[0x37:])
+	// the extra segment starts with the length of the connection string
+	start := i - 2
+	extra := append([]byte{}, data[start:i]...)
+	extra = append(extra, "\x07\x00ARCHIVE"...)
+	extra = append(extra, data[i+8:start+int(length)]...)
+	copy(data[start:], extra)
+	binary.LittleEndian.PutUint32(data[0x37:], length+1)
+	return data
+}
+
+func TestParseFileWithUnsupportedARZ(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "t1.frm")
+	if err := os.WriteFile(path, archiveFRM(t), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// the version 1 format of MySQL 5.0
+	arz := make([]byte, 128)
+	arz[0], arz[1] = 0xfe, 1
+	if err := os.WriteFile(filepath.Join(dir, "t1.ARZ"), arz, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("an unsupported .ARZ should not fail parsing: %v", err)
+	}
+	mt := schema.(*table.MySQLTable)
+	if mt.Options.Engine != "ARCHIVE" {
+		t.Fatalf("engine %s", mt.Options.Engine)
+	}
+	if len(mt.Warnings) != 1 || !strings.Contains(mt.Warnings[0], "unsupported ARZ version 1") {
+		t.Errorf("warnings %q", mt.Warnings)
+	}
+	if mt.Statistics != nil || mt.Options.AutoIncrement != 0 {
+		t.Error("statistics set from an unsupported .ARZ")
+	}
+}
+
+func TestParseFileWithMD5Mode(t *testing.T) {
+	const stored, computed = "6f85afbfa98a19d78ab7fd9d46ed3c0c", "f39985101a3892917d4ae3c8c477bf82"
+	original, err := os.ReadFile("../test_frms/view_md5_mismatch.frm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		mode     view.MD5Mode
+		mismatch bool
+		warnings int
+		// rewritten is whether the file gets the computed md5
+		rewritten bool
+	}{
+		{mode: view.MD5_IGNORE},
+		{mode: view.MD5_WARN, warnings: 1},
+		{mode: view.MD5_STRICT, mismatch: true},
+		{mode: view.MD5_REPAIR, warnings: 2, rewritten: true},
+	} {
+		path := filepath.Join(t.TempDir(), "v1.frm")
+		if err := os.WriteFile(path, original, 0o640); err != nil {
+			t.Fatal(err)
+		}
+		schema, err := ParseFileWithOptions(path, &view.ParseOptions{MD5Mode: c.mode, UseSource: true})
+		if errors.Is(err, view.ErrMD5Mismatch) != c.mismatch || err != nil && !c.mismatch {
+			t.Fatalf("mode %d: error %v", c.mode, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := original
+		if c.rewritten {
+			want = bytes.Replace(original, []byte("md5="+stored), []byte("md5="+computed), 1)
+		}
+		if !bytes.Equal(data, want) {
+			t.Errorf("mode %d: file content\n%s", c.mode, data)
+		}
+		if c.mismatch {
+			continue
+		}
+		v := schema.(*view.MySQLView)
+		if len(v.Warnings) != c.warnings {
+			t.Errorf("mode %d: warnings %q", c.mode, v.Warnings)
+		}
+		if !v.UseSource {
+			t.Errorf("mode %d: options not applied", c.mode)
+		}
+		if c.rewritten && (v.StoredMD5 != computed || !v.MD5Matches()) {
+			t.Errorf("mode %d: stored md5 %s", c.mode, v.StoredMD5)
+		}
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o640 {
+			t.Errorf("mode %d: file mode %v, %v", c.mode, info.Mode(), err)
+		}
+	}
+}
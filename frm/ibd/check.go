@@ -0,0 +1,184 @@
+package ibd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+	"github.com/zing22845/go-frm-parser/frm/utils"
+)
+
+// Mismatch is a table property on which the .frm and the tablespace disagree
+type Mismatch struct {
+	Property string
+	FRM      string
+	IBD      string
+}
+
+func (m *Mismatch) String() string {
+	return fmt.Sprintf("%s: .frm has %s, .ibd has %s", m.Property, m.FRM, m.IBD)
+}
+
+// Report is the result of checking a .frm against one of its .ibd files
+type Report struct {
+	Table string
+	Path  string
+	// Header is nil if the file is missing or can't be read
+	Header     *Header
+	Mismatches []*Mismatch
+}
+
+// Consistent reports whether the .frm and the .ibd belong together
+func (r *Report) Consistent() bool {
+	return len(r.Mismatches) == 0
+}
+
+func (r *Report) String() string {
+	status := "OK"
+	if !r.Consistent() {
+		status = "MISMATCH"
+	}
+	lines := []string{fmt.Sprintf("%s: %s (table %s)", r.Path, status, r.Table)}
+	if r.Header != nil {
+		lines[0] = fmt.Sprintf("%s: %s (table %s, space id %d, %s)",
+			r.Path, status, r.Table, r.Header.SpaceID, r.Header.Flags)
+	}
+	for _, m := range r.Mismatches {
+		lines = append(lines, "  "+m.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *Report) addMismatch(property, frm, ibd string) {
+	r.Mismatches = append(r.Mismatches, &Mismatch{Property: property, FRM: frm, IBD: ibd})
+}
+
+// CheckFile checks the tablespace file at path against mt
+func CheckFile(mt *table.MySQLTable, path string) (*Report, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	h, err := ReadHeader(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return Check(mt, path, h), nil
+}
+
+// Check compares the tablespace header h of the .ibd file at path with the
+// ENGINE, ROW_FORMAT, KEY_BLOCK_SIZE and DATA DIRECTORY of mt. DATA DIRECTORY
+// is not in the .frm, it is only known if mt was resolved with its .isl files
+func Check(mt *table.MySQLTable, path string, h *Header) *Report {
+	r := &Report{Table: mt.Name, Path: path, Header: h}
+	stem := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	dataDirectory, ok := mt.DataDirectoryOf(stem)
+	if !ok {
+		r.addMismatch("TABLE", mt.Name, stem)
+	}
+	if !strings.EqualFold(mt.Options.Engine, "InnoDB") {
+		r.addMismatch("ENGINE", mt.Options.Engine, "InnoDB")
+	}
+	if h.Flags.SDI() {
+		r.addMismatch("VERSION", mt.MySQLVersion.String(), "8.0 (SDI)")
+	}
+	if h.Flags.Shared() {
+		// the row format of a general tablespace is the one of each table
+		r.addMismatch("TABLESPACE", "file-per-table", "general tablespace")
+		return r
+	}
+	checkRowFormat(r, mt.Options, h.Flags)
+	if dataDirectory != "" && !h.Flags.DataDir() {
+		r.addMismatch("DATA DIRECTORY", dataDirectory, "not set")
+	} else if dataDirectory == "" && h.Flags.DataDir() {
+		r.addMismatch("DATA DIRECTORY", "not set", "set")
+	}
+	return r
+}
+
+// checkRowFormat follows the way InnoDB chooses the row format at
+// CREATE TABLE, the default row format is innodb_default_row_format
+// which can be either COMPACT or DYNAMIC
+func checkRowFormat(r *Report, o *table.Options, flags TablespaceFlags) {
+	expected := ""
+	keyBlockSize := o.KeyBlockSize
+	switch o.RowFormat {
+	case table.RT_COMPRESSED:
+		expected = "COMPRESSED"
+	case table.RT_DYNAMIC:
+		expected = "DYNAMIC"
+	case table.RT_COMPACT, table.RT_REDUNDANT:
+		expected = "ANTELOPE"
+	default:
+		// KEY_BLOCK_SIZE alone implies ROW_FORMAT=COMPRESSED
+		if keyBlockSize != 0 {
+			expected = "COMPRESSED"
+		}
+	}
+	frmRowFormat := o.RowFormat.String()
+	if frmRowFormat == "" {
+		frmRowFormat = "DEFAULT"
+	}
+	actual := flags.RowFormat()
+	switch {
+	case expected == "" && actual == "COMPRESSED":
+		r.addMismatch("ROW_FORMAT", frmRowFormat, actual)
+	case expected != "" && expected != actual:
+		r.addMismatch("ROW_FORMAT", frmRowFormat, actual)
+	case expected == "COMPRESSED":
+		if keyBlockSize == 0 {
+			// half of the page size, at most 8KiB
+			keyBlockSize = uint16(flags.PageSize() / 2 / 1024)
+			if keyBlockSize > 8 {
+				keyBlockSize = 8
+			}
+		}
+		if keyBlockSize != flags.KeyBlockSize() {
+			r.addMismatch("KEY_BLOCK_SIZE",
+				fmt.Sprint(keyBlockSize), fmt.Sprint(flags.KeyBlockSize()))
+		}
+	}
+}
+
+// CheckTable checks every .ibd file of mt, one per (sub)partition for
+// partitioned tables. dir is the schema directory, files created with
+// DATA DIRECTORY are looked up there. A file which is missing or can't be
+// read, and files sharing a space id, are reported as mismatches
+func CheckTable(mt *table.MySQLTable, dir string) ([]*Report, error) {
+	stems := []string{utils.EncodeMySQLObject2File(mt.Name)}
+	if mt.Options.Partitioning != nil {
+		stems = mt.Options.Partitioning.FileNames(mt.Name)
+	}
+	reports := make([]*Report, 0, len(stems))
+	spaces := make(map[uint32]*Report)
+	for _, stem := range stems {
+		path := filepath.Join(dir, stem+".ibd")
+		if dataDirectory, _ := mt.DataDirectoryOf(stem); dataDirectory != "" {
+			path = filepath.Join(dataDirectory, filepath.Base(dir), stem+".ibd")
+		}
+		report, err := CheckFile(mt, path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			report = &Report{Table: mt.Name, Path: path}
+			report.addMismatch("FILE", stem+".ibd", "missing")
+		case err != nil:
+			report = &Report{Table: mt.Name, Path: path}
+			report.addMismatch("FILE", stem+".ibd", err.Error())
+		case spaces[report.Header.SpaceID] != nil:
+			// a copy of another file of the table
+			report.addMismatch("SPACE ID", "a space id of its own",
+				fmt.Sprintf("%d, as %s", report.Header.SpaceID, spaces[report.Header.SpaceID].Path))
+		case report.Header.SpaceID == 0:
+			report.addMismatch("SPACE ID", "a file-per-table space id", "0 (system tablespace)")
+		default:
+			spaces[report.Header.SpaceID] = report
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
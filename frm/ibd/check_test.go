@@ -0,0 +1,68 @@
+package ibd
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+// writeTablespace writes the page 0 of a file-per-table tablespace
+func writeTablespace(t *testing.T, path string, spaceID uint32) {
+	page := make([]byte, UNIV_PAGE_SIZE_DEF)
+	binary.BigEndian.PutUint16(page[FIL_PAGE_TYPE:], uint16(FIL_PAGE_TYPE_FSP_HDR))
+	binary.BigEndian.PutUint32(page[FIL_PAGE_ARCH_LOG_NO_OR_SPACE_ID:], spaceID)
+	binary.BigEndian.PutUint32(page[FSP_HEADER_OFFSET+FSP_SPACE_ID:], spaceID)
+	if err := os.WriteFile(path, page, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckTablePartitions(t *testing.T) {
+	mt := &table.MySQLTable{
+		Name: "t1",
+		Options: &table.Options{
+			Engine:     "InnoDB",
+			Partitions: " PARTITION BY HASH (id)\nPARTITIONS 4",
+		},
+	}
+	if err := mt.Options.DecodePartitioning(); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	writeTablespace(t, filepath.Join(dir, "t1#P#p0.ibd"), 10)
+	writeTablespace(t, filepath.Join(dir, "t1#P#p2.ibd"), 10)
+	writeTablespace(t, filepath.Join(dir, "t1#P#p3.ibd"), 12)
+
+	reports, err := CheckTable(mt, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		consistent bool
+		property   string
+	}{
+		{true, ""},
+		{false, "FILE"},
+		{false, "SPACE ID"},
+		{true, ""},
+	}
+	if len(reports) != len(want) {
+		t.Fatalf("%d reports, want %d", len(reports), len(want))
+	}
+	for i, w := range want {
+		r := reports[i]
+		if r.Consistent() != w.consistent {
+			t.Errorf("%s: consistent %v, want %v: %s", r.Path, r.Consistent(), w.consistent, r)
+			continue
+		}
+		if !w.consistent && r.Mismatches[0].Property != w.property {
+			t.Errorf("%s: %s, want %s", r.Path, r.Mismatches[0].Property, w.property)
+		}
+	}
+	if reports[1].Header != nil || reports[1].String() == "" {
+		t.Errorf("missing file report: %s", reports[1])
+	}
+}
@@ -0,0 +1,124 @@
+package ibd
+
+const (
+	// FIL_PAGE_DATA is the size of the FIL header, page data starts here
+	FIL_PAGE_DATA = 38
+	// FIL_PAGE_DATA_END is the size of the FIL trailer
+	FIL_PAGE_DATA_END = 8
+	// FIL_NULL is the page number of a missing page
+	FIL_NULL = 0xFFFFFFFF
+
+	// offsets in the FIL header
+	FIL_PAGE_OFFSET                  = 4
+	FIL_PAGE_PREV                    = 8
+	FIL_PAGE_NEXT                    = 12
+	FIL_PAGE_LSN                     = 16
+	FIL_PAGE_TYPE                    = 24
+	FIL_PAGE_FILE_FLUSH_LSN          = 26
+	FIL_PAGE_ARCH_LOG_NO_OR_SPACE_ID = 34
+
+	// FSP_HEADER_OFFSET is where the tablespace header starts on page 0
+	FSP_HEADER_OFFSET = FIL_PAGE_DATA
+	// FSP_HEADER_SIZE is the size of the tablespace header
+	FSP_HEADER_SIZE = 112
+
+	// offsets in the FSP header
+	FSP_SPACE_ID    = 0
+	FSP_SIZE        = 8
+	FSP_FREE_LIMIT  = 12
+	FSP_SPACE_FLAGS = 16
+
+	// XDES_ARR_OFFSET is where the extent descriptors start on page 0
+	XDES_ARR_OFFSET = FSP_HEADER_OFFSET + FSP_HEADER_SIZE
+	// XDES_BITMAP is the offset of the page bitmap in an extent descriptor
+	XDES_BITMAP = 24
+	// ENCRYPTION_INFO_MAX_SIZE is the space reserved for the encryption
+	// key after the extent descriptors on page 0
+	ENCRYPTION_INFO_MAX_SIZE = 115
+
+	// UNIV_PAGE_SIZE_DEF is the default InnoDB page size
+	UNIV_PAGE_SIZE_DEF = 16384
+
+	// offsets in the INDEX page header, relative to PAGE_HEADER
+	PAGE_HEADER      = FIL_PAGE_DATA
+	PAGE_N_DIR_SLOTS = 0
+	PAGE_HEAP_TOP    = 2
+	PAGE_N_HEAP      = 4
+	PAGE_FREE        = 6
+	PAGE_GARBAGE     = 8
+	PAGE_LAST_INSERT = 10
+	PAGE_DIRECTION   = 12
+	PAGE_N_DIRECTION = 14
+	PAGE_N_RECS      = 16
+	PAGE_MAX_TRX_ID  = 18
+	PAGE_LEVEL       = 26
+	PAGE_INDEX_ID    = 28
+	// PAGE_DATA is where the infimum record starts
+	PAGE_DATA = PAGE_HEADER + 36 + 2*10
+
+	// origins of the infimum and supremum records
+	PAGE_NEW_INFIMUM  = PAGE_DATA + REC_N_NEW_EXTRA_BYTES
+	PAGE_NEW_SUPREMUM = PAGE_NEW_INFIMUM + 13
+	PAGE_OLD_INFIMUM  = PAGE_DATA + 1 + REC_N_OLD_EXTRA_BYTES
+	PAGE_OLD_SUPREMUM = PAGE_OLD_INFIMUM + 15
+
+	// REC_N_NEW_EXTRA_BYTES is the size of the COMPACT record header
+	REC_N_NEW_EXTRA_BYTES = 5
+	// REC_N_OLD_EXTRA_BYTES is the size of the REDUNDANT record header
+	REC_N_OLD_EXTRA_BYTES = 6
+
+	// BLOB page header, relative to FIL_PAGE_DATA
+	BTR_BLOB_HDR_PART_LEN     = 0
+	BTR_BLOB_HDR_NEXT_PAGE_NO = 4
+	BTR_BLOB_HDR_SIZE         = 8
+
+	// BTR_EXTERN_FIELD_REF_SIZE is the size of the reference to an
+	// externally stored field
+	BTR_EXTERN_FIELD_REF_SIZE = 20
+)
+
+// PageType represents the FIL_PAGE_TYPE of a page
+type PageType uint16
+
+const (
+	FIL_PAGE_TYPE_ALLOCATED PageType = 0
+	FIL_PAGE_UNDO_LOG       PageType = 2
+	FIL_PAGE_INODE          PageType = 3
+	FIL_PAGE_IBUF_FREE_LIST PageType = 4
+	FIL_PAGE_IBUF_BITMAP    PageType = 5
+	FIL_PAGE_TYPE_SYS       PageType = 6
+	FIL_PAGE_TYPE_TRX_SYS   PageType = 7
+	FIL_PAGE_TYPE_FSP_HDR   PageType = 8
+	FIL_PAGE_TYPE_XDES      PageType = 9
+	FIL_PAGE_TYPE_BLOB      PageType = 10
+	FIL_PAGE_TYPE_ZBLOB     PageType = 11
+	FIL_PAGE_TYPE_ZBLOB2    PageType = 12
+	FIL_PAGE_TYPE_UNKNOWN   PageType = 13
+	FIL_PAGE_COMPRESSED     PageType = 14
+	FIL_PAGE_ENCRYPTED      PageType = 15
+	FIL_PAGE_TYPE_LOB_INDEX PageType = 22
+	FIL_PAGE_TYPE_LOB_DATA  PageType = 23
+	FIL_PAGE_TYPE_LOB_FIRST PageType = 24
+	FIL_PAGE_SDI            PageType = 17853
+	FIL_PAGE_RTREE          PageType = 17854
+	FIL_PAGE_SDI_BLOB       PageType = 18431
+	FIL_PAGE_SDI_ZBLOB      PageType = 18432
+	FIL_PAGE_INDEX          PageType = 17855
+)
+
+// RecordType represents the status bits of a COMPACT record header
+type RecordType uint8
+
+const (
+	REC_STATUS_ORDINARY RecordType = iota
+	REC_STATUS_NODE_PTR
+	REC_STATUS_INFIMUM
+	REC_STATUS_SUPREMUM
+)
+
+const (
+	// REC_INFO_MIN_REC_FLAG marks the leftmost node pointer of a level
+	REC_INFO_MIN_REC_FLAG = 0x10
+	// REC_INFO_DELETED_FLAG marks a delete-marked record
+	REC_INFO_DELETED_FLAG = 0x20
+)
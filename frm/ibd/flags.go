@@ -0,0 +1,164 @@
+package ibd
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// bits of the tablespace flags, FSP_FLAGS_* in fsp0types.h
+const (
+	FSP_FLAGS_MASK_POST_ANTELOPE = 1 << 0
+	FSP_FLAGS_POS_ZIP_SSIZE      = 1
+	FSP_FLAGS_MASK_ZIP_SSIZE     = 0xF << FSP_FLAGS_POS_ZIP_SSIZE
+	FSP_FLAGS_MASK_ATOMIC_BLOBS  = 1 << 5
+	FSP_FLAGS_POS_PAGE_SSIZE     = 6
+	FSP_FLAGS_MASK_PAGE_SSIZE    = 0xF << FSP_FLAGS_POS_PAGE_SSIZE
+	FSP_FLAGS_MASK_DATA_DIR      = 1 << 10
+	FSP_FLAGS_MASK_SHARED        = 1 << 11
+	FSP_FLAGS_MASK_TEMPORARY     = 1 << 12
+	FSP_FLAGS_MASK_ENCRYPTION    = 1 << 13
+)
+
+// TablespaceFlags are the FSP_SPACE_FLAGS of the tablespace header
+type TablespaceFlags uint32
+
+func (f TablespaceFlags) PostAntelope() bool {
+	return f&FSP_FLAGS_MASK_POST_ANTELOPE != 0
+}
+
+// ZipSSize is the compressed page size shift, 0 if not compressed
+func (f TablespaceFlags) ZipSSize() uint32 {
+	return uint32(f&FSP_FLAGS_MASK_ZIP_SSIZE) >> FSP_FLAGS_POS_ZIP_SSIZE
+}
+
+func (f TablespaceFlags) IsCompressed() bool {
+	return f.ZipSSize() != 0
+}
+
+// KeyBlockSize is the KEY_BLOCK_SIZE in KiB of a compressed tablespace
+func (f TablespaceFlags) KeyBlockSize() uint16 {
+	if !f.IsCompressed() {
+		return 0
+	}
+	return uint16((512 << f.ZipSSize()) / 1024)
+}
+
+func (f TablespaceFlags) AtomicBlobs() bool {
+	return f&FSP_FLAGS_MASK_ATOMIC_BLOBS != 0
+}
+
+// PageSize is the logical page size, PAGE_SSIZE 0 means the default 16KiB
+func (f TablespaceFlags) PageSize() uint32 {
+	ssize := uint32(f&FSP_FLAGS_MASK_PAGE_SSIZE) >> FSP_FLAGS_POS_PAGE_SSIZE
+	if ssize == 0 {
+		return UNIV_PAGE_SIZE_DEF
+	}
+	return 512 << ssize
+}
+
+// PhysicalPageSize is the size of the pages in the file
+func (f TablespaceFlags) PhysicalPageSize() uint32 {
+	if f.IsCompressed() {
+		return 512 << f.ZipSSize()
+	}
+	return f.PageSize()
+}
+
+// DataDir is set if the table was created with DATA DIRECTORY
+func (f TablespaceFlags) DataDir() bool {
+	return f&FSP_FLAGS_MASK_DATA_DIR != 0
+}
+
+// Shared is set for general tablespaces
+func (f TablespaceFlags) Shared() bool {
+	return f&FSP_FLAGS_MASK_SHARED != 0
+}
+
+func (f TablespaceFlags) Temporary() bool {
+	return f&FSP_FLAGS_MASK_TEMPORARY != 0
+}
+
+func (f TablespaceFlags) Encryption() bool {
+	return f&FSP_FLAGS_MASK_ENCRYPTION != 0
+}
+
+// SDI is set for MySQL 8.0 tablespaces
+func (f TablespaceFlags) SDI() bool {
+	return f&FSP_FLAGS_MASK_SDI != 0
+}
+
+// RowFormat is the row format a file-per-table tablespace was created for,
+// REDUNDANT and COMPACT share the same flags and are reported as "ANTELOPE"
+func (f TablespaceFlags) RowFormat() string {
+	switch {
+	case f.IsCompressed():
+		return "COMPRESSED"
+	case f.AtomicBlobs():
+		return "DYNAMIC"
+	default:
+		return "ANTELOPE"
+	}
+}
+
+func (f TablespaceFlags) String() string {
+	parts := []string{
+		fmt.Sprintf("PAGE_SIZE=%d", f.PageSize()),
+		fmt.Sprintf("ROW_FORMAT=%s", f.RowFormat()),
+	}
+	if f.IsCompressed() {
+		parts = append(parts, fmt.Sprintf("KEY_BLOCK_SIZE=%d", f.KeyBlockSize()))
+	}
+	for _, flag := range []struct {
+		set  bool
+		name string
+	}{
+		{f.PostAntelope(), "POST_ANTELOPE"},
+		{f.AtomicBlobs(), "ATOMIC_BLOBS"},
+		{f.DataDir(), "DATA_DIR"},
+		{f.Shared(), "SHARED"},
+		{f.Temporary(), "TEMPORARY"},
+		{f.Encryption(), "ENCRYPTION"},
+		{f.SDI(), "SDI"},
+	} {
+		if flag.set {
+			parts = append(parts, flag.name)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Header is the FSP header on page 0 of a tablespace
+type Header struct {
+	SpaceID   uint32
+	Size      uint32
+	FreeLimit uint32
+	Flags     TablespaceFlags
+}
+
+// ReadHeader reads the FSP header from page 0, it works for
+// compressed tablespaces as well
+func ReadHeader(r io.ReaderAt) (*Header, error) {
+	header := make([]byte, FSP_HEADER_OFFSET+FSP_HEADER_SIZE)
+	_, err := r.ReadAt(header, 0)
+	if err != nil {
+		return nil, fmt.Errorf("read tablespace header failed: %w", err)
+	}
+	if PageType(binary.BigEndian.Uint16(header[FIL_PAGE_TYPE:])) != FIL_PAGE_TYPE_FSP_HDR {
+		return nil, fmt.Errorf("page 0 is not a FSP_HDR page")
+	}
+	fsp := header[FSP_HEADER_OFFSET:]
+	h := &Header{
+		SpaceID:   binary.BigEndian.Uint32(fsp[FSP_SPACE_ID:]),
+		Size:      binary.BigEndian.Uint32(fsp[FSP_SIZE:]),
+		FreeLimit: binary.BigEndian.Uint32(fsp[FSP_FREE_LIMIT:]),
+		Flags:     TablespaceFlags(binary.BigEndian.Uint32(fsp[FSP_SPACE_FLAGS:])),
+	}
+	spaceID := binary.BigEndian.Uint32(header[FIL_PAGE_ARCH_LOG_NO_OR_SPACE_ID:])
+	if spaceID != h.SpaceID {
+		return nil, fmt.Errorf("space id %d in the FIL header differs from %d in the FSP header",
+			spaceID, h.SpaceID)
+	}
+	return h, nil
+}
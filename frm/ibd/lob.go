@@ -0,0 +1,125 @@
+package ibd
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	// offsets in an external field reference
+	BTR_EXTERN_SPACE_ID = 0
+	BTR_EXTERN_PAGE_NO  = 4
+	BTR_EXTERN_OFFSET   = 8
+	BTR_EXTERN_LEN      = 12
+
+	// FIL_ADDR_SIZE is the size of a file address, page number and offset
+	FIL_ADDR_SIZE = 6
+	// FLST_BASE_NODE_SIZE is the size of a list base node, length,
+	// first and last address
+	FLST_BASE_NODE_SIZE = 4 + 2*FIL_ADDR_SIZE
+
+	// offsets in the first page of a MySQL 8.0 LOB
+	LOB_FIRST_OFFSET_DATA_LEN   = FIL_PAGE_DATA + 16
+	LOB_FIRST_OFFSET_INDEX_LIST = FIL_PAGE_DATA + 26
+	LOB_FIRST_PAGE_DATA         = LOB_FIRST_OFFSET_INDEX_LIST + 2*FLST_BASE_NODE_SIZE
+	// LOB_FIRST_INDEX_ENTRIES is the number of index entries on the first
+	// page of a LOB in a 16k page
+	LOB_FIRST_INDEX_ENTRIES = 10
+
+	// offsets in a LOB index entry
+	LOB_INDEX_ENTRY_NEXT     = FIL_ADDR_SIZE
+	LOB_INDEX_ENTRY_PAGE_NO  = 48
+	LOB_INDEX_ENTRY_DATA_LEN = 52
+	LOB_INDEX_ENTRY_SIZE     = 60
+
+	// LOB_DATA_PAGE_DATA is where the data starts on a LOB data page
+	LOB_DATA_PAGE_DATA = FIL_PAGE_DATA + 11
+)
+
+// ExternRef is the reference to an externally stored field, the last
+// BTR_EXTERN_FIELD_REF_SIZE bytes of the local part of the field
+type ExternRef struct {
+	SpaceID uint32
+	PageNo  uint32
+	Offset  uint32
+	Length  uint32
+}
+
+func NewExternRef(data []byte) *ExternRef {
+	return &ExternRef{
+		SpaceID: binary.BigEndian.Uint32(data[BTR_EXTERN_SPACE_ID:]),
+		PageNo:  binary.BigEndian.Uint32(data[BTR_EXTERN_PAGE_NO:]),
+		Offset:  binary.BigEndian.Uint32(data[BTR_EXTERN_OFFSET:]),
+		// the high 4 bytes of the length hold flags
+		Length: binary.BigEndian.Uint32(data[BTR_EXTERN_LEN+4:]),
+	}
+}
+
+// ReadExtern reads the externally stored part of a field, from the BLOB
+// pages of MySQL 5.x or the LOB pages of MySQL 8.0
+func (ts *Tablespace) ReadExtern(ref *ExternRef) ([]byte, error) {
+	page, err := ts.ReadPage(ref.PageNo)
+	if err != nil {
+		return nil, err
+	}
+	switch page.Type() {
+	case FIL_PAGE_TYPE_BLOB, FIL_PAGE_SDI_BLOB:
+		return ts.ReadBlob(ref.PageNo, ref.Length)
+	case FIL_PAGE_TYPE_LOB_FIRST:
+		return ts.ReadLOB(page, ref.Length)
+	case FIL_PAGE_TYPE_ZBLOB, FIL_PAGE_TYPE_ZBLOB2:
+		return nil, fmt.Errorf("compressed BLOB page %d is not supported", ref.PageNo)
+	}
+	return nil, fmt.Errorf("page %d of type %d is not a BLOB page", ref.PageNo, page.Type())
+}
+
+// ReadLOB reads length bytes of a MySQL 8.0 LOB by following the index
+// entries of its first page
+func (ts *Tablespace) ReadLOB(first *Page, length uint32) (lob []byte, err error) {
+	lob = make([]byte, 0, length)
+	entries := LOB_FIRST_INDEX_ENTRIES * int(ts.PageSize) / UNIV_PAGE_SIZE_DEF
+	firstData := LOB_FIRST_PAGE_DATA + entries*LOB_INDEX_ENTRY_SIZE
+
+	page := first
+	base := first.Data[LOB_FIRST_OFFSET_INDEX_LIST:]
+	count := binary.BigEndian.Uint32(base)
+	pageNo := binary.BigEndian.Uint32(base[4:])
+	offset := int(binary.BigEndian.Uint16(base[8:]))
+	for i := uint32(0); i < count && pageNo != FIL_NULL; i++ {
+		if page.PageNo() != pageNo {
+			page, err = ts.ReadPage(pageNo)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if offset+LOB_INDEX_ENTRY_SIZE > len(page.Data) {
+			return nil, fmt.Errorf("LOB page %d: index entry at %d is out of page", pageNo, offset)
+		}
+		entry := page.Data[offset:]
+		dataPageNo := binary.BigEndian.Uint32(entry[LOB_INDEX_ENTRY_PAGE_NO:])
+		dataLength := int(binary.BigEndian.Uint16(entry[LOB_INDEX_ENTRY_DATA_LEN:]))
+		var data []byte
+		if dataPageNo == first.PageNo() {
+			data = first.Data[firstData:]
+		} else {
+			dataPage, err := ts.ReadPage(dataPageNo)
+			if err != nil {
+				return nil, err
+			}
+			if dataPage.Type() != FIL_PAGE_TYPE_LOB_DATA {
+				return nil, fmt.Errorf("page %d of type %d is not a LOB data page", dataPageNo, dataPage.Type())
+			}
+			data = dataPage.Data[LOB_DATA_PAGE_DATA:]
+		}
+		if dataLength > len(data)-FIL_PAGE_DATA_END {
+			return nil, fmt.Errorf("LOB page %d: data length %d is out of page", dataPageNo, dataLength)
+		}
+		lob = append(lob, data[:dataLength]...)
+		pageNo = binary.BigEndian.Uint32(entry[LOB_INDEX_ENTRY_NEXT:])
+		offset = int(binary.BigEndian.Uint16(entry[LOB_INDEX_ENTRY_NEXT+4:]))
+	}
+	if uint32(len(lob)) != length {
+		return nil, fmt.Errorf("LOB length %d, expected %d", len(lob), length)
+	}
+	return lob, nil
+}
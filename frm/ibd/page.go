@@ -0,0 +1,99 @@
+package ibd
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Page is a single page of a tablespace
+type Page struct {
+	Data []byte
+}
+
+func (p *Page) PageNo() uint32 {
+	return binary.BigEndian.Uint32(p.Data[FIL_PAGE_OFFSET:])
+}
+
+func (p *Page) Prev() uint32 {
+	return binary.BigEndian.Uint32(p.Data[FIL_PAGE_PREV:])
+}
+
+func (p *Page) Next() uint32 {
+	return binary.BigEndian.Uint32(p.Data[FIL_PAGE_NEXT:])
+}
+
+func (p *Page) Type() PageType {
+	return PageType(binary.BigEndian.Uint16(p.Data[FIL_PAGE_TYPE:]))
+}
+
+func (p *Page) SpaceID() uint32 {
+	return binary.BigEndian.Uint32(p.Data[FIL_PAGE_ARCH_LOG_NO_OR_SPACE_ID:])
+}
+
+// Level is the B-tree level of an INDEX page, 0 for leaf pages
+func (p *Page) Level() uint16 {
+	return binary.BigEndian.Uint16(p.Data[PAGE_HEADER+PAGE_LEVEL:])
+}
+
+// IndexID is the id of the index an INDEX page belongs to
+func (p *Page) IndexID() uint64 {
+	return binary.BigEndian.Uint64(p.Data[PAGE_HEADER+PAGE_INDEX_ID:])
+}
+
+// NRecs is the number of user records on an INDEX page
+func (p *Page) NRecs() uint16 {
+	return binary.BigEndian.Uint16(p.Data[PAGE_HEADER+PAGE_N_RECS:])
+}
+
+// IsCompact reports whether an INDEX page uses the COMPACT record format
+func (p *Page) IsCompact() bool {
+	return binary.BigEndian.Uint16(p.Data[PAGE_HEADER+PAGE_N_HEAP:])&0x8000 != 0
+}
+
+// RecordOrigins returns the origins of the user records on an INDEX page
+// in key order, by following the next record pointers from the infimum
+func (p *Page) RecordOrigins() (origins []int, err error) {
+	var offset, supremum int
+	if p.IsCompact() {
+		offset, supremum = PAGE_NEW_INFIMUM, PAGE_NEW_SUPREMUM
+	} else {
+		offset, supremum = PAGE_OLD_INFIMUM, PAGE_OLD_SUPREMUM
+	}
+	heapSize := int(binary.BigEndian.Uint16(p.Data[PAGE_HEADER+PAGE_N_HEAP:]) & 0x7FFF)
+	for i := 0; i <= heapSize; i++ {
+		offset, err = p.nextRecord(offset)
+		if err != nil {
+			return nil, err
+		}
+		if offset == supremum {
+			return origins, nil
+		}
+		origins = append(origins, offset)
+	}
+	return nil, fmt.Errorf("page %d: record list is not terminated by supremum", p.PageNo())
+}
+
+func (p *Page) nextRecord(origin int) (int, error) {
+	next := int(binary.BigEndian.Uint16(p.Data[origin-2:]))
+	if p.IsCompact() {
+		// relative offset, wraps around the page
+		next = (origin + int(int16(next))) & (len(p.Data) - 1)
+	}
+	if next < PAGE_DATA || next >= len(p.Data)-FIL_PAGE_DATA_END {
+		return 0, fmt.Errorf("page %d: record at %d points out of page to %d", p.PageNo(), origin, next)
+	}
+	return next, nil
+}
+
+// InfoBits returns the info bits (deleted and min rec flags) of a record
+func (p *Page) InfoBits(origin int) uint8 {
+	if p.IsCompact() {
+		return p.Data[origin-REC_N_NEW_EXTRA_BYTES] & 0xF0
+	}
+	return p.Data[origin-REC_N_OLD_EXTRA_BYTES] & 0xF0
+}
+
+// RecordStatus returns the status of a COMPACT record
+func (p *Page) RecordStatus(origin int) RecordType {
+	return RecordType(p.Data[origin-3] & 0x07)
+}
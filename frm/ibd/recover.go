@@ -0,0 +1,609 @@
+package ibd
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+	"github.com/zing22845/go-frm-parser/frm/utils"
+)
+
+const (
+	DATA_ROW_ID_LEN   = 6
+	DATA_TRX_ID_LEN   = 6
+	DATA_ROLL_PTR_LEN = 7
+	// DICT_MAX_FIXED_COL_LEN is the longest fixed length field of an index,
+	// longer ones are stored like variable length fields
+	DICT_MAX_FIXED_COL_LEN = 768
+
+	// REC_INFO_INSTANT_FLAG and REC_INFO_VERSION_FLAG mark records of
+	// tables with instantly added or dropped columns, MySQL 8.0
+	REC_INFO_INSTANT_FLAG = 0x80
+	REC_INFO_VERSION_FLAG = 0x40
+)
+
+type indexFieldKind int
+
+const (
+	indexFieldColumn indexFieldKind = iota
+	indexFieldRowID
+	indexFieldTrxID
+	indexFieldRollPtr
+)
+
+// indexField is a field of a clustered index record, dict_field_t
+type indexField struct {
+	kind   indexFieldKind
+	layout *table.FieldLayout
+	// fixedLength is 0 for fields stored with their length in the
+	// COMPACT record header
+	fixedLength int
+	// bigColumn is set for columns whose length can take 2 bytes in the
+	// COMPACT record header, DATA_BIG_COL
+	bigColumn bool
+	nullable  bool
+	// prefix is set for column prefixes of the primary key, the whole
+	// column follows in the record
+	prefix       bool
+	prefixLength int
+}
+
+// RecoverOptions are the options of a RowReader
+type RecoverOptions struct {
+	// IndexID is the id of the clustered index, the smallest index id
+	// found in the tablespace if 0
+	IndexID uint64
+	// IncludeDeleted returns the delete-marked records too
+	IncludeDeleted bool
+}
+
+// Row is a record recovered from a leaf page of the clustered index
+type Row struct {
+	PageNo  uint32
+	Deleted bool
+	TrxID   uint64
+	RollPtr uint64
+	// Values are in the order of the columns of the table, as decoded by
+	// table.RecordDecoder
+	Values []interface{}
+}
+
+// RowReader reads the rows of a table from the leaf pages of the
+// clustered index of its tablespace, the way undrop-for-innodb does
+type RowReader struct {
+	Table      *table.MySQLTable
+	Tablespace *Tablespace
+	Options    *RecoverOptions
+	Decoder    *table.RecordDecoder
+	// IndexID is the id of the clustered index being read
+	IndexID  uint64
+	fields   []*indexField
+	nullable int
+	blobs    map[*table.FieldLayout][]byte
+	page     *Page
+	origins  []int
+	visited  map[uint32]bool
+}
+
+// NewRowReader returns a reader of the rows of the table mt stored in
+// the tablespace ts
+func NewRowReader(mt *table.MySQLTable, ts *Tablespace, opts *RecoverOptions) (*RowReader, error) {
+	if opts == nil {
+		opts = &RecoverOptions{}
+	}
+	decoder, err := table.NewRecordDecoder(mt)
+	if err != nil {
+		return nil, err
+	}
+	rr := &RowReader{
+		Table:      mt,
+		Tablespace: ts,
+		Options:    opts,
+		Decoder:    decoder,
+		blobs:      make(map[*table.FieldLayout][]byte),
+		visited:    make(map[uint32]bool),
+	}
+	decoder.BlobData = rr.blobData
+	err = rr.buildFields()
+	if err != nil {
+		return nil, err
+	}
+	pageNo, indexID, err := ts.FirstLeafPage(opts.IndexID)
+	if err != nil {
+		return nil, err
+	}
+	rr.IndexID = indexID
+	err = rr.readPage(pageNo)
+	if err != nil {
+		return nil, err
+	}
+	return rr, nil
+}
+
+// ClusteredKey returns the key InnoDB clusters the table on, the primary
+// key or the first unique key of NOT NULL columns, nil if the records
+// are clustered on DB_ROW_ID
+func ClusteredKey(mt *table.MySQLTable) *table.Key {
+	if mt.Keys == nil || len(mt.Keys.Items) == 0 {
+		return nil
+	}
+	// the server sorts the keys, the candidate comes first
+	key := mt.Keys.Items[0]
+	if key.Name == "PRIMARY" {
+		return key
+	}
+	if !key.IsUnique || key.IndexType != "BTREE" {
+		return nil
+	}
+	for _, part := range key.Parts {
+		if part.Column == nil || part.Expression != "" ||
+			part.Column.Flags.HasFlag(table.FF_MAYBE_NULL) || isPrefixPart(part) {
+			return nil
+		}
+	}
+	return key
+}
+
+func isPrefixPart(part *table.KeyPart) bool {
+	c := part.Column
+	switch c.TypeCode {
+	case table.MT_TINY_BLOB, table.MT_MEDIUM_BLOB, table.MT_LONG_BLOB,
+		table.MT_BLOB, table.MT_JSON, table.MT_GEOMETRY:
+		return true
+	case table.MT_STRING, table.MT_VARCHAR, table.MT_VAR_STRING:
+		return part.Length < c.Length
+	}
+	return false
+}
+
+// buildFields builds the fields of the clustered index records
+func (rr *RowReader) buildFields() (err error) {
+	for _, f := range rr.Decoder.Layout.Fields {
+		if f.BitPos >= 0 {
+			return fmt.Errorf("column %s: BIT bits stored in the null bytes are not supported", f.Column.Name)
+		}
+	}
+	rr.fields, _, err = clusteredIndexFields(rr.Table, rr.Decoder.Layout, true)
+	if err != nil {
+		return err
+	}
+	for _, field := range rr.fields {
+		if field.nullable {
+			rr.nullable++
+		}
+	}
+	return nil
+}
+
+// clusteredIndexFields follows dict_index_build_internal_clust: the key
+// columns, DB_TRX_ID, DB_ROLL_PTR and the other columns in table order.
+// nUnique is the number of key fields, which node pointers hold
+func clusteredIndexFields(mt *table.MySQLTable, layout *table.RecordLayout, compact bool) (
+	fields []*indexField, nUnique int, err error) {
+	layouts := make(map[*table.Column]*table.FieldLayout, len(layout.Fields))
+	for _, f := range layout.Fields {
+		layouts[f.Column] = f
+	}
+	inKey := make(map[*table.Column]bool)
+	if key := ClusteredKey(mt); key != nil {
+		for _, part := range key.Parts {
+			f, ok := layouts[part.Column]
+			if !ok {
+				return nil, 0, fmt.Errorf("key %s: column not found", key.Name)
+			}
+			field := newIndexField(f, compact)
+			if isPrefixPart(part) {
+				field.prefix = true
+				field.prefixLength = int(part.Length)
+				if field.fixedLength > field.prefixLength {
+					field.fixedLength = field.prefixLength
+				}
+			} else {
+				inKey[part.Column] = true
+			}
+			fields = append(fields, field)
+		}
+	} else {
+		fields = append(fields, &indexField{kind: indexFieldRowID, fixedLength: DATA_ROW_ID_LEN})
+	}
+	nUnique = len(fields)
+	fields = append(fields,
+		&indexField{kind: indexFieldTrxID, fixedLength: DATA_TRX_ID_LEN},
+		&indexField{kind: indexFieldRollPtr, fixedLength: DATA_ROLL_PTR_LEN})
+	for _, f := range layout.Fields {
+		if f.PackLength == 0 || inKey[f.Column] {
+			continue
+		}
+		fields = append(fields, newIndexField(f, compact))
+	}
+	return fields, nUnique, nil
+}
+
+// newIndexField returns the field of a column, compact is set for the
+// COMPACT, DYNAMIC and COMPRESSED row formats
+func newIndexField(f *table.FieldLayout, compact bool) *indexField {
+	c := f.Column
+	field := &indexField{
+		kind:        indexFieldColumn,
+		layout:      f,
+		fixedLength: int(f.PackLength),
+		bigColumn:   f.PackLength > 255,
+		nullable:    c.Flags.HasFlag(table.FF_MAYBE_NULL),
+	}
+	switch c.TypeCode {
+	case table.MT_TINY_BLOB, table.MT_MEDIUM_BLOB, table.MT_LONG_BLOB,
+		table.MT_BLOB, table.MT_JSON, table.MT_GEOMETRY:
+		field.fixedLength = 0
+		field.bigColumn = true
+	case table.MT_VARCHAR, table.MT_VAR_STRING:
+		field.fixedLength = 0
+		field.bigColumn = c.Length > 255
+	case table.MT_STRING:
+		// CHAR of a variable width character set, in the COMPACT format
+		if compact && c.Collation != nil && c.Collation.Maxlen > mbMinLen(c.Collation.CharsetName) {
+			field.fixedLength = 0
+		}
+	}
+	if field.fixedLength > DICT_MAX_FIXED_COL_LEN {
+		field.fixedLength = 0
+	}
+	return field
+}
+
+// mbMinLen is the shortest character of a character set
+func mbMinLen(charsetName string) int {
+	switch charsetName {
+	case "ucs2", "utf16", "utf16le":
+		return 2
+	case "utf32":
+		return 4
+	}
+	return 1
+}
+
+// FirstLeafPage scans the tablespace for the leftmost leaf page of the
+// index indexID, of the index with the smallest id if indexID is 0
+func (ts *Tablespace) FirstLeafPage(indexID uint64) (pageNo uint32, id uint64, err error) {
+	found := false
+	for no := uint32(1); no < ts.Size; no++ {
+		page, err := ts.ReadPage(no)
+		if err != nil {
+			// free or damaged page
+			continue
+		}
+		if page.Type() != FIL_PAGE_INDEX || page.Level() != 0 || page.Prev() != FIL_NULL {
+			continue
+		}
+		if indexID != 0 && page.IndexID() != indexID {
+			continue
+		}
+		if !found || page.IndexID() < id {
+			pageNo, id, found = no, page.IndexID(), true
+		}
+	}
+	if !found {
+		if indexID != 0 {
+			return 0, 0, fmt.Errorf("tablespace %d: no leaf page of index %d", ts.SpaceID, indexID)
+		}
+		return 0, 0, fmt.Errorf("tablespace %d: no index leaf page", ts.SpaceID)
+	}
+	return pageNo, id, nil
+}
+
+func (rr *RowReader) readPage(pageNo uint32) (err error) {
+	if rr.visited[pageNo] {
+		return fmt.Errorf("page %d: loop in the leaf page list", pageNo)
+	}
+	rr.visited[pageNo] = true
+	rr.page, err = rr.Tablespace.ReadPage(pageNo)
+	if err != nil {
+		return err
+	}
+	if rr.page.Type() != FIL_PAGE_INDEX || rr.page.IndexID() != rr.IndexID || rr.page.Level() != 0 {
+		return fmt.Errorf("page %d is not a leaf page of index %d", pageNo, rr.IndexID)
+	}
+	rr.origins, err = rr.page.RecordOrigins()
+	return err
+}
+
+// Next returns the next row, io.EOF after the last one
+func (rr *RowReader) Next() (*Row, error) {
+	for {
+		for len(rr.origins) > 0 {
+			origin := rr.origins[0]
+			rr.origins = rr.origins[1:]
+			row, err := rr.readRow(origin)
+			if err != nil {
+				return nil, fmt.Errorf("page %d: record at %d: %w", rr.page.PageNo(), origin, err)
+			}
+			if row != nil {
+				return row, nil
+			}
+		}
+		if rr.page.Next() == FIL_NULL {
+			return nil, io.EOF
+		}
+		err := rr.readPage(rr.page.Next())
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (rr *RowReader) readRow(origin int) (*Row, error) {
+	page := rr.page
+	info := page.InfoBits(origin)
+	deleted := info&REC_INFO_DELETED_FLAG != 0
+	if deleted && !rr.Options.IncludeDeleted {
+		return nil, nil
+	}
+	if page.IsCompact() && page.RecordStatus(origin) != REC_STATUS_ORDINARY {
+		return nil, nil
+	}
+	if info&(REC_INFO_INSTANT_FLAG|REC_INFO_VERSION_FLAG) != 0 {
+		return nil, fmt.Errorf("records of instantly added columns are not supported")
+	}
+	var fields [][]byte
+	var externs []bool
+	var err error
+	if page.IsCompact() {
+		fields, externs, err = rr.compactFields(origin)
+	} else {
+		fields, externs, err = rr.redundantFields(origin)
+	}
+	if err != nil {
+		return nil, err
+	}
+	row := &Row{PageNo: page.PageNo(), Deleted: deleted}
+	record := make([]byte, rr.Decoder.Layout.Length)
+	for f := range rr.blobs {
+		delete(rr.blobs, f)
+	}
+	for i, field := range rr.fields {
+		data := fields[i]
+		switch field.kind {
+		case indexFieldTrxID:
+			row.TrxID = utils.Uint48BE(data)
+			continue
+		case indexFieldRollPtr:
+			row.RollPtr = uint64(data[0])<<48 | utils.Uint48BE(data[1:])
+			continue
+		case indexFieldRowID:
+			continue
+		}
+		if field.prefix {
+			continue
+		}
+		f := field.layout
+		if data == nil {
+			if f.NullPos < 0 {
+				return nil, fmt.Errorf("column %s is NULL", f.Column.Name)
+			}
+			record[f.NullPos/8] |= 1 << (f.NullPos % 8)
+			continue
+		}
+		if externs[i] {
+			data, err = rr.readExtern(data)
+			if err != nil {
+				return nil, fmt.Errorf("column %s: %w", f.Column.Name, err)
+			}
+		}
+		err = rr.store(record, f, data)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", f.Column.Name, err)
+		}
+	}
+	row.Values, err = rr.Decoder.Decode(record)
+	if err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+// compactFields splits a COMPACT record into its fields, nil for NULL
+func (rr *RowReader) compactFields(origin int) (fields [][]byte, externs []bool, err error) {
+	data := rr.page.Data
+	end := len(data) - FIL_PAGE_DATA_END
+	nulls := origin - REC_N_NEW_EXTRA_BYTES - 1
+	lengths := nulls - (rr.nullable+7)/8
+	fields = make([][]byte, len(rr.fields))
+	externs = make([]bool, len(rr.fields))
+	pos := origin
+	nullIndex := 0
+	for i, field := range rr.fields {
+		if field.nullable {
+			isNull := data[nulls-nullIndex/8]&(1<<(nullIndex%8)) != 0
+			nullIndex++
+			if isNull {
+				continue
+			}
+		}
+		length := field.fixedLength
+		if length == 0 {
+			if lengths < PAGE_DATA {
+				return nil, nil, fmt.Errorf("record header out of page")
+			}
+			length = int(data[lengths])
+			lengths--
+			if field.bigColumn && length&0x80 != 0 {
+				externs[i] = length&0x40 != 0
+				length = (length&0x3F)<<8 | int(data[lengths])
+				lengths--
+			}
+		}
+		if pos+length > end {
+			return nil, nil, fmt.Errorf("field %d out of page", i)
+		}
+		fields[i] = data[pos : pos+length]
+		pos += length
+	}
+	return fields, externs, nil
+}
+
+// redundantFields splits a REDUNDANT record into its fields, nil for NULL
+func (rr *RowReader) redundantFields(origin int) (fields [][]byte, externs []bool, err error) {
+	data := rr.page.Data
+	nFields := int(binary.BigEndian.Uint16(data[origin-4:])>>1) & 0x3FF
+	if nFields != len(rr.fields) {
+		return nil, nil, fmt.Errorf("record has %d fields, the table %d", nFields, len(rr.fields))
+	}
+	short := data[origin-3]&1 != 0
+	end := len(data) - FIL_PAGE_DATA_END
+	fields = make([][]byte, nFields)
+	externs = make([]bool, nFields)
+	start := 0
+	for i := 0; i < nFields; i++ {
+		var stop int
+		var isNull bool
+		if short {
+			v := data[origin-REC_N_OLD_EXTRA_BYTES-1-i]
+			isNull, stop = v&0x80 != 0, int(v&0x7F)
+		} else {
+			v := binary.BigEndian.Uint16(data[origin-REC_N_OLD_EXTRA_BYTES-2*(i+1):])
+			isNull, externs[i], stop = v&0x8000 != 0, v&0x4000 != 0, int(v&0x3FFF)
+		}
+		if stop < start || origin+stop > end {
+			return nil, nil, fmt.Errorf("field %d out of page", i)
+		}
+		if !isNull {
+			fields[i] = data[origin+start : origin+stop]
+		}
+		start = stop
+	}
+	return fields, externs, nil
+}
+
+func (rr *RowReader) readExtern(data []byte) ([]byte, error) {
+	if len(data) < BTR_EXTERN_FIELD_REF_SIZE {
+		return nil, fmt.Errorf("invalid external reference")
+	}
+	local := len(data) - BTR_EXTERN_FIELD_REF_SIZE
+	ref := NewExternRef(data[local:])
+	if ref.PageNo == 0 || ref.PageNo == FIL_NULL {
+		// not written yet or already freed
+		return nil, fmt.Errorf("external reference to page %d", ref.PageNo)
+	}
+	extern, err := rr.Tablespace.ReadExtern(ref)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, data[:local]...), extern...), nil
+}
+
+// store writes a field in the InnoDB format to record, in the server's
+// row format
+func (rr *RowReader) store(record []byte, f *table.FieldLayout, data []byte) error {
+	c := f.Column
+	dst := record[f.Offset : f.Offset+f.PackLength]
+	switch c.TypeCode {
+	case table.MT_VARCHAR:
+		lengthBytes := f.PackLength - uint32(c.Length)
+		if uint32(len(data)) > uint32(c.Length) {
+			return fmt.Errorf("length %d exceeds %d", len(data), c.Length)
+		}
+		if lengthBytes == 1 {
+			dst[0] = byte(len(data))
+		} else {
+			binary.LittleEndian.PutUint16(dst, uint16(len(data)))
+		}
+		copy(dst[lengthBytes:], data)
+		return nil
+	case table.MT_STRING, table.MT_VAR_STRING:
+		if uint32(len(data)) > f.PackLength {
+			return fmt.Errorf("length %d exceeds %d", len(data), f.PackLength)
+		}
+		// trailing spaces are stripped from CHAR of variable width
+		// character sets
+		copy(dst, data)
+		pad := []byte{' '}
+		if c.Collation != nil {
+			pad = make([]byte, mbMinLen(c.Collation.CharsetName))
+			pad[len(pad)-1] = ' '
+		}
+		for i := len(data); i+len(pad) <= len(dst); i += len(pad) {
+			copy(dst[i:], pad)
+		}
+		return nil
+	case table.MT_TINY_BLOB, table.MT_MEDIUM_BLOB, table.MT_LONG_BLOB,
+		table.MT_BLOB, table.MT_JSON, table.MT_GEOMETRY:
+		length := uint64(len(data))
+		for i := 0; i < int(f.PackLength-table.PORTABLE_SIZEOF_CHAR_PTR); i++ {
+			dst[i] = byte(length)
+			length >>= 8
+		}
+		rr.blobs[f] = data
+		return nil
+	}
+	if uint32(len(data)) != f.PackLength {
+		return fmt.Errorf("length %d, expected %d", len(data), f.PackLength)
+	}
+	isInteger, isSigned := storedAsInteger(f)
+	if !isInteger {
+		copy(dst, data)
+		return nil
+	}
+	// big-endian with the sign bit flipped, row_mysql_store_col_in_innobase_format
+	for i := range data {
+		dst[len(data)-1-i] = data[i]
+	}
+	if isSigned {
+		dst[len(dst)-1] ^= 0x80
+	}
+	return nil
+}
+
+// storedAsInteger reports whether InnoDB stores a column as an integer,
+// DATA_INT, and whether it is signed
+func storedAsInteger(f *table.FieldLayout) (isInteger, isSigned bool) {
+	c := f.Column
+	switch c.TypeCode {
+	case table.MT_TINY, table.MT_SHORT, table.MT_INT24, table.MT_LONG, table.MT_LONGLONG:
+		return true, c.Flags.HasFlag(table.FF_DECIMAL)
+	case table.MT_YEAR, table.MT_ENUM, table.MT_SET:
+		return true, false
+	case table.MT_DATE, table.MT_NEWDATE, table.MT_DATETIME:
+		return true, true
+	case table.MT_TIME:
+		return f.PackLength == 3, true
+	case table.MT_TIMESTAMP:
+		return f.PackLength == 4, false
+	}
+	return false, false
+}
+
+func (rr *RowReader) blobData(f *table.FieldLayout, ref *table.BlobRef) ([]byte, error) {
+	data := rr.blobs[f]
+	if uint32(len(data)) != ref.Length {
+		return nil, fmt.Errorf("blob length %d, %d bytes found", ref.Length, len(data))
+	}
+	return data, nil
+}
+
+// RecoverFile reads the rows of the table mt from the .ibd file at path
+func RecoverFile(mt *table.MySQLTable, path string, opts *RecoverOptions) (rows []*Row, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	ts, err := NewTablespace(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	rr, err := NewRowReader(mt, ts, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for {
+		row, err := rr.Next()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		rows = append(rows, row)
+	}
+}
@@ -0,0 +1,169 @@
+package ibd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+const testSpaceID = 42
+
+func newPage(no uint32, pageType PageType) []byte {
+	p := make([]byte, UNIV_PAGE_SIZE_DEF)
+	binary.BigEndian.PutUint32(p[FIL_PAGE_OFFSET:], no)
+	binary.BigEndian.PutUint32(p[FIL_PAGE_PREV:], FIL_NULL)
+	binary.BigEndian.PutUint32(p[FIL_PAGE_NEXT:], FIL_NULL)
+	binary.BigEndian.PutUint16(p[FIL_PAGE_TYPE:], uint16(pageType))
+	binary.BigEndian.PutUint32(p[FIL_PAGE_ARCH_LOG_NO_OR_SPACE_ID:], testSpaceID)
+	return p
+}
+
+// compactRecord is a user record of a COMPACT page, extra is the null
+// bitmap and the field lengths before the record header, in file order
+type compactRecord struct {
+	extra []byte
+	info  uint8
+	data  []byte
+}
+
+// newLeafPage returns a leaf page of the index holding records, linked
+// in the order given
+func newLeafPage(no uint32, indexID uint64, records ...compactRecord) []byte {
+	p := newPage(no, FIL_PAGE_INDEX)
+	binary.BigEndian.PutUint16(p[PAGE_HEADER+PAGE_N_HEAP:], 0x8000|uint16(2+len(records)))
+	binary.BigEndian.PutUint16(p[PAGE_HEADER+PAGE_N_RECS:], uint16(len(records)))
+	binary.BigEndian.PutUint64(p[PAGE_HEADER+PAGE_INDEX_ID:], indexID)
+	p[PAGE_NEW_INFIMUM-3] = byte(REC_STATUS_INFIMUM)
+	copy(p[PAGE_NEW_INFIMUM:], "infimum\x00")
+	p[PAGE_NEW_SUPREMUM-3] = byte(REC_STATUS_SUPREMUM)
+	copy(p[PAGE_NEW_SUPREMUM:], "supremum")
+	link := func(from, to int) {
+		binary.BigEndian.PutUint16(p[from-2:], uint16(to-from))
+	}
+	prev, pos := PAGE_NEW_INFIMUM, PAGE_NEW_SUPREMUM+8
+	for _, r := range records {
+		copy(p[pos:], r.extra)
+		origin := pos + len(r.extra) + REC_N_NEW_EXTRA_BYTES
+		p[origin-REC_N_NEW_EXTRA_BYTES] = r.info
+		copy(p[origin:], r.data)
+		link(prev, origin)
+		prev, pos = origin, origin+len(r.data)
+	}
+	link(prev, PAGE_NEW_SUPREMUM)
+	return p
+}
+
+// newBlobPage returns a BLOB page of MySQL 5.x holding part of a field
+func newBlobPage(no, next uint32, part string) []byte {
+	p := newPage(no, FIL_PAGE_TYPE_BLOB)
+	binary.BigEndian.PutUint32(p[FIL_PAGE_DATA+BTR_BLOB_HDR_PART_LEN:], uint32(len(part)))
+	binary.BigEndian.PutUint32(p[FIL_PAGE_DATA+BTR_BLOB_HDR_NEXT_PAGE_NO:], next)
+	copy(p[FIL_PAGE_DATA+BTR_BLOB_HDR_SIZE:], part)
+	return p
+}
+
+func TestRecoverFile(t *testing.T) {
+	// latin1_swedish_ci
+	latin1, err := table.GetCollationByID(8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := &table.Column{Name: "id", TypeCode: table.MT_LONG, Length: 11, Flags: table.FF_DECIMAL}
+	mt := &table.MySQLTable{
+		Name:    "t1",
+		Options: &table.Options{},
+		Columns: &table.Columns{Items: []*table.Column{
+			id,
+			{Name: "name", TypeCode: table.MT_VARCHAR, Length: 20, Collation: latin1, Flags: table.FF_MAYBE_NULL},
+			{Name: "amount", TypeCode: table.MT_LONG, Length: 11, Flags: table.FF_DECIMAL},
+			{Name: "note", TypeCode: table.MT_BLOB, Collation: latin1, Flags: table.FF_MAYBE_NULL},
+		}},
+		Keys: &table.Keys{Items: []*table.Key{
+			{Name: "PRIMARY", Parts: []*table.KeyPart{{Column: id, Length: 4}}, IsUnique: true, IndexType: "BTREE"},
+		}},
+	}
+	// the clustered index records hold id, DB_TRX_ID, DB_ROLL_PTR, name,
+	// amount and note, integers big-endian with the sign bit flipped
+	system := func(id, trxID byte) []byte {
+		return []byte{0x80, 0, 0, id, 0, 0, 0, 0, 0, trxID, 0x80, 0, 0, 0, 0x01, 0x10, trxID}
+	}
+	ref := make([]byte, BTR_EXTERN_FIELD_REF_SIZE)
+	binary.BigEndian.PutUint32(ref[BTR_EXTERN_SPACE_ID:], testSpaceID)
+	binary.BigEndian.PutUint32(ref[BTR_EXTERN_PAGE_NO:], 5)
+	binary.BigEndian.PutUint32(ref[BTR_EXTERN_OFFSET:], FIL_PAGE_DATA)
+	binary.BigEndian.PutUint32(ref[BTR_EXTERN_LEN+4:], 22)
+	pages := [][]byte{
+		newPage(0, FIL_PAGE_TYPE_FSP_HDR),
+		newPage(1, FIL_PAGE_IBUF_BITMAP),
+		newPage(2, FIL_PAGE_INODE),
+		newLeafPage(3, 150,
+			// the lengths of note and name, then the null bits
+			compactRecord{extra: []byte{10, 5, 0x00},
+				data: bytes.Join([][]byte{system(1, 7), []byte("alice"), {0x7f, 0xff, 0xff, 0xfd}, []byte("short note")}, nil)},
+			compactRecord{extra: []byte{0x03}, info: REC_INFO_DELETED_FLAG,
+				data: bytes.Join([][]byte{system(2, 8), {0x80, 0, 0, 5}}, nil)},
+		),
+		newLeafPage(4, 150,
+			// note is stored on the BLOB pages 5 and 6
+			compactRecord{extra: []byte{BTR_EXTERN_FIELD_REF_SIZE, 0xc0, 0, 0x00},
+				data: bytes.Join([][]byte{system(3, 9), {0x80, 0, 0, 100}, ref}, nil)},
+		),
+		newBlobPage(5, 6, "externally "),
+		newBlobPage(6, FIL_NULL, "stored note"),
+		// the leaf page of a secondary index
+		newLeafPage(7, 151),
+	}
+	fsp := pages[0][FSP_HEADER_OFFSET:]
+	binary.BigEndian.PutUint32(fsp[FSP_SPACE_ID:], testSpaceID)
+	binary.BigEndian.PutUint32(fsp[FSP_SIZE:], uint32(len(pages)))
+	binary.BigEndian.PutUint32(pages[3][FIL_PAGE_NEXT:], 4)
+	binary.BigEndian.PutUint32(pages[4][FIL_PAGE_PREV:], 3)
+	path := filepath.Join(t.TempDir(), "t1.ibd")
+	if err := os.WriteFile(path, bytes.Join(pages, nil), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	alice := &Row{PageNo: 3, TrxID: 7, RollPtr: 0x80000000011007,
+		Values: []interface{}{int64(1), "alice", int64(-3), "short note"}}
+	deleted := &Row{PageNo: 3, Deleted: true, TrxID: 8, RollPtr: 0x80000000011008,
+		Values: []interface{}{int64(2), nil, int64(5), nil}}
+	extern := &Row{PageNo: 4, TrxID: 9, RollPtr: 0x80000000011009,
+		Values: []interface{}{int64(3), "", int64(100), "externally stored note"}}
+	for _, c := range []struct {
+		name string
+		opts *RecoverOptions
+		want []*Row
+	}{
+		{"default", nil, []*Row{alice, extern}},
+		{"deleted", &RecoverOptions{IncludeDeleted: true}, []*Row{alice, deleted, extern}},
+		{"index", &RecoverOptions{IndexID: 150}, []*Row{alice, extern}},
+	} {
+		rows, err := RecoverFile(mt, path, c.opts)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if !reflect.DeepEqual(rows, c.want) {
+			t.Errorf("%s: got", c.name)
+			for _, row := range rows {
+				t.Errorf("%+v", row)
+			}
+		}
+	}
+	if _, err := RecoverFile(mt, path, &RecoverOptions{IndexID: 152}); err == nil {
+		t.Error("no error for a missing index")
+	}
+
+	// a loop in the leaf page list
+	binary.BigEndian.PutUint32(pages[4][FIL_PAGE_NEXT:], 3)
+	if err := os.WriteFile(path, bytes.Join(pages, nil), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RecoverFile(mt, path, nil); err == nil {
+		t.Error("no error for a loop in the leaf page list")
+	}
+}
@@ -0,0 +1,275 @@
+package ibd
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+const (
+	// HA_MAX_REC_LENGTH is the longest record of the server, BLOBs count
+	// with their length and pointer only
+	HA_MAX_REC_LENGTH = 65535
+	// REC_MAX_DATA_SIZE is the longest InnoDB record
+	REC_MAX_DATA_SIZE = 16384
+	// BTR_EXTERN_LOCAL_STORED_MAX_SIZE is the longest column DYNAMIC and
+	// COMPRESSED keep in the page when it may be stored off-page
+	BTR_EXTERN_LOCAL_STORED_MAX_SIZE = 2 * BTR_EXTERN_FIELD_REF_SIZE
+	// BTR_EXTERN_ANTELOPE_LOCAL_MAX_SIZE is the prefix REDUNDANT and COMPACT
+	// keep in the page of a column stored off-page, with the reference
+	BTR_EXTERN_ANTELOPE_LOCAL_MAX_SIZE = 768 + BTR_EXTERN_FIELD_REF_SIZE
+	// REC_NODE_PTR_SIZE is the size of the child page number of a node pointer
+	REC_NODE_PTR_SIZE = 4
+	// PAGE_DIR is the size of the page trailer and PAGE_DIR_SLOT_SIZE of
+	// a slot of the page directory
+	PAGE_DIR           = FIL_PAGE_DATA_END
+	PAGE_DIR_SLOT_SIZE = 2
+	// PAGE_ZIP_CLUST_LEAF_SLOT_SIZE is the uncompressed part of a record
+	// on a compressed leaf page of a clustered index
+	PAGE_ZIP_CLUST_LEAF_SLOT_SIZE = PAGE_DIR_SLOT_SIZE + DATA_TRX_ID_LEN + DATA_ROLL_PTR_LEN
+)
+
+// RowSizeOptions are the server settings the row size depends on
+type RowSizeOptions struct {
+	// PageSize is innodb_page_size, 16KiB if 0
+	PageSize uint32
+	// DefaultRowFormat is innodb_default_row_format, DYNAMIC if empty
+	DefaultRowFormat string
+}
+
+// RowSizeReport is the row size of a table against the limits of the
+// server and of InnoDB
+type RowSizeReport struct {
+	Table string
+	// RowSize is the length of a record in the server's row format,
+	// limited to ServerLimit bytes
+	RowSize     uint32
+	ServerLimit uint32
+	// InnoDB is set for InnoDB tables
+	InnoDB    bool
+	RowFormat string
+	PageSize  uint32
+	// ZipSize is the compressed page size of ROW_FORMAT=COMPRESSED
+	ZipSize uint32
+	// RecordSize is the largest clustered index record on a leaf page,
+	// the columns which may be stored off-page count with their in-page
+	// part, it has to stay below RecordLimit
+	RecordSize  int
+	RecordLimit int
+	// NodePointerSize is the largest node pointer record, which has to
+	// stay below NodePointerLimit
+	NodePointerSize  int
+	NodePointerLimit int
+	Findings         []string
+}
+
+// TooLarge reports whether the table can't be created or rows can't be
+// inserted because of their size
+func (r *RowSizeReport) TooLarge() bool {
+	return len(r.Findings) != 0
+}
+
+func (r *RowSizeReport) String() string {
+	var b strings.Builder
+	status := "OK"
+	if r.TooLarge() {
+		status = "TOO LARGE"
+	}
+	fmt.Fprintf(&b, "%s: %s, row size %d/%d", r.Table, status, r.RowSize, r.ServerLimit)
+	if r.InnoDB {
+		fmt.Fprintf(&b, ", ROW_FORMAT=%s record size %d/%d", r.RowFormat, r.RecordSize, r.RecordLimit)
+	}
+	for _, finding := range r.Findings {
+		fmt.Fprintf(&b, "\n  %s", finding)
+	}
+	return b.String()
+}
+
+// CheckRowSize computes the row size of the table mt against the 65535
+// bytes of the server, and for InnoDB tables the size of the records of
+// the clustered index against half of the page, dict_index_too_big_for_tree
+func CheckRowSize(mt *table.MySQLTable, opts *RowSizeOptions) (*RowSizeReport, error) {
+	if opts == nil {
+		opts = &RowSizeOptions{}
+	}
+	layout, err := mt.RecordLayout()
+	if err != nil {
+		return nil, err
+	}
+	r := &RowSizeReport{
+		Table:       mt.Name,
+		RowSize:     layout.Length,
+		ServerLimit: HA_MAX_REC_LENGTH,
+		InnoDB:      strings.EqualFold(mt.Options.Engine, "InnoDB"),
+	}
+	if r.RowSize > r.ServerLimit {
+		r.Findings = append(r.Findings, fmt.Sprintf(
+			"row size %d exceeds the server limit of %d bytes, BLOB and TEXT columns count 9 to 12 bytes",
+			r.RowSize, r.ServerLimit))
+	}
+	if !r.InnoDB {
+		return r, nil
+	}
+	r.PageSize = opts.PageSize
+	if r.PageSize == 0 {
+		r.PageSize = UNIV_PAGE_SIZE_DEF
+	}
+	r.RowFormat, r.ZipSize = innodbRowFormat(mt.Options, opts.DefaultRowFormat, r.PageSize)
+	compact := r.RowFormat != "REDUNDANT"
+	fields, nUnique, err := clusteredIndexFields(mt, layout, compact)
+	if err != nil {
+		return nil, err
+	}
+	r.recordSize(fields, nUnique)
+	if r.RecordSize >= r.RecordLimit {
+		r.Findings = append(r.Findings, fmt.Sprintf(
+			"record size %d exceeds the InnoDB limit of %d bytes for ROW_FORMAT=%s and %dKiB pages",
+			r.RecordSize, r.RecordLimit, r.RowFormat, r.PageSize/1024))
+	}
+	if r.NodePointerSize >= r.NodePointerLimit {
+		r.Findings = append(r.Findings, fmt.Sprintf(
+			"primary key node pointer size %d exceeds the InnoDB limit of %d bytes",
+			r.NodePointerSize, r.NodePointerLimit))
+	}
+	return r, nil
+}
+
+// innodbRowFormat returns the row format InnoDB creates the table with
+func innodbRowFormat(o *table.Options, defaultRowFormat string, pageSize uint32) (rowFormat string, zipSize uint32) {
+	switch o.RowFormat {
+	case table.RT_REDUNDANT, table.RT_COMPACT, table.RT_DYNAMIC, table.RT_COMPRESSED:
+		rowFormat = o.RowFormat.String()
+	default:
+		rowFormat = strings.ToUpper(defaultRowFormat)
+		if rowFormat == "" {
+			rowFormat = "DYNAMIC"
+		}
+		if o.KeyBlockSize != 0 {
+			rowFormat = "COMPRESSED"
+		}
+	}
+	if rowFormat != "COMPRESSED" {
+		return rowFormat, 0
+	}
+	zipSize = uint32(o.KeyBlockSize) * 1024
+	if zipSize == 0 {
+		// half of the page size, at most 8KiB
+		zipSize = pageSize / 2
+		if zipSize > 8192 {
+			zipSize = 8192
+		}
+	}
+	return rowFormat, zipSize
+}
+
+// recordSize computes the worst case size of the leaf and node pointer
+// records and their limits
+func (r *RowSizeReport) recordSize(fields []*indexField, nUnique int) {
+	compact := r.RowFormat != "REDUNDANT"
+	atomicBlobs := r.RowFormat == "DYNAMIC" || r.RowFormat == "COMPRESSED"
+	size := 0
+	if r.ZipSize != 0 && r.ZipSize < r.PageSize {
+		r.RecordLimit = pageZipEmptySize(len(fields), r.ZipSize) - 1
+		r.NodePointerLimit = r.RecordLimit / 2
+		// no record header but an entry in the dense page directory
+		size = PAGE_DIR_SLOT_SIZE
+	} else {
+		r.RecordLimit = pageFreeSpaceOfEmpty(compact, r.PageSize) / 2
+		if r.RecordLimit > REC_MAX_DATA_SIZE-1 {
+			r.RecordLimit = REC_MAX_DATA_SIZE - 1
+		}
+		r.NodePointerLimit = r.RecordLimit
+		size = REC_N_OLD_EXTRA_BYTES
+		if compact {
+			size = REC_N_NEW_EXTRA_BYTES
+		}
+	}
+	if compact {
+		nullable := 0
+		for _, field := range fields {
+			if field.nullable {
+				nullable++
+			}
+		}
+		size += (nullable + 7) / 8
+	} else {
+		size += 2 * len(fields)
+	}
+	localMax := BTR_EXTERN_ANTELOPE_LOCAL_MAX_SIZE
+	if atomicBlobs {
+		localMax = BTR_EXTERN_LOCAL_STORED_MAX_SIZE
+	}
+	for i, field := range fields {
+		if field.fixedLength != 0 {
+			size += field.fixedLength
+		} else {
+			maxSize := field.maxSize()
+			lengthBytes := 1
+			if maxSize >= 256 {
+				lengthBytes = 2
+			}
+			if field.prefix {
+				if field.prefixLength < maxSize {
+					maxSize = field.prefixLength
+				}
+			} else if maxSize > localMax {
+				maxSize = localMax
+				if atomicBlobs {
+					lengthBytes = 1
+				}
+			}
+			size += maxSize
+			if compact {
+				size += lengthBytes
+			}
+		}
+		if i+1 == nUnique {
+			r.NodePointerSize = size + REC_NODE_PTR_SIZE
+		}
+	}
+	r.RecordSize = size
+}
+
+// maxSize is the longest value of the field, dict_col_get_max_size
+func (field *indexField) maxSize() int {
+	if field.kind != indexFieldColumn {
+		return field.fixedLength
+	}
+	c := field.layout.Column
+	switch c.TypeCode {
+	case table.MT_TINY_BLOB, table.MT_MEDIUM_BLOB, table.MT_LONG_BLOB,
+		table.MT_BLOB, table.MT_JSON, table.MT_GEOMETRY:
+		return math.MaxInt32
+	case table.MT_VARCHAR, table.MT_VAR_STRING, table.MT_STRING:
+		return int(c.Length)
+	}
+	return int(field.layout.PackLength)
+}
+
+// pageFreeSpaceOfEmpty is the space for records on an empty page,
+// page_get_free_space_of_empty
+func pageFreeSpaceOfEmpty(compact bool, pageSize uint32) int {
+	supremumEnd := PAGE_OLD_SUPREMUM + 9
+	if compact {
+		supremumEnd = PAGE_NEW_SUPREMUM + 8
+	}
+	return int(pageSize) - supremumEnd - PAGE_DIR - 2*PAGE_DIR_SLOT_SIZE
+}
+
+// pageZipEmptySize is the space for a record on an empty compressed
+// page, page_zip_empty_size
+func pageZipEmptySize(nFields int, zipSize uint32) int {
+	size := int(zipSize) - (PAGE_DATA + PAGE_ZIP_CLUST_LEAF_SLOT_SIZE + 1 + 1 - REC_N_NEW_EXTRA_BYTES) -
+		compressBound(2*(nFields+1))
+	if size < 0 {
+		return 0
+	}
+	return size
+}
+
+// compressBound is the zlib bound of the compressed size of n bytes
+func compressBound(n int) int {
+	return n + (n >> 12) + (n >> 14) + (n >> 25) + 13
+}
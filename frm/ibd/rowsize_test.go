@@ -0,0 +1,180 @@
+package ibd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/zing22845/go-frm-parser/frm/ddl"
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+// parseTable builds the table of a CREATE TABLE statement
+func parseTable(t *testing.T, sql string) *table.MySQLTable {
+	t.Helper()
+	definitions, err := ddl.ParseDefinitions("db", sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(definitions) != 1 || definitions[0].Table == nil {
+		t.Fatalf("%q doesn't define one table", sql)
+	}
+	return definitions[0].Table
+}
+
+// textColumns returns n TEXT columns
+func textColumns(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, ", c%d TEXT", i)
+	}
+	return b.String()
+}
+
+// charColumns returns n CHAR(255) columns
+func charColumns(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, ", c%d CHAR(255) NOT NULL", i)
+	}
+	return b.String()
+}
+
+func TestCheckRowSizeLimits(t *testing.T) {
+	for _, c := range []struct {
+		options          string
+		pageSize         uint32
+		rowFormat        string
+		zipSize          uint32
+		recordLimit      int
+		nodePointerLimit int
+	}{
+		{"", 16384, "DYNAMIC", 0, 8126, 8126},
+		{"ROW_FORMAT=COMPACT", 16384, "COMPACT", 0, 8126, 8126},
+		{"ROW_FORMAT=REDUNDANT", 16384, "REDUNDANT", 0, 8123, 8123},
+		{"ROW_FORMAT=COMPRESSED", 16384, "COMPRESSED", 8192, 8064, 4032},
+		// REC_MAX_DATA_SIZE limits larger pages
+		{"ROW_FORMAT=DYNAMIC", 65536, "DYNAMIC", 0, 16383, 16383},
+		{"ROW_FORMAT=COMPACT", 65536, "COMPACT", 0, 16383, 16383},
+		{"ROW_FORMAT=REDUNDANT", 65536, "REDUNDANT", 0, 16383, 16383},
+		{"ROW_FORMAT=DYNAMIC", 8192, "DYNAMIC", 0, 4030, 4030},
+		// KEY_BLOCK_SIZE implies ROW_FORMAT=COMPRESSED
+		{"KEY_BLOCK_SIZE=1", 16384, "COMPRESSED", 1024, 896, 448},
+		{"KEY_BLOCK_SIZE=4", 16384, "COMPRESSED", 4096, 3968, 1984},
+		{"ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8", 16384, "COMPRESSED", 8192, 8064, 4032},
+		// an uncompressed page of KEY_BLOCK_SIZE has the limits of COMPACT
+		{"ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=16", 16384, "COMPRESSED", 16384, 8126, 8126},
+	} {
+		t.Run(fmt.Sprintf("%s %dK", c.options, c.pageSize/1024), func(t *testing.T) {
+			mt := parseTable(t, "CREATE TABLE t (id INT PRIMARY KEY) ENGINE=InnoDB "+c.options)
+			r, err := CheckRowSize(mt, &RowSizeOptions{PageSize: c.pageSize})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.RowFormat != c.rowFormat || r.ZipSize != c.zipSize || r.PageSize != c.pageSize ||
+				r.RecordLimit != c.recordLimit || r.NodePointerLimit != c.nodePointerLimit || r.TooLarge() {
+				t.Errorf("got %s %d/%d limits %d %d, findings %q", r.RowFormat, r.ZipSize, r.PageSize,
+					r.RecordLimit, r.NodePointerLimit, r.Findings)
+			}
+		})
+	}
+}
+
+func TestCheckRowSizeOffPage(t *testing.T) {
+	for _, c := range []struct {
+		name             string
+		sql              string
+		defaultRowFormat string
+		recordSize       int
+		tooLarge         bool
+	}{
+		{
+			// the 768 bytes prefix of each TEXT stays in the page
+			name:       "TEXT COMPACT",
+			sql:        "CREATE TABLE t (id INT PRIMARY KEY" + textColumns(11) + ") ENGINE=InnoDB ROW_FORMAT=COMPACT",
+			recordSize: 8714,
+			tooLarge:   true,
+		},
+		{
+			name:             "TEXT default COMPACT",
+			sql:              "CREATE TABLE t (id INT PRIMARY KEY" + textColumns(11) + ") ENGINE=InnoDB",
+			defaultRowFormat: "compact",
+			recordSize:       8714,
+			tooLarge:         true,
+		},
+		{
+			// only the 20 bytes reference stays in the page
+			name:       "TEXT DYNAMIC",
+			sql:        "CREATE TABLE t (id INT PRIMARY KEY" + textColumns(11) + ") ENGINE=InnoDB ROW_FORMAT=DYNAMIC",
+			recordSize: 475,
+		},
+		{
+			name:       "VARCHAR COMPACT",
+			sql:        "CREATE TABLE t (id INT PRIMARY KEY, v VARCHAR(1000)) CHARSET latin1 ENGINE=InnoDB ROW_FORMAT=COMPACT",
+			recordSize: 813,
+		},
+		{
+			name:       "VARCHAR DYNAMIC",
+			sql:        "CREATE TABLE t (id INT PRIMARY KEY, v VARCHAR(1000)) CHARSET latin1 ENGINE=InnoDB ROW_FORMAT=DYNAMIC",
+			recordSize: 64,
+		},
+		{
+			// fixed length columns are never stored off-page
+			name:       "CHAR DYNAMIC",
+			sql:        "CREATE TABLE t (id INT PRIMARY KEY" + charColumns(32) + ") CHARSET latin1 ENGINE=InnoDB",
+			recordSize: 8182,
+			tooLarge:   true,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			r, err := CheckRowSize(parseTable(t, c.sql), &RowSizeOptions{DefaultRowFormat: c.defaultRowFormat})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.RecordSize != c.recordSize || r.TooLarge() != c.tooLarge {
+				t.Errorf("record size %d, findings %q, want %d", r.RecordSize, r.Findings, c.recordSize)
+			}
+		})
+	}
+}
+
+func TestCheckRowSizeServerLimit(t *testing.T) {
+	for _, c := range []struct {
+		column   string
+		rowSize  uint32
+		tooLarge bool
+	}{
+		{"VARCHAR(65533) NOT NULL", 65535, false},
+		{"VARCHAR(65534) NOT NULL", 65536, true},
+		// the NULL bit takes a byte
+		{"VARCHAR(65532)", 65535, false},
+		{"VARCHAR(65533)", 65536, true},
+		{"VARCHAR(65532) NOT NULL, b LONGBLOB NOT NULL", 65546, true},
+	} {
+		t.Run(c.column, func(t *testing.T) {
+			mt := parseTable(t, "CREATE TABLE t (a "+c.column+") CHARSET latin1 ENGINE=MyISAM")
+			// as the server creates tables with VARCHAR columns
+			mt.Options.HandlerOptions |= table.HO_PACK_RECORD
+			r, err := CheckRowSize(mt, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.RowSize != c.rowSize || r.TooLarge() != c.tooLarge || r.InnoDB || r.RecordLimit != 0 {
+				t.Errorf("row size %d, findings %q, want %d", r.RowSize, r.Findings, c.rowSize)
+			}
+		})
+	}
+}
+
+func TestRowSizeReportString(t *testing.T) {
+	r, err := CheckRowSize(parseTable(t,
+		"CREATE TABLE t (id INT PRIMARY KEY"+textColumns(11)+") ENGINE=InnoDB ROW_FORMAT=COMPACT"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "t: TOO LARGE, row size 116/65535, ROW_FORMAT=COMPACT record size 8714/8126\n" +
+		"  record size 8714 exceeds the InnoDB limit of 8126 bytes for ROW_FORMAT=COMPACT and 16KiB pages"
+	if got := r.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,224 @@
+package ibd
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	// FSP_FLAGS_MASK_SDI is set in the tablespace flags of 8.0 tablespaces
+	// which carry serialized dictionary information
+	FSP_FLAGS_MASK_SDI = 1 << 14
+	// SDI_VERSION is the version stored in front of the SDI root page number
+	SDI_VERSION = 1
+
+	SDI_TYPE_TABLE      = 1
+	SDI_TYPE_TABLESPACE = 2
+
+	// offsets of the fields of a SDI leaf record, relative to the origin:
+	// type(4), id(8), DB_TRX_ID(6), DB_ROLL_PTR(7), uncompressed length(4),
+	// compressed length(4), data
+	sdiRecTypeOffset            = 0
+	sdiRecIDOffset              = 4
+	sdiRecUncompressedLenOffset = 25
+	sdiRecCompressedLenOffset   = 29
+	sdiRecDataOffset            = 33
+	sdiNodePtrChildOffset       = 12 // type(4), id(8), child page no(4)
+	sdiExternLengthOffset       = 16
+	sdiExternPageNoOffset       = 4
+)
+
+// SDIRecord is a serialized dictionary information record of a tablespace
+type SDIRecord struct {
+	Type uint32
+	ID   uint64
+	// Data is the inflated JSON document
+	Data []byte
+}
+
+// SDIRootPage returns the page number of the root of the SDI index,
+// which is stored on page 0 after the extent descriptors and encryption info
+func (ts *Tablespace) SDIRootPage() (uint32, error) {
+	if !ts.Flags.SDI() {
+		return 0, fmt.Errorf("tablespace %d has no SDI, created before MySQL 8.0", ts.SpaceID)
+	}
+	page, err := ts.ReadPage(0)
+	if err != nil {
+		return 0, err
+	}
+	extentSize := ts.extentSize()
+	xdesSize := XDES_BITMAP + (extentSize*2+7)/8
+	offset := XDES_ARR_OFFSET + xdesSize*(ts.PageSize/extentSize) + ENCRYPTION_INFO_MAX_SIZE
+	version := binary.BigEndian.Uint32(page.Data[offset:])
+	root := binary.BigEndian.Uint32(page.Data[offset+4:])
+	if version == SDI_VERSION && root != 0 && root < ts.Size {
+		return root, nil
+	}
+	return ts.scanSDIRootPage()
+}
+
+// scanSDIRootPage looks for the SDI root page when page 0 is unusable,
+// the root is the highest SDI page without siblings
+func (ts *Tablespace) scanSDIRootPage() (root uint32, err error) {
+	found := false
+	var level uint16
+	for pageNo := uint32(1); pageNo < ts.Size; pageNo++ {
+		page, err := ts.ReadPage(pageNo)
+		if err != nil {
+			break
+		}
+		if page.Type() != FIL_PAGE_SDI || page.Prev() != FIL_NULL || page.Next() != FIL_NULL {
+			continue
+		}
+		if !found || page.Level() > level {
+			root, level, found = pageNo, page.Level(), true
+		}
+	}
+	if !found {
+		return 0, fmt.Errorf("tablespace %d: SDI root page not found", ts.SpaceID)
+	}
+	return root, nil
+}
+
+// ReadSDI walks the SDI B-tree from its root down to the leftmost leaf
+// and returns every record of the leaf level
+func (ts *Tablespace) ReadSDI() (records []*SDIRecord, err error) {
+	root, err := ts.SDIRootPage()
+	if err != nil {
+		return nil, err
+	}
+	page, err := ts.ReadPage(root)
+	if err != nil {
+		return nil, err
+	}
+	for page.Level() > 0 {
+		if page.Type() != FIL_PAGE_SDI {
+			return nil, fmt.Errorf("page %d is not a SDI page", page.PageNo())
+		}
+		origins, err := page.RecordOrigins()
+		if err != nil {
+			return nil, err
+		}
+		if len(origins) == 0 {
+			return nil, fmt.Errorf("SDI node page %d has no records", page.PageNo())
+		}
+		child := binary.BigEndian.Uint32(page.Data[origins[0]+sdiNodePtrChildOffset:])
+		page, err = ts.ReadPage(child)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for {
+		if page.Type() != FIL_PAGE_SDI {
+			return nil, fmt.Errorf("page %d is not a SDI page", page.PageNo())
+		}
+		if !page.IsCompact() {
+			return nil, fmt.Errorf("SDI page %d is not in COMPACT format", page.PageNo())
+		}
+		origins, err := page.RecordOrigins()
+		if err != nil {
+			return nil, err
+		}
+		for _, origin := range origins {
+			if page.InfoBits(origin)&REC_INFO_DELETED_FLAG != 0 {
+				continue
+			}
+			record, err := ts.readSDIRecord(page, origin)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, record)
+		}
+		if page.Next() == FIL_NULL {
+			return records, nil
+		}
+		page, err = ts.ReadPage(page.Next())
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (ts *Tablespace) readSDIRecord(page *Page, origin int) (*SDIRecord, error) {
+	data := page.Data
+	record := &SDIRecord{
+		Type: binary.BigEndian.Uint32(data[origin+sdiRecTypeOffset:]),
+		ID:   binary.BigEndian.Uint64(data[origin+sdiRecIDOffset:]),
+	}
+	uncompressedLength := binary.BigEndian.Uint32(data[origin+sdiRecUncompressedLenOffset:])
+	compressedLength := binary.BigEndian.Uint32(data[origin+sdiRecCompressedLenOffset:])
+
+	// the only variable length field is the data, its length is stored
+	// in 1 or 2 bytes in front of the record header
+	lengthOffset := origin - REC_N_NEW_EXTRA_BYTES - 1
+	length := int(data[lengthOffset])
+	external := false
+	if length&0x80 != 0 {
+		external = length&0x40 != 0
+		length = (length&0x3F)<<8 | int(data[lengthOffset-1])
+	}
+	start := origin + sdiRecDataOffset
+	if start+length > len(data) {
+		return nil, fmt.Errorf("page %d: SDI record at %d is out of page", page.PageNo(), origin)
+	}
+	compressed := append([]byte{}, data[start:start+length]...)
+	if external {
+		if length < BTR_EXTERN_FIELD_REF_SIZE {
+			return nil, fmt.Errorf("page %d: invalid external reference at %d", page.PageNo(), origin)
+		}
+		ref := compressed[length-BTR_EXTERN_FIELD_REF_SIZE:]
+		compressed = compressed[:length-BTR_EXTERN_FIELD_REF_SIZE]
+		blob, err := ts.ReadBlob(
+			binary.BigEndian.Uint32(ref[sdiExternPageNoOffset:]),
+			binary.BigEndian.Uint32(ref[sdiExternLengthOffset:]))
+		if err != nil {
+			return nil, err
+		}
+		compressed = append(compressed, blob...)
+	}
+	if uint32(len(compressed)) != compressedLength {
+		return nil, fmt.Errorf("SDI record %d:%d: compressed length %d, expected %d",
+			record.Type, record.ID, len(compressed), compressedLength)
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("SDI record %d:%d: %w", record.Type, record.ID, err)
+	}
+	defer zr.Close()
+	record.Data, err = io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("SDI record %d:%d: %w", record.Type, record.ID, err)
+	}
+	if uint32(len(record.Data)) != uncompressedLength {
+		return nil, fmt.Errorf("SDI record %d:%d: uncompressed length %d, expected %d",
+			record.Type, record.ID, len(record.Data), uncompressedLength)
+	}
+	return record, nil
+}
+
+// ReadBlob reads length bytes of an externally stored field
+// from the chain of BLOB pages starting at pageNo
+func (ts *Tablespace) ReadBlob(pageNo, length uint32) (blob []byte, err error) {
+	blob = make([]byte, 0, length)
+	for pageNo != FIL_NULL && uint32(len(blob)) < length {
+		page, err := ts.ReadPage(pageNo)
+		if err != nil {
+			return nil, err
+		}
+		header := page.Data[FIL_PAGE_DATA:]
+		partLength := binary.BigEndian.Uint32(header[BTR_BLOB_HDR_PART_LEN:])
+		start := FIL_PAGE_DATA + BTR_BLOB_HDR_SIZE
+		if start+int(partLength) > len(page.Data)-FIL_PAGE_DATA_END {
+			return nil, fmt.Errorf("BLOB page %d: part length %d is out of page", pageNo, partLength)
+		}
+		blob = append(blob, page.Data[start:start+int(partLength)]...)
+		pageNo = binary.BigEndian.Uint32(header[BTR_BLOB_HDR_NEXT_PAGE_NO:])
+	}
+	if uint32(len(blob)) != length {
+		return nil, fmt.Errorf("BLOB length %d, expected %d", len(blob), length)
+	}
+	return blob, nil
+}
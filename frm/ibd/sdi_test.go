@@ -0,0 +1,49 @@
+package ibd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestReadSDI(t *testing.T) {
+	data, err := os.ReadFile("../../test_frms/table_orders.ibd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("../../test_frms/table_orders.sdi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, want); err != nil {
+		t.Fatal(err)
+	}
+	// the root is found from page 0, or by scanning the pages when the
+	// pointer of page 0 is lost
+	const sdiInfo = XDES_ARR_OFFSET + (XDES_BITMAP+(64*2+7)/8)*(UNIV_PAGE_SIZE_DEF/64) + ENCRYPTION_INFO_MAX_SIZE
+	lost := bytes.Clone(data)
+	binary.BigEndian.PutUint32(lost[sdiInfo+4:], 0)
+	for name, data := range map[string][]byte{"page 0": data, "scan": lost} {
+		ts, err := NewTablespace(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		root, err := ts.SDIRootPage()
+		if err != nil || root != 3 {
+			t.Fatalf("%s: root %d, %v", name, root, err)
+		}
+		records, err := ts.ReadSDI()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 1 || records[0].Type != SDI_TYPE_TABLE || records[0].ID != 1066 {
+			t.Fatalf("%s: records %+v", name, records)
+		}
+		if !bytes.Equal(records[0].Data, compact.Bytes()) {
+			t.Errorf("%s: data\n%s", name, records[0].Data)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package ibd
+
+import (
+	"fmt"
+	"io"
+)
+
+// Tablespace reads pages from an InnoDB tablespace file
+type Tablespace struct {
+	Reader   io.ReaderAt
+	SpaceID  uint32
+	Size     uint32
+	Flags    TablespaceFlags
+	PageSize uint32
+}
+
+// NewTablespace reads the tablespace header from page 0
+func NewTablespace(r io.ReaderAt) (ts *Tablespace, err error) {
+	h, err := ReadHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	if h.Flags.IsCompressed() {
+		return nil, fmt.Errorf("compressed tablespaces are not supported")
+	}
+	ts = &Tablespace{
+		Reader:   r,
+		SpaceID:  h.SpaceID,
+		Size:     h.Size,
+		Flags:    h.Flags,
+		PageSize: h.Flags.PageSize(),
+	}
+	return ts, nil
+}
+
+// ReadPage reads the page with the given page number
+func (ts *Tablespace) ReadPage(pageNo uint32) (*Page, error) {
+	data := make([]byte, ts.PageSize)
+	_, err := ts.Reader.ReadAt(data, int64(pageNo)*int64(ts.PageSize))
+	if err != nil {
+		return nil, fmt.Errorf("read page %d failed: %w", pageNo, err)
+	}
+	p := &Page{Data: data}
+	if p.PageNo() != pageNo {
+		return nil, fmt.Errorf("page %d has page number %d in its header", pageNo, p.PageNo())
+	}
+	return p, nil
+}
+
+// extentSize is the number of pages in an extent
+func (ts *Tablespace) extentSize() uint32 {
+	switch {
+	case ts.PageSize <= UNIV_PAGE_SIZE_DEF:
+		return (1 << 20) / ts.PageSize
+	case ts.PageSize == 32768:
+		return (2 << 20) / ts.PageSize
+	default:
+		return (4 << 20) / ts.PageSize
+	}
+}
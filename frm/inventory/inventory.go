@@ -0,0 +1,270 @@
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/zing22845/go-frm-parser/frm"
+	"github.com/zing22845/go-frm-parser/frm/table"
+	"github.com/zing22845/go-frm-parser/frm/view"
+)
+
+// DEFAULT_TOP is how many of the widest and largest-key tables a report
+// keeps by default
+const DEFAULT_TOP = 10
+
+// baseTypeRegex matches the name of a column type without its length and
+// attributes
+var baseTypeRegex = regexp.MustCompile(`^[a-z0-9]+`)
+
+// Counts maps a value to the number of objects having it
+type Counts map[string]int
+
+// Sorted returns the values by decreasing count, then by name
+func (c Counts) Sorted() []string {
+	values := make([]string, 0, len(c))
+	for value := range c {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if c[values[i]] != c[values[j]] {
+			return c[values[i]] > c[values[j]]
+		}
+		return values[i] < values[j]
+	})
+	return values
+}
+
+// TableStat is the size of a table in the widest and largest-key lists
+type TableStat struct {
+	Schema  string `json:"schema"`
+	Table   string `json:"table"`
+	Columns int    `json:"columns"`
+	// RecordLength is the length in bytes of the fixed part of a record
+	RecordLength int `json:"record_length"`
+	// Key and KeyLength are the longest key and its length in bytes
+	Key       string `json:"key,omitempty"`
+	KeyLength int    `json:"key_length"`
+}
+
+// Report is the inventory of the tables and views of a datadir
+type Report struct {
+	Datadir     string `json:"datadir"`
+	Schemas     int    `json:"schemas"`
+	Tables      int    `json:"tables"`
+	Views       int    `json:"views"`
+	Partitioned int    `json:"partitioned_tables"`
+	Columns     int    `json:"columns"`
+	Keys        int    `json:"keys"`
+
+	TablesPerSchema Counts `json:"tables_per_schema"`
+	ViewsPerSchema  Counts `json:"views_per_schema"`
+	Engines         Counts `json:"engines"`
+	RowFormats      Counts `json:"row_formats"`
+	Charsets        Counts `json:"charsets"`
+	Collations      Counts `json:"collations"`
+	MySQLVersions   Counts `json:"mysql_versions"`
+	ColumnTypes     Counts `json:"column_types"`
+
+	// Widest are the tables with the most columns, LargestKeys those
+	// with the longest keys, Top of each
+	Top         int          `json:"-"`
+	Widest      []*TableStat `json:"widest_tables"`
+	LargestKeys []*TableStat `json:"largest_key_tables"`
+
+	Errors []string `json:"errors,omitempty"`
+
+	stats []*TableStat
+}
+
+// NewReport creates an empty report keeping top tables in the widest and
+// largest-key lists
+func NewReport(datadir string, top int) *Report {
+	return &Report{
+		Datadir:         datadir,
+		TablesPerSchema: make(Counts),
+		ViewsPerSchema:  make(Counts),
+		Engines:         make(Counts),
+		RowFormats:      make(Counts),
+		Charsets:        make(Counts),
+		Collations:      make(Counts),
+		MySQLVersions:   make(Counts),
+		ColumnTypes:     make(Counts),
+		Top:             top,
+	}
+}
+
+// Build parses the .frm files of every schema of the datadir and returns
+// their inventory, files which can't be parsed are listed in Errors
+func Build(datadir string, top int) (*Report, error) {
+	r := NewReport(datadir, top)
+	d, err := frm.ReadDatadir(datadir)
+	if err != nil {
+		return nil, err
+	}
+	for _, sd := range d.Schemas {
+		if sd.IsSchema() {
+			r.Schemas++
+		}
+	}
+	err = d.Walk(func(sd *frm.SchemaDir, path string, parsed frm.MySQLSchema, err error) error {
+		if err != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("%s: %v", path, err))
+			return nil
+		}
+		r.Add(sd.Schema, parsed)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.Finish()
+	return r, nil
+}
+
+// Add counts a parsed table or view of the schema
+func (r *Report) Add(schema string, s frm.MySQLSchema) {
+	switch o := s.(type) {
+	case *table.MySQLTable:
+		r.addTable(schema, o)
+	case *view.MySQLView:
+		r.Views++
+		r.ViewsPerSchema[schema]++
+	}
+}
+
+func (r *Report) addTable(schema string, mt *table.MySQLTable) {
+	r.Tables++
+	r.TablesPerSchema[schema]++
+	o := mt.Options
+	r.Engines[o.Engine]++
+	if o.RowFormat == table.RT_DEFAULT {
+		r.RowFormats["DEFAULT"]++
+	} else {
+		r.RowFormats[o.RowFormat.String()]++
+	}
+	if o.Collation != nil {
+		charsetName, collationName := table.CanonicalCharset(o.Collation)
+		r.Charsets[charsetName]++
+		r.Collations[collationName]++
+	}
+	if mt.MySQLVersion != nil {
+		r.MySQLVersions[mt.MySQLVersion.String()]++
+	}
+	if o.Partitioning != nil || o.Partitions != "" {
+		r.Partitioned++
+	}
+
+	stat := &TableStat{Schema: schema, Table: mt.Name}
+	for _, c := range mt.Columns.Items {
+		stat.Columns++
+		r.ColumnTypes[baseTypeRegex.FindString(c.TypeName)]++
+	}
+	r.Columns += stat.Columns
+	if layout, err := mt.RecordLayout(); err == nil {
+		stat.RecordLength = int(layout.Length)
+	}
+	for _, k := range mt.Keys.Items {
+		if k.String() == "" {
+			continue
+		}
+		r.Keys++
+		length := 0
+		for _, part := range k.Parts {
+			length += int(part.Length)
+		}
+		if length > stat.KeyLength {
+			stat.Key, stat.KeyLength = k.Name, length
+		}
+	}
+	r.stats = append(r.stats, stat)
+}
+
+// Finish fills the widest and largest-key lists once every object has
+// been added
+func (r *Report) Finish() {
+	r.Widest = r.topTables(func(a, b *TableStat) bool { return a.Columns > b.Columns })
+	r.LargestKeys = r.topTables(func(a, b *TableStat) bool { return a.KeyLength > b.KeyLength })
+	for i, stat := range r.LargestKeys {
+		if stat.KeyLength == 0 {
+			// the rest has no keys
+			r.LargestKeys = r.LargestKeys[:i]
+			break
+		}
+	}
+}
+
+func (r *Report) topTables(less func(a, b *TableStat) bool) []*TableStat {
+	stats := append([]*TableStat(nil), r.stats...)
+	sort.SliceStable(stats, func(i, j int) bool {
+		if less(stats[i], stats[j]) || less(stats[j], stats[i]) {
+			return less(stats[i], stats[j])
+		}
+		if stats[i].Schema != stats[j].Schema {
+			return stats[i].Schema < stats[j].Schema
+		}
+		return stats[i].Table < stats[j].Table
+	})
+	if r.Top > 0 && len(stats) > r.Top {
+		stats = stats[:r.Top]
+	}
+	return stats
+}
+
+// WriteJSON writes the report as an indented JSON document
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+func (r *Report) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Datadir: %s\n", r.Datadir)
+	fmt.Fprintf(&sb, "Schemas: %d, tables: %d (%d partitioned), views: %d, columns: %d, keys: %d\n",
+		r.Schemas, r.Tables, r.Partitioned, r.Views, r.Columns, r.Keys)
+	for _, section := range []struct {
+		title  string
+		counts Counts
+	}{
+		{"Tables per schema", r.TablesPerSchema},
+		{"Views per schema", r.ViewsPerSchema},
+		{"Engines", r.Engines},
+		{"Row formats", r.RowFormats},
+		{"Charsets", r.Charsets},
+		{"Collations", r.Collations},
+		{"MySQL versions", r.MySQLVersions},
+		{"Column types", r.ColumnTypes},
+	} {
+		if len(section.counts) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n%s:\n", section.title)
+		for _, value := range section.counts.Sorted() {
+			fmt.Fprintf(&sb, "  %-32s %d\n", value, section.counts[value])
+		}
+	}
+	if len(r.Widest) != 0 {
+		sb.WriteString("\nWidest tables:\n")
+		for _, stat := range r.Widest {
+			fmt.Fprintf(&sb, "  %-32s %d columns, %d bytes\n", stat.Schema+"."+stat.Table, stat.Columns, stat.RecordLength)
+		}
+	}
+	if len(r.LargestKeys) != 0 {
+		sb.WriteString("\nLargest-key tables:\n")
+		for _, stat := range r.LargestKeys {
+			fmt.Fprintf(&sb, "  %-32s %d bytes (%s)\n", stat.Schema+"."+stat.Table, stat.KeyLength, stat.Key)
+		}
+	}
+	if len(r.Errors) != 0 {
+		sb.WriteString("\nErrors:\n")
+		for _, err := range r.Errors {
+			fmt.Fprintf(&sb, "  %s\n", err)
+		}
+	}
+	return sb.String()
+}
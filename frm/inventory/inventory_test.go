@@ -0,0 +1,138 @@
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zing22845/go-frm-parser/frm"
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+// writeFile writes data, or copies the file of test_frms named by data
+// when it starts with @
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	content := []byte(data)
+	if name, ok := strings.CutPrefix(data, "@"); ok {
+		var err error
+		content, err = os.ReadFile(filepath.Join("../../test_frms", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// parseTable parses a table of test_frms
+func parseTable(t *testing.T, name string) *table.MySQLTable {
+	t.Helper()
+	s, err := frm.ParseFile(filepath.Join("../../test_frms", name+".frm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s.(*table.MySQLTable)
+}
+
+func TestBuild(t *testing.T) {
+	datadir := t.TempDir()
+	for path, data := range map[string]string{
+		"ibdata1":                 "",
+		"shop/db.opt":             "default-character-set=utf8\n",
+		"shop/t1.frm":             "@table_simple.frm",
+		"shop/t2.frm":             "@table_key_using_btree.frm",
+		"shop/v1.frm":             "@view_md5_success.frm",
+		"shop/#sql-1a2b_3.frm":    "@table_simple.frm",
+		"shop/broken.frm":         "x",
+		"bare/db.opt":             "",
+		"#innodb_temp/temp_1.ibt": "",
+		"lost+found/.keep":        "",
+	} {
+		writeFile(t, filepath.Join(datadir, path), data)
+	}
+	if err := os.Mkdir(filepath.Join(datadir, "empty"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Build(datadir, DEFAULT_TOP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// #innodb_temp, lost+found and empty hold no definition
+	if r.Schemas != 2 || r.Tables != 2 || r.Views != 1 || r.Columns != 3 || r.Keys != 1 {
+		t.Errorf("got %d schemas, %d tables, %d views, %d columns and %d keys",
+			r.Schemas, r.Tables, r.Views, r.Columns, r.Keys)
+	}
+	if want := (Counts{"shop": 2}); !reflect.DeepEqual(r.TablesPerSchema, want) {
+		t.Errorf("got tables per schema %v, want %v", r.TablesPerSchema, want)
+	}
+	if want := (Counts{"shop": 1}); !reflect.DeepEqual(r.ViewsPerSchema, want) {
+		t.Errorf("got views per schema %v, want %v", r.ViewsPerSchema, want)
+	}
+	if len(r.Errors) != 1 || !strings.HasPrefix(r.Errors[0], filepath.Join(datadir, "shop", "broken.frm")+": ") {
+		t.Errorf("got errors %q", r.Errors)
+	}
+}
+
+func TestAddTable(t *testing.T) {
+	r := NewReport("/var/lib/mysql", 1)
+	r.Add("shop", parseTable(t, "table_simple"))
+	partitioned := parseTable(t, "table_key_using_btree")
+	partitioned.Options.Partitions = " PARTITION BY HASH (id) PARTITIONS 4"
+	r.Add("shop", partitioned)
+	r.Add("crm", parseTable(t, "table_simple"))
+	r.Finish()
+
+	if r.Tables != 3 || r.Partitioned != 1 || r.Columns != 4 || r.Keys != 1 {
+		t.Errorf("got %d tables, %d partitioned, %d columns and %d keys", r.Tables, r.Partitioned, r.Columns, r.Keys)
+	}
+	for _, c := range []struct {
+		name string
+		got  Counts
+		want Counts
+	}{
+		{"tables per schema", r.TablesPerSchema, Counts{"shop": 2, "crm": 1}},
+		{"engines", r.Engines, Counts{"InnoDB": 3}},
+		{"row formats", r.RowFormats, Counts{"DEFAULT": 3}},
+		{"charsets", r.Charsets, Counts{"utf8mb3": 3}},
+		{"collations", r.Collations, Counts{"utf8mb3_general_ci": 3}},
+		{"MySQL versions", r.MySQLVersions, Counts{"5.7.24": 3}},
+		{"column types", r.ColumnTypes, Counts{"int": 3, "char": 1}},
+	} {
+		if !reflect.DeepEqual(c.got, c.want) {
+			t.Errorf("got %s %v, want %v", c.name, c.got, c.want)
+		}
+	}
+	if got := r.ColumnTypes.Sorted(); !reflect.DeepEqual(got, []string{"int", "char"}) {
+		t.Errorf("got sorted column types %q", got)
+	}
+
+	// top keeps one table of each list
+	want := &TableStat{Schema: "shop", Table: "table_key_using_btree", Columns: 2, RecordLength: 101, Key: "user_idx", KeyLength: 4}
+	if len(r.Widest) != 1 || !reflect.DeepEqual(r.Widest[0], want) {
+		t.Errorf("got widest tables %+v", r.Widest)
+	}
+	if len(r.LargestKeys) != 1 || !reflect.DeepEqual(r.LargestKeys[0], want) {
+		t.Errorf("got largest-key tables %+v", r.LargestKeys)
+	}
+}
+
+func TestFinishWithoutKeys(t *testing.T) {
+	r := NewReport("/var/lib/mysql", DEFAULT_TOP)
+	r.Add("shop", parseTable(t, "table_simple"))
+	r.Add("crm", parseTable(t, "table_simple"))
+	r.Finish()
+	// equal widths are ordered by schema, tables without keys are dropped
+	if len(r.Widest) != 2 || r.Widest[0].Schema != "crm" || r.Widest[1].Schema != "shop" {
+		t.Errorf("got widest tables %+v", r.Widest)
+	}
+	if len(r.LargestKeys) != 0 {
+		t.Errorf("got largest-key tables %+v", r.LargestKeys)
+	}
+}
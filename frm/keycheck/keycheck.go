@@ -0,0 +1,297 @@
+package keycheck
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+// Kind is why an index is redundant
+type Kind string
+
+const (
+	// KIND_DUPLICATE is an index with the same parts as another one
+	KIND_DUPLICATE Kind = "duplicate"
+	// KIND_LEFT_PREFIX is an index whose parts are a left prefix of another
+	// one, which serves the same lookups
+	KIND_LEFT_PREFIX Kind = "left-prefix"
+	// KIND_UNIQUE_BY_PRIMARY is a unique key holding all the primary key
+	// columns, the primary key already makes its rows unique
+	KIND_UNIQUE_BY_PRIMARY Kind = "unique-by-primary"
+	// KIND_PRIMARY_SUFFIX is an InnoDB secondary index ending with the
+	// primary key columns, which InnoDB appends to every secondary index
+	KIND_PRIMARY_SUFFIX Kind = "primary-key-suffix"
+)
+
+// Finding is a redundant index of a table
+type Finding struct {
+	Table string `json:"table"`
+	Kind  Kind   `json:"kind"`
+	// Key and Definition are the redundant index, CoveredBy and
+	// CoveredByDefinition the index which makes it so
+	Key                 string `json:"key"`
+	Definition          string `json:"definition"`
+	CoveredBy           string `json:"covered_by"`
+	CoveredByDefinition string `json:"covered_by_definition"`
+	// Width is the estimated size in bytes of an index entry
+	Width int `json:"width"`
+	// Statement drops the index, or for KIND_PRIMARY_SUFFIX recreates it
+	// without the primary key columns
+	Statement string `json:"statement"`
+}
+
+func (f *Finding) String() string {
+	return fmt.Sprintf("%s: %s is %s %s, about %d bytes per entry\n  %s\n  %s\n%s",
+		f.Table, quoteName(f.Key), f.Kind.describe(), quoteName(f.CoveredBy), f.Width,
+		f.Definition, f.CoveredByDefinition, f.Statement)
+}
+
+func (k Kind) describe() string {
+	switch k {
+	case KIND_DUPLICATE:
+		return "a duplicate of"
+	case KIND_LEFT_PREFIX:
+		return "a left prefix of"
+	case KIND_UNIQUE_BY_PRIMARY:
+		return "a unique key implied by"
+	case KIND_PRIMARY_SUFFIX:
+		return "repeating the clustered columns of"
+	}
+	return string(k)
+}
+
+// Check finds the duplicate and redundant indexes of the table, an index
+// is reported once, for the first reason found
+func Check(mt *table.MySQLTable) []*Finding {
+	var keys []*table.Key
+	for _, k := range mt.Keys.Items {
+		if len(k.Parts) != 0 && k.String() != "" {
+			keys = append(keys, k)
+		}
+	}
+	c := &checker{mt: mt, keys: keys, reported: make(map[*table.Key]bool), covering: make(map[*table.Key]bool)}
+	c.duplicates()
+	c.leftPrefixes()
+	c.uniqueByPrimary()
+	c.primarySuffixes()
+	sort.SliceStable(c.findings, func(i, j int) bool {
+		return c.findings[i].Key < c.findings[j].Key
+	})
+	return c.findings
+}
+
+type checker struct {
+	mt       *table.MySQLTable
+	keys     []*table.Key
+	reported map[*table.Key]bool
+	// covering are the indexes which make a reported one redundant, they
+	// have to be kept
+	covering map[*table.Key]bool
+	findings []*Finding
+}
+
+func (c *checker) report(kind Kind, k, by *table.Key) {
+	c.reported[k] = true
+	c.covering[by] = true
+	c.findings = append(c.findings, &Finding{
+		Table:               c.mt.Name,
+		Kind:                kind,
+		Key:                 k.Name,
+		Definition:          definition(k),
+		CoveredBy:           by.Name,
+		CoveredByDefinition: definition(by),
+		Width:               c.width(k),
+		Statement:           fmt.Sprintf("ALTER TABLE %s DROP INDEX %s;", quoteName(c.mt.Name), quoteName(k.Name)),
+	})
+}
+
+// duplicates reports indexes with the same type and parts as an earlier
+// one, the primary key and unique keys are kept over plain indexes
+func (c *checker) duplicates() {
+	for i, a := range c.keys {
+		for _, b := range c.keys[i+1:] {
+			if c.reported[a] || c.reported[b] || a.IndexType != b.IndexType || !sameParts(a.Parts, b.Parts) {
+				continue
+			}
+			if rank(b) > rank(a) {
+				c.report(KIND_DUPLICATE, a, b)
+			} else {
+				c.report(KIND_DUPLICATE, b, a)
+			}
+		}
+	}
+}
+
+// leftPrefixes reports non-unique BTREE indexes which are a left prefix of
+// another BTREE index
+func (c *checker) leftPrefixes() {
+	for _, a := range c.keys {
+		if c.reported[a] || a.IsUnique || a.IndexType != "BTREE" {
+			continue
+		}
+		for _, b := range c.keys {
+			if a == b || c.reported[b] || b.IndexType != "BTREE" || !isLeftPrefix(a.Parts, b.Parts) {
+				continue
+			}
+			c.report(KIND_LEFT_PREFIX, a, b)
+			break
+		}
+	}
+}
+
+// uniqueByPrimary reports unique keys which hold every column of the
+// primary key in full, but those serving the lookups of a reported index
+func (c *checker) uniqueByPrimary() {
+	pk := c.mt.PrimaryKey()
+	if pk == nil {
+		return
+	}
+	for _, k := range c.keys {
+		if k == pk || c.reported[k] || c.covering[k] || !k.IsUnique || k.IndexType != "BTREE" {
+			continue
+		}
+		if containsAll(k.Parts, pk.Parts) {
+			c.report(KIND_UNIQUE_BY_PRIMARY, k, pk)
+		}
+	}
+}
+
+// primarySuffixes reports InnoDB secondary indexes ending with the primary
+// key columns, the statement recreates them without those columns
+func (c *checker) primarySuffixes() {
+	pk := c.mt.PrimaryKey()
+	if pk == nil || !strings.EqualFold(c.mt.Options.Engine, "InnoDB") {
+		return
+	}
+	for _, k := range c.keys {
+		if k == pk || c.reported[k] || k.IsUnique || k.IndexType != "BTREE" || len(k.Parts) <= len(pk.Parts) {
+			continue
+		}
+		prefix := k.Parts[:len(k.Parts)-len(pk.Parts)]
+		if !sameParts(k.Parts[len(prefix):], pk.Parts) {
+			continue
+		}
+		c.report(KIND_PRIMARY_SUFFIX, k, pk)
+		parts := make([]string, len(prefix))
+		for i, part := range prefix {
+			parts[i] = k.FormatKeyPart(part)
+		}
+		finding := c.findings[len(c.findings)-1]
+		finding.Statement = fmt.Sprintf("ALTER TABLE %s DROP INDEX %s, ADD INDEX %s (%s);",
+			quoteName(c.mt.Name), quoteName(k.Name), quoteName(k.Name), strings.Join(parts, ","))
+	}
+}
+
+// width estimates the bytes of an index entry: the key parts with their
+// length bytes and NULL flags, and for InnoDB secondary indexes the
+// primary key columns the index doesn't hold
+func (c *checker) width(k *table.Key) int {
+	width := 0
+	for _, part := range k.Parts {
+		width += partWidth(part)
+	}
+	pk := c.mt.PrimaryKey()
+	if pk == nil || k == pk || !strings.EqualFold(c.mt.Options.Engine, "InnoDB") {
+		return width
+	}
+	for _, part := range pk.Parts {
+		if !holdsColumn(k.Parts, part) {
+			width += partWidth(part)
+		}
+	}
+	return width
+}
+
+func partWidth(part *table.KeyPart) int {
+	width := int(part.Length)
+	if part.Column == nil {
+		return width
+	}
+	switch part.Column.TypeCode {
+	case table.MT_VARCHAR, table.MT_VAR_STRING, table.MT_TINY_BLOB, table.MT_MEDIUM_BLOB,
+		table.MT_LONG_BLOB, table.MT_BLOB, table.MT_GEOMETRY, table.MT_JSON:
+		width += 2
+	}
+	if part.Column.Flags.HasFlag(table.FF_MAYBE_NULL) {
+		width++
+	}
+	return width
+}
+
+// rank orders which of two duplicates is kept
+func rank(k *table.Key) int {
+	switch {
+	case k.Name == "PRIMARY":
+		return 2
+	case k.IsUnique:
+		return 1
+	}
+	return 0
+}
+
+func samePart(a, b *table.KeyPart) bool {
+	if a.Expression != "" || b.Expression != "" {
+		return a.Expression == b.Expression && a.Descending == b.Descending
+	}
+	return a.Column == b.Column && a.Length == b.Length && a.Descending == b.Descending
+}
+
+func sameParts(a, b []*table.KeyPart) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !samePart(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isLeftPrefix reports whether a is a left prefix of b, the last part of a
+// may index a shorter prefix of the column than b does
+func isLeftPrefix(a, b []*table.KeyPart) bool {
+	if len(a) > len(b) {
+		return false
+	}
+	for i, part := range a {
+		if samePart(part, b[i]) {
+			continue
+		}
+		last := i == len(a)-1
+		if !last || part.Expression != "" || part.Column != b[i].Column ||
+			part.Descending != b[i].Descending || part.Length > b[i].Length {
+			return false
+		}
+	}
+	return true
+}
+
+// holdsColumn reports whether parts index all of the column of part
+func holdsColumn(parts []*table.KeyPart, part *table.KeyPart) bool {
+	for _, p := range parts {
+		if p.Expression == "" && p.Column == part.Column && p.Length >= part.Length {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAll(parts, of []*table.KeyPart) bool {
+	for _, part := range of {
+		if part.Expression != "" || !holdsColumn(parts, part) {
+			return false
+		}
+	}
+	return true
+}
+
+func definition(k *table.Key) string {
+	return strings.Replace(strings.TrimSpace(k.String()), " USING BTREE", "", 1)
+}
+
+func quoteName(name string) string {
+	return fmt.Sprintf("`%s`", strings.ReplaceAll(name, "`", "``"))
+}
@@ -0,0 +1,199 @@
+package keycheck
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+// newTable returns a table t1 of the engine with the columns id and c INT
+// NOT NULL, a INT, name VARCHAR(100) latin1 and title VARCHAR(50) utf8mb4,
+// and the keys. A key is its name followed by its parts, the name PRIMARY,
+// a UNIQUE or FULLTEXT prefix set its type, a part is the column name
+// with an optional prefix length in characters and DESC
+func newTable(t *testing.T, engine string, keys ...string) *table.MySQLTable {
+	t.Helper()
+	latin1, err := table.GetCollationByName("latin1_swedish_ci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	utf8mb4, err := table.GetCollationByName("utf8mb4_general_ci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns := []*table.Column{
+		{Name: "id", TypeCode: table.MT_LONG, Length: 11},
+		{Name: "c", TypeCode: table.MT_LONG, Length: 11},
+		{Name: "a", TypeCode: table.MT_LONG, Length: 11, Flags: table.FF_MAYBE_NULL},
+		{Name: "name", TypeCode: table.MT_VARCHAR, Length: 100, Collation: latin1, Flags: table.FF_MAYBE_NULL},
+		{Name: "title", TypeCode: table.MT_VARCHAR, Length: 200, Collation: utf8mb4},
+	}
+	// the length of a key part of the whole column
+	partLength := map[string]uint16{"id": 4, "c": 4, "a": 4, "name": 100, "title": 200}
+	mt := &table.MySQLTable{
+		Name:    "t1",
+		Options: &table.Options{Engine: engine},
+		Columns: &table.Columns{Items: columns},
+		Keys:    &table.Keys{},
+	}
+	for _, spec := range keys {
+		fields := strings.Fields(spec)
+		k := &table.Key{IndexType: "BTREE"}
+		switch fields[0] {
+		case "UNIQUE":
+			k.IsUnique, fields = true, fields[1:]
+		case "FULLTEXT":
+			k.IndexType, fields = "FULLTEXT", fields[1:]
+		}
+		k.Name = fields[0]
+		k.IsUnique = k.IsUnique || k.Name == "PRIMARY"
+		for _, field := range fields[1:] {
+			if field == "DESC" {
+				k.Parts[len(k.Parts)-1].Descending = true
+				continue
+			}
+			name, prefix, _ := strings.Cut(strings.TrimSuffix(field, ")"), "(")
+			part := &table.KeyPart{Length: partLength[name]}
+			for _, c := range columns {
+				if c.Name == name {
+					part.Column = c
+				}
+			}
+			if prefix != "" {
+				n, err := strconv.Atoi(prefix)
+				if err != nil {
+					t.Fatal(err)
+				}
+				part.Length = uint16(n * part.Column.Collation.Maxlen)
+			}
+			k.Parts = append(k.Parts, part)
+		}
+		mt.Keys.Items = append(mt.Keys.Items, k)
+	}
+	return mt
+}
+
+func findings(mt *table.MySQLTable) (lines []string) {
+	for _, f := range Check(mt) {
+		lines = append(lines, fmt.Sprintf("%s %s %s, %d bytes: %s", f.Key, f.Kind, f.CoveredBy, f.Width, f.Statement))
+	}
+	return lines
+}
+
+func TestCheck(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		engine string
+		keys   []string
+		want   []string
+	}{
+		{
+			name:   "duplicates",
+			engine: "InnoDB",
+			keys: []string{
+				"PRIMARY id", "k_id id", "UNIQUE uk_a a", "k_a a", "k_a2 a", "k_c c", "UNIQUE uk_c c",
+				// the order, the index type and the prefix length matter
+				"k_c_desc c DESC", "FULLTEXT ft name", "k_name name", "k_name10 name(10)",
+			},
+			want: []string{
+				"k_a duplicate uk_a, 9 bytes: ALTER TABLE `t1` DROP INDEX `k_a`;",
+				"k_a2 duplicate uk_a, 9 bytes: ALTER TABLE `t1` DROP INDEX `k_a2`;",
+				// the primary key and unique keys are kept over plain indexes
+				"k_c duplicate uk_c, 8 bytes: ALTER TABLE `t1` DROP INDEX `k_c`;",
+				"k_id duplicate PRIMARY, 4 bytes: ALTER TABLE `t1` DROP INDEX `k_id`;",
+				"k_name10 left-prefix k_name, 17 bytes: ALTER TABLE `t1` DROP INDEX `k_name10`;",
+			},
+		},
+		{
+			name:   "left prefixes",
+			engine: "MyISAM",
+			keys: []string{
+				"k_a a", "k_a_c a c", "k_name10 name(10)", "k_name20_a name(20) a", "k_name30 name(30)",
+				"k_title title(10)", "k_title_c title(20) c", "UNIQUE uk_c c", "k_c_a c a",
+				"k_c_desc c DESC", "k_c_asc_a c a DESC",
+			},
+			want: []string{
+				"k_a left-prefix k_a_c, 5 bytes: ALTER TABLE `t1` DROP INDEX `k_a`;",
+				"k_name10 left-prefix k_name20_a, 13 bytes: ALTER TABLE `t1` DROP INDEX `k_name10`;",
+				"k_title left-prefix k_title_c, 42 bytes: ALTER TABLE `t1` DROP INDEX `k_title`;",
+			},
+		},
+		{
+			name:   "unique keys implied by the primary key",
+			engine: "InnoDB",
+			keys: []string{
+				"PRIMARY id", "UNIQUE uk_c_id c id", "UNIQUE uk_name_id name(10) id", "UNIQUE uk_c c",
+				"UNIQUE uk_a a",
+			},
+			want: []string{
+				"uk_c_id unique-by-primary PRIMARY, 8 bytes: ALTER TABLE `t1` DROP INDEX `uk_c_id`;",
+				"uk_name_id unique-by-primary PRIMARY, 17 bytes: ALTER TABLE `t1` DROP INDEX `uk_name_id`;",
+			},
+		},
+		{
+			// dropping both would leave no index on c
+			name:   "unique key implied by the primary key covering an index",
+			engine: "InnoDB",
+			keys:   []string{"PRIMARY id", "k_c c", "UNIQUE uk_c_id c id", "k_a_id a id", "UNIQUE uk_a_id a id"},
+			want: []string{
+				"k_a_id duplicate uk_a_id, 9 bytes: ALTER TABLE `t1` DROP INDEX `k_a_id`;",
+				"k_c left-prefix uk_c_id, 8 bytes: ALTER TABLE `t1` DROP INDEX `k_c`;",
+			},
+		},
+		{
+			name:   "no primary key",
+			engine: "InnoDB",
+			keys:   []string{"UNIQUE uk_c_id c id", "k_a_c a c"},
+		},
+		{
+			name:   "primary key suffixes",
+			engine: "InnoDB",
+			keys: []string{
+				"PRIMARY id", "k_a_id a id", "k_name_id name(10) id", "k_id_a id a", "k_title_a_id title(5) a id",
+			},
+			want: []string{
+				"k_a_id primary-key-suffix PRIMARY, 9 bytes: ALTER TABLE `t1` DROP INDEX `k_a_id`, ADD INDEX `k_a_id` (`a`);",
+				"k_name_id primary-key-suffix PRIMARY, 17 bytes: " +
+					"ALTER TABLE `t1` DROP INDEX `k_name_id`, ADD INDEX `k_name_id` (`name`(10));",
+				"k_title_a_id primary-key-suffix PRIMARY, 31 bytes: " +
+					"ALTER TABLE `t1` DROP INDEX `k_title_a_id`, ADD INDEX `k_title_a_id` (`title`(5),`a`);",
+			},
+		},
+		{
+			name:   "composite primary key suffixes",
+			engine: "InnoDB",
+			keys:   []string{"PRIMARY id c", "k_a_id_c a id c", "k_a_c_id a c id"},
+			want: []string{
+				"k_a_id_c primary-key-suffix PRIMARY, 13 bytes: ALTER TABLE `t1` DROP INDEX `k_a_id_c`, ADD INDEX `k_a_id_c` (`a`);",
+			},
+		},
+		{
+			// MyISAM indexes don't hold the primary key
+			name:   "MyISAM primary key suffixes",
+			engine: "MyISAM",
+			keys:   []string{"PRIMARY id", "k_a_id a id"},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if got := findings(newTable(t, c.engine, c.keys...)); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestFindingString(t *testing.T) {
+	f := Check(newTable(t, "InnoDB", "PRIMARY id", "k_a a", "k_a_c a c"))
+	if len(f) != 1 {
+		t.Fatalf("%d findings", len(f))
+	}
+	want := "t1: `k_a` is a left prefix of `k_a_c`, about 9 bytes per entry\n" +
+		"  KEY `k_a` (`a`)\n  KEY `k_a_c` (`a`,`c`)\nALTER TABLE `t1` DROP INDEX `k_a`;"
+	if got := f[0].String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,127 @@
+package lint
+
+import (
+	"sort"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+// Severity is the level of a finding, named like the SARIF levels
+type Severity string
+
+const (
+	SEVERITY_ERROR   Severity = "error"
+	SEVERITY_WARNING Severity = "warning"
+	SEVERITY_NOTE    Severity = "note"
+)
+
+// Location is where a finding is, Column and Key are empty for
+// findings about the whole table
+type Location struct {
+	File   string `json:"file,omitempty"`
+	Table  string `json:"table"`
+	Column string `json:"column,omitempty"`
+	Key    string `json:"key,omitempty"`
+}
+
+// Finding is a problem a rule found in a table
+type Finding struct {
+	RuleID   string   `json:"rule_id"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	Location Location `json:"location"`
+}
+
+// Rule checks a table, Check returns the findings with their message and
+// column or key, the linter fills the rest
+type Rule struct {
+	ID          string
+	Description string
+	Severity    Severity
+	Check       func(mt *table.MySQLTable) []*Finding
+}
+
+// Linter runs the enabled rules over tables
+type Linter struct {
+	Rules    []*Rule
+	disabled map[string]bool
+}
+
+// NewLinter returns a linter with the rules, DefaultRules if none are given
+func NewLinter(rules ...*Rule) *Linter {
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+	return &Linter{Rules: rules, disabled: make(map[string]bool)}
+}
+
+// Disable switches the rules off
+func (l *Linter) Disable(ids ...string) {
+	for _, id := range ids {
+		l.disabled[id] = true
+	}
+}
+
+// Enable switches the rules on again
+func (l *Linter) Enable(ids ...string) {
+	for _, id := range ids {
+		delete(l.disabled, id)
+	}
+}
+
+// EnableOnly switches all the rules off but the given ones
+func (l *Linter) EnableOnly(ids ...string) {
+	for _, rule := range l.Rules {
+		l.disabled[rule.ID] = true
+	}
+	l.Enable(ids...)
+}
+
+// Enabled reports whether the rule is switched on
+func (l *Linter) Enabled(id string) bool {
+	return !l.disabled[id]
+}
+
+// Rule returns the rule with the id, nil if there is none
+func (l *Linter) Rule(id string) *Rule {
+	for _, rule := range l.Rules {
+		if rule.ID == id {
+			return rule
+		}
+	}
+	return nil
+}
+
+// Lint runs the enabled rules over the table parsed from the file at path
+func (l *Linter) Lint(path string, mt *table.MySQLTable) []*Finding {
+	var findings []*Finding
+	for _, rule := range l.Rules {
+		if !l.Enabled(rule.ID) {
+			continue
+		}
+		for _, finding := range rule.Check(mt) {
+			finding.RuleID = rule.ID
+			if finding.Severity == "" {
+				finding.Severity = rule.Severity
+			}
+			finding.Location.File = path
+			finding.Location.Table = mt.Name
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+// Sort orders the findings by file, table and rule
+func Sort(findings []*Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Location.File != b.Location.File {
+			return a.Location.File < b.Location.File
+		}
+		if a.Location.Table != b.Location.Table {
+			return a.Location.Table < b.Location.Table
+		}
+		return a.RuleID < b.RuleID
+	})
+}
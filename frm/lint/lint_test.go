@@ -0,0 +1,98 @@
+package lint
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+// legacyTable is a MyISAM latin1 table without a primary key
+func legacyTable(t *testing.T) *table.MySQLTable {
+	return newTable(t, "MyISAM", "latin1_swedish_ci",
+		&table.Column{Name: "price", TypeCode: table.MT_FLOAT},
+		&table.Column{Name: "y", TypeCode: table.MT_YEAR, Length: 2})
+}
+
+func ruleIDs(findings []*Finding) (ids []string) {
+	for _, f := range findings {
+		ids = append(ids, f.RuleID)
+	}
+	return ids
+}
+
+func TestLinterRules(t *testing.T) {
+	mt := legacyTable(t)
+	l := NewLinter()
+	if len(l.Rules) != len(DefaultRules()) {
+		t.Fatalf("%d rules, want the %d default ones", len(l.Rules), len(DefaultRules()))
+	}
+	want := []string{RULE_NO_PRIMARY_KEY, RULE_ENGINE, RULE_LEGACY_CHARSET, RULE_FLOAT_MONEY, RULE_YEAR2}
+	if got := ruleIDs(l.Lint("t1.frm", mt)); !reflect.DeepEqual(got, want) {
+		t.Errorf("all rules: got %q, want %q", got, want)
+	}
+
+	l.Disable(RULE_ENGINE, RULE_LEGACY_CHARSET)
+	want = []string{RULE_NO_PRIMARY_KEY, RULE_FLOAT_MONEY, RULE_YEAR2}
+	if got := ruleIDs(l.Lint("t1.frm", mt)); !reflect.DeepEqual(got, want) {
+		t.Errorf("disabled: got %q, want %q", got, want)
+	}
+
+	l.Enable(RULE_ENGINE)
+	want = []string{RULE_NO_PRIMARY_KEY, RULE_ENGINE, RULE_FLOAT_MONEY, RULE_YEAR2}
+	if got := ruleIDs(l.Lint("t1.frm", mt)); !reflect.DeepEqual(got, want) {
+		t.Errorf("enabled again: got %q, want %q", got, want)
+	}
+
+	l.EnableOnly(RULE_YEAR2, RULE_ENGINE)
+	want = []string{RULE_ENGINE, RULE_YEAR2}
+	if got := ruleIDs(l.Lint("t1.frm", mt)); !reflect.DeepEqual(got, want) {
+		t.Errorf("enabled only: got %q, want %q", got, want)
+	}
+	if l.Enabled(RULE_FLOAT_MONEY) || !l.Enabled(RULE_YEAR2) {
+		t.Error("EnableOnly left the other rules on")
+	}
+	if l.Rule(RULE_YEAR2) == nil || l.Rule("no-such-rule") != nil {
+		t.Error("Rule doesn't find the rules by id")
+	}
+}
+
+func TestLint(t *testing.T) {
+	findings := NewLinter(Engine(), FloatMoney()).Lint("db/t1.frm", legacyTable(t))
+	want := []*Finding{
+		{
+			RuleID:   RULE_ENGINE,
+			Severity: SEVERITY_WARNING,
+			Message:  "table uses the MyISAM engine, which is not transactional nor crash safe",
+			Location: Location{File: "db/t1.frm", Table: "t1"},
+		},
+		{
+			RuleID:   RULE_FLOAT_MONEY,
+			Severity: SEVERITY_WARNING,
+			Message:  "column looks like money but is FLOAT, use DECIMAL",
+			Location: Location{File: "db/t1.frm", Table: "t1", Column: "price"},
+		},
+	}
+	if !reflect.DeepEqual(findings, want) {
+		t.Errorf("got %+v, want %+v", findings, want)
+	}
+}
+
+func TestSort(t *testing.T) {
+	findings := []*Finding{
+		{RuleID: "b", Location: Location{File: "db/t2.frm", Table: "t2"}},
+		{RuleID: "b", Location: Location{File: "db/t1.frm", Table: "t1"}},
+		{RuleID: "a", Location: Location{File: "db/t1.frm", Table: "t1", Column: "c2"}},
+		{RuleID: "a", Location: Location{File: "db/t1.frm", Table: "t1", Column: "c1"}},
+	}
+	Sort(findings)
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Location.Table+"."+f.Location.Column+" "+f.RuleID)
+	}
+	// findings of the same rule keep their order
+	want := []string{"t1.c2 a", "t1.c1 a", "t1. b", "t2. b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
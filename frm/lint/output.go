@@ -0,0 +1,185 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	FORMAT_TEXT  = "text"
+	FORMAT_JSON  = "json"
+	FORMAT_SARIF = "sarif"
+
+	SARIF_VERSION = "2.1.0"
+	SARIF_SCHEMA  = "https://json.schemastore.org/sarif-2.1.0.json"
+	TOOL_NAME     = "go-frm-parser"
+	TOOL_URI      = "https://github.com/zing22845/go-frm-parser"
+)
+
+// String formats the finding as a line of text
+func (f *Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s: %s [%s]",
+		f.Location.File, f.Location.String(), f.Severity, f.Message, f.RuleID)
+}
+
+// String names the table and the column or key of the location
+func (l *Location) String() string {
+	parts := []string{fmt.Sprintf("`%s`", l.Table)}
+	if l.Column != "" {
+		parts = append(parts, fmt.Sprintf("`%s`", l.Column))
+	}
+	s := strings.Join(parts, ".")
+	if l.Key != "" {
+		s += fmt.Sprintf(" key `%s`", l.Key)
+	}
+	return s
+}
+
+// Write writes the findings in the format, text, json or sarif
+func (l *Linter) Write(w io.Writer, format string, findings []*Finding) error {
+	switch format {
+	case FORMAT_TEXT, "":
+		return WriteText(w, findings)
+	case FORMAT_JSON:
+		return WriteJSON(w, findings)
+	case FORMAT_SARIF:
+		return l.WriteSARIF(w, findings)
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
+
+// WriteText writes a line per finding
+func WriteText(w io.Writer, findings []*Finding) error {
+	for _, f := range findings {
+		_, err := fmt.Fprintln(w, f.String())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the findings as a JSON array
+func WriteJSON(w io.Writer, findings []*Finding) error {
+	if findings == nil {
+		findings = []*Finding{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(findings)
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level Severity `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     Severity        `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// WriteSARIF writes the findings as a SARIF 2.1.0 log, with the enabled
+// rules of the linter
+func (l *Linter) WriteSARIF(w io.Writer, findings []*Finding) error {
+	driver := sarifDriver{Name: TOOL_NAME, InformationURI: TOOL_URI, Rules: []sarifRule{}}
+	ruleIndex := make(map[string]int)
+	for _, rule := range l.Rules {
+		if !l.Enabled(rule.ID) {
+			continue
+		}
+		ruleIndex[rule.ID] = len(driver.Rules)
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   rule.ID,
+			ShortDescription:     sarifMessage{Text: rule.Description},
+			DefaultConfiguration: sarifConfiguration{Level: rule.Severity},
+		})
+	}
+	run := sarifRun{Tool: sarifTool{Driver: driver}, Results: []sarifResult{}}
+	for _, f := range findings {
+		location := sarifLocation{LogicalLocations: []sarifLogicalLocation{f.Location.sarifLogicalLocation()}}
+		if f.Location.File != "" {
+			location.PhysicalLocation = &sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: f.Location.File},
+			}
+		}
+		index, ok := ruleIndex[f.RuleID]
+		if !ok {
+			index = -1
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    f.RuleID,
+			RuleIndex: index,
+			Level:     f.Severity,
+			Message:   sarifMessage{Text: f.Message},
+			Locations: []sarifLocation{location},
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(&sarifLog{Schema: SARIF_SCHEMA, Version: SARIF_VERSION, Runs: []sarifRun{run}})
+}
+
+func (l *Location) sarifLogicalLocation() sarifLogicalLocation {
+	switch {
+	case l.Column != "":
+		return sarifLogicalLocation{Name: l.Column, FullyQualifiedName: l.Table + "." + l.Column, Kind: "member"}
+	case l.Key != "":
+		return sarifLogicalLocation{Name: l.Key, FullyQualifiedName: l.Table + "." + l.Key, Kind: "member"}
+	}
+	return sarifLogicalLocation{Name: l.Table, FullyQualifiedName: l.Table, Kind: "type"}
+}
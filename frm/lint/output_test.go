@@ -0,0 +1,186 @@
+package lint
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sarifGolden is the SARIF log of the findings of legacyTable by the
+// engine and year2 rules, legacy-charset being disabled, and of a finding
+// of a rule the linter doesn't run
+const sarifGolden = `{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "go-frm-parser",
+          "informationUri": "https://github.com/zing22845/go-frm-parser",
+          "rules": [
+            {
+              "id": "engine",
+              "shortDescription": {
+                "text": "Table uses the MyISAM or MEMORY engine"
+              },
+              "defaultConfiguration": {
+                "level": "warning"
+              }
+            },
+            {
+              "id": "year2",
+              "shortDescription": {
+                "text": "Column uses YEAR(2)"
+              },
+              "defaultConfiguration": {
+                "level": "error"
+              }
+            }
+          ]
+        }
+      },
+      "results": [
+        {
+          "ruleId": "engine",
+          "ruleIndex": 0,
+          "level": "warning",
+          "message": {
+            "text": "table uses the MyISAM engine, which is not transactional nor crash safe"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "db/t1.frm"
+                }
+              },
+              "logicalLocations": [
+                {
+                  "name": "t1",
+                  "fullyQualifiedName": "t1",
+                  "kind": "type"
+                }
+              ]
+            }
+          ]
+        },
+        {
+          "ruleId": "year2",
+          "ruleIndex": 1,
+          "level": "error",
+          "message": {
+            "text": "column uses YEAR(2), which MySQL 5.7.5 removed, use YEAR(4)"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "db/t1.frm"
+                }
+              },
+              "logicalLocations": [
+                {
+                  "name": "y",
+                  "fullyQualifiedName": "t1.y",
+                  "kind": "member"
+                }
+              ]
+            }
+          ]
+        },
+        {
+          "ruleId": "nullable-unique-key",
+          "ruleIndex": -1,
+          "level": "note",
+          "message": {
+            "text": "key of a disabled rule"
+          },
+          "locations": [
+            {
+              "logicalLocations": [
+                {
+                  "name": "uk_y",
+                  "fullyQualifiedName": "t1.uk_y",
+                  "kind": "member"
+                }
+              ]
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}
+`
+
+func TestWriteSARIF(t *testing.T) {
+	l := NewLinter(Engine(), LegacyCharset(), Year2())
+	l.Disable(RULE_LEGACY_CHARSET)
+	findings := l.Lint("db/t1.frm", legacyTable(t))
+	// a finding of a rule the linter doesn't run has no rule index and
+	// one without a file no physical location
+	findings = append(findings, &Finding{
+		RuleID:   RULE_NULLABLE_UNIQUE_KEY,
+		Severity: SEVERITY_NOTE,
+		Message:  "key of a disabled rule",
+		Location: Location{Table: "t1", Key: "uk_y"},
+	})
+	var b strings.Builder
+	if err := l.Write(&b, FORMAT_SARIF, findings); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != sarifGolden {
+		t.Errorf("got\n%s\nwant\n%s", got, sarifGolden)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var b strings.Builder
+	if err := NewLinter().Write(&b, FORMAT_JSON, nil); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "[]\n" {
+		t.Errorf("no findings: got %q", b.String())
+	}
+
+	findings := NewLinter(FloatMoney()).Lint("db/t1.frm", legacyTable(t))
+	b.Reset()
+	if err := NewLinter().Write(&b, FORMAT_JSON, findings); err != nil {
+		t.Fatal(err)
+	}
+	var got []map[string]interface{}
+	if err := json.Unmarshal([]byte(b.String()), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]interface{}{{
+		"rule_id":  "float-money",
+		"severity": "warning",
+		"message":  "column looks like money but is FLOAT, use DECIMAL",
+		"location": map[string]interface{}{"file": "db/t1.frm", "table": "t1", "column": "price"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	l := NewLinter(Engine(), NullableUniqueKey())
+	findings := []*Finding{
+		{RuleID: RULE_ENGINE, Severity: SEVERITY_WARNING, Message: "engine", Location: Location{File: "db/t1.frm", Table: "t1"}},
+		{RuleID: RULE_NULLABLE_UNIQUE_KEY, Severity: SEVERITY_WARNING, Message: "key", Location: Location{File: "db/t1.frm", Table: "t1", Key: "uk"}},
+	}
+	var b strings.Builder
+	if err := l.Write(&b, "", findings); err != nil {
+		t.Fatal(err)
+	}
+	want := "db/t1.frm: `t1`: warning: engine [engine]\n" +
+		"db/t1.frm: `t1` key `uk`: warning: key [nullable-unique-key]\n"
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+	if err := l.Write(&b, "xml", findings); err == nil {
+		t.Error("wrote an unknown format")
+	}
+}
@@ -0,0 +1,285 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+const (
+	RULE_NO_PRIMARY_KEY      = "no-primary-key"
+	RULE_ENGINE              = "engine"
+	RULE_LEGACY_CHARSET      = "legacy-charset"
+	RULE_FLOAT_MONEY         = "float-money"
+	RULE_ZERO_DATE_DEFAULT   = "zero-date-default"
+	RULE_YEAR2               = "year2"
+	RULE_LEGACY_TEMPORAL     = "legacy-temporal"
+	RULE_NULLABLE_UNIQUE_KEY = "nullable-unique-key"
+	RULE_OVERSIZED_VARCHAR   = "oversized-varchar"
+
+	// DEFAULT_MAX_VARCHAR_LENGTH is the longest VARCHAR, in characters,
+	// the oversized-varchar rule of DefaultRules accepts
+	DEFAULT_MAX_VARCHAR_LENGTH = 1024
+)
+
+var (
+	// moneyNameRegex matches the words of column names holding money
+	moneyNameRegex = regexp.MustCompile(`(^|_)(price|amount|cost|balance|money|salary|wage|fee|tax|discount|payment|revenue|credit|debit|total|subtotal)s?(_|$)`)
+	// camelCaseRegex finds the word boundaries of camelCase names
+	camelCaseRegex = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+	// zeroDateRegex matches defaults with a zero year, month or day
+	zeroDateRegex = regexp.MustCompile(`^'(0000-\d\d-\d\d|\d{4}-00-\d\d|\d{4}-\d\d-00)`)
+)
+
+// DefaultRules returns the built-in rules
+func DefaultRules() []*Rule {
+	return []*Rule{
+		NoPrimaryKey(),
+		Engine(),
+		LegacyCharset(),
+		FloatMoney(),
+		ZeroDateDefault(),
+		Year2(),
+		LegacyTemporal(),
+		NullableUniqueKey(),
+		OversizedVarchar(DEFAULT_MAX_VARCHAR_LENGTH),
+	}
+}
+
+// NoPrimaryKey flags tables without a primary key
+func NoPrimaryKey() *Rule {
+	return &Rule{
+		ID:          RULE_NO_PRIMARY_KEY,
+		Description: "Table has no primary key",
+		Severity:    SEVERITY_WARNING,
+		Check: func(mt *table.MySQLTable) []*Finding {
+			pk := mt.PrimaryKey()
+			if pk == nil {
+				return []*Finding{{Message: "table has no primary key"}}
+			}
+			if pk.Name != "PRIMARY" {
+				return []*Finding{{
+					Severity: SEVERITY_NOTE,
+					Message:  fmt.Sprintf("table has no primary key, unique key `%s` is used instead", pk.Name),
+					Location: Location{Key: pk.Name},
+				}}
+			}
+			return nil
+		},
+	}
+}
+
+// Engine flags MyISAM and MEMORY tables, which are neither transactional
+// nor crash safe
+func Engine() *Rule {
+	return &Rule{
+		ID:          RULE_ENGINE,
+		Description: "Table uses the MyISAM or MEMORY engine",
+		Severity:    SEVERITY_WARNING,
+		Check: func(mt *table.MySQLTable) []*Finding {
+			engine := strings.ToUpper(mt.Options.Engine)
+			switch engine {
+			case "MYISAM", "MEMORY", "HEAP":
+				return []*Finding{{
+					Message: fmt.Sprintf("table uses the %s engine, which is not transactional nor crash safe", mt.Options.Engine),
+				}}
+			}
+			return nil
+		},
+	}
+}
+
+// LegacyCharset flags tables and columns in the utf8 (utf8mb3) and latin1
+// character sets
+func LegacyCharset() *Rule {
+	return &Rule{
+		ID:          RULE_LEGACY_CHARSET,
+		Description: "Table or column uses the utf8 (utf8mb3) or latin1 character set",
+		Severity:    SEVERITY_WARNING,
+		Check: func(mt *table.MySQLTable) (findings []*Finding) {
+			if mt.Collation != nil && isLegacyCharset(mt.Collation.CharsetName) {
+				findings = append(findings, &Finding{
+					Message: fmt.Sprintf("table default character set is %s, use utf8mb4", mt.Collation.CharsetName),
+				})
+			}
+			for _, c := range mt.Columns.Items {
+				if c.Collation == nil || !table.HasCharset(c.TypeCode) || c.Collation.CharsetName == "binary" || c.Collation == mt.Collation {
+					continue
+				}
+				if isLegacyCharset(c.Collation.CharsetName) {
+					findings = append(findings, &Finding{
+						Message:  fmt.Sprintf("column character set is %s, use utf8mb4", c.Collation.CharsetName),
+						Location: Location{Column: c.Name},
+					})
+				}
+			}
+			return findings
+		},
+	}
+}
+
+// FloatMoney flags FLOAT and DOUBLE columns whose name looks like they
+// hold money, which needs the exact DECIMAL type
+func FloatMoney() *Rule {
+	return &Rule{
+		ID:          RULE_FLOAT_MONEY,
+		Description: "Money-like column uses FLOAT or DOUBLE",
+		Severity:    SEVERITY_WARNING,
+		Check: func(mt *table.MySQLTable) (findings []*Finding) {
+			for _, c := range mt.Columns.Items {
+				if c.TypeCode != table.MT_FLOAT && c.TypeCode != table.MT_DOUBLE {
+					continue
+				}
+				name := strings.ToLower(camelCaseRegex.ReplaceAllString(c.Name, "${1}_${2}"))
+				if moneyNameRegex.MatchString(name) {
+					findings = append(findings, &Finding{
+						Message:  fmt.Sprintf("column looks like money but is %s, use DECIMAL", strings.ToUpper(typeName(c))),
+						Location: Location{Column: c.Name},
+					})
+				}
+			}
+			return findings
+		},
+	}
+}
+
+// ZeroDateDefault flags temporal columns defaulting to a zero date, which
+// NO_ZERO_DATE and NO_ZERO_IN_DATE reject
+func ZeroDateDefault() *Rule {
+	return &Rule{
+		ID:          RULE_ZERO_DATE_DEFAULT,
+		Description: "Temporal column defaults to a zero date",
+		Severity:    SEVERITY_ERROR,
+		Check: func(mt *table.MySQLTable) (findings []*Finding) {
+			for _, c := range mt.Columns.Items {
+				switch c.TypeCode {
+				case table.MT_DATE, table.MT_NEWDATE, table.MT_DATETIME, table.MT_DATETIME2,
+					table.MT_TIMESTAMP, table.MT_TIMESTAMP2:
+				default:
+					continue
+				}
+				if zeroDateRegex.MatchString(c.Default) {
+					findings = append(findings, &Finding{
+						Message:  fmt.Sprintf("column defaults to the zero date %s, rejected by NO_ZERO_DATE and NO_ZERO_IN_DATE", c.Default),
+						Location: Location{Column: c.Name},
+					})
+				}
+			}
+			return findings
+		},
+	}
+}
+
+// Year2 flags YEAR(2) columns, removed in MySQL 5.7.5
+func Year2() *Rule {
+	return &Rule{
+		ID:          RULE_YEAR2,
+		Description: "Column uses YEAR(2)",
+		Severity:    SEVERITY_ERROR,
+		Check: func(mt *table.MySQLTable) (findings []*Finding) {
+			for _, c := range mt.Columns.Items {
+				if c.TypeCode == table.MT_YEAR && c.Length == 2 {
+					findings = append(findings, &Finding{
+						Message:  "column uses YEAR(2), which MySQL 5.7.5 removed, use YEAR(4)",
+						Location: Location{Column: c.Name},
+					})
+				}
+			}
+			return findings
+		},
+	}
+}
+
+// LegacyTemporal flags TIME, DATETIME and TIMESTAMP columns in the storage
+// format of MySQL before 5.6.4, which an upgrade has to convert
+func LegacyTemporal() *Rule {
+	return &Rule{
+		ID:          RULE_LEGACY_TEMPORAL,
+		Description: "Temporal column uses the pre-5.6.4 storage format",
+		Severity:    SEVERITY_WARNING,
+		Check: func(mt *table.MySQLTable) (findings []*Finding) {
+			for _, c := range mt.Columns.Items {
+				switch c.TypeCode {
+				case table.MT_TIME, table.MT_DATETIME, table.MT_TIMESTAMP:
+					findings = append(findings, &Finding{
+						Message:  fmt.Sprintf("column uses the pre-5.6.4 %s storage format, rebuild the table to convert it", strings.ToUpper(typeName(c))),
+						Location: Location{Column: c.Name},
+					})
+				}
+			}
+			return findings
+		},
+	}
+}
+
+// NullableUniqueKey flags unique keys over nullable columns, which don't
+// prevent duplicate rows with NULLs
+func NullableUniqueKey() *Rule {
+	return &Rule{
+		ID:          RULE_NULLABLE_UNIQUE_KEY,
+		Description: "Unique key has nullable columns",
+		Severity:    SEVERITY_WARNING,
+		Check: func(mt *table.MySQLTable) (findings []*Finding) {
+			for _, k := range mt.Keys.Items {
+				if !k.IsUnique || k.Name == "PRIMARY" {
+					continue
+				}
+				var nullable []string
+				for _, part := range k.Parts {
+					if part.Column != nil && part.Column.Flags.HasFlag(table.FF_MAYBE_NULL) {
+						nullable = append(nullable, fmt.Sprintf("`%s`", part.Column.Name))
+					}
+				}
+				if len(nullable) != 0 {
+					findings = append(findings, &Finding{
+						Message:  fmt.Sprintf("unique key has nullable columns %s, rows with NULLs are not unique", strings.Join(nullable, ", ")),
+						Location: Location{Key: k.Name},
+					})
+				}
+			}
+			return findings
+		},
+	}
+}
+
+// OversizedVarchar flags VARCHAR columns longer than maxLength characters
+func OversizedVarchar(maxLength int) *Rule {
+	return &Rule{
+		ID:          RULE_OVERSIZED_VARCHAR,
+		Description: fmt.Sprintf("VARCHAR column is longer than %d characters", maxLength),
+		Severity:    SEVERITY_NOTE,
+		Check: func(mt *table.MySQLTable) (findings []*Finding) {
+			for _, c := range mt.Columns.Items {
+				if c.TypeCode != table.MT_VARCHAR && c.TypeCode != table.MT_VAR_STRING {
+					continue
+				}
+				length := int(c.Length)
+				if c.Collation != nil && c.Collation.Maxlen > 1 {
+					length /= c.Collation.Maxlen
+				}
+				if length > maxLength {
+					findings = append(findings, &Finding{
+						Message:  fmt.Sprintf("VARCHAR(%d) is longer than %d characters, use TEXT or a shorter length", length, maxLength),
+						Location: Location{Column: c.Name},
+					})
+				}
+			}
+			return findings
+		},
+	}
+}
+
+func isLegacyCharset(name string) bool {
+	return name == "utf8" || name == "utf8mb3" || name == "latin1"
+}
+
+// typeName is the name of the column type without its attributes
+func typeName(c *table.Column) string {
+	name, err := c.TypeCode.Name()
+	if err != nil {
+		return c.TypeName
+	}
+	return name
+}
@@ -0,0 +1,257 @@
+package lint
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+func collation(t *testing.T, name string) *table.Collation {
+	t.Helper()
+	c, err := table.GetCollationByName(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// newTable returns a table t1 of the engine and default collation
+func newTable(t *testing.T, engine, collationName string, columns ...*table.Column) *table.MySQLTable {
+	t.Helper()
+	mt := &table.MySQLTable{
+		Name:      "t1",
+		Collation: collation(t, collationName),
+		Options:   &table.Options{Engine: engine},
+		Columns:   &table.Columns{Items: columns},
+		Keys:      &table.Keys{},
+	}
+	for _, c := range columns {
+		if c.Collation == nil && table.HasCharset(c.TypeCode) {
+			c.Collation = mt.Collation
+		}
+	}
+	return mt
+}
+
+// addKey adds a key over the columns named
+func addKey(mt *table.MySQLTable, name string, unique bool, columns ...string) *table.MySQLTable {
+	k := &table.Key{Name: name, IsUnique: unique}
+	for _, name := range columns {
+		for _, c := range mt.Columns.Items {
+			if c.Name == name {
+				k.Parts = append(k.Parts, &table.KeyPart{Column: c, Length: c.Length})
+			}
+		}
+	}
+	mt.Keys.Items = append(mt.Keys.Items, k)
+	return mt
+}
+
+func intColumn(name string, flags table.FieldFlag) *table.Column {
+	return &table.Column{Name: name, TypeCode: table.MT_LONG, Length: 11, Flags: flags}
+}
+
+func TestRules(t *testing.T) {
+	for _, c := range []struct {
+		name  string
+		rule  *Rule
+		table func(t *testing.T) *table.MySQLTable
+		want  []string
+	}{
+		{
+			name: "no primary key",
+			rule: NoPrimaryKey(),
+			table: func(t *testing.T) *table.MySQLTable {
+				return newTable(t, "InnoDB", "utf8mb4_general_ci", intColumn("id", 0))
+			},
+			want: []string{"t1.frm: `t1`: warning: table has no primary key [no-primary-key]"},
+		},
+		{
+			name: "primary key",
+			rule: NoPrimaryKey(),
+			table: func(t *testing.T) *table.MySQLTable {
+				return addKey(newTable(t, "InnoDB", "utf8mb4_general_ci", intColumn("id", 0)), "PRIMARY", true, "id")
+			},
+		},
+		{
+			name: "unique key used as primary key",
+			rule: NoPrimaryKey(),
+			table: func(t *testing.T) *table.MySQLTable {
+				return addKey(newTable(t, "InnoDB", "utf8mb4_general_ci", intColumn("id", 0)), "uk_id", true, "id")
+			},
+			want: []string{"t1.frm: `t1` key `uk_id`: note: table has no primary key, unique key `uk_id` is used instead [no-primary-key]"},
+		},
+		{
+			name: "nullable unique key is no primary key",
+			rule: NoPrimaryKey(),
+			table: func(t *testing.T) *table.MySQLTable {
+				mt := newTable(t, "InnoDB", "utf8mb4_general_ci", intColumn("id", table.FF_MAYBE_NULL))
+				return addKey(mt, "uk_id", true, "id")
+			},
+			want: []string{"t1.frm: `t1`: warning: table has no primary key [no-primary-key]"},
+		},
+		{
+			name: "MyISAM",
+			rule: Engine(),
+			table: func(t *testing.T) *table.MySQLTable {
+				return newTable(t, "MyISAM", "utf8mb4_general_ci")
+			},
+			want: []string{"t1.frm: `t1`: warning: table uses the MyISAM engine, which is not transactional nor crash safe [engine]"},
+		},
+		{
+			name: "MEMORY",
+			rule: Engine(),
+			table: func(t *testing.T) *table.MySQLTable {
+				return newTable(t, "MEMORY", "utf8mb4_general_ci")
+			},
+			want: []string{"t1.frm: `t1`: warning: table uses the MEMORY engine, which is not transactional nor crash safe [engine]"},
+		},
+		{
+			name: "InnoDB",
+			rule: Engine(),
+			table: func(t *testing.T) *table.MySQLTable {
+				return newTable(t, "InnoDB", "utf8mb4_general_ci")
+			},
+		},
+		{
+			name: "legacy table charset",
+			rule: LegacyCharset(),
+			table: func(t *testing.T) *table.MySQLTable {
+				// columns of the table default are not flagged again
+				return newTable(t, "InnoDB", "latin1_swedish_ci",
+					&table.Column{Name: "name", TypeCode: table.MT_VARCHAR, Length: 20},
+					&table.Column{Name: "code", TypeCode: table.MT_VARCHAR, Length: 30, Collation: collation(t, "utf8_general_ci")},
+					&table.Column{Name: "hash", TypeCode: table.MT_STRING, Length: 20, Collation: collation(t, "binary")})
+			},
+			want: []string{
+				"t1.frm: `t1`: warning: table default character set is latin1, use utf8mb4 [legacy-charset]",
+				"t1.frm: `t1`.`code`: warning: column character set is utf8, use utf8mb4 [legacy-charset]",
+			},
+		},
+		{
+			name: "legacy column charset",
+			rule: LegacyCharset(),
+			table: func(t *testing.T) *table.MySQLTable {
+				return newTable(t, "InnoDB", "utf8mb4_general_ci",
+					&table.Column{Name: "name", TypeCode: table.MT_VARCHAR, Length: 80},
+					&table.Column{Name: "code", TypeCode: table.MT_STRING, Length: 10, Collation: collation(t, "latin1_bin")},
+					&table.Column{Name: "id", TypeCode: table.MT_LONG, Length: 11, Collation: collation(t, "latin1_bin")})
+			},
+			want: []string{"t1.frm: `t1`.`code`: warning: column character set is latin1, use utf8mb4 [legacy-charset]"},
+		},
+		{
+			name: "float money",
+			rule: FloatMoney(),
+			table: func(t *testing.T) *table.MySQLTable {
+				return newTable(t, "InnoDB", "utf8mb4_general_ci",
+					&table.Column{Name: "total_price", TypeCode: table.MT_DOUBLE},
+					&table.Column{Name: "unitCost", TypeCode: table.MT_FLOAT},
+					&table.Column{Name: "fees", TypeCode: table.MT_DOUBLE},
+					&table.Column{Name: "ratio", TypeCode: table.MT_DOUBLE},
+					&table.Column{Name: "taxonomy", TypeCode: table.MT_FLOAT},
+					&table.Column{Name: "amount", TypeCode: table.MT_NEWDECIMAL})
+			},
+			want: []string{
+				"t1.frm: `t1`.`total_price`: warning: column looks like money but is DOUBLE, use DECIMAL [float-money]",
+				"t1.frm: `t1`.`unitCost`: warning: column looks like money but is FLOAT, use DECIMAL [float-money]",
+				"t1.frm: `t1`.`fees`: warning: column looks like money but is DOUBLE, use DECIMAL [float-money]",
+			},
+		},
+		{
+			name: "zero date default",
+			rule: ZeroDateDefault(),
+			table: func(t *testing.T) *table.MySQLTable {
+				return newTable(t, "InnoDB", "utf8mb4_general_ci",
+					&table.Column{Name: "created", TypeCode: table.MT_DATETIME2, Default: "'0000-00-00 00:00:00'"},
+					&table.Column{Name: "shipped", TypeCode: table.MT_NEWDATE, Default: "'2020-00-01'"},
+					&table.Column{Name: "paid", TypeCode: table.MT_TIMESTAMP2, Default: "'2020-01-00 00:00:00'"},
+					&table.Column{Name: "updated", TypeCode: table.MT_TIMESTAMP2, Default: "CURRENT_TIMESTAMP"},
+					&table.Column{Name: "due", TypeCode: table.MT_NEWDATE, Default: "'2020-01-01'"},
+					&table.Column{Name: "label", TypeCode: table.MT_VARCHAR, Length: 40, Default: "'0000-00-00'"})
+			},
+			want: []string{
+				"t1.frm: `t1`.`created`: error: column defaults to the zero date '0000-00-00 00:00:00', rejected by NO_ZERO_DATE and NO_ZERO_IN_DATE [zero-date-default]",
+				"t1.frm: `t1`.`shipped`: error: column defaults to the zero date '2020-00-01', rejected by NO_ZERO_DATE and NO_ZERO_IN_DATE [zero-date-default]",
+				"t1.frm: `t1`.`paid`: error: column defaults to the zero date '2020-01-00 00:00:00', rejected by NO_ZERO_DATE and NO_ZERO_IN_DATE [zero-date-default]",
+			},
+		},
+		{
+			name: "YEAR(2)",
+			rule: Year2(),
+			table: func(t *testing.T) *table.MySQLTable {
+				return newTable(t, "InnoDB", "utf8mb4_general_ci",
+					&table.Column{Name: "y2", TypeCode: table.MT_YEAR, Length: 2},
+					&table.Column{Name: "y4", TypeCode: table.MT_YEAR, Length: 4})
+			},
+			want: []string{"t1.frm: `t1`.`y2`: error: column uses YEAR(2), which MySQL 5.7.5 removed, use YEAR(4) [year2]"},
+		},
+		{
+			name: "legacy temporal",
+			rule: LegacyTemporal(),
+			table: func(t *testing.T) *table.MySQLTable {
+				return newTable(t, "InnoDB", "utf8mb4_general_ci",
+					&table.Column{Name: "t", TypeCode: table.MT_TIME},
+					&table.Column{Name: "dt", TypeCode: table.MT_DATETIME},
+					&table.Column{Name: "ts", TypeCode: table.MT_TIMESTAMP},
+					&table.Column{Name: "t2", TypeCode: table.MT_TIME2},
+					&table.Column{Name: "dt2", TypeCode: table.MT_DATETIME2},
+					&table.Column{Name: "ts2", TypeCode: table.MT_TIMESTAMP2},
+					&table.Column{Name: "d", TypeCode: table.MT_NEWDATE})
+			},
+			want: []string{
+				"t1.frm: `t1`.`t`: warning: column uses the pre-5.6.4 TIME storage format, rebuild the table to convert it [legacy-temporal]",
+				"t1.frm: `t1`.`dt`: warning: column uses the pre-5.6.4 DATETIME storage format, rebuild the table to convert it [legacy-temporal]",
+				"t1.frm: `t1`.`ts`: warning: column uses the pre-5.6.4 TIMESTAMP storage format, rebuild the table to convert it [legacy-temporal]",
+			},
+		},
+		{
+			name: "nullable unique key",
+			rule: NullableUniqueKey(),
+			table: func(t *testing.T) *table.MySQLTable {
+				mt := newTable(t, "InnoDB", "utf8mb4_general_ci",
+					intColumn("id", 0), intColumn("a", table.FF_MAYBE_NULL), intColumn("b", table.FF_MAYBE_NULL), intColumn("c", 0))
+				addKey(mt, "PRIMARY", true, "id")
+				addKey(mt, "uk_ab", true, "a", "c", "b")
+				addKey(mt, "uk_c", true, "c")
+				return addKey(mt, "k_a", false, "a")
+			},
+			want: []string{"t1.frm: `t1` key `uk_ab`: warning: unique key has nullable columns `a`, `b`, rows with NULLs are not unique [nullable-unique-key]"},
+		},
+		{
+			name: "oversized VARCHAR",
+			rule: OversizedVarchar(DEFAULT_MAX_VARCHAR_LENGTH),
+			table: func(t *testing.T) *table.MySQLTable {
+				// the lengths are in bytes
+				return newTable(t, "InnoDB", "utf8mb4_general_ci",
+					&table.Column{Name: "note", TypeCode: table.MT_VARCHAR, Length: 4 * 2000},
+					&table.Column{Name: "title", TypeCode: table.MT_VARCHAR, Length: 4 * 1024},
+					&table.Column{Name: "code", TypeCode: table.MT_VARCHAR, Length: 1500, Collation: collation(t, "latin1_bin")},
+					&table.Column{Name: "body", TypeCode: table.MT_BLOB, Length: 0xffff})
+			},
+			want: []string{
+				"t1.frm: `t1`.`note`: note: VARCHAR(2000) is longer than 1024 characters, use TEXT or a shorter length [oversized-varchar]",
+				"t1.frm: `t1`.`code`: note: VARCHAR(1500) is longer than 1024 characters, use TEXT or a shorter length [oversized-varchar]",
+			},
+		},
+		{
+			name: "oversized VARCHAR of a lower maximum",
+			rule: OversizedVarchar(255),
+			table: func(t *testing.T) *table.MySQLTable {
+				return newTable(t, "InnoDB", "utf8mb4_general_ci",
+					&table.Column{Name: "title", TypeCode: table.MT_VARCHAR, Length: 4 * 256})
+			},
+			want: []string{"t1.frm: `t1`.`title`: note: VARCHAR(256) is longer than 255 characters, use TEXT or a shorter length [oversized-varchar]"},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			var got []string
+			for _, f := range NewLinter(c.rule).Lint("t1.frm", c.table(t)) {
+				got = append(got, f.String())
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
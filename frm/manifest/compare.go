@@ -0,0 +1,51 @@
+package manifest
+
+import (
+	"sort"
+)
+
+// Difference is a table or view whose fingerprint differs between two
+// manifests
+type Difference struct {
+	Schema string `json:"schema"`
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+	Status string `json:"status"`
+	// Old and New are the entries of the two manifests, nil when the
+	// object is missing from one of them
+	Old *Entry `json:"old,omitempty"`
+	New *Entry `json:"new,omitempty"`
+}
+
+// Compare returns the objects added, removed or changed from old to new
+func Compare(old, new *Manifest) []*Difference {
+	oldEntries := make(map[string]*Entry, len(old.Entries))
+	for _, e := range old.Entries {
+		oldEntries[e.Key()] = e
+	}
+	var diffs []*Difference
+	seen := make(map[string]bool, len(new.Entries))
+	for _, e := range new.Entries {
+		key := e.Key()
+		seen[key] = true
+		o, ok := oldEntries[key]
+		switch {
+		case !ok:
+			diffs = append(diffs, &Difference{Schema: e.Schema, Name: e.Name, Kind: e.Kind, Status: STATUS_ADDED, New: e})
+		case o.Fingerprint != e.Fingerprint || o.Kind != e.Kind:
+			diffs = append(diffs, &Difference{Schema: e.Schema, Name: e.Name, Kind: e.Kind, Status: STATUS_CHANGED, Old: o, New: e})
+		}
+	}
+	for _, e := range old.Entries {
+		if !seen[e.Key()] {
+			diffs = append(diffs, &Difference{Schema: e.Schema, Name: e.Name, Kind: e.Kind, Status: STATUS_REMOVED, Old: e})
+		}
+	}
+	sort.SliceStable(diffs, func(i, j int) bool {
+		if diffs[i].Schema != diffs[j].Schema {
+			return diffs[i].Schema < diffs[j].Schema
+		}
+		return diffs[i].Name < diffs[j].Name
+	})
+	return diffs
+}
@@ -0,0 +1,284 @@
+package manifest
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zing22845/go-frm-parser/frm"
+	"github.com/zing22845/go-frm-parser/frm/ddl"
+	"github.com/zing22845/go-frm-parser/frm/table"
+	"github.com/zing22845/go-frm-parser/frm/view"
+)
+
+const (
+	KIND_TABLE = "table"
+	KIND_VIEW  = "view"
+
+	STATUS_ADDED   = "added"
+	STATUS_REMOVED = "removed"
+	STATUS_CHANGED = "changed"
+)
+
+// Entry is the fingerprint of a table or view
+type Entry struct {
+	Schema      string `json:"schema"`
+	Name        string `json:"name"`
+	Kind        string `json:"kind"`
+	File        string `json:"file,omitempty"`
+	Fingerprint string `json:"sha256"`
+	// Canonical is the canonical form the fingerprint is computed from
+	Canonical string `json:"canonical,omitempty"`
+}
+
+// Key identifies the entry in a manifest
+func (e *Entry) Key() string {
+	return fmt.Sprintf("`%s`.`%s`", e.Schema, e.Name)
+}
+
+// Manifest lists the fingerprints of the tables and views of a datadir
+type Manifest struct {
+	Source  string   `json:"source"`
+	Entries []*Entry `json:"entries"`
+	// Errors are the files which could not be parsed
+	Errors []string `json:"errors,omitempty"`
+}
+
+// NewManifest returns an empty manifest of the source
+func NewManifest(source string) *Manifest {
+	return &Manifest{Source: source}
+}
+
+// Add adds the fingerprint of a parsed table or view of the schema
+func (m *Manifest) Add(schema, file string, s frm.MySQLSchema) error {
+	entry := &Entry{Schema: schema, Name: s.GetName(), File: file}
+	switch t := s.(type) {
+	case *table.MySQLTable:
+		entry.Kind = KIND_TABLE
+		entry.Canonical = t.Canonical()
+	case *view.MySQLView:
+		entry.Kind = KIND_VIEW
+		entry.Canonical = t.Canonical()
+	default:
+		return fmt.Errorf("%s: unsupported schema object %T", file, s)
+	}
+	entry.Fingerprint = fingerprint(entry.Canonical)
+	m.Entries = append(m.Entries, entry)
+	return nil
+}
+
+// Sort orders the entries by schema and name
+func (m *Manifest) Sort() {
+	sort.SliceStable(m.Entries, func(i, j int) bool {
+		a, b := m.Entries[i], m.Entries[j]
+		if a.Schema != b.Schema {
+			return a.Schema < b.Schema
+		}
+		return a.Name < b.Name
+	})
+}
+
+// Fingerprint is the SHA-256 of the sorted entry fingerprints, equal for
+// datadirs with the same schema
+func (m *Manifest) Fingerprint() string {
+	var b strings.Builder
+	for _, line := range m.lines() {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return fingerprint(b.String())
+}
+
+func (m *Manifest) lines() []string {
+	lines := make([]string, 0, len(m.Entries))
+	for _, e := range m.Entries {
+		lines = append(lines, fmt.Sprintf("%s  %s %s", e.Fingerprint, e.Kind, e.Key()))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// WriteText writes a line per entry, like sha256sum
+func (m *Manifest) WriteText(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, line := range m.lines() {
+		fmt.Fprintln(bw, line)
+	}
+	return bw.Flush()
+}
+
+// WriteJSON writes the manifest as JSON, without the canonical forms
+// unless withCanonical is set
+func (m *Manifest) WriteJSON(w io.Writer, withCanonical bool) error {
+	out := *m
+	if !withCanonical {
+		out.Entries = make([]*Entry, len(m.Entries))
+		for i, e := range m.Entries {
+			entry := *e
+			entry.Canonical = ""
+			out.Entries[i] = &entry
+		}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(&out)
+}
+
+// ReadJSON reads a manifest written by WriteJSON
+func ReadJSON(r io.Reader) (*Manifest, error) {
+	m := &Manifest{}
+	err := json.NewDecoder(r).Decode(m)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	return m, nil
+}
+
+// Build parses the .frm files of the schema directories of a datadir,
+// and the .sdi and .ibd files of MySQL 8.0 tables without one, into a
+// manifest. #sql files of interrupted DDL are skipped
+func Build(datadir string) (*Manifest, error) {
+	m := NewManifest(datadir)
+	d, err := frm.ReadDatadir(datadir)
+	if err != nil {
+		return nil, err
+	}
+	for _, sd := range d.Schemas {
+		err = m.addSchema(sd)
+		if err != nil {
+			return nil, err
+		}
+	}
+	m.Sort()
+	return m, nil
+}
+
+// SQLOptions are the options of BuildFromSQL
+type SQLOptions struct {
+	// Schema is the schema of the statements before any USE statement
+	Schema string
+	// SkipForeignKeys leaves the foreign keys out, the .frm files of
+	// MySQL 5.x don't record the InnoDB ones
+	SkipForeignKeys bool
+}
+
+// BuildFromSQL fingerprints the tables and views of the CREATE TABLE and
+// CREATE VIEW statements of sql, such as the output of SHOW CREATE or of
+// mysqldump --no-data --skip-triggers, into a manifest comparable with
+// the one of a datadir
+func BuildFromSQL(source, sql string, opts *SQLOptions) (*Manifest, error) {
+	if opts == nil {
+		opts = &SQLOptions{}
+	}
+	definitions, err := ddl.ParseDefinitions(opts.Schema, sql)
+	if err != nil {
+		return nil, err
+	}
+	m := NewManifest(source)
+	for _, d := range definitions {
+		var s frm.MySQLSchema = d.View
+		if d.Table != nil {
+			if opts.SkipForeignKeys {
+				d.Table.ForeignKeys = nil
+			}
+			s = d.Table
+		}
+		err = m.Add(d.Schema, source, s)
+		if err != nil {
+			return nil, err
+		}
+	}
+	m.Sort()
+	return m, nil
+}
+
+func (m *Manifest) addSchema(sd *frm.SchemaDir) error {
+	frms := make(map[string]bool)
+	for _, file := range sd.Files {
+		name := file.Name()
+		if strings.EqualFold(filepath.Ext(name), ".frm") {
+			frms[strings.TrimSuffix(name, filepath.Ext(name))] = true
+		}
+	}
+	for _, file := range sd.Files {
+		name := file.Name()
+		path := filepath.Join(sd.Path, name)
+		ext := strings.ToLower(filepath.Ext(name))
+		stem := strings.TrimSuffix(name, filepath.Ext(name))
+		var schemas []frm.MySQLSchema
+		var err error
+		switch {
+		case ext == ".frm" || ext == ".sdi" && !frms[sdiTableName(stem)]:
+			var s frm.MySQLSchema
+			s, err = frm.ParseFile(path)
+			schemas = []frm.MySQLSchema{s}
+		case ext == ".ibd" && !frms[partitionTableName(stem)]:
+			schemas, err = parseIBD(path)
+		}
+		if err != nil {
+			m.Errors = append(m.Errors, err.Error())
+			continue
+		}
+		for _, s := range schemas {
+			if m.has(sd.Schema, s.GetName()) {
+				// the partitions of a table share its definition
+				continue
+			}
+			err = m.Add(sd.Schema, path, s)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Manifest) has(schema, name string) bool {
+	for _, e := range m.Entries {
+		if e.Schema == schema && e.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func parseIBD(path string) ([]frm.MySQLSchema, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return frm.ParseIBD(path, file)
+}
+
+// sdiTableName strips the _<id> suffix of the name of an .sdi file
+func sdiTableName(stem string) string {
+	i := strings.LastIndex(stem, "_")
+	if i < 0 {
+		return stem
+	}
+	return stem[:i]
+}
+
+// partitionTableName strips the #P#<partition> suffix of the name of a
+// partition tablespace
+func partitionTableName(stem string) string {
+	for _, sep := range []string{"#P#", "#p#"} {
+		if i := strings.Index(stem, sep); i >= 0 {
+			return stem[:i]
+		}
+	}
+	return stem
+}
+
+func fingerprint(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
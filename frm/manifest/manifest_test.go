@@ -0,0 +1,65 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zing22845/go-frm-parser/frm"
+)
+
+func TestBuildFromSQL(t *testing.T) {
+	datadir := t.TempDir()
+	schemaDir := filepath.Join(datadir, "shop")
+	if err := os.Mkdir(schemaDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// the dump is what SHOW CREATE prints for the objects of the datadir
+	dump := []string{"USE `shop`;"}
+	for _, name := range []string{"table_simple", "table_key_using_btree", "view_md5_success"} {
+		data, err := os.ReadFile("../../test_frms/" + name + ".frm")
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(schemaDir, name+".frm")
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		s, err := frm.ParseFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dump = append(dump, s.String())
+	}
+	want, err := Build(datadir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := BuildFromSQL("dump.sql", strings.Join(dump, "\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(got.Entries))
+	}
+	if diffs := Compare(want, got); len(diffs) != 0 {
+		for _, d := range diffs {
+			t.Errorf("%s %s %s.%s", d.Status, d.Kind, d.Schema, d.Name)
+		}
+	}
+
+	fk := "CREATE TABLE `c` (`p` int, CONSTRAINT `c_p` FOREIGN KEY (`p`) REFERENCES `p` (`id`));"
+	with, err := BuildFromSQL("fk.sql", fk, &SQLOptions{Schema: "shop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	without, err := BuildFromSQL("fk.sql", fk, &SQLOptions{Schema: "shop", SkipForeignKeys: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if with.Entries[0].Schema != "shop" || with.Fingerprint() == without.Fingerprint() ||
+		strings.Contains(without.Entries[0].Canonical, "FOREIGN KEY") {
+		t.Errorf("foreign keys not skipped:\n%s", without.Entries[0].Canonical)
+	}
+}
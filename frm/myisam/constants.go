@@ -0,0 +1,61 @@
+package myisam
+
+// myisam_file_magic, the first bytes of every .MYI file
+var MYISAM_FILE_MAGIC = []byte{0xfe, 0xfe, 0x07, 0x01}
+
+const (
+	// size of MI_STATE_INFO.header
+	MI_STATE_HEADER_SIZE = 24
+	// size of the fixed part of the state, saved in header.state_info_length
+	MI_STATE_INFO_SIZE = MI_STATE_HEADER_SIZE + 14*8 + 7*4 + 2*2 + 8
+	// size of MI_BASE_INFO
+	MI_BASE_INFO_SIZE = 5*8 + 8*4 + 4 + 4*2 + 16
+)
+
+// state header offsets
+const (
+	MI_HEADER_OPTIONS           = 4
+	MI_HEADER_LENGTH            = 6
+	MI_HEADER_STATE_INFO_LENGTH = 8
+	MI_HEADER_BASE_INFO_LENGTH  = 10
+	MI_HEADER_BASE_POS          = 12
+	MI_HEADER_KEY_PARTS         = 14
+	MI_HEADER_UNIQUE_KEY_PARTS  = 16
+	MI_HEADER_KEYS              = 18
+	MI_HEADER_UNIQUES           = 19
+	MI_HEADER_LANGUAGE          = 20
+	MI_HEADER_MAX_BLOCK_SIZE    = 21
+	MI_HEADER_FULLTEXT_KEYS     = 22
+)
+
+// STATE_* flags of state.changed
+const (
+	STATE_CHANGED            = 1
+	STATE_CRASHED            = 2
+	STATE_CRASHED_ON_REPAIR  = 4
+	STATE_NOT_ANALYZED       = 8
+	STATE_NOT_OPTIMIZED_KEYS = 16
+	STATE_NOT_SORTED_PAGES   = 32
+)
+
+// myisam_pack_file_magic, the first bytes of a .MYD compressed by myisampack
+var MYISAM_PACK_FILE_MAGIC = []byte{0xfe, 0xfe, 0x08, 0x02}
+
+// en_fieldtype, how a column is stored in a dynamic record
+type FieldType int
+
+const (
+	FIELD_NORMAL        FieldType = 0
+	FIELD_SKIP_ENDSPACE FieldType = 1
+	FIELD_SKIP_PRESPACE FieldType = 2
+	FIELD_SKIP_ZERO     FieldType = 3
+	FIELD_BLOB          FieldType = 4
+	FIELD_VARCHAR       FieldType = 8
+)
+
+const (
+	// MI_MIN_BLOCK_LENGTH is the smallest block of a dynamic record file
+	MI_MIN_BLOCK_LENGTH = 20
+	// MI_BLOCK_HEADER_MAX_LENGTH is the longest block header
+	MI_BLOCK_HEADER_MAX_LENGTH = 20
+)
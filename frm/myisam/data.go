@@ -0,0 +1,479 @@
+package myisam
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+	"github.com/zing22845/go-frm-parser/frm/utils"
+)
+
+// columnDef is how a part of the record is stored in a dynamic record,
+// MI_COLUMNDEF as built by table2myisam in ha_myisam.cc
+type columnDef struct {
+	Type   FieldType
+	Length uint32
+	Field  *table.FieldLayout
+}
+
+// DataReader reads the rows of a MyISAM .MYD file in the fixed or the
+// dynamic row format, deleted rows are skipped
+type DataReader struct {
+	Table   *table.MySQLTable
+	Decoder *table.RecordDecoder
+	// RecordLength is the size of a fixed length record in the file,
+	// the record length of the .frm unless set from the .MYI base info
+	RecordLength uint32
+	reader       io.ReaderAt
+	size         int64
+	dynamic      bool
+	columns      []*columnDef
+	packBits     uint32
+	pos          int64
+	blobs        map[*table.FieldLayout][]byte
+}
+
+// NewDataReader returns a reader of the rows stored in r, the .MYD file
+// of size bytes of the table mt
+func NewDataReader(mt *table.MySQLTable, r io.ReaderAt, size int64) (*DataReader, error) {
+	if mt.Options.HandlerOptions.HasOption(table.HO_COMPRESS_RECORD) {
+		return nil, fmt.Errorf("table %s is compressed by myisampack, which is not supported", mt.Name)
+	}
+	magic := make([]byte, len(MYISAM_PACK_FILE_MAGIC))
+	if size >= int64(len(magic)) {
+		_, err := r.ReadAt(magic, 0)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(magic, MYISAM_PACK_FILE_MAGIC) {
+			return nil, fmt.Errorf("table %s is compressed by myisampack, which is not supported", mt.Name)
+		}
+	}
+	decoder, err := table.NewRecordDecoder(mt)
+	if err != nil {
+		return nil, err
+	}
+	dr := &DataReader{
+		Table:        mt,
+		Decoder:      decoder,
+		RecordLength: decoder.Layout.Length,
+		reader:       r,
+		size:         size,
+		dynamic:      mt.Options.HandlerOptions.HasOption(table.HO_PACK_RECORD),
+		blobs:        make(map[*table.FieldLayout][]byte),
+	}
+	decoder.BlobData = dr.blobData
+	dr.buildColumnDefs()
+	return dr, nil
+}
+
+// buildColumnDefs follows table2myisam: the fields in record order, the
+// gaps between them (the null bytes) as FIELD_NORMAL
+func (dr *DataReader) buildColumnDefs() {
+	layout := dr.Decoder.Layout
+	fields := make([]*table.FieldLayout, 0, len(layout.Fields))
+	for _, f := range layout.Fields {
+		if f.PackLength != 0 {
+			fields = append(fields, f)
+		}
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].Offset < fields[j].Offset
+	})
+	var pos uint32
+	for _, f := range fields {
+		if f.Offset > pos {
+			dr.columns = append(dr.columns, &columnDef{Type: FIELD_NORMAL, Length: f.Offset - pos})
+		}
+		def := &columnDef{Type: fieldType(f, dr.dynamic), Length: f.PackLength, Field: f}
+		if def.Type != FIELD_NORMAL && def.Type != FIELD_VARCHAR {
+			dr.packBits++
+		}
+		dr.columns = append(dr.columns, def)
+		pos = f.Offset + f.PackLength
+	}
+	if layout.Length > pos {
+		dr.columns = append(dr.columns, &columnDef{Type: FIELD_NORMAL, Length: layout.Length - pos})
+	}
+	dr.packBits = (dr.packBits + 7) / 8
+}
+
+func fieldType(f *table.FieldLayout, packRecord bool) FieldType {
+	c := f.Column
+	switch c.TypeCode {
+	case table.MT_TINY_BLOB, table.MT_MEDIUM_BLOB, table.MT_LONG_BLOB,
+		table.MT_BLOB, table.MT_JSON, table.MT_GEOMETRY:
+		return FIELD_BLOB
+	case table.MT_VARCHAR:
+		return FIELD_VARCHAR
+	}
+	if !packRecord {
+		return FIELD_NORMAL
+	}
+	switch c.TypeCode {
+	case table.MT_STRING, table.MT_VAR_STRING, table.MT_DECIMAL, table.MT_ENUM, table.MT_SET:
+		// Field::zero_pack() is false for these
+	default:
+		return FIELD_SKIP_ZERO
+	}
+	if f.PackLength <= 3 || c.Flags.HasFlag(table.FF_ZEROFILL) {
+		return FIELD_NORMAL
+	}
+	if c.TypeCode == table.MT_STRING || c.TypeCode == table.MT_VAR_STRING {
+		return FIELD_SKIP_ENDSPACE
+	}
+	return FIELD_SKIP_PRESPACE
+}
+
+func (dr *DataReader) blobData(f *table.FieldLayout, ref *table.BlobRef) ([]byte, error) {
+	data := dr.blobs[f]
+	if uint32(len(data)) != ref.Length {
+		return nil, fmt.Errorf("blob length %d, %d bytes found", ref.Length, len(data))
+	}
+	return data, nil
+}
+
+// Next returns the values of the next row, io.EOF after the last one
+func (dr *DataReader) Next() ([]interface{}, error) {
+	record, err := dr.NextRecord()
+	if err != nil {
+		return nil, err
+	}
+	return dr.Decoder.Decode(record)
+}
+
+// NextRecord returns the next row as a record in the server's row format
+func (dr *DataReader) NextRecord() (record []byte, err error) {
+	for dr.pos < dr.size {
+		if dr.dynamic {
+			record, err = dr.nextDynamic()
+		} else {
+			record, err = dr.nextStatic()
+		}
+		if err != nil || record != nil {
+			return record, err
+		}
+	}
+	return nil, io.EOF
+}
+
+// nextStatic reads a fixed length record, a deleted one starts with 0
+func (dr *DataReader) nextStatic() ([]byte, error) {
+	if dr.RecordLength == 0 {
+		return nil, fmt.Errorf("record length is 0")
+	}
+	record := make([]byte, dr.RecordLength)
+	_, err := dr.reader.ReadAt(record, dr.pos)
+	if err != nil {
+		return nil, fmt.Errorf("read record at %d failed: %w", dr.pos, err)
+	}
+	dr.pos += int64(dr.RecordLength)
+	if record[0] == 0 {
+		return nil, nil
+	}
+	return record, nil
+}
+
+// blockInfo is the header of a block of a dynamic record file,
+// MI_BLOCK_INFO as read by _mi_get_block_info
+type blockInfo struct {
+	recLength   uint32
+	dataLength  uint32
+	blockLength uint32
+	dataPos     int64
+	nextPos     int64
+	first       bool
+	last        bool
+	deleted     bool
+}
+
+func (dr *DataReader) readBlock(pos int64) (*blockInfo, error) {
+	header := make([]byte, MI_BLOCK_HEADER_MAX_LENGTH)
+	n, err := dr.reader.ReadAt(header, pos)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if n < 4 {
+		return nil, fmt.Errorf("truncated block header at %d", pos)
+	}
+	header = header[:n]
+	b := &blockInfo{}
+	var headerLength int
+	switch header[0] {
+	case 0:
+		if n < MI_BLOCK_HEADER_MAX_LENGTH {
+			return nil, fmt.Errorf("truncated deleted block at %d", pos)
+		}
+		b.deleted = true
+		b.blockLength = utils.Uint24BE(header[1:])
+		if b.blockLength < MI_MIN_BLOCK_LENGTH {
+			return nil, fmt.Errorf("invalid deleted block length %d at %d", b.blockLength, pos)
+		}
+		b.dataPos = pos
+		return b, nil
+	case 1:
+		b.recLength = uint32(binary.BigEndian.Uint16(header[1:]))
+		b.dataLength, b.blockLength = b.recLength, b.recLength
+		b.first, b.last, headerLength = true, true, 3
+	case 2:
+		b.recLength = utils.Uint24BE(header[1:])
+		b.dataLength, b.blockLength = b.recLength, b.recLength
+		b.first, b.last, headerLength = true, true, 4
+	case 3:
+		b.recLength = uint32(binary.BigEndian.Uint16(header[1:]))
+		b.dataLength = b.recLength
+		b.blockLength = b.recLength + uint32(header[3])
+		b.first, b.last, headerLength = true, true, 4
+	case 4:
+		b.recLength = utils.Uint24BE(header[1:])
+		b.dataLength = b.recLength
+		b.blockLength = b.recLength + uint32(header[4])
+		b.first, b.last, headerLength = true, true, 5
+	case 5:
+		b.recLength = uint32(binary.BigEndian.Uint16(header[1:]))
+		b.dataLength = uint32(binary.BigEndian.Uint16(header[3:]))
+		b.blockLength = b.dataLength
+		b.nextPos = int64(binary.BigEndian.Uint64(header[5:]))
+		b.first, headerLength = true, 13
+	case 6:
+		b.recLength = utils.Uint24BE(header[1:])
+		b.dataLength = utils.Uint24BE(header[4:])
+		b.blockLength = b.dataLength
+		b.nextPos = int64(binary.BigEndian.Uint64(header[7:]))
+		b.first, headerLength = true, 15
+	case 7:
+		b.dataLength = uint32(binary.BigEndian.Uint16(header[1:]))
+		b.blockLength = b.dataLength
+		b.last, headerLength = true, 3
+	case 8:
+		b.dataLength = utils.Uint24BE(header[1:])
+		b.blockLength = b.dataLength
+		b.last, headerLength = true, 4
+	case 9:
+		b.dataLength = uint32(binary.BigEndian.Uint16(header[1:]))
+		b.blockLength = b.dataLength + uint32(header[3])
+		b.last, headerLength = true, 4
+	case 10:
+		b.dataLength = utils.Uint24BE(header[1:])
+		b.blockLength = b.dataLength + uint32(header[4])
+		b.last, headerLength = true, 5
+	case 11:
+		b.dataLength = uint32(binary.BigEndian.Uint16(header[1:]))
+		b.blockLength = b.dataLength
+		b.nextPos = int64(binary.BigEndian.Uint64(header[3:]))
+		headerLength = 11
+	case 12:
+		b.dataLength = utils.Uint24BE(header[1:])
+		b.blockLength = b.dataLength
+		b.nextPos = int64(binary.BigEndian.Uint64(header[4:]))
+		headerLength = 12
+	case 13:
+		b.recLength = binary.BigEndian.Uint32(header[1:])
+		b.dataLength = utils.Uint24BE(header[5:])
+		b.blockLength = b.dataLength
+		b.nextPos = int64(binary.BigEndian.Uint64(header[8:]))
+		b.first, headerLength = true, 16
+	default:
+		return nil, fmt.Errorf("invalid block type %d at %d", header[0], pos)
+	}
+	if n < headerLength {
+		return nil, fmt.Errorf("truncated block header at %d", pos)
+	}
+	b.dataPos = pos + int64(headerLength)
+	return b, nil
+}
+
+// nextDynamic reads the record starting in the block at the current
+// position, following the chain of its blocks, continuation and deleted
+// blocks are skipped
+func (dr *DataReader) nextDynamic() ([]byte, error) {
+	b, err := dr.readBlock(dr.pos)
+	if err != nil {
+		return nil, err
+	}
+	if b.deleted {
+		dr.pos += int64(b.blockLength)
+		return nil, nil
+	}
+	dr.pos = b.dataPos + int64(b.blockLength)
+	if !b.first {
+		return nil, nil
+	}
+	recLength := b.recLength
+	packed := make([]byte, 0, recLength)
+	for {
+		if uint32(len(packed))+b.dataLength > recLength {
+			return nil, fmt.Errorf("record at %d is longer than %d", b.dataPos, recLength)
+		}
+		data := make([]byte, b.dataLength)
+		_, err = dr.reader.ReadAt(data, b.dataPos)
+		if err != nil {
+			return nil, fmt.Errorf("read block at %d failed: %w", b.dataPos, err)
+		}
+		packed = append(packed, data...)
+		if uint32(len(packed)) == recLength {
+			break
+		}
+		if b.last || b.nextPos <= 0 || b.nextPos >= dr.size {
+			return nil, fmt.Errorf("broken block chain of the record at %d", b.dataPos)
+		}
+		b, err = dr.readBlock(b.nextPos)
+		if err != nil {
+			return nil, err
+		}
+		if b.deleted || b.first {
+			return nil, fmt.Errorf("broken block chain of the record at %d", b.dataPos)
+		}
+	}
+	return dr.unpack(packed)
+}
+
+// unpack follows _mi_rec_unpack, blob data is kept aside for blobData
+func (dr *DataReader) unpack(packed []byte) ([]byte, error) {
+	if uint32(len(packed)) < dr.packBits {
+		return nil, fmt.Errorf("packed record too short")
+	}
+	record := make([]byte, dr.Decoder.Layout.Length)
+	for f := range dr.blobs {
+		delete(dr.blobs, f)
+	}
+	flags := packed[:dr.packBits]
+	from := packed[dr.packBits:]
+	var to uint32
+	bit := 0
+	errShort := fmt.Errorf("packed record is corrupted")
+	for _, col := range dr.columns {
+		dst := record[to : to+col.Length]
+		to += col.Length
+		switch col.Type {
+		case FIELD_NORMAL:
+			if uint32(len(from)) < col.Length {
+				return nil, errShort
+			}
+			copy(dst, from)
+			from = from[col.Length:]
+			continue
+		case FIELD_VARCHAR:
+			var length uint32
+			if col.Length-1 < 256 {
+				if len(from) < 1 {
+					return nil, errShort
+				}
+				length = uint32(from[0])
+				dst[0] = from[0]
+				from = from[1:]
+				if uint32(len(from)) < length || length > col.Length-1 {
+					return nil, errShort
+				}
+				copy(dst[1:], from[:length])
+			} else {
+				if len(from) < 1 {
+					return nil, errShort
+				}
+				if from[0] != 0xff {
+					length = uint32(from[0])
+					from = from[1:]
+				} else {
+					if len(from) < 3 {
+						return nil, errShort
+					}
+					length = uint32(binary.BigEndian.Uint16(from[1:]))
+					from = from[3:]
+				}
+				if uint32(len(from)) < length || length > col.Length-2 {
+					return nil, errShort
+				}
+				binary.LittleEndian.PutUint16(dst, uint16(length))
+				copy(dst[2:], from[:length])
+			}
+			from = from[length:]
+			continue
+		}
+		isSet := flags[bit/8]&(1<<(bit%8)) != 0
+		bit++
+		switch {
+		case isSet && (col.Type == FIELD_BLOB || col.Type == FIELD_SKIP_ZERO):
+			// all zeros, as the record already is
+		case isSet:
+			// FIELD_SKIP_ENDSPACE or FIELD_SKIP_PRESPACE
+			if len(from) < 1 {
+				return nil, errShort
+			}
+			length := uint32(from[0])
+			if col.Length > 255 && from[0]&0x80 != 0 {
+				if len(from) < 2 {
+					return nil, errShort
+				}
+				length = uint32(from[0]&0x7f) + uint32(from[1])<<7
+				from = from[2:]
+			} else {
+				from = from[1:]
+			}
+			if length >= col.Length || uint32(len(from)) < length {
+				return nil, errShort
+			}
+			if col.Type == FIELD_SKIP_ENDSPACE {
+				copy(dst, from[:length])
+				copy(dst[length:], bytes.Repeat([]byte{' '}, int(col.Length-length)))
+			} else {
+				copy(dst, bytes.Repeat([]byte{' '}, int(col.Length-length)))
+				copy(dst[col.Length-length:], from[:length])
+			}
+			from = from[length:]
+		case col.Type == FIELD_BLOB:
+			sizeLength := col.Length - table.PORTABLE_SIZEOF_CHAR_PTR
+			if uint32(len(from)) < sizeLength {
+				return nil, errShort
+			}
+			copy(dst, from[:sizeLength])
+			var length uint32
+			for i := int(sizeLength) - 1; i >= 0; i-- {
+				length = length<<8 | uint32(from[i])
+			}
+			from = from[sizeLength:]
+			if uint32(len(from)) < length {
+				return nil, errShort
+			}
+			dr.blobs[col.Field] = from[:length]
+			from = from[length:]
+		default:
+			if uint32(len(from)) < col.Length {
+				return nil, errShort
+			}
+			copy(dst, from[:col.Length])
+			from = from[col.Length:]
+		}
+	}
+	return record, nil
+}
+
+// ReadDataFile reads all the rows of the .MYD file at path
+func ReadDataFile(mt *table.MySQLTable, path string) (rows [][]interface{}, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	fi, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	dr, err := NewDataReader(mt, file, fi.Size())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for {
+		row, err := dr.Next()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		rows = append(rows, row)
+	}
+}
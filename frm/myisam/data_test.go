@@ -0,0 +1,141 @@
+package myisam
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+// newTable returns a MyISAM table of columns id INT, name CHAR(10),
+// v VARCHAR(20) and the last column, in the latin1 charset
+func newTable(t *testing.T, options table.HandlerOption, last *table.Column) *table.MySQLTable {
+	// latin1_swedish_ci
+	latin1, err := table.GetCollationByID(8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.Collation == nil {
+		last.Collation = latin1
+	}
+	return &table.MySQLTable{
+		Name:    "t1",
+		Options: &table.Options{HandlerOptions: options},
+		Columns: &table.Columns{Items: []*table.Column{
+			{Name: "id", TypeCode: table.MT_LONG, Length: 11, Flags: table.FF_DECIMAL},
+			{Name: "name", TypeCode: table.MT_STRING, Length: 10, Collation: latin1},
+			{Name: "v", TypeCode: table.MT_VARCHAR, Length: 20, Collation: latin1},
+			last,
+		}},
+	}
+}
+
+func TestReadDataFileStatic(t *testing.T) {
+	mt := newTable(t, 0, &table.Column{Name: "n", TypeCode: table.MT_LONG, Length: 11,
+		Flags: table.FF_DECIMAL | table.FF_MAYBE_NULL})
+	// the records start with the null byte, bit 0 is set in the live
+	// ones, then id at 1, name at 5, v at 15 and n at 36
+	data := bytes.Join([][]byte{
+		{0x01, 1, 0, 0, 0}, []byte("alice     "), append([]byte{3}, "abc"...), make([]byte, 17),
+		{0xf9, 0xff, 0xff, 0xff},
+		// deleted
+		make([]byte, 40),
+		{0x03, 2, 0, 0, 0}, []byte("bob       "), make([]byte, 21), make([]byte, 4),
+	}, nil)
+	path := filepath.Join(t.TempDir(), "t1.MYD")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := ReadDataFile(mt, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]interface{}{
+		{int64(1), "alice", "abc", int64(-7)},
+		{int64(2), "bob", "", nil},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("got %#v, want %#v", rows, want)
+	}
+
+	if _, err := ReadDataFile(mt, filepath.Join(t.TempDir(), "t2.MYD")); err == nil {
+		t.Error("no error for a missing file")
+	}
+	if err := os.WriteFile(path, data[:60], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadDataFile(mt, path); err == nil {
+		t.Error("no error for a truncated record")
+	}
+}
+
+func TestDataReaderDynamic(t *testing.T) {
+	mt := newTable(t, table.HO_PACK_RECORD, &table.Column{Name: "note", TypeCode: table.MT_BLOB,
+		Flags: table.FF_MAYBE_NULL})
+	mt.Columns.Items = append(mt.Columns.Items[:3], &table.Column{Name: "amount", TypeCode: table.MT_LONG,
+		Length: 11, Flags: table.FF_DECIMAL}, mt.Columns.Items[3])
+	// the packed records, after the bits of the fields stored packed
+	// (id, name, amount and note) come the null byte and the fields
+	data := bytes.Join([][]byte{
+		// a record in one block: name without its trailing spaces and
+		// amount 0 left out
+		{1, 0, 22},
+		{0x06, 0x00, 1, 0, 0, 0, 5}, []byte("alice"), {2}, []byte("hi"), {5, 0}, []byte("hello"),
+		// a deleted block
+		{0, 0, 0, 20}, bytes.Repeat([]byte{0xff}, 16),
+		// the first block of a record continued in the block at 93
+		{5, 0, 15, 0, 8, 0, 0, 0, 0, 0, 0, 0, 93},
+		{0x0a, 0x01, 2, 0, 0, 0, 3, 'b'},
+		// a record with an empty blob
+		{1, 0, 24},
+		{0x08, 0x00, 3, 0, 0, 0}, []byte("carol_long"), {3}, []byte("xyz"), {5, 0, 0, 0},
+		// the last block of the record at 45
+		{7, 0, 7},
+		[]byte("ob"), {0, 0x2c, 1, 0, 0},
+	}, nil)
+	dr, err := NewDataReader(mt, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows [][]interface{}
+	for {
+		row, err := dr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, row)
+	}
+	want := [][]interface{}{
+		{int64(1), "alice", "hi", int64(0), "hello"},
+		{int64(2), "bob", "", int64(300), nil},
+		{int64(3), "carol_long", "xyz", int64(5), ""},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("got %#v, want %#v", rows, want)
+	}
+
+	// the continuation block points past the end of the file
+	broken := append([]byte{}, data[:66]...)
+	dr, err = NewDataReader(mt, bytes.NewReader(broken), int64(len(broken)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dr.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dr.Next(); err == nil {
+		t.Error("no error for a broken block chain")
+	}
+
+	packed := append(append([]byte{}, MYISAM_PACK_FILE_MAGIC...), make([]byte, 16)...)
+	if _, err := NewDataReader(mt, bytes.NewReader(packed), int64(len(packed))); err == nil {
+		t.Error("no error for a file compressed by myisampack")
+	}
+}
@@ -0,0 +1,150 @@
+package myisam
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+// State is the state header of a .MYI file, MI_STATE_INFO in mi_open.c,
+// MyISAM keeps there what the .frm doesn't record, like the row count
+// and the AUTO_INCREMENT counter
+type State struct {
+	Options        table.HandlerOption
+	Keys           uint8
+	KeyParts       uint16
+	Uniques        uint8
+	UniqueKeyParts uint16
+	FulltextKeys   uint8
+	OpenCount      uint16
+	Changed        uint8
+	Records        uint64
+	Deleted        uint64
+	Split          uint64
+	KeyFileLength  uint64
+	DataFileLength uint64
+	// deleted bytes in the data file and in the key file
+	Empty    uint64
+	KeyEmpty uint64
+	// AutoIncrement is the last used value, the next one is AutoIncrement+1
+	AutoIncrement uint64
+	Checksum      uint64
+	KeyRoots      []uint64
+	KeyMap        uint64
+	CreateTime    time.Time
+	RecoverTime   time.Time
+	CheckTime     time.Time
+	// RecPerKeyPart is the average number of records per value
+	// of every key part, 0 if the table has not been analyzed
+	RecPerKeyPart []uint32
+}
+
+// ReadState reads the state header at the beginning of a .MYI file
+func ReadState(r io.Reader) (s *State, err error) {
+	header := make([]byte, MI_STATE_HEADER_SIZE)
+	_, err = io.ReadFull(r, header)
+	if err != nil {
+		return nil, fmt.Errorf("read MyISAM header failed: %w", err)
+	}
+	if !bytes.Equal(header[:4], MYISAM_FILE_MAGIC) {
+		return nil, fmt.Errorf("invalid MyISAM index file magic %x", header[:4])
+	}
+	s = &State{
+		Options:        table.HandlerOption(binary.BigEndian.Uint16(header[MI_HEADER_OPTIONS:])),
+		KeyParts:       binary.BigEndian.Uint16(header[MI_HEADER_KEY_PARTS:]),
+		UniqueKeyParts: binary.BigEndian.Uint16(header[MI_HEADER_UNIQUE_KEY_PARTS:]),
+		Keys:           header[MI_HEADER_KEYS],
+		Uniques:        header[MI_HEADER_UNIQUES],
+		FulltextKeys:   header[MI_HEADER_FULLTEXT_KEYS],
+	}
+	stateInfoLength := int(binary.BigEndian.Uint16(header[MI_HEADER_STATE_INFO_LENGTH:]))
+	if stateInfoLength < MI_STATE_INFO_SIZE {
+		return nil, fmt.Errorf("MyISAM state info length %d is less than %d",
+			stateInfoLength, MI_STATE_INFO_SIZE)
+	}
+	keyBlocks := int(header[MI_HEADER_MAX_BLOCK_SIZE])
+	length := stateInfoLength - MI_STATE_HEADER_SIZE +
+		int(s.Keys)*8 + keyBlocks*8 + int(s.KeyParts)*4
+	data := make([]byte, length)
+	_, err = io.ReadFull(r, data)
+	if err != nil {
+		return nil, fmt.Errorf("read MyISAM state failed: %w", err)
+	}
+	s.decode(data, stateInfoLength-MI_STATE_INFO_SIZE, keyBlocks)
+	return s, nil
+}
+
+// decode follows mi_state_info_read
+func (s *State) decode(data []byte, stateDiffLength, keyBlocks int) {
+	pos := 0
+	uint64At := func() uint64 {
+		v := binary.BigEndian.Uint64(data[pos:])
+		pos += 8
+		return v
+	}
+	uint32At := func() uint32 {
+		v := binary.BigEndian.Uint32(data[pos:])
+		pos += 4
+		return v
+	}
+	timeAt := func() time.Time {
+		v := uint64At()
+		if v == 0 {
+			return time.Time{}
+		}
+		return time.Unix(int64(v), 0)
+	}
+	s.OpenCount = binary.BigEndian.Uint16(data[pos:])
+	pos += 2
+	s.Changed = data[pos]
+	// changed and sortkey
+	pos += 2
+	s.Records = uint64At()
+	s.Deleted = uint64At()
+	s.Split = uint64At()
+	// dellink
+	pos += 8
+	s.KeyFileLength = uint64At()
+	s.DataFileLength = uint64At()
+	s.Empty = uint64At()
+	s.KeyEmpty = uint64At()
+	s.AutoIncrement = uint64At()
+	s.Checksum = uint64At()
+	// process, unique, status and update_count
+	pos += 4 * 4
+	pos += stateDiffLength
+	s.KeyRoots = make([]uint64, s.Keys)
+	for i := range s.KeyRoots {
+		s.KeyRoots[i] = uint64At()
+	}
+	// key_del, sec_index_changed, sec_index_used and version
+	pos += keyBlocks*8 + 3*4
+	s.KeyMap = uint64At()
+	s.CreateTime = timeAt()
+	s.RecoverTime = timeAt()
+	s.CheckTime = timeAt()
+	// rec_per_key_rows
+	pos += 8
+	s.RecPerKeyPart = make([]uint32, s.KeyParts)
+	for i := range s.RecPerKeyPart {
+		s.RecPerKeyPart[i] = uint32At()
+	}
+}
+
+// IsCrashed reports whether the table is marked as crashed
+func (s *State) IsCrashed() bool {
+	return s.Changed&(STATE_CRASHED|STATE_CRASHED_ON_REPAIR) != 0
+}
+
+// Cardinality returns the estimated number of distinct values of the
+// key part at index i counted over all the keys, like SHOW INDEX does
+func (s *State) Cardinality(i int) uint64 {
+	if i >= len(s.RecPerKeyPart) || s.RecPerKeyPart[i] == 0 {
+		return 0
+	}
+	return s.Records / uint64(s.RecPerKeyPart[i])
+}
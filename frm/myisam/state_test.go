@@ -0,0 +1,144 @@
+package myisam
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+// writeState writes the .MYI header of s as mi_state_info_write does, with
+// stateDiff unknown bytes after the fixed part of the state like newer
+// servers write, and keyBlocks key_del pointers
+func writeState(s *State, stateDiff, keyBlocks int) []byte {
+	var b bytes.Buffer
+	put := func(values ...interface{}) {
+		for _, v := range values {
+			_ = binary.Write(&b, binary.BigEndian, v)
+		}
+	}
+	unix := func(t time.Time) uint64 {
+		if t.IsZero() {
+			return 0
+		}
+		return uint64(t.Unix())
+	}
+	b.Write(MYISAM_FILE_MAGIC)
+	put(uint16(s.Options), uint16(0), uint16(MI_STATE_INFO_SIZE+stateDiff), uint16(MI_BASE_INFO_SIZE),
+		uint16(0), s.KeyParts, s.UniqueKeyParts, s.Keys, s.Uniques, uint8(8), uint8(keyBlocks),
+		s.FulltextKeys, uint8(0))
+	put(s.OpenCount, s.Changed, uint8(0), s.Records, s.Deleted, s.Split, uint64(0),
+		s.KeyFileLength, s.DataFileLength, s.Empty, s.KeyEmpty, s.AutoIncrement, s.Checksum,
+		uint32(0), uint32(0), uint32(0), uint32(0))
+	b.Write(bytes.Repeat([]byte{0xAA}, stateDiff))
+	put(s.KeyRoots)
+	for i := 0; i < keyBlocks; i++ {
+		put(^uint64(0))
+	}
+	put(uint32(0), uint32(0), uint32(1), s.KeyMap, unix(s.CreateTime), unix(s.RecoverTime),
+		unix(s.CheckTime), s.Records)
+	put(s.RecPerKeyPart)
+	return b.Bytes()
+}
+
+func TestReadState(t *testing.T) {
+	want := &State{
+		Options:        table.HO_PACK_RECORD | table.HO_CHECKSUM,
+		Keys:           2,
+		KeyParts:       3,
+		OpenCount:      1,
+		Changed:        STATE_CHANGED | STATE_CRASHED,
+		Records:        1000,
+		Deleted:        3,
+		Split:          1003,
+		KeyFileLength:  44032,
+		DataFileLength: 30120,
+		Empty:          60,
+		AutoIncrement:  1017,
+		Checksum:       0xDEADBEEF,
+		KeyRoots:       []uint64{1024, 2048},
+		KeyMap:         3,
+		CreateTime:     time.Unix(1712740569, 0),
+		CheckTime:      time.Unix(1712740600, 0),
+		RecPerKeyPart:  []uint32{1, 50, 2},
+	}
+	for _, stateDiff := range []int{0, 16} {
+		s, err := ReadState(bytes.NewReader(writeState(want, stateDiff, 1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(s, want) {
+			t.Errorf("state diff %d: got\n%+v\nwant\n%+v", stateDiff, s, want)
+		}
+	}
+	if !want.IsCrashed() || want.Cardinality(1) != 20 || want.Cardinality(3) != 0 {
+		t.Errorf("crashed %v, cardinality %d %d", want.IsCrashed(), want.Cardinality(1), want.Cardinality(3))
+	}
+
+	data := writeState(want, 0, 1)
+	data[0] = 0
+	if _, err := ReadState(bytes.NewReader(data)); err == nil {
+		t.Error("no error for a wrong magic")
+	}
+	if _, err := ReadState(bytes.NewReader(writeState(want, 0, 1)[:100])); err == nil {
+		t.Error("no error for a truncated state")
+	}
+}
+
+func TestResolveState(t *testing.T) {
+	data, err := os.ReadFile("../../test_frms/table_key_using_btree.frm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mt, err := table.Parse("t1.frm", bytes.Replace(data, []byte("InnoDB"), []byte("MyISAM"), 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mt.Name = "t1"
+	mt.Options.Partitions = " PARTITION BY HASH (user_id)\nPARTITIONS 3"
+	if err := mt.Options.DecodePartitioning(); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	states := []*State{
+		{Keys: 1, KeyParts: 1, Records: 10, Deleted: 1, DataFileLength: 400, KeyFileLength: 2048,
+			AutoIncrement: 99, KeyRoots: []uint64{1024}, RecPerKeyPart: []uint32{5},
+			CreateTime: time.Unix(1712740000, 0)},
+		{Keys: 1, KeyParts: 1, Records: 30, Empty: 8, DataFileLength: 1200, KeyFileLength: 3072,
+			AutoIncrement: 41, KeyRoots: []uint64{1024}, RecPerKeyPart: []uint32{3},
+			CreateTime: time.Unix(1712730000, 0), CheckTime: time.Unix(1712750000, 0)},
+	}
+	// the last partition has no .MYI and is skipped
+	for i, stem := range mt.Options.Partitioning.FileNames(mt.Name)[:2] {
+		if err := os.WriteFile(filepath.Join(dir, stem+".MYI"), writeState(states[i], 0, 0), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	found, err := ResolveState(dir, mt)
+	if err != nil || !found {
+		t.Fatalf("found %v, %v", found, err)
+	}
+	want := &table.Statistics{
+		Rows: 40, DeletedRows: 1, DataLength: 1600, IndexLength: 5120, DataFree: 8,
+		CreateTime: time.Unix(1712730000, 0), CheckTime: time.Unix(1712750000, 0),
+	}
+	if !reflect.DeepEqual(mt.Statistics, want) {
+		t.Errorf("statistics %+v, want %+v", mt.Statistics, want)
+	}
+	if mt.Options.AutoIncrement != 100 {
+		t.Errorf("AUTO_INCREMENT %d, want 100", mt.Options.AutoIncrement)
+	}
+	if cardinality := mt.Keys.Items[0].Parts[0].Cardinality; cardinality != 10 {
+		t.Errorf("cardinality %d, want 10 from the largest partition", cardinality)
+	}
+
+	found, err = ResolveState(t.TempDir(), mt)
+	if err != nil || found {
+		t.Errorf("found %v, %v without .MYI files", found, err)
+	}
+}
@@ -0,0 +1,91 @@
+package myisam
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+	"github.com/zing22845/go-frm-parser/frm/utils"
+)
+
+// ReadStateFile reads the state header of the .MYI file at path
+func ReadStateFile(path string) (*State, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadState(file)
+}
+
+// ResolveState reads the .MYI files of the table, or of all its
+// (sub)partitions, in dir and applies them to mt. Missing files are
+// skipped, found is false if there was none
+func ResolveState(dir string, mt *table.MySQLTable) (found bool, err error) {
+	stems := []string{utils.EncodeMySQLObject2File(mt.Name)}
+	if mt.Options.Partitioning != nil {
+		stems = mt.Options.Partitioning.FileNames(mt.Name)
+	}
+	states := make([]*State, 0, len(stems))
+	for _, stem := range stems {
+		s, err := ReadStateFile(filepath.Join(dir, stem+".MYI"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		states = append(states, s)
+	}
+	if len(states) == 0 {
+		return false, nil
+	}
+	Apply(mt, states...)
+	return true, nil
+}
+
+// Apply sets the AUTO_INCREMENT option, the statistics and the key
+// cardinality of mt from the states of its .MYI files, one per partition
+// for partitioned tables. Like ha_partition, the counters are summed and
+// the cardinality is taken from the partition with the most records
+func Apply(mt *table.MySQLTable, states ...*State) {
+	if len(states) == 0 {
+		return
+	}
+	stats := &table.Statistics{}
+	var autoIncrement uint64
+	largest := states[0]
+	for _, s := range states {
+		stats.Rows += s.Records
+		stats.DeletedRows += s.Deleted
+		stats.DataLength += s.DataFileLength
+		stats.IndexLength += s.KeyFileLength
+		stats.DataFree += s.Empty
+		stats.Checksum += s.Checksum
+		if stats.CreateTime.IsZero() || s.CreateTime.Before(stats.CreateTime) {
+			stats.CreateTime = s.CreateTime
+		}
+		if s.CheckTime.After(stats.CheckTime) {
+			stats.CheckTime = s.CheckTime
+		}
+		if s.AutoIncrement > autoIncrement {
+			autoIncrement = s.AutoIncrement
+		}
+		if s.Records > largest.Records {
+			largest = s
+		}
+	}
+	mt.Statistics = stats
+	mt.Options.AutoIncrement = autoIncrement + 1
+	if mt.Keys == nil {
+		return
+	}
+	// the key parts of the .MYI are in the same order as in the .frm
+	i := 0
+	for _, k := range mt.Keys.Items {
+		for _, part := range k.Parts {
+			part.Cardinality = largest.Cardinality(i)
+			i++
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package mysqldb
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+const (
+	NATIVE_PASSWORD_PLUGIN = "mysql_native_password"
+	OLD_PASSWORD_PLUGIN    = "mysql_old_password"
+	// SCRAMBLED_PASSWORD_LENGTH is the length of a mysql_native_password
+	// hash, SCRAMBLED_PASSWORD_LENGTH_323 of a mysql_old_password one
+	SCRAMBLED_PASSWORD_LENGTH     = 41
+	SCRAMBLED_PASSWORD_LENGTH_323 = 16
+)
+
+// Account is a user account of the mysql.user table, with the privileges
+// granted to it by the mysql.user, db, tables_priv, columns_priv and
+// procs_priv tables
+type Account struct {
+	User                 string
+	Host                 string
+	Plugin               string
+	AuthenticationString string
+	// SSLType is '', ANY, X509 or SPECIFIED
+	SSLType            string
+	SSLCipher          string
+	X509Issuer         string
+	X509Subject        string
+	MaxQuestions       int64
+	MaxUpdates         int64
+	MaxConnections     int64
+	MaxUserConnections int64
+	PasswordExpired    bool
+	// PasswordLifetime is the password lifetime in days, nil for the
+	// default of the server
+	PasswordLifetime *int64
+	AccountLocked    bool
+	Grants           []*Grant
+}
+
+// NewAccount returns the account of a mysql.user row, with its global
+// privileges
+func NewAccount(row Row) *Account {
+	a := &Account{
+		User:                 row.Text("user"),
+		Host:                 row.Text("host"),
+		Plugin:               row.Text("plugin"),
+		AuthenticationString: row.Text("authentication_string"),
+		SSLType:              row.Text("ssl_type"),
+		SSLCipher:            row.Text("ssl_cipher"),
+		X509Issuer:           row.Text("x509_issuer"),
+		X509Subject:          row.Text("x509_subject"),
+		MaxQuestions:         row.Int("max_questions"),
+		MaxUpdates:           row.Int("max_updates"),
+		MaxConnections:       row.Int("max_connections"),
+		MaxUserConnections:   row.Int("max_user_connections"),
+		PasswordExpired:      row.Text("password_expired") == "Y",
+		AccountLocked:        row.Text("account_locked") == "Y",
+	}
+	if !row.IsNull("password_lifetime") {
+		lifetime := row.Int("password_lifetime")
+		a.PasswordLifetime = &lifetime
+	}
+	// before MySQL 5.7 the hash is in the Password column
+	if password := row.Text("password"); password != "" && a.AuthenticationString == "" {
+		a.AuthenticationString = password
+	}
+	if a.Plugin == "" {
+		a.Plugin = NATIVE_PASSWORD_PLUGIN
+		if len(a.AuthenticationString) == SCRAMBLED_PASSWORD_LENGTH_323 {
+			a.Plugin = OLD_PASSWORD_PLUGIN
+		}
+	}
+	a.Grants = append(a.Grants, globalGrant(row))
+	return a
+}
+
+// ReadAccounts reads the accounts and their privileges from the mysql
+// schema directory dir, the privilege tables other than mysql.user are
+// skipped when they are missing
+func ReadAccounts(dir string) (accounts []*Account, err error) {
+	users, err := ReadTable(dir, "user")
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*Account, len(users))
+	for _, row := range users {
+		a := NewAccount(row)
+		accounts = append(accounts, a)
+		byName[accountKey(a.User, a.Host)] = a
+	}
+	account := func(row Row) *Account {
+		return byName[accountKey(row.Text("user"), row.Text("host"))]
+	}
+
+	rows, err := readOptionalTable(dir, "db")
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if a := account(row); a != nil {
+			a.Grants = append(a.Grants, databaseGrant(row))
+		}
+	}
+
+	tables, err := readOptionalTable(dir, "tables_priv")
+	if err != nil {
+		return nil, err
+	}
+	columns, err := readOptionalTable(dir, "columns_priv")
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range tables {
+		if a := account(row); a != nil {
+			a.Grants = append(a.Grants, tableGrant(row, columns))
+		}
+	}
+
+	rows, err = readOptionalTable(dir, "procs_priv")
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if a := account(row); a != nil {
+			a.Grants = append(a.Grants, routineGrant(row))
+		}
+	}
+	return accounts, nil
+}
+
+func readOptionalTable(dir, name string) ([]Row, error) {
+	rows, err := ReadTable(dir, name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return rows, err
+}
+
+func accountKey(user, host string) string {
+	return user + "@" + strings.ToLower(host)
+}
+
+// Name is the account name as 'user'@'host'
+func (a *Account) Name() string {
+	return accountName(a.User, a.Host)
+}
+
+func accountName(user, host string) string {
+	return table.QuoteString(user) + "@" + table.QuoteString(host)
+}
+
+// CreateStatement returns the CREATE USER statement of the account as
+// SHOW CREATE USER of MySQL 5.7 does
+func (a *Account) CreateStatement() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE USER %s IDENTIFIED WITH %s", a.Name(), table.QuoteString(a.Plugin))
+	if a.AuthenticationString != "" {
+		fmt.Fprintf(&b, " AS %s", table.QuoteString(a.AuthenticationString))
+	}
+	b.WriteString(" REQUIRE ")
+	switch a.SSLType {
+	case "ANY":
+		b.WriteString("SSL")
+	case "X509":
+		b.WriteString("X509")
+	case "SPECIFIED":
+		var options []string
+		if a.X509Subject != "" {
+			options = append(options, "SUBJECT "+table.QuoteString(a.X509Subject))
+		}
+		if a.X509Issuer != "" {
+			options = append(options, "ISSUER "+table.QuoteString(a.X509Issuer))
+		}
+		if a.SSLCipher != "" {
+			options = append(options, "CIPHER "+table.QuoteString(a.SSLCipher))
+		}
+		b.WriteString(strings.Join(options, " AND "))
+	default:
+		b.WriteString("NONE")
+	}
+	if a.MaxQuestions != 0 || a.MaxUpdates != 0 || a.MaxConnections != 0 || a.MaxUserConnections != 0 {
+		fmt.Fprintf(&b, " WITH MAX_QUERIES_PER_HOUR %d MAX_UPDATES_PER_HOUR %d MAX_CONNECTIONS_PER_HOUR %d MAX_USER_CONNECTIONS %d",
+			a.MaxQuestions, a.MaxUpdates, a.MaxConnections, a.MaxUserConnections)
+	}
+	switch {
+	case a.PasswordExpired:
+		b.WriteString(" PASSWORD EXPIRE")
+	case a.PasswordLifetime == nil:
+		b.WriteString(" PASSWORD EXPIRE DEFAULT")
+	case *a.PasswordLifetime == 0:
+		b.WriteString(" PASSWORD EXPIRE NEVER")
+	default:
+		fmt.Fprintf(&b, " PASSWORD EXPIRE INTERVAL %d DAY", *a.PasswordLifetime)
+	}
+	if a.AccountLocked {
+		b.WriteString(" ACCOUNT LOCK")
+	} else {
+		b.WriteString(" ACCOUNT UNLOCK")
+	}
+	return b.String()
+}
+
+// GrantStatements returns the GRANT statements of the account as SHOW
+// GRANTS does
+func (a *Account) GrantStatements() []string {
+	statements := make([]string, 0, len(a.Grants))
+	for _, g := range a.Grants {
+		statements = append(statements, g.Statement(a.User, a.Host))
+	}
+	return statements
+}
+
+// String returns the CREATE USER and GRANT statements of the account
+func (a *Account) String() string {
+	statements := append([]string{a.CreateStatement()}, a.GrantStatements()...)
+	return strings.Join(statements, ";\n") + ";"
+}
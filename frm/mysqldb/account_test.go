@@ -0,0 +1,95 @@
+package mysqldb
+
+import "testing"
+
+func TestNewAccount(t *testing.T) {
+	for _, c := range []struct {
+		name           string
+		row            Row
+		plugin         string
+		authentication string
+	}{
+		{
+			name:           "5.7",
+			row:            Row{"user": "app", "host": "%", "plugin": "sha256_password", "authentication_string": "$5$hash"},
+			plugin:         "sha256_password",
+			authentication: "$5$hash",
+		},
+		{
+			name: "Password column",
+			row: Row{"user": "app", "host": "%", "plugin": "", "authentication_string": "",
+				"password": "*2470C0C06DEE42FD1618BB99005ADCA2EC9D1E19"},
+			plugin:         NATIVE_PASSWORD_PLUGIN,
+			authentication: "*2470C0C06DEE42FD1618BB99005ADCA2EC9D1E19",
+		},
+		{
+			name:           "old password",
+			row:            Row{"user": "app", "host": "%", "password": "565491d704013245"},
+			plugin:         OLD_PASSWORD_PLUGIN,
+			authentication: "565491d704013245",
+		},
+		{
+			name:   "no password",
+			row:    Row{"user": "app", "host": "%", "password": ""},
+			plugin: NATIVE_PASSWORD_PLUGIN,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			a := NewAccount(c.row)
+			if a.Plugin != c.plugin || a.AuthenticationString != c.authentication {
+				t.Errorf("got %s AS %q, want %s AS %q", a.Plugin, a.AuthenticationString, c.plugin, c.authentication)
+			}
+		})
+	}
+}
+
+func TestCreateStatement(t *testing.T) {
+	const prefix = "CREATE USER 'app'@'10.0.%' IDENTIFIED WITH 'mysql_native_password' AS '*2470C0C06DEE42FD1618BB99005ADCA2EC9D1E19'"
+	for _, c := range []struct {
+		name string
+		row  Row
+		want string
+	}{
+		{
+			name: "defaults",
+			row:  Row{},
+			want: prefix + " REQUIRE NONE PASSWORD EXPIRE DEFAULT ACCOUNT UNLOCK",
+		},
+		{
+			name: "ssl",
+			row:  Row{"ssl_type": "ANY", "password_lifetime": int64(0)},
+			want: prefix + " REQUIRE SSL PASSWORD EXPIRE NEVER ACCOUNT UNLOCK",
+		},
+		{
+			name: "x509",
+			row:  Row{"ssl_type": "X509", "password_lifetime": int64(90), "account_locked": "Y"},
+			want: prefix + " REQUIRE X509 PASSWORD EXPIRE INTERVAL 90 DAY ACCOUNT LOCK",
+		},
+		{
+			name: "specified",
+			row: Row{"ssl_type": "SPECIFIED", "x509_subject": "/CN=app", "x509_issuer": "/CN=ca",
+				"ssl_cipher": "EDH-RSA-DES-CBC3-SHA", "password_expired": "Y", "password_lifetime": int64(90)},
+			want: prefix + " REQUIRE SUBJECT '/CN=app' AND ISSUER '/CN=ca' AND CIPHER 'EDH-RSA-DES-CBC3-SHA'" +
+				" PASSWORD EXPIRE ACCOUNT UNLOCK",
+		},
+		{
+			name: "specified issuer only",
+			row:  Row{"ssl_type": "SPECIFIED", "x509_issuer": "/CN=ca"},
+			want: prefix + " REQUIRE ISSUER '/CN=ca' PASSWORD EXPIRE DEFAULT ACCOUNT UNLOCK",
+		},
+		{
+			name: "resource limits",
+			row:  Row{"max_questions": int64(100), "max_user_connections": uint64(5)},
+			want: prefix + " REQUIRE NONE WITH MAX_QUERIES_PER_HOUR 100 MAX_UPDATES_PER_HOUR 0" +
+				" MAX_CONNECTIONS_PER_HOUR 0 MAX_USER_CONNECTIONS 5 PASSWORD EXPIRE DEFAULT ACCOUNT UNLOCK",
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			c.row["user"], c.row["host"] = "app", "10.0.%"
+			c.row["authentication_string"] = "*2470C0C06DEE42FD1618BB99005ADCA2EC9D1E19"
+			if got := NewAccount(c.row).CreateStatement(); got != c.want {
+				t.Errorf("got\n%s\nwant\n%s", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package mysqldb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+const mysqlDatetimeLayout = "2006-01-02 15:04:05"
+
+// Event is a scheduled event, a row of mysql.event
+type Event struct {
+	Schema string
+	Name   string
+	Body   string
+	// Definer is user@host
+	Definer string
+	// ExecuteAt is set for events which run once, the times are stored
+	// in UTC
+	ExecuteAt string
+	// IntervalValue and IntervalField are set for recurring events
+	IntervalValue int64
+	IntervalField string
+	Created       string
+	Modified      string
+	LastExecuted  string
+	Starts        string
+	Ends          string
+	// Status is ENABLED, DISABLED or SLAVESIDE_DISABLED
+	Status string
+	// OnCompletion is DROP or PRESERVE
+	OnCompletion        string
+	SQLMode             string
+	Comment             string
+	Originator          int64
+	TimeZone            string
+	CharacterSetClient  string
+	CollationConnection string
+	DBCollation         string
+}
+
+// NewEvent returns the event of a mysql.event row
+func NewEvent(row Row) *Event {
+	return &Event{
+		Schema:              row.Text("db"),
+		Name:                row.Text("name"),
+		Body:                row.Text("body"),
+		Definer:             row.Text("definer"),
+		ExecuteAt:           row.Text("execute_at"),
+		IntervalValue:       row.Int("interval_value"),
+		IntervalField:       row.Text("interval_field"),
+		Created:             row.Text("created"),
+		Modified:            row.Text("modified"),
+		LastExecuted:        row.Text("last_executed"),
+		Starts:              row.Text("starts"),
+		Ends:                row.Text("ends"),
+		Status:              row.Text("status"),
+		OnCompletion:        row.Text("on_completion"),
+		SQLMode:             row.Text("sql_mode"),
+		Comment:             row.Text("comment"),
+		Originator:          row.Int("originator"),
+		TimeZone:            row.Text("time_zone"),
+		CharacterSetClient:  row.Text("character_set_client"),
+		CollationConnection: row.Text("collation_connection"),
+		DBCollation:         row.Text("db_collation"),
+	}
+}
+
+// ReadEvents reads the events of mysql.event from the mysql schema
+// directory dir
+func ReadEvents(dir string) (events []*Event, err error) {
+	rows, err := ReadTable(dir, "event")
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		events = append(events, NewEvent(row))
+	}
+	return events, nil
+}
+
+// String returns the CREATE statement as SHOW CREATE EVENT does,
+// Event_timed::get_create_event
+func (e *Event) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE %s EVENT %s", definerClause(e.Definer), quoteIdentifier(e.Name))
+	if e.IntervalField != "" {
+		fmt.Fprintf(&b, " ON SCHEDULE EVERY %s %s", e.intervalExpression(), e.IntervalField)
+		if e.Starts != "" {
+			fmt.Fprintf(&b, " STARTS '%s'", e.localTime(e.Starts))
+		}
+		if e.Ends != "" {
+			fmt.Fprintf(&b, " ENDS '%s'", e.localTime(e.Ends))
+		}
+	} else {
+		fmt.Fprintf(&b, " ON SCHEDULE AT '%s'", e.localTime(e.ExecuteAt))
+	}
+	if e.OnCompletion == "DROP" {
+		b.WriteString(" ON COMPLETION NOT PRESERVE ")
+	} else {
+		b.WriteString(" ON COMPLETION PRESERVE ")
+	}
+	switch e.Status {
+	case "ENABLED":
+		b.WriteString("ENABLE")
+	case "SLAVESIDE_DISABLED":
+		b.WriteString("DISABLE ON SLAVE")
+	default:
+		b.WriteString("DISABLE")
+	}
+	if e.Comment != "" {
+		fmt.Fprintf(&b, " COMMENT %s", table.QuoteString(e.Comment))
+	}
+	fmt.Fprintf(&b, " DO %s", e.Body)
+	return b.String()
+}
+
+// Dump returns the event as mysqldump --events writes it, in the
+// character set context, sql_mode and time zone it was created with
+func (e *Event) Dump() string {
+	set, restore := sessionContext(e.CharacterSetClient, e.CollationConnection, e.SQLMode, e.TimeZone, ";;")
+	return fmt.Sprintf("DELIMITER ;;\n%s%s ;;\n%sDELIMITER ;\n", set, e.String(), restore)
+}
+
+// localTime converts a time stored in UTC to the time zone of the event
+func (e *Event) localTime(value string) string {
+	t, err := time.ParseInLocation(mysqlDatetimeLayout, value, time.UTC)
+	if err != nil {
+		return value
+	}
+	loc := eventLocation(e.TimeZone)
+	if loc == nil {
+		return value
+	}
+	return t.In(loc).Format(mysqlDatetimeLayout)
+}
+
+// eventLocation returns the location of a time_zone value, nil for
+// SYSTEM which depends on the server
+func eventLocation(timeZone string) *time.Location {
+	if timeZone == "" || strings.EqualFold(timeZone, "SYSTEM") {
+		return nil
+	}
+	if len(timeZone) == 6 && (timeZone[0] == '+' || timeZone[0] == '-') && timeZone[3] == ':' {
+		hours, err1 := strconv.Atoi(timeZone[1:3])
+		minutes, err2 := strconv.Atoi(timeZone[4:])
+		if err1 != nil || err2 != nil {
+			return nil
+		}
+		offset := hours*3600 + minutes*60
+		if timeZone[0] == '-' {
+			offset = -offset
+		}
+		return time.FixedZone(timeZone, offset)
+	}
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
+
+// intervalExpression follows Events::reconstruct_interval_expression,
+// the interval is stored in its smallest unit
+func (e *Event) intervalExpression() string {
+	expr := e.IntervalValue
+	var b strings.Builder
+	closeQuote := true
+	separator := ":"
+	multiplier := int64(0)
+	switch e.IntervalField {
+	case "YEAR_MONTH":
+		multiplier, separator = 12, "-"
+	case "DAY_HOUR":
+		multiplier, separator = 24, " "
+	case "HOUR_MINUTE", "MINUTE_SECOND":
+		multiplier = 60
+	case "DAY_MINUTE":
+		days := expr / (24 * 60)
+		minutes := expr - days*24*60
+		fmt.Fprintf(&b, "'%d %d", days, minutes/60)
+		expr = minutes % 60
+	case "HOUR_SECOND":
+		fmt.Fprintf(&b, "'%d:%d", expr/3600, expr%3600/60)
+		expr = expr % 60
+	case "DAY_SECOND":
+		days := expr / (24 * 3600)
+		seconds := expr - days*24*3600
+		fmt.Fprintf(&b, "'%d %d:%d", days, seconds/3600, seconds%3600/60)
+		expr = seconds % 60
+	case "QUARTER":
+		expr /= 3
+		closeQuote = false
+	case "WEEK":
+		expr /= 7
+		closeQuote = false
+	default:
+		closeQuote = false
+	}
+	if multiplier != 0 {
+		fmt.Fprintf(&b, "'%d", expr/multiplier)
+		expr = expr % multiplier
+	}
+	if closeQuote {
+		b.WriteString(separator)
+	}
+	fmt.Fprintf(&b, "%d", expr)
+	if closeQuote {
+		b.WriteString("'")
+	}
+	return b.String()
+}
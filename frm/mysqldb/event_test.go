@@ -0,0 +1,122 @@
+package mysqldb
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIntervalExpression(t *testing.T) {
+	for _, c := range []struct {
+		field string
+		// value is in the smallest unit of the field, as stored
+		value int64
+		want  string
+	}{
+		{"DAY", 3, "3"},
+		{"YEAR_MONTH", 14, "'1-2'"},
+		{"DAY_HOUR", 26, "'1 2'"},
+		{"HOUR_MINUTE", 125, "'2:5'"},
+		{"MINUTE_SECOND", 61, "'1:1'"},
+		{"DAY_MINUTE", 24*60 + 2*60 + 5, "'1 2:5'"},
+		{"HOUR_SECOND", 3600 + 2*60 + 5, "'1:2:5'"},
+		{"DAY_SECOND", 24*3600 + 3600 + 2*60 + 3, "'1 1:2:3'"},
+		{"QUARTER", 6, "2"},
+		{"WEEK", 14, "2"},
+	} {
+		e := &Event{IntervalField: c.field, IntervalValue: c.value}
+		if got := e.intervalExpression(); got != c.want {
+			t.Errorf("%s %d: got %s, want %s", c.field, c.value, got, c.want)
+		}
+	}
+}
+
+func TestLocalTime(t *testing.T) {
+	for _, c := range []struct {
+		timeZone string
+		want     string
+	}{
+		{"SYSTEM", "2024-01-01 23:30:00"},
+		{"", "2024-01-01 23:30:00"},
+		{"+08:00", "2024-01-02 07:30:00"},
+		{"-05:30", "2024-01-01 18:00:00"},
+		{"+8:00", "2024-01-01 23:30:00"},
+	} {
+		e := &Event{TimeZone: c.timeZone}
+		if got := e.localTime("2024-01-01 23:30:00"); got != c.want {
+			t.Errorf("time zone %q: got %s, want %s", c.timeZone, got, c.want)
+		}
+	}
+}
+
+func TestEventString(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		row  Row
+		want string
+	}{
+		{
+			name: "recurring",
+			row: Row{
+				"db": "shop", "name": "purge", "body": []byte("DELETE FROM sessions"), "definer": "root@localhost",
+				"interval_value": int64(24*60 + 2*60 + 5), "interval_field": "DAY_MINUTE",
+				"starts": "2024-01-01 00:00:00", "ends": "2024-12-31 16:00:00",
+				"status": "ENABLED", "on_completion": "DROP", "time_zone": "+08:00", "comment": "it's nightly",
+			},
+			want: "CREATE DEFINER=`root`@`localhost` EVENT `purge` ON SCHEDULE EVERY '1 2:5' DAY_MINUTE " +
+				"STARTS '2024-01-01 08:00:00' ENDS '2025-01-01 00:00:00' ON COMPLETION NOT PRESERVE ENABLE " +
+				"COMMENT 'it\\'s nightly' DO DELETE FROM sessions",
+		},
+		{
+			name: "once",
+			row: Row{
+				"name": "cleanup", "body": []byte("DROP TABLE tmp"), "definer": "app@%",
+				"execute_at": "2024-03-01 12:00:00", "status": "DISABLED", "on_completion": "PRESERVE",
+				"time_zone": "SYSTEM",
+			},
+			want: "CREATE DEFINER=`app`@`%` EVENT `cleanup` ON SCHEDULE AT '2024-03-01 12:00:00' " +
+				"ON COMPLETION PRESERVE DISABLE DO DROP TABLE tmp",
+		},
+		{
+			name: "disabled on replica",
+			row: Row{
+				"name": "tick", "body": []byte("DO 1"), "definer": "root@localhost",
+				"interval_value": uint64(1), "interval_field": "HOUR", "status": "SLAVESIDE_DISABLED",
+				"on_completion": "DROP", "time_zone": "SYSTEM",
+			},
+			want: "CREATE DEFINER=`root`@`localhost` EVENT `tick` ON SCHEDULE EVERY 1 HOUR " +
+				"ON COMPLETION NOT PRESERVE DISABLE ON SLAVE DO DO 1",
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if got := NewEvent(c.row).String(); got != c.want {
+				t.Errorf("got\n%s\nwant\n%s", got, c.want)
+			}
+		})
+	}
+}
+
+func TestEventDump(t *testing.T) {
+	e := NewEvent(Row{
+		"name": "tick", "body": []byte("DO 1"), "definer": "root@localhost",
+		"interval_value": int64(1), "interval_field": "HOUR", "status": "ENABLED", "on_completion": "DROP",
+		"sql_mode": "", "time_zone": "+00:00", "character_set_client": "latin1",
+		"collation_connection": "latin1_swedish_ci",
+	})
+	dump := e.Dump()
+	if !strings.HasPrefix(dump, "DELIMITER ;;\n/*!50003 SET @saved_cs_client      = @@character_set_client */ ;;\n") {
+		t.Errorf("dump doesn't start with the delimiter:\n%s", dump)
+	}
+	for _, want := range []string{
+		"/*!50003 SET time_zone             = '+00:00' */ ;;\n" +
+			"CREATE DEFINER=`root`@`localhost` EVENT `tick` ON SCHEDULE EVERY 1 HOUR ON COMPLETION NOT PRESERVE ENABLE DO DO 1 ;;\n" +
+			"/*!50003 SET time_zone             = @saved_time_zone */ ;;\n",
+		"/*!50003 SET sql_mode              = '' */ ;;\n",
+	} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("dump without %q:\n%s", want, dump)
+		}
+	}
+	if !strings.HasSuffix(dump, "/*!50003 SET collation_connection  = @saved_col_connection */ ;;\nDELIMITER ;\n") {
+		t.Errorf("dump doesn't end by restoring the delimiter:\n%s", dump)
+	}
+}
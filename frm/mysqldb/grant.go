@@ -0,0 +1,188 @@
+package mysqldb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// privilege is a privilege as SHOW GRANTS names it, with the column of
+// mysql.user and mysql.db it is stored in
+type privilege struct {
+	Name     string
+	Column   string
+	Database bool
+}
+
+// privileges are in the order SHOW GRANTS lists them, command_array
+var privileges = []*privilege{
+	{"SELECT", "select_priv", true},
+	{"INSERT", "insert_priv", true},
+	{"UPDATE", "update_priv", true},
+	{"DELETE", "delete_priv", true},
+	{"CREATE", "create_priv", true},
+	{"DROP", "drop_priv", true},
+	{"RELOAD", "reload_priv", false},
+	{"SHUTDOWN", "shutdown_priv", false},
+	{"PROCESS", "process_priv", false},
+	{"FILE", "file_priv", false},
+	{"REFERENCES", "references_priv", true},
+	{"INDEX", "index_priv", true},
+	{"ALTER", "alter_priv", true},
+	{"SHOW DATABASES", "show_db_priv", false},
+	{"SUPER", "super_priv", false},
+	{"CREATE TEMPORARY TABLES", "create_tmp_table_priv", true},
+	{"LOCK TABLES", "lock_tables_priv", true},
+	{"EXECUTE", "execute_priv", true},
+	{"REPLICATION SLAVE", "repl_slave_priv", false},
+	{"REPLICATION CLIENT", "repl_client_priv", false},
+	{"CREATE VIEW", "create_view_priv", true},
+	{"SHOW VIEW", "show_view_priv", true},
+	{"CREATE ROUTINE", "create_routine_priv", true},
+	{"ALTER ROUTINE", "alter_routine_priv", true},
+	{"CREATE USER", "create_user_priv", false},
+	{"EVENT", "event_priv", true},
+	{"TRIGGER", "trigger_priv", true},
+	{"CREATE TABLESPACE", "create_tablespace_priv", false},
+}
+
+// tablePrivileges are the privileges of tables_priv.Table_priv and
+// columns_priv.Column_priv, in the order SHOW GRANTS lists them
+var tablePrivileges = []string{
+	"SELECT", "INSERT", "UPDATE", "DELETE", "CREATE", "DROP", "REFERENCES",
+	"INDEX", "ALTER", "CREATE VIEW", "SHOW VIEW", "TRIGGER",
+}
+
+// routinePrivileges are the privileges of procs_priv.Proc_priv
+var routinePrivileges = []string{"EXECUTE", "ALTER ROUTINE"}
+
+// Grant is the privileges of an account on one object
+type Grant struct {
+	// Privileges are the privilege names, with the column list for
+	// column privileges
+	Privileges []string
+	// On is the object, e.g. *.*, `db`.*, `db`.`t` or PROCEDURE `db`.`p`
+	On              string
+	WithGrantOption bool
+}
+
+// Statement returns the GRANT statement of the privileges to user@host
+func (g *Grant) Statement(user, host string) string {
+	privileges := "USAGE"
+	if len(g.Privileges) != 0 {
+		privileges = strings.Join(g.Privileges, ", ")
+	}
+	statement := fmt.Sprintf("GRANT %s ON %s TO %s", privileges, g.On, accountName(user, host))
+	if g.WithGrantOption {
+		statement += " WITH GRANT OPTION"
+	}
+	return statement
+}
+
+// granted collects the privileges set to Y in the columns of a mysql.user
+// or mysql.db row, ALL PRIVILEGES when every privilege the row has
+// columns for is set
+func granted(row Row, database bool) []string {
+	var names []string
+	all := true
+	for _, p := range privileges {
+		if database && !p.Database {
+			continue
+		}
+		if _, ok := row[p.Column]; !ok {
+			continue
+		}
+		if row.Text(p.Column) == "Y" {
+			names = append(names, p.Name)
+		} else {
+			all = false
+		}
+	}
+	if all && len(names) != 0 {
+		return []string{"ALL PRIVILEGES"}
+	}
+	return names
+}
+
+func globalGrant(row Row) *Grant {
+	return &Grant{
+		Privileges:      granted(row, false),
+		On:              "*.*",
+		WithGrantOption: row.Text("grant_priv") == "Y",
+	}
+}
+
+func databaseGrant(row Row) *Grant {
+	return &Grant{
+		Privileges:      granted(row, true),
+		On:              quoteIdentifier(row.Text("db")) + ".*",
+		WithGrantOption: row.Text("grant_priv") == "Y",
+	}
+}
+
+// setOf returns the upper case members of a SET value
+func setOf(value string) map[string]bool {
+	members := make(map[string]bool)
+	for _, m := range strings.Split(value, ",") {
+		if m != "" {
+			members[strings.ToUpper(m)] = true
+		}
+	}
+	return members
+}
+
+// tableGrant merges a tables_priv row with the columns_priv rows of the
+// same table, as SHOW GRANTS does
+func tableGrant(row Row, columns []Row) *Grant {
+	db, name := row.Text("db"), row.Text("table_name")
+	tablePrivs := setOf(row.Text("table_priv"))
+	columnPrivs := make(map[string][]string)
+	for _, c := range columns {
+		if c.Text("db") != db || c.Text("table_name") != name ||
+			c.Text("user") != row.Text("user") || !strings.EqualFold(c.Text("host"), row.Text("host")) {
+			continue
+		}
+		for p := range setOf(c.Text("column_priv")) {
+			columnPrivs[p] = append(columnPrivs[p], quoteIdentifier(c.Text("column_name")))
+		}
+	}
+	g := &Grant{
+		On:              quoteIdentifier(db) + "." + quoteIdentifier(name),
+		WithGrantOption: tablePrivs["GRANT"],
+	}
+	all := true
+	for _, p := range tablePrivileges {
+		if !tablePrivs[p] {
+			all = false
+		}
+	}
+	if all {
+		g.Privileges = []string{"ALL PRIVILEGES"}
+		return g
+	}
+	for _, p := range tablePrivileges {
+		if tablePrivs[p] {
+			g.Privileges = append(g.Privileges, p)
+		}
+		if cols := columnPrivs[p]; len(cols) != 0 {
+			sort.Strings(cols)
+			g.Privileges = append(g.Privileges, fmt.Sprintf("%s (%s)", p, strings.Join(cols, ", ")))
+		}
+	}
+	return g
+}
+
+func routineGrant(row Row) *Grant {
+	procPrivs := setOf(row.Text("proc_priv"))
+	g := &Grant{
+		On: fmt.Sprintf("%s %s.%s", row.Text("routine_type"),
+			quoteIdentifier(row.Text("db")), quoteIdentifier(row.Text("routine_name"))),
+		WithGrantOption: procPrivs["GRANT"],
+	}
+	for _, p := range routinePrivileges {
+		if procPrivs[p] {
+			g.Privileges = append(g.Privileges, p)
+		}
+	}
+	return g
+}
@@ -0,0 +1,130 @@
+package mysqldb
+
+import (
+	"reflect"
+	"testing"
+)
+
+// privilegeRow returns a mysql.user or mysql.db row with every privilege
+// column set to Y but those of revoked
+func privilegeRow(database bool, revoked ...string) Row {
+	row := Row{"user": "app", "host": "%", "db": "shop"}
+	for _, p := range privileges {
+		if !database || p.Database {
+			row[p.Column] = "Y"
+		}
+	}
+	for _, column := range revoked {
+		row[column] = "N"
+	}
+	return row
+}
+
+func TestGranted(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		row      Row
+		database bool
+		want     []string
+	}{
+		{"global all", privilegeRow(false), false, []string{"ALL PRIVILEGES"}},
+		{"database all", privilegeRow(true), true, []string{"ALL PRIVILEGES"}},
+		{
+			name: "global without super",
+			row:  privilegeRow(false, "super_priv"),
+			want: []string{"SELECT", "INSERT", "UPDATE", "DELETE", "CREATE", "DROP", "RELOAD", "SHUTDOWN",
+				"PROCESS", "FILE", "REFERENCES", "INDEX", "ALTER", "SHOW DATABASES", "CREATE TEMPORARY TABLES",
+				"LOCK TABLES", "EXECUTE", "REPLICATION SLAVE", "REPLICATION CLIENT", "CREATE VIEW", "SHOW VIEW",
+				"CREATE ROUTINE", "ALTER ROUTINE", "CREATE USER", "EVENT", "TRIGGER", "CREATE TABLESPACE"},
+		},
+		{
+			// privileges of a later version are not columns of a 5.0 table
+			name: "older table",
+			row:  Row{"select_priv": "Y", "insert_priv": "Y"},
+			want: []string{"ALL PRIVILEGES"},
+		},
+		{
+			name:     "some",
+			row:      Row{"select_priv": "Y", "insert_priv": "N", "execute_priv": "Y", "reload_priv": "Y"},
+			database: true,
+			want:     []string{"SELECT", "EXECUTE"},
+		},
+		{"none", Row{"select_priv": "N"}, false, nil},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if got := granted(c.row, c.database); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestGrantStatement(t *testing.T) {
+	a := NewAccount(Row{"user": "app", "host": "%", "select_priv": "N"})
+	a.Grants = append(a.Grants, databaseGrant(Row{"db": "shop", "grant_priv": "Y", "select_priv": "Y", "insert_priv": "N"}))
+	want := []string{
+		"GRANT USAGE ON *.* TO 'app'@'%'",
+		"GRANT SELECT ON `shop`.* TO 'app'@'%' WITH GRANT OPTION",
+	}
+	if got := a.GrantStatements(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTableGrant(t *testing.T) {
+	columns := []Row{
+		{"user": "app", "host": "%", "db": "shop", "table_name": "orders", "column_name": "total", "column_priv": "Select,Update"},
+		{"user": "app", "host": "%", "db": "shop", "table_name": "orders", "column_name": "id", "column_priv": "Select"},
+		{"user": "app", "host": "%", "db": "shop", "table_name": "items", "column_name": "sku", "column_priv": "Select"},
+		{"user": "web", "host": "%", "db": "shop", "table_name": "orders", "column_name": "note", "column_priv": "Select"},
+	}
+	for _, c := range []struct {
+		name  string
+		priv  string
+		want  []string
+		grant bool
+	}{
+		{
+			name: "table and columns",
+			priv: "Insert,Grant",
+			want: []string{"SELECT (`id`, `total`)", "INSERT", "UPDATE (`total`)"},
+			// GRANT OPTION is not a privilege of the list
+			grant: true,
+		},
+		{
+			name: "columns only",
+			want: []string{"SELECT (`id`, `total`)", "UPDATE (`total`)"},
+		},
+		{
+			name: "table and column of the same privilege",
+			priv: "Select",
+			want: []string{"SELECT", "SELECT (`id`, `total`)", "UPDATE (`total`)"},
+		},
+		{
+			name: "all",
+			priv: "Select,Insert,Update,Delete,Create,Drop,References,Index,Alter,Create View,Show view,Trigger",
+			want: []string{"ALL PRIVILEGES"},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			g := tableGrant(Row{"user": "app", "host": "%", "db": "shop", "table_name": "orders", "table_priv": c.priv}, columns)
+			if g.On != "`shop`.`orders`" || !reflect.DeepEqual(g.Privileges, c.want) || g.WithGrantOption != c.grant {
+				t.Errorf("got %q ON %s grant option %v, want %q grant option %v",
+					g.Privileges, g.On, g.WithGrantOption, c.want, c.grant)
+			}
+		})
+	}
+}
+
+func TestRoutineGrant(t *testing.T) {
+	g := routineGrant(Row{"db": "shop", "routine_name": "add_item", "routine_type": "PROCEDURE",
+		"proc_priv": "Alter Routine,Execute,Grant"})
+	if got, want := g.Statement("app", "%"),
+		"GRANT EXECUTE, ALTER ROUTINE ON PROCEDURE `shop`.`add_item` TO 'app'@'%' WITH GRANT OPTION"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	g = routineGrant(Row{"db": "shop", "routine_name": "price", "routine_type": "FUNCTION", "proc_priv": "Execute"})
+	if got, want := g.Statement("app", "%"), "GRANT EXECUTE ON FUNCTION `shop`.`price` TO 'app'@'%'"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
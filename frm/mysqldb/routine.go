@@ -0,0 +1,107 @@
+package mysqldb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+// Routine is a stored procedure or function, a row of mysql.proc
+type Routine struct {
+	Schema string
+	Name   string
+	// Type is PROCEDURE or FUNCTION
+	Type string
+	// SQLDataAccess is CONTAINS_SQL, NO_SQL, READS_SQL_DATA or
+	// MODIFIES_SQL_DATA
+	SQLDataAccess   string
+	IsDeterministic bool
+	// SecurityType is INVOKER or DEFINER
+	SecurityType        string
+	ParamList           string
+	Returns             string
+	Body                string
+	Definer             string
+	Created             string
+	Modified            string
+	SQLMode             string
+	Comment             string
+	CharacterSetClient  string
+	CollationConnection string
+	DBCollation         string
+}
+
+// NewRoutine returns the routine of a mysql.proc row
+func NewRoutine(row Row) *Routine {
+	return &Routine{
+		Schema:              row.Text("db"),
+		Name:                row.Text("name"),
+		Type:                row.Text("type"),
+		SQLDataAccess:       row.Text("sql_data_access"),
+		IsDeterministic:     row.Text("is_deterministic") == "YES",
+		SecurityType:        row.Text("security_type"),
+		ParamList:           row.Text("param_list"),
+		Returns:             row.Text("returns"),
+		Body:                row.Text("body"),
+		Definer:             row.Text("definer"),
+		Created:             row.Text("created"),
+		Modified:            row.Text("modified"),
+		SQLMode:             row.Text("sql_mode"),
+		Comment:             row.Text("comment"),
+		CharacterSetClient:  row.Text("character_set_client"),
+		CollationConnection: row.Text("collation_connection"),
+		DBCollation:         row.Text("db_collation"),
+	}
+}
+
+// ReadRoutines reads the stored routines of mysql.proc from the mysql
+// schema directory dir
+func ReadRoutines(dir string) (routines []*Routine, err error) {
+	rows, err := ReadTable(dir, "proc")
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		routines = append(routines, NewRoutine(row))
+	}
+	return routines, nil
+}
+
+// String returns the CREATE statement as SHOW CREATE PROCEDURE/FUNCTION
+// does, show_create_sp
+func (r *Routine) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE %s %s %s(%s)",
+		definerClause(r.Definer), r.Type, quoteIdentifier(r.Name), r.ParamList)
+	if r.Type == "FUNCTION" {
+		fmt.Fprintf(&b, " RETURNS %s", r.Returns)
+	}
+	b.WriteString("\n")
+	switch r.SQLDataAccess {
+	case "NO_SQL":
+		b.WriteString("    NO SQL\n")
+	case "READS_SQL_DATA":
+		b.WriteString("    READS SQL DATA\n")
+	case "MODIFIES_SQL_DATA":
+		b.WriteString("    MODIFIES SQL DATA\n")
+	}
+	if r.IsDeterministic {
+		b.WriteString("    DETERMINISTIC\n")
+	}
+	if r.SecurityType == "INVOKER" {
+		b.WriteString("    SQL SECURITY INVOKER\n")
+	}
+	if r.Comment != "" {
+		fmt.Fprintf(&b, "    COMMENT %s\n", table.QuoteString(r.Comment))
+	}
+	b.WriteString(r.Body)
+	return b.String()
+}
+
+// Dump returns the routine as mysqldump --routines writes it, in the
+// character set context and sql_mode it was created with
+func (r *Routine) Dump() string {
+	set, restore := sessionContext(r.CharacterSetClient, r.CollationConnection, r.SQLMode, "", ";")
+	return fmt.Sprintf("%sDELIMITER ;;\n%s ;;\nDELIMITER ;\n%s", set, r.String(), restore)
+}
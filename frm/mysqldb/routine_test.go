@@ -0,0 +1,84 @@
+package mysqldb
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRoutineString(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		row  Row
+		want string
+	}{
+		{
+			name: "procedure",
+			row: Row{
+				"db": "shop", "name": "add`item", "type": "PROCEDURE", "sql_data_access": "CONTAINS_SQL",
+				"is_deterministic": "NO", "security_type": "DEFINER",
+				"param_list": []byte("IN id INT, OUT n INT"), "returns": []byte(""),
+				"body":    []byte("BEGIN\n  SELECT COUNT(*) INTO n FROM items WHERE item_id = id;\nEND"),
+				"definer": "app@%",
+			},
+			want: "CREATE DEFINER=`app`@`%` PROCEDURE `add``item`(IN id INT, OUT n INT)\n" +
+				"BEGIN\n  SELECT COUNT(*) INTO n FROM items WHERE item_id = id;\nEND",
+		},
+		{
+			name: "function with characteristics",
+			row: Row{
+				"db": "shop", "name": "price", "type": "FUNCTION", "sql_data_access": "READS_SQL_DATA",
+				"is_deterministic": "YES", "security_type": "INVOKER",
+				"param_list": []byte("p DECIMAL(10,2)"), "returns": []byte("decimal(10,2) CHARSET latin1"),
+				"body": []byte("RETURN p * 1.2"), "definer": "root@localhost", "comment": "it's taxed",
+			},
+			want: "CREATE DEFINER=`root`@`localhost` FUNCTION `price`(p DECIMAL(10,2)) RETURNS decimal(10,2) CHARSET latin1\n" +
+				"    READS SQL DATA\n    DETERMINISTIC\n    SQL SECURITY INVOKER\n    COMMENT 'it\\'s taxed'\n" +
+				"RETURN p * 1.2",
+		},
+		{
+			name: "no sql",
+			row: Row{
+				"name": "noop", "type": "PROCEDURE", "sql_data_access": "NO_SQL", "security_type": "DEFINER",
+				"body": []byte("BEGIN END"), "definer": "root@localhost",
+			},
+			want: "CREATE DEFINER=`root`@`localhost` PROCEDURE `noop`()\n    NO SQL\nBEGIN END",
+		},
+		{
+			name: "modifies sql data",
+			row: Row{
+				"name": "purge", "type": "PROCEDURE", "sql_data_access": "MODIFIES_SQL_DATA", "security_type": "DEFINER",
+				"body": []byte("DELETE FROM t"), "definer": "root@localhost",
+			},
+			want: "CREATE DEFINER=`root`@`localhost` PROCEDURE `purge`()\n    MODIFIES SQL DATA\nDELETE FROM t",
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if got := NewRoutine(c.row).String(); got != c.want {
+				t.Errorf("got\n%s\nwant\n%s", got, c.want)
+			}
+		})
+	}
+}
+
+func TestRoutineDump(t *testing.T) {
+	r := NewRoutine(Row{
+		"name": "noop", "type": "PROCEDURE", "sql_data_access": "CONTAINS_SQL", "security_type": "DEFINER",
+		"body": []byte("BEGIN END"), "definer": "root@localhost", "sql_mode": "STRICT_TRANS_TABLES",
+		"character_set_client": "utf8mb4", "collation_connection": "utf8mb4_general_ci",
+	})
+	dump := r.Dump()
+	for _, want := range []string{
+		"/*!50003 SET character_set_client  = utf8mb4 */ ;\n",
+		"/*!50003 SET collation_connection  = utf8mb4_general_ci */ ;\n",
+		"/*!50003 SET sql_mode              = 'STRICT_TRANS_TABLES' */ ;\n" +
+			"DELIMITER ;;\nCREATE DEFINER=`root`@`localhost` PROCEDURE `noop`()\nBEGIN END ;;\nDELIMITER ;\n" +
+			"/*!50003 SET sql_mode              = @saved_sql_mode */ ;\n",
+	} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("dump without %q:\n%s", want, dump)
+		}
+	}
+	if strings.Contains(dump, "time_zone") {
+		t.Errorf("routine dump sets the time zone:\n%s", dump)
+	}
+}
@@ -0,0 +1,122 @@
+package mysqldb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/zing22845/go-frm-parser/frm/myisam"
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+// Row is a row of a table of the mysql schema, the values by lower case
+// column name as decoded by table.RecordDecoder
+type Row map[string]interface{}
+
+// IsNull reports whether the column is NULL or missing
+func (r Row) IsNull(name string) bool {
+	return r[name] == nil
+}
+
+// Text returns the value of a column as a string, "" if NULL
+func (r Row) Text(name string) string {
+	switch v := r[name].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// Int returns the value of an integer column, 0 if NULL
+func (r Row) Int(name string) int64 {
+	switch v := r[name].(type) {
+	case int64:
+		return v
+	case uint64:
+		return int64(v)
+	case string:
+		n, _ := strconv.ParseInt(v, 10, 64)
+		return n
+	}
+	return 0
+}
+
+// ReadTable reads the rows of the MyISAM table name of the mysql schema
+// directory dir, from its .frm and .MYD files
+func ReadTable(dir, name string) (rows []Row, err error) {
+	path := filepath.Join(dir, name+".frm")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	mt, err := table.Parse(path, data)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(mt.Options.Engine, "MyISAM") {
+		return nil, fmt.Errorf("%s: engine %s is not MyISAM", path, mt.Options.Engine)
+	}
+	values, err := myisam.ReadDataFile(mt, filepath.Join(dir, name+".MYD"))
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range values {
+		row := make(Row, len(v))
+		for i, c := range mt.Columns.Items {
+			row[strings.ToLower(c.Name)] = v[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// splitDefiner splits a user@host definer at the last @
+func splitDefiner(definer string) (user, host string) {
+	i := strings.LastIndex(definer, "@")
+	if i < 0 {
+		return definer, ""
+	}
+	return definer[:i], definer[i+1:]
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// definerClause is DEFINER=`user`@`host` as written by append_definer
+func definerClause(definer string) string {
+	user, host := splitDefiner(definer)
+	return fmt.Sprintf("DEFINER=%s@%s", quoteIdentifier(user), quoteIdentifier(host))
+}
+
+// sessionContext returns the statements mysqldump writes around a stored
+// program to restore the session it was created in, terminated by
+// delimiter
+func sessionContext(characterSetClient, collationConnection, sqlMode, timeZone, delimiter string) (set, restore string) {
+	var s, r strings.Builder
+	fmt.Fprintf(&s, "/*!50003 SET @saved_cs_client      = @@character_set_client */ %s\n", delimiter)
+	fmt.Fprintf(&s, "/*!50003 SET @saved_cs_results     = @@character_set_results */ %s\n", delimiter)
+	fmt.Fprintf(&s, "/*!50003 SET @saved_col_connection = @@collation_connection */ %s\n", delimiter)
+	fmt.Fprintf(&s, "/*!50003 SET character_set_client  = %s */ %s\n", characterSetClient, delimiter)
+	fmt.Fprintf(&s, "/*!50003 SET character_set_results = %s */ %s\n", characterSetClient, delimiter)
+	fmt.Fprintf(&s, "/*!50003 SET collation_connection  = %s */ %s\n", collationConnection, delimiter)
+	fmt.Fprintf(&s, "/*!50003 SET @saved_sql_mode       = @@sql_mode */ %s\n", delimiter)
+	fmt.Fprintf(&s, "/*!50003 SET sql_mode              = %s */ %s\n", table.QuoteString(sqlMode), delimiter)
+	if timeZone != "" {
+		fmt.Fprintf(&s, "/*!50003 SET @saved_time_zone      = @@time_zone */ %s\n", delimiter)
+		fmt.Fprintf(&s, "/*!50003 SET time_zone             = %s */ %s\n", table.QuoteString(timeZone), delimiter)
+		fmt.Fprintf(&r, "/*!50003 SET time_zone             = @saved_time_zone */ %s\n", delimiter)
+	}
+	fmt.Fprintf(&r, "/*!50003 SET sql_mode              = @saved_sql_mode */ %s\n", delimiter)
+	fmt.Fprintf(&r, "/*!50003 SET character_set_client  = @saved_cs_client */ %s\n", delimiter)
+	fmt.Fprintf(&r, "/*!50003 SET character_set_results = @saved_cs_results */ %s\n", delimiter)
+	fmt.Fprintf(&r, "/*!50003 SET collation_connection  = @saved_col_connection */ %s\n", delimiter)
+	return s.String(), r.String()
+}
@@ -0,0 +1,179 @@
+package sdi
+
+import "github.com/zing22845/go-frm-parser/frm/table"
+
+// ColumnType represents dd::enum_column_types
+type ColumnType int
+
+const (
+	CT_DECIMAL ColumnType = iota + 1
+	CT_TINY
+	CT_SHORT
+	CT_LONG
+	CT_FLOAT
+	CT_DOUBLE
+	CT_TYPE_NULL
+	CT_TIMESTAMP
+	CT_LONGLONG
+	CT_INT24
+	CT_DATE
+	CT_TIME
+	CT_DATETIME
+	CT_YEAR
+	CT_NEWDATE
+	CT_VARCHAR
+	CT_BIT
+	CT_TIMESTAMP2
+	CT_DATETIME2
+	CT_TIME2
+	CT_NEWDECIMAL
+	CT_ENUM
+	CT_SET
+	CT_TINY_BLOB
+	CT_MEDIUM_BLOB
+	CT_LONG_BLOB
+	CT_BLOB
+	CT_VAR_STRING
+	CT_STRING
+	CT_GEOMETRY
+	CT_JSON
+)
+
+var columnTypeMap = map[ColumnType]table.MySQLType{
+	CT_DECIMAL:     table.MT_DECIMAL,
+	CT_TINY:        table.MT_TINY,
+	CT_SHORT:       table.MT_SHORT,
+	CT_LONG:        table.MT_LONG,
+	CT_FLOAT:       table.MT_FLOAT,
+	CT_DOUBLE:      table.MT_DOUBLE,
+	CT_TYPE_NULL:   table.MT_NULL,
+	CT_TIMESTAMP:   table.MT_TIMESTAMP,
+	CT_LONGLONG:    table.MT_LONGLONG,
+	CT_INT24:       table.MT_INT24,
+	CT_DATE:        table.MT_DATE,
+	CT_TIME:        table.MT_TIME,
+	CT_DATETIME:    table.MT_DATETIME,
+	CT_YEAR:        table.MT_YEAR,
+	CT_NEWDATE:     table.MT_NEWDATE,
+	CT_VARCHAR:     table.MT_VARCHAR,
+	CT_BIT:         table.MT_BIT,
+	CT_TIMESTAMP2:  table.MT_TIMESTAMP2,
+	CT_DATETIME2:   table.MT_DATETIME2,
+	CT_TIME2:       table.MT_TIME2,
+	CT_NEWDECIMAL:  table.MT_NEWDECIMAL,
+	CT_ENUM:        table.MT_ENUM,
+	CT_SET:         table.MT_SET,
+	CT_TINY_BLOB:   table.MT_TINY_BLOB,
+	CT_MEDIUM_BLOB: table.MT_MEDIUM_BLOB,
+	CT_LONG_BLOB:   table.MT_LONG_BLOB,
+	CT_BLOB:        table.MT_BLOB,
+	CT_VAR_STRING:  table.MT_VAR_STRING,
+	CT_STRING:      table.MT_STRING,
+	CT_GEOMETRY:    table.MT_GEOMETRY,
+	CT_JSON:        table.MT_JSON,
+}
+
+// MySQLType maps the dd column type to the .frm field type
+func (ct ColumnType) MySQLType() (table.MySQLType, bool) {
+	mt, ok := columnTypeMap[ct]
+	return mt, ok
+}
+
+// HiddenType represents dd::Column::enum_hidden_type
+type HiddenType int
+
+const (
+	HT_VISIBLE HiddenType = iota + 1
+	HT_HIDDEN_SE
+	HT_HIDDEN_SQL
+	HT_HIDDEN_USER
+)
+
+// IndexType represents dd::Index::enum_index_type
+type IndexType int
+
+const (
+	IT_PRIMARY IndexType = iota + 1
+	IT_UNIQUE
+	IT_MULTIPLE
+	IT_FULLTEXT
+	IT_SPATIAL
+)
+
+// IndexAlgorithm represents dd::Index::enum_index_algorithm
+type IndexAlgorithm int
+
+const (
+	IA_SE_SPECIFIC IndexAlgorithm = iota + 1
+	IA_BTREE
+	IA_RTREE
+	IA_HASH
+	IA_FULLTEXT
+)
+
+// ElementOrder represents dd::Index_element::enum_index_element_order
+type ElementOrder int
+
+const (
+	ORDER_UNDEF ElementOrder = iota + 1
+	ORDER_ASC
+	ORDER_DESC
+)
+
+// ForeignKeyRule represents dd::Foreign_key::enum_rule
+type ForeignKeyRule int
+
+const (
+	RULE_NO_ACTION ForeignKeyRule = iota + 1
+	RULE_RESTRICT
+	RULE_CASCADE
+	RULE_SET_NULL
+	RULE_SET_DEFAULT
+)
+
+// String returns the rule as printed by SHOW CREATE TABLE,
+// the default NO ACTION/RESTRICT rules are not printed
+func (r ForeignKeyRule) String() string {
+	switch r {
+	case RULE_CASCADE:
+		return "CASCADE"
+	case RULE_SET_NULL:
+		return "SET NULL"
+	case RULE_SET_DEFAULT:
+		return "SET DEFAULT"
+	default:
+		return ""
+	}
+}
+
+// PartitionType represents dd::Table::enum_partition_type
+type PartitionType int
+
+const (
+	PT_NONE PartitionType = iota
+	PT_HASH
+	PT_KEY_51
+	PT_KEY_55
+	PT_LINEAR_HASH
+	PT_LINEAR_KEY_51
+	PT_LINEAR_KEY_55
+	PT_RANGE
+	PT_LIST
+	PT_RANGE_COLUMNS
+	PT_LIST_COLUMNS
+	PT_AUTO
+	PT_AUTO_LINEAR
+)
+
+// DefaultPartitioning represents dd::Table::enum_default_partitioning
+type DefaultPartitioning int
+
+const (
+	DP_NONE DefaultPartitioning = iota
+	DP_NO
+	DP_YES
+	DP_NUMBER
+)
+
+// UTF8MB4_DEFAULT_COLLATION_ID is utf8mb4_0900_ai_ci, the default collation of utf8mb4 since MySQL 8.0
+const UTF8MB4_DEFAULT_COLLATION_ID = 255
@@ -0,0 +1,147 @@
+package sdi
+
+import "encoding/json"
+
+// Document is the envelope of a serialized dictionary object, as stored in
+// the SDI of a tablespace or in a standalone .sdi file
+type Document struct {
+	MySQLDVersionID uint32          `json:"mysqld_version_id"`
+	DDVersion       uint32          `json:"dd_version"`
+	SDIVersion      uint64          `json:"sdi_version"`
+	DDObjectType    string          `json:"dd_object_type"`
+	DDObject        json.RawMessage `json:"dd_object"`
+}
+
+// Table is the dd::Table object
+type Table struct {
+	Name                       string              `json:"name"`
+	MySQLVersionID             uint32              `json:"mysql_version_id"`
+	Created                    uint64              `json:"created"`
+	LastAltered                uint64              `json:"last_altered"`
+	Hidden                     int                 `json:"hidden"`
+	Options                    string              `json:"options"`
+	Columns                    []*Column           `json:"columns"`
+	SchemaRef                  string              `json:"schema_ref"`
+	SEPrivateID                uint64              `json:"se_private_id"`
+	Engine                     string              `json:"engine"`
+	Comment                    string              `json:"comment"`
+	SEPrivateData              string              `json:"se_private_data"`
+	RowFormat                  int                 `json:"row_format"`
+	PartitionType              PartitionType       `json:"partition_type"`
+	PartitionExpressionUTF8    string              `json:"partition_expression_utf8"`
+	DefaultPartitioning        DefaultPartitioning `json:"default_partitioning"`
+	SubpartitionType           PartitionType       `json:"subpartition_type"`
+	SubpartitionExpressionUTF8 string              `json:"subpartition_expression_utf8"`
+	DefaultSubpartitioning     DefaultPartitioning `json:"default_subpartitioning"`
+	Indexes                    []*Index            `json:"indexes"`
+	ForeignKeys                []*ForeignKey       `json:"foreign_keys"`
+	Partitions                 []*Partition        `json:"partitions"`
+	CollationID                int                 `json:"collation_id"`
+}
+
+// Column is the dd::Column object
+type Column struct {
+	Name                     string     `json:"name"`
+	Type                     ColumnType `json:"type"`
+	IsNullable               bool       `json:"is_nullable"`
+	IsZerofill               bool       `json:"is_zerofill"`
+	IsUnsigned               bool       `json:"is_unsigned"`
+	IsAutoIncrement          bool       `json:"is_auto_increment"`
+	IsVirtual                bool       `json:"is_virtual"`
+	Hidden                   HiddenType `json:"hidden"`
+	OrdinalPosition          int        `json:"ordinal_position"`
+	CharLength               uint32     `json:"char_length"`
+	NumericPrecision         uint32     `json:"numeric_precision"`
+	NumericScale             uint32     `json:"numeric_scale"`
+	DatetimePrecision        uint32     `json:"datetime_precision"`
+	HasNoDefault             bool       `json:"has_no_default"`
+	DefaultValueNull         bool       `json:"default_value_null"`
+	DefaultValueUTF8Null     bool       `json:"default_value_utf8_null"`
+	DefaultValueUTF8         string     `json:"default_value_utf8"`
+	DefaultOption            string     `json:"default_option"`
+	UpdateOption             string     `json:"update_option"`
+	Comment                  string     `json:"comment"`
+	GenerationExpressionUTF8 string     `json:"generation_expression_utf8"`
+	Options                  string     `json:"options"`
+	SEPrivateData            string     `json:"se_private_data"`
+	ColumnTypeUTF8           string     `json:"column_type_utf8"`
+	Elements                 []*Element `json:"elements"`
+	CollationID              int        `json:"collation_id"`
+	IsExplicitCollation      bool       `json:"is_explicit_collation"`
+}
+
+// Element is an ENUM or SET value, the name is base64 encoded
+type Element struct {
+	Name  []byte `json:"name"`
+	Index int    `json:"index"`
+}
+
+// Index is the dd::Index object
+type Index struct {
+	Name                string          `json:"name"`
+	Hidden              bool            `json:"hidden"`
+	IsGenerated         bool            `json:"is_generated"`
+	OrdinalPosition     int             `json:"ordinal_position"`
+	Comment             string          `json:"comment"`
+	Options             string          `json:"options"`
+	SEPrivateData       string          `json:"se_private_data"`
+	Type                IndexType       `json:"type"`
+	Algorithm           IndexAlgorithm  `json:"algorithm"`
+	IsAlgorithmExplicit bool            `json:"is_algorithm_explicit"`
+	IsVisible           bool            `json:"is_visible"`
+	Engine              string          `json:"engine"`
+	Elements            []*IndexElement `json:"elements"`
+}
+
+// IndexElement is the dd::Index_element object
+type IndexElement struct {
+	OrdinalPosition int          `json:"ordinal_position"`
+	Length          uint32       `json:"length"`
+	Order           ElementOrder `json:"order"`
+	Hidden          bool         `json:"hidden"`
+	ColumnOpx       int          `json:"column_opx"`
+}
+
+// ForeignKey is the dd::Foreign_key object
+type ForeignKey struct {
+	Name                       string               `json:"name"`
+	MatchOption                int                  `json:"match_option"`
+	UpdateRule                 ForeignKeyRule       `json:"update_rule"`
+	DeleteRule                 ForeignKeyRule       `json:"delete_rule"`
+	UniqueConstraintName       string               `json:"unique_constraint_name"`
+	ReferencedTableCatalogName string               `json:"referenced_table_catalog_name"`
+	ReferencedTableSchemaName  string               `json:"referenced_table_schema_name"`
+	ReferencedTableName        string               `json:"referenced_table_name"`
+	Elements                   []*ForeignKeyElement `json:"elements"`
+}
+
+// ForeignKeyElement is the dd::Foreign_key_element object
+type ForeignKeyElement struct {
+	ColumnOpx            int    `json:"column_opx"`
+	OrdinalPosition      int    `json:"ordinal_position"`
+	ReferencedColumnName string `json:"referenced_column_name"`
+}
+
+// Partition is the dd::Partition object
+type Partition struct {
+	Name              string            `json:"name"`
+	ParentPartitionID uint64            `json:"parent_partition_id"`
+	Number            int               `json:"number"`
+	SEPrivateID       uint64            `json:"se_private_id"`
+	DescriptionUTF8   string            `json:"description_utf8"`
+	Engine            string            `json:"engine"`
+	Comment           string            `json:"comment"`
+	Options           string            `json:"options"`
+	SEPrivateData     string            `json:"se_private_data"`
+	Values            []*PartitionValue `json:"values"`
+	Subpartitions     []*Partition      `json:"subpartitions"`
+}
+
+// PartitionValue is the dd::Partition_value object
+type PartitionValue struct {
+	MaxValue  bool   `json:"max_value"`
+	NullValue bool   `json:"null_value"`
+	ListNum   int    `json:"list_num"`
+	ColumnNum int    `json:"column_num"`
+	ValueUTF8 string `json:"value_utf8"`
+}
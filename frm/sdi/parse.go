@@ -0,0 +1,61 @@
+package sdi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/zing22845/go-frm-parser/frm/ibd"
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+const (
+	DD_OBJECT_TYPE_TABLE      = "Table"
+	DD_OBJECT_TYPE_TABLESPACE = "Tablespace"
+)
+
+// Decode decodes a serialized dd::Table JSON document into a MySQLTable
+func Decode(path string, data []byte) (*table.MySQLTable, error) {
+	doc := &Document{}
+	err := json.Unmarshal(data, doc)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid SDI document: %w", path, err)
+	}
+	if doc.DDObjectType != DD_OBJECT_TYPE_TABLE {
+		return nil, fmt.Errorf("%s: SDI object type is %s, not %s",
+			path, doc.DDObjectType, DD_OBJECT_TYPE_TABLE)
+	}
+	dd := &Table{}
+	err = json.Unmarshal(doc.DDObject, dd)
+	if err != nil {
+		return nil, fmt.Errorf("%s: decode dd::Table failed: %w", path, err)
+	}
+	return NewMySQLTable(dd)
+}
+
+// ParseIBD extracts the table definitions from the SDI of a MySQL 8.0
+// tablespace, a general tablespace can hold more than one table
+func ParseIBD(path string, r io.ReaderAt) (tables []*table.MySQLTable, err error) {
+	ts, err := ibd.NewTablespace(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	records, err := ts.ReadSDI()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, record := range records {
+		if record.Type != ibd.SDI_TYPE_TABLE {
+			continue
+		}
+		mt, err := Decode(path, record.Data)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, mt)
+	}
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("%s: no table found in SDI", path)
+	}
+	return tables, nil
+}
@@ -0,0 +1,57 @@
+package sdi
+
+import (
+	"os"
+	"testing"
+)
+
+// ordersSQL is what SHOW CREATE TABLE prints for test_frms/table_orders
+const ordersSQL = "CREATE TABLE `orders` (\n" +
+	"  `id` int unsigned NOT NULL AUTO_INCREMENT,\n" +
+	"  `customer_id` int NOT NULL,\n" +
+	"  `note` varchar(64) CHARACTER SET latin1 DEFAULT NULL COMMENT 'free text, it\\'s optional',\n" +
+	"  `status` enum('new','paid') NOT NULL DEFAULT 'new',\n" +
+	"  `created` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3),\n" +
+	"  PRIMARY KEY (`id`),\n" +
+	"  KEY `customer_idx` (`customer_id`),\n" +
+	"  KEY `note_idx` (`note`(10) DESC),\n" +
+	"  CONSTRAINT `orders_ibfk_1` FOREIGN KEY (`customer_id`) REFERENCES `customers` (`id`) ON DELETE CASCADE\n" +
+	") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='customer orders';\n"
+
+func TestDecode(t *testing.T) {
+	data, err := os.ReadFile("../../test_frms/table_orders.sdi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mt, err := Decode("table_orders.sdi", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mt.String(); got != "\n"+ordersSQL {
+		t.Errorf("got\n%s\nwant\n%s", got, ordersSQL)
+	}
+	if mt.MySQLVersion.String() != "8.0.32" {
+		t.Errorf("version %s", mt.MySQLVersion)
+	}
+	if _, err := Decode("x.sdi", []byte(`{"dd_object_type": "Tablespace", "dd_object": {}}`)); err == nil {
+		t.Error("a tablespace document decoded as a table")
+	}
+}
+
+func TestParseIBD(t *testing.T) {
+	file, err := os.Open("../../test_frms/table_orders.ibd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	tables, err := ParseIBD("table_orders.ibd", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 1 {
+		t.Fatalf("got %d tables, want 1", len(tables))
+	}
+	if got := tables[0].String(); got != "\n"+ordersSQL {
+		t.Errorf("got\n%s\nwant\n%s", got, ordersSQL)
+	}
+}
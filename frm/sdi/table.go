@@ -0,0 +1,478 @@
+package sdi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+)
+
+// tableDecoder maps a dd::Table into a table.MySQLTable
+type tableDecoder struct {
+	dd         *Table
+	mt         *table.MySQLTable
+	collations map[int]*table.Collation
+	// columns by ordinal position in dd, including hidden ones
+	// which are referred to by indexes
+	columns []*table.Column
+}
+
+// NewMySQLTable maps a dd::Table into the same model table.Parse
+// builds from a .frm, so it renders the same way
+func NewMySQLTable(dd *Table) (mt *table.MySQLTable, err error) {
+	d := &tableDecoder{
+		dd:         dd,
+		collations: make(map[int]*table.Collation),
+	}
+	return d.decode()
+}
+
+func (d *tableDecoder) decode() (mt *table.MySQLTable, err error) {
+	d.mt = &table.MySQLTable{
+		Name:         d.dd.Name,
+		MySQLVersion: table.NewMySQLVersionFromID(d.dd.MySQLVersionID),
+	}
+	mt = d.mt
+	mt.Collation, err = d.collation(d.dd.CollationID)
+	if err != nil {
+		return nil, err
+	}
+	err = d.decodeOptions()
+	if err != nil {
+		return nil, err
+	}
+	err = d.decodeColumns()
+	if err != nil {
+		return nil, err
+	}
+	err = d.decodeKeys()
+	if err != nil {
+		return nil, err
+	}
+	err = d.decodeForeignKeys()
+	if err != nil {
+		return nil, err
+	}
+	err = d.decodePartitions()
+	if err != nil {
+		return nil, err
+	}
+	return mt, nil
+}
+
+// collation returns the collation with the MySQL 8.0 default,
+// utf8mb4_0900_ai_ci instead of utf8mb4_general_ci
+func (d *tableDecoder) collation(id int) (*table.Collation, error) {
+	if c, ok := d.collations[id]; ok {
+		return c, nil
+	}
+	c, err := table.GetCollationByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if c.CharsetName == "utf8mb4" {
+		c80 := *c
+		c80.IsDefault = c.ID == UTF8MB4_DEFAULT_COLLATION_ID
+		c = &c80
+	}
+	d.collations[id] = c
+	return c, nil
+}
+
+// ParseOptions splits a dd options string, e.g. "avg_row_length=0;key_block_size=0;"
+func ParseOptions(options string) map[string]string {
+	result := make(map[string]string)
+	for _, option := range strings.Split(options, ";") {
+		parts := strings.SplitN(option, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		result[parts[0]] = parts[1]
+	}
+	return result
+}
+
+func (d *tableDecoder) decodeOptions() error {
+	o := &table.Options{
+		Engine:    d.dd.Engine,
+		Collation: d.mt.Collation,
+		Comment:   d.dd.Comment,
+		RowFormat: table.RowType(d.dd.RowFormat),
+	}
+	for key, value := range ParseOptions(d.dd.Options) {
+		n, _ := strconv.ParseUint(value, 10, 64)
+		switch key {
+		case "avg_row_length":
+			o.AvgRowLength = uint32(n)
+		case "max_rows":
+			o.MaxRows = uint32(n)
+		case "min_rows":
+			o.MinRows = uint32(n)
+		case "key_block_size":
+			o.KeyBlockSize = uint16(n)
+		case "row_type":
+			o.RowFormat = table.RowType(n)
+		case "pack_record":
+			if n != 0 {
+				o.HandlerOptions |= table.HO_PACK_RECORD
+			}
+		case "checksum":
+			if n != 0 {
+				o.HandlerOptions |= table.HO_CHECKSUM
+			}
+		case "delay_key_write":
+			if n != 0 {
+				o.HandlerOptions |= table.HO_DELAY_KEY_WRITE
+			}
+		case "data_file_name":
+			o.DataDirectory = value
+		case "index_file_name":
+			o.IndexDirectory = value
+		}
+	}
+	d.mt.Options = o
+	return nil
+}
+
+func (d *tableDecoder) decodeColumns() error {
+	cs := &table.Columns{
+		Items: make([]*table.Column, 0, len(d.dd.Columns)),
+	}
+	d.columns = make([]*table.Column, len(d.dd.Columns))
+	combined := make([]string, 0, len(d.dd.Columns))
+	for i, ddc := range d.dd.Columns {
+		c, err := d.decodeColumn(len(cs.Items), ddc)
+		if err != nil {
+			return err
+		}
+		d.columns[i] = c
+		if ddc.Hidden == HT_HIDDEN_SE || ddc.Hidden == HT_HIDDEN_SQL {
+			continue
+		}
+		if c.Flags.HasFlag(table.FF_MAYBE_NULL) {
+			cs.NullCount++
+		}
+		cs.Items = append(cs.Items, c)
+		combined = append(combined, "  "+c.String())
+	}
+	cs.Count = uint16(len(cs.Items))
+	cs.Combined = strings.Join(combined, ",\n")
+	d.mt.Columns = cs
+	return nil
+}
+
+func (d *tableDecoder) decodeColumn(number int, ddc *Column) (c *table.Column, err error) {
+	typeCode, ok := ddc.Type.MySQLType()
+	if !ok {
+		return nil, fmt.Errorf("column %s: unknown dd column type %d", ddc.Name, ddc.Type)
+	}
+	c = &table.Column{
+		Name:           ddc.Name,
+		Number:         number,
+		TypeCode:       typeCode,
+		TypeName:       ddc.ColumnTypeUTF8,
+		Length:         uint16(ddc.CharLength),
+		Comment:        ddc.Comment,
+		TableCollation: d.mt.Collation,
+		Scale:          table.FieldFlag(ddc.NumericScale),
+	}
+	c.Collation, err = d.collation(ddc.CollationID)
+	if err != nil {
+		return nil, err
+	}
+	if ddc.IsNullable {
+		c.Flags |= table.FF_MAYBE_NULL
+	}
+	if !ddc.IsUnsigned {
+		c.Flags |= table.FF_DECIMAL
+	}
+	if ddc.IsZerofill {
+		c.Flags |= table.FF_ZEROFILL
+	}
+	if ddc.HasNoDefault {
+		c.Flags |= table.FF_NO_DEFAULT
+	}
+	isBlob := isBlobType(typeCode)
+	switch {
+	case ddc.IsAutoIncrement:
+		c.Utype = table.UT_NEXT_NUMBER
+	case isBlob:
+		c.Utype = table.UT_BLOB_FIELD
+	}
+	if len(ddc.Elements) > 0 {
+		c.LabelStrs = make([]string, len(ddc.Elements))
+		for i, e := range ddc.Elements {
+			c.LabelStrs[i] = string(e.Name)
+		}
+	}
+
+	// charset and collation of character columns
+	if table.HasCharset(typeCode) && c.Collation.CharsetName != "binary" {
+		if c.Collation != c.TableCollation {
+			c.TypeName += " CHARACTER SET " + c.Collation.CharsetName
+		}
+		if !c.Collation.IsDefault {
+			c.TypeName += " COLLATE " + c.Collation.Name
+		}
+	}
+	if ddc.GenerationExpressionUTF8 != "" {
+		storage := "STORED"
+		if ddc.IsVirtual {
+			storage = "VIRTUAL"
+		}
+		c.TypeName += fmt.Sprintf(" GENERATED ALWAYS AS (%s) %s", ddc.GenerationExpressionUTF8, storage)
+	}
+	if !ddc.IsNullable {
+		c.TypeName += " NOT NULL"
+	} else if typeCode == table.MT_TIMESTAMP || typeCode == table.MT_TIMESTAMP2 {
+		c.TypeName += " NULL"
+	}
+	if ddc.Hidden == HT_HIDDEN_USER {
+		c.TypeName += " /*!80023 INVISIBLE */"
+	}
+	if ddc.IsAutoIncrement {
+		c.TypeName += " AUTO_INCREMENT"
+	}
+	c.Default = decodeDefault(ddc, typeCode, isBlob)
+	return c, nil
+}
+
+func decodeDefault(ddc *Column, typeCode table.MySQLType, isBlob bool) (value string) {
+	if ddc.IsAutoIncrement || ddc.GenerationExpressionUTF8 != "" {
+		return ""
+	}
+	switch {
+	case ddc.DefaultOption != "":
+		value = ddc.DefaultOption
+		if !strings.HasPrefix(strings.ToUpper(value), "CURRENT_TIMESTAMP") {
+			// expression default since 8.0.13
+			value = "(" + value + ")"
+		}
+	case ddc.HasNoDefault:
+		return ""
+	case ddc.DefaultValueUTF8Null || ddc.DefaultValueNull:
+		if !ddc.IsNullable || isBlob {
+			return ""
+		}
+		value = "NULL"
+	case typeCode == table.MT_BIT && strings.HasPrefix(ddc.DefaultValueUTF8, "b'"):
+		value = ddc.DefaultValueUTF8
+	default:
+		value = "'" + strings.ReplaceAll(ddc.DefaultValueUTF8, "'", "''") + "'"
+	}
+	if ddc.UpdateOption != "" {
+		value += " ON UPDATE " + ddc.UpdateOption
+	}
+	return value
+}
+
+func isBlobType(typeCode table.MySQLType) bool {
+	switch typeCode {
+	case table.MT_TINY_BLOB, table.MT_MEDIUM_BLOB, table.MT_LONG_BLOB, table.MT_BLOB,
+		table.MT_JSON, table.MT_GEOMETRY:
+		return true
+	}
+	return false
+}
+
+func (d *tableDecoder) decodeKeys() error {
+	ks := &table.Keys{}
+	combined := make([]string, 0, len(d.dd.Indexes))
+	for _, ddi := range d.dd.Indexes {
+		if ddi.Hidden {
+			continue
+		}
+		k := &table.Key{
+			Name:      ddi.Name,
+			Comment:   ddi.Comment,
+			Invisible: !ddi.IsVisible,
+			Keys:      ks,
+			Columns:   d.mt.Columns,
+		}
+		switch ddi.Type {
+		case IT_PRIMARY:
+			k.IsUnique = true
+			k.IndexType = "BTREE"
+		case IT_UNIQUE:
+			k.IsUnique = true
+			k.IndexType = "BTREE"
+		case IT_FULLTEXT:
+			k.IndexType = "FULLTEXT"
+		case IT_SPATIAL:
+			k.IndexType = "SPATIAL"
+		default:
+			k.IndexType = "BTREE"
+		}
+		if ddi.IsAlgorithmExplicit {
+			switch ddi.Algorithm {
+			case IA_BTREE:
+				k.Algorithm = table.HA_KEY_ALG_BTREE
+			case IA_HASH:
+				k.Algorithm = table.HA_KEY_ALG_HASH
+				k.IndexType = "HASH"
+			}
+		}
+		options := ParseOptions(ddi.Options)
+		k.Parser = options["parser_name"]
+		if blockSize, err := strconv.ParseUint(options["block_size"], 10, 16); err == nil {
+			k.BlockSize = uint16(blockSize)
+		}
+		for _, e := range ddi.Elements {
+			if e.Hidden {
+				continue
+			}
+			if e.ColumnOpx < 0 || e.ColumnOpx >= len(d.columns) {
+				return fmt.Errorf("index %s: column %d out of range", ddi.Name, e.ColumnOpx)
+			}
+			ddc := d.dd.Columns[e.ColumnOpx]
+			part := &table.KeyPart{
+				Column:     d.columns[e.ColumnOpx],
+				Length:     uint16(e.Length),
+				Descending: e.Order == ORDER_DESC,
+			}
+			if ddc.Hidden == HT_HIDDEN_SQL {
+				part.Expression = ddc.GenerationExpressionUTF8
+			}
+			if e.Length == 0xFFFFFFFF || e.Length >= ddc.CharLength {
+				// full column length
+				part.Length = uint16(ddc.CharLength)
+			}
+			k.Parts = append(k.Parts, part)
+		}
+		k.PartsCount = uint8(len(k.Parts))
+		ks.Items = append(ks.Items, k)
+		ks.Names = append(ks.Names, k.Name)
+		combined = append(combined, "  "+k.String())
+	}
+	ks.Count = uint8(len(ks.Items))
+	ks.Combined = strings.Join(combined, ",\n")
+	d.mt.Keys = ks
+	return nil
+}
+
+func (d *tableDecoder) decodeForeignKeys() error {
+	for _, ddfk := range d.dd.ForeignKeys {
+		fk := &table.ForeignKey{
+			Name:            ddfk.Name,
+			ReferencedTable: ddfk.ReferencedTableName,
+			OnDelete:        ddfk.DeleteRule.String(),
+			OnUpdate:        ddfk.UpdateRule.String(),
+		}
+		if ddfk.ReferencedTableSchemaName != d.dd.SchemaRef {
+			fk.ReferencedSchema = ddfk.ReferencedTableSchemaName
+		}
+		for _, e := range ddfk.Elements {
+			if e.ColumnOpx < 0 || e.ColumnOpx >= len(d.dd.Columns) {
+				return fmt.Errorf("foreign key %s: column %d out of range", ddfk.Name, e.ColumnOpx)
+			}
+			fk.Columns = append(fk.Columns, d.dd.Columns[e.ColumnOpx].Name)
+			fk.ReferencedColumns = append(fk.ReferencedColumns, e.ReferencedColumnName)
+		}
+		d.mt.ForeignKeys = append(d.mt.ForeignKeys, fk)
+	}
+	return nil
+}
+
+// decodePartitions builds the PARTITION BY clause the way the .frm stores
+// it and parses it into the Partitioning model
+func (d *tableDecoder) decodePartitions() (err error) {
+	if d.dd.PartitionType == PT_NONE || d.dd.PartitionType >= PT_AUTO {
+		return nil
+	}
+	var sb strings.Builder
+	sb.WriteString(" PARTITION BY ")
+	sb.WriteString(partitionMethodString(d.dd.PartitionType, d.dd.PartitionExpressionUTF8))
+	defaultParts := d.dd.DefaultPartitioning == DP_YES || d.dd.DefaultPartitioning == DP_NUMBER
+	if defaultParts {
+		sb.WriteString(fmt.Sprintf("\nPARTITIONS %d", len(d.dd.Partitions)))
+	}
+	defaultSubParts := true
+	if d.dd.SubpartitionType != PT_NONE {
+		sb.WriteString("\nSUBPARTITION BY ")
+		sb.WriteString(partitionMethodString(d.dd.SubpartitionType, d.dd.SubpartitionExpressionUTF8))
+		defaultSubParts = d.dd.DefaultSubpartitioning == DP_YES || d.dd.DefaultSubpartitioning == DP_NUMBER
+		if defaultSubParts && len(d.dd.Partitions) > 0 {
+			sb.WriteString(fmt.Sprintf("\nSUBPARTITIONS %d", len(d.dd.Partitions[0].Subpartitions)))
+		}
+	}
+	if !defaultParts {
+		defs := make([]string, len(d.dd.Partitions))
+		for i, p := range d.dd.Partitions {
+			defs[i] = partitionDefinitionString(d.dd.PartitionType, p, !defaultSubParts)
+		}
+		sb.WriteString("\n(" + strings.Join(defs, ",\n ") + ")")
+	}
+	d.mt.Options.Partitions = sb.String()
+	// the raw clause is kept when the model can't be parsed
+	_ = d.mt.Options.DecodePartitioning()
+	return nil
+}
+
+func partitionMethodString(pt PartitionType, expr string) string {
+	switch pt {
+	case PT_HASH:
+		return fmt.Sprintf("HASH (%s)", expr)
+	case PT_LINEAR_HASH:
+		return fmt.Sprintf("LINEAR HASH (%s)", expr)
+	case PT_KEY_51:
+		return fmt.Sprintf("KEY ALGORITHM = 1 (%s)", expr)
+	case PT_KEY_55:
+		return fmt.Sprintf("KEY (%s)", expr)
+	case PT_LINEAR_KEY_51:
+		return fmt.Sprintf("LINEAR KEY ALGORITHM = 1 (%s)", expr)
+	case PT_LINEAR_KEY_55:
+		return fmt.Sprintf("LINEAR KEY (%s)", expr)
+	case PT_RANGE:
+		return fmt.Sprintf("RANGE (%s)", expr)
+	case PT_LIST:
+		return fmt.Sprintf("LIST (%s)", expr)
+	case PT_RANGE_COLUMNS:
+		return fmt.Sprintf("RANGE  COLUMNS(%s)", expr)
+	case PT_LIST_COLUMNS:
+		return fmt.Sprintf("LIST  COLUMNS(%s)", expr)
+	}
+	return ""
+}
+
+func partitionDefinitionString(pt PartitionType, p *Partition, withSubPartitions bool) string {
+	var sb strings.Builder
+	sb.WriteString("PARTITION " + p.Name)
+	switch pt {
+	case PT_RANGE, PT_RANGE_COLUMNS:
+		if p.DescriptionUTF8 == "MAXVALUE" && pt == PT_RANGE {
+			sb.WriteString(" VALUES LESS THAN MAXVALUE")
+		} else {
+			sb.WriteString(fmt.Sprintf(" VALUES LESS THAN (%s)", p.DescriptionUTF8))
+		}
+	case PT_LIST, PT_LIST_COLUMNS:
+		sb.WriteString(fmt.Sprintf(" VALUES IN (%s)", p.DescriptionUTF8))
+	}
+	sb.WriteString(partitionOptionsString(p))
+	if withSubPartitions && len(p.Subpartitions) > 0 {
+		subs := make([]string, len(p.Subpartitions))
+		for i, sub := range p.Subpartitions {
+			subs[i] = "SUBPARTITION " + sub.Name + partitionOptionsString(sub)
+		}
+		sb.WriteString("\n (" + strings.Join(subs, ",\n  ") + ")")
+	}
+	return sb.String()
+}
+
+func partitionOptionsString(p *Partition) string {
+	var sb strings.Builder
+	options := ParseOptions(p.Options)
+	if value := options["data_file_name"]; value != "" {
+		sb.WriteString(fmt.Sprintf(" DATA DIRECTORY = '%s'", value))
+	}
+	if value := options["index_file_name"]; value != "" {
+		sb.WriteString(fmt.Sprintf(" INDEX DIRECTORY = '%s'", value))
+	}
+	if p.Comment != "" {
+		sb.WriteString(fmt.Sprintf(" COMMENT = '%s'", strings.ReplaceAll(p.Comment, "'", "''")))
+	}
+	if p.Engine != "" {
+		sb.WriteString(" ENGINE = " + p.Engine)
+	}
+	return sb.String()
+}
@@ -0,0 +1,343 @@
+package table
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// BinlogRowMetadata is the binlog_row_metadata setting, how much optional
+// metadata a TABLE_MAP_EVENT carries in MySQL 8.0
+type BinlogRowMetadata uint8
+
+const (
+	BINLOG_ROW_METADATA_MINIMAL BinlogRowMetadata = iota
+	BINLOG_ROW_METADATA_FULL
+)
+
+// OptionalMetadataType is the type of an optional metadata field of a
+// TABLE_MAP_EVENT
+type OptionalMetadataType uint8
+
+const (
+	OMT_SIGNEDNESS OptionalMetadataType = iota + 1
+	OMT_DEFAULT_CHARSET
+	OMT_COLUMN_CHARSET
+	OMT_COLUMN_NAME
+	OMT_SET_STR_VALUE
+	OMT_ENUM_STR_VALUE
+	OMT_GEOMETRY_TYPE
+	OMT_SIMPLE_PRIMARY_KEY
+	OMT_PRIMARY_KEY_WITH_PREFIX
+	OMT_ENUM_AND_SET_DEFAULT_CHARSET
+	OMT_ENUM_AND_SET_COLUMN_CHARSET
+	OMT_COLUMN_VISIBILITY
+)
+
+// BinlogColumn is a column as a TABLE_MAP_EVENT describes it
+type BinlogColumn struct {
+	Column *Column
+	// Type is the type code of the column in the binlog, Field::binlog_type
+	Type MySQLType
+	// Metadata is written by Field::save_field_metadata
+	Metadata []byte
+	Nullable bool
+}
+
+// BinlogTableMap is the column part of the TABLE_MAP_EVENT of a table
+type BinlogTableMap struct {
+	Columns []*BinlogColumn
+	// ColumnTypes, ColumnMetadata and NullBitmap are the fields of the
+	// event body, ColumnMetadata without its length prefix
+	ColumnTypes    []byte
+	ColumnMetadata []byte
+	NullBitmap     []byte
+	// OptionalMetadata is the MySQL 8.0 optional metadata, type-length-value
+	// fields as written with binlog_row_metadata
+	OptionalMetadata []byte
+}
+
+// BinlogTableMap returns the column types and metadata a TABLE_MAP_EVENT
+// of the table would carry, with the optional metadata of MySQL 8.0
+func (mt *MySQLTable) BinlogTableMap(rowMetadata BinlogRowMetadata) (*BinlogTableMap, error) {
+	tm := &BinlogTableMap{
+		NullBitmap: make([]byte, (len(mt.Columns.Items)+7)/8),
+	}
+	for i, c := range mt.Columns.Items {
+		bc, err := c.BinlogColumn()
+		if err != nil {
+			return nil, err
+		}
+		tm.Columns = append(tm.Columns, bc)
+		tm.ColumnTypes = append(tm.ColumnTypes, byte(bc.Type))
+		tm.ColumnMetadata = append(tm.ColumnMetadata, bc.Metadata...)
+		if bc.Nullable {
+			tm.NullBitmap[i/8] |= 1 << (i % 8)
+		}
+	}
+	tm.OptionalMetadata = mt.binlogOptionalMetadata(tm.Columns, rowMetadata)
+	return tm, nil
+}
+
+// BinlogColumn returns the binlog type and metadata of the column
+func (c *Column) BinlogColumn() (*BinlogColumn, error) {
+	bc := &BinlogColumn{
+		Column:   c,
+		Type:     c.TypeCode,
+		Nullable: c.Flags.HasFlag(FF_MAYBE_NULL),
+	}
+	packLength, err := c.PackLength()
+	if err != nil {
+		return nil, err
+	}
+	switch c.TypeCode {
+	case MT_FLOAT, MT_DOUBLE:
+		bc.Metadata = []byte{byte(packLength)}
+	case MT_TIMESTAMP2:
+		bc.Metadata = []byte{byte(c.temporalScale(MAX_DATETIME_WIDTH))}
+	case MT_DATETIME2:
+		bc.Metadata = []byte{byte(c.temporalScale(MAX_DATETIME_WIDTH))}
+	case MT_TIME2:
+		bc.Metadata = []byte{byte(c.temporalScale(MAX_TIME_WIDTH))}
+	case MT_NEWDATE:
+		bc.Type = MT_DATE
+	case MT_VARCHAR:
+		bc.Metadata = make([]byte, 2)
+		binary.LittleEndian.PutUint16(bc.Metadata, c.Length)
+	case MT_BIT:
+		bc.Metadata = []byte{byte(c.Length % 8), byte(c.Length / 8)}
+	case MT_NEWDECIMAL:
+		bc.Metadata = []byte{byte(c.Precision()), byte(c.Scale)}
+	case MT_ENUM, MT_SET:
+		bc.Type = MT_STRING
+		bc.Metadata = []byte{byte(c.TypeCode), byte(packLength)}
+	case MT_STRING, MT_VAR_STRING:
+		bc.Type = MT_STRING
+		bc.Metadata = []byte{byte(c.TypeCode) ^ byte((c.Length&0x300)>>4), byte(c.Length)}
+	case MT_TINY_BLOB, MT_MEDIUM_BLOB, MT_LONG_BLOB, MT_BLOB:
+		bc.Type = MT_BLOB
+		bc.Metadata = []byte{byte(c.blobLengthBytes())}
+	case MT_JSON, MT_GEOMETRY:
+		bc.Metadata = []byte{byte(c.blobLengthBytes())}
+	}
+	return bc, nil
+}
+
+// PrimaryKey returns the primary key of the table, or the first unique
+// key of whole NOT NULL columns the server promotes to it, nil if there
+// is none
+func (mt *MySQLTable) PrimaryKey() *Key {
+	if mt.Keys == nil || len(mt.Keys.Items) == 0 {
+		return nil
+	}
+	key := mt.Keys.Items[0]
+	if key.Name == "PRIMARY" {
+		return key
+	}
+	if !key.IsUnique {
+		return nil
+	}
+	for _, part := range key.Parts {
+		if part.Column == nil || part.Column.Flags.HasFlag(FF_MAYBE_NULL) || part.isPrefix() {
+			return nil
+		}
+	}
+	return key
+}
+
+// isPrefix reports whether the key part indexes a prefix of its column
+func (part *KeyPart) isPrefix() bool {
+	keyPrefix, _ := part.Column.TypeCode.KeyPrefix()
+	return keyPrefix == KP_MAYBE && part.Length != part.Column.Length || keyPrefix == KP_ALWAYS
+}
+
+func (bc *BinlogColumn) isNumeric() bool {
+	switch bc.Type {
+	case MT_TINY, MT_SHORT, MT_INT24, MT_LONG, MT_LONGLONG, MT_NEWDECIMAL, MT_FLOAT, MT_DOUBLE:
+		return true
+	}
+	return false
+}
+
+func (bc *BinlogColumn) isCharacter() bool {
+	switch bc.Column.TypeCode {
+	case MT_STRING, MT_VAR_STRING, MT_VARCHAR, MT_TINY_BLOB, MT_MEDIUM_BLOB, MT_LONG_BLOB, MT_BLOB:
+		return true
+	}
+	return false
+}
+
+func (bc *BinlogColumn) isEnumOrSet() bool {
+	return bc.Column.TypeCode == MT_ENUM || bc.Column.TypeCode == MT_SET
+}
+
+// binlogOptionalMetadata follows Table_map_log_event::init_metadata_fields
+func (mt *MySQLTable) binlogOptionalMetadata(columns []*BinlogColumn, rowMetadata BinlogRowMetadata) []byte {
+	var buf []byte
+	// signedness, a bit per numeric column, 1 for unsigned
+	var signedness []byte
+	numeric := 0
+	for _, bc := range columns {
+		if !bc.isNumeric() {
+			continue
+		}
+		if numeric%8 == 0 {
+			signedness = append(signedness, 0)
+		}
+		if !bc.Column.Flags.HasFlag(FF_DECIMAL) {
+			signedness[numeric/8] |= 1 << (7 - numeric%8)
+		}
+		numeric++
+	}
+	if numeric != 0 {
+		buf = appendTLV(buf, OMT_SIGNEDNESS, signedness)
+	}
+	buf = appendCharsetField(buf, columns, (*BinlogColumn).isCharacter, OMT_DEFAULT_CHARSET, OMT_COLUMN_CHARSET)
+	var geometryTypes []byte
+	for _, bc := range columns {
+		if bc.Column.TypeCode == MT_GEOMETRY {
+			geometryTypes = appendPackedInteger(geometryTypes, uint64(bc.Column.SubTypeCode))
+		}
+	}
+	if geometryTypes != nil {
+		buf = appendTLV(buf, OMT_GEOMETRY_TYPE, geometryTypes)
+	}
+	if rowMetadata != BINLOG_ROW_METADATA_FULL {
+		return buf
+	}
+
+	var names []byte
+	for _, bc := range columns {
+		names = appendPackedString(names, bc.Column.Name)
+	}
+	buf = appendTLV(buf, OMT_COLUMN_NAME, names)
+	buf = appendCharsetField(buf, columns, (*BinlogColumn).isEnumOrSet,
+		OMT_ENUM_AND_SET_DEFAULT_CHARSET, OMT_ENUM_AND_SET_COLUMN_CHARSET)
+	for _, field := range []struct {
+		typeCode MySQLType
+		omt      OptionalMetadataType
+	}{{MT_SET, OMT_SET_STR_VALUE}, {MT_ENUM, OMT_ENUM_STR_VALUE}} {
+		var values []byte
+		for _, bc := range columns {
+			if bc.Column.TypeCode != field.typeCode {
+				continue
+			}
+			values = appendPackedInteger(values, uint64(len(bc.Column.LabelStrs)))
+			for _, label := range bc.Column.LabelStrs {
+				values = appendPackedString(values, label)
+			}
+		}
+		if values != nil {
+			buf = appendTLV(buf, field.omt, values)
+		}
+	}
+
+	if key := mt.PrimaryKey(); key != nil {
+		var simple, withPrefix []byte
+		hasPrefix := false
+		for _, part := range key.Parts {
+			var prefix uint64
+			if part.isPrefix() {
+				hasPrefix = true
+				maxlen := 1
+				if part.Column.Collation != nil && part.Column.Collation.Maxlen > 0 {
+					maxlen = part.Column.Collation.Maxlen
+				}
+				prefix = uint64(int(part.Length) / maxlen)
+			}
+			simple = appendPackedInteger(simple, uint64(part.Column.Number))
+			withPrefix = appendPackedInteger(withPrefix, uint64(part.Column.Number))
+			withPrefix = appendPackedInteger(withPrefix, prefix)
+		}
+		if hasPrefix {
+			buf = appendTLV(buf, OMT_PRIMARY_KEY_WITH_PREFIX, withPrefix)
+		} else {
+			buf = appendTLV(buf, OMT_SIMPLE_PRIMARY_KEY, simple)
+		}
+	}
+
+	// every column of a .frm is visible
+	visibility := make([]byte, (len(columns)+7)/8)
+	for i := range columns {
+		visibility[i/8] |= 1 << (7 - i%8)
+	}
+	return appendTLV(buf, OMT_COLUMN_VISIBILITY, visibility)
+}
+
+// appendCharsetField writes the collations of the columns selected by
+// include, as the most used one followed by the exceptions or as a list,
+// whichever is shorter
+func appendCharsetField(buf []byte, columns []*BinlogColumn, include func(*BinlogColumn) bool,
+	defaultType, columnType OptionalMetadataType) []byte {
+	var collations []uint64
+	counts := make(map[uint64]int)
+	for _, bc := range columns {
+		if !include(bc) {
+			continue
+		}
+		var id uint64
+		if bc.Column.Collation != nil {
+			id = uint64(bc.Column.Collation.ID)
+		}
+		collations = append(collations, id)
+		counts[id]++
+	}
+	if len(collations) == 0 {
+		return buf
+	}
+	ids := make([]uint64, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	mostUsed := ids[0]
+	for _, id := range ids {
+		if counts[id] > counts[mostUsed] {
+			mostUsed = id
+		}
+	}
+	defaultCharset := appendPackedInteger(nil, mostUsed)
+	var columnCharset []byte
+	for i, id := range collations {
+		if id != mostUsed {
+			defaultCharset = appendPackedInteger(defaultCharset, uint64(i))
+			defaultCharset = appendPackedInteger(defaultCharset, id)
+		}
+		columnCharset = appendPackedInteger(columnCharset, id)
+	}
+	if len(defaultCharset) < len(columnCharset) {
+		return appendTLV(buf, defaultType, defaultCharset)
+	}
+	return appendTLV(buf, columnType, columnCharset)
+}
+
+func appendTLV(buf []byte, t OptionalMetadataType, value []byte) []byte {
+	buf = append(buf, byte(t))
+	buf = appendPackedInteger(buf, uint64(len(value)))
+	return append(buf, value...)
+}
+
+// appendPackedInteger writes a length encoded integer, net_store_length
+func appendPackedInteger(buf []byte, n uint64) []byte {
+	switch {
+	case n < 251:
+		return append(buf, byte(n))
+	case n < 1<<16:
+		return append(buf, 0xfc, byte(n), byte(n>>8))
+	case n < 1<<24:
+		return append(buf, 0xfd, byte(n), byte(n>>8), byte(n>>16))
+	}
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, n)
+	return append(append(buf, 0xfe), b...)
+}
+
+func appendPackedString(buf []byte, s string) []byte {
+	buf = appendPackedInteger(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// String returns the name, binlog type and metadata of the column
+func (bc *BinlogColumn) String() string {
+	return fmt.Sprintf("`%s` type=%d meta=%x nullable=%t", bc.Column.Name, bc.Type, bc.Metadata, bc.Nullable)
+}
@@ -0,0 +1,124 @@
+package table
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestBinlogTableMap(t *testing.T) {
+	collation := func(name string) *Collation {
+		c, err := GetCollationByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	latin1, utf8mb4 := collation("latin1_swedish_ci"), collation("utf8mb4_general_ci")
+	id := &Column{Name: "id", TypeCode: MT_LONG, Length: 10}
+	tag := &Column{Name: "tag", TypeCode: MT_VARCHAR, Length: 32, Collation: utf8mb4}
+	mt := &MySQLTable{
+		Options: &Options{},
+		Columns: &Columns{Items: []*Column{
+			id,
+			{Name: "price", TypeCode: MT_NEWDECIMAL, Length: 12, Scale: 2, Flags: FF_DECIMAL | FF_MAYBE_NULL},
+			{Name: "f", TypeCode: MT_DOUBLE, Length: 22, Flags: FF_DECIMAL},
+			{Name: "name", TypeCode: MT_VARCHAR, Length: 1200, Collation: utf8mb4},
+			{Name: "code", TypeCode: MT_STRING, Length: 1020, Collation: utf8mb4},
+			{Name: "ts", TypeCode: MT_TIMESTAMP2, Length: 23},
+			{Name: "d", TypeCode: MT_NEWDATE, Length: 10},
+			{Name: "b", TypeCode: MT_BIT, Length: 10},
+			{Name: "e", TypeCode: MT_ENUM, LabelStrs: []string{"a", "b"}, Collation: utf8mb4},
+			{Name: "s", TypeCode: MT_SET, LabelStrs: []string{"x", "y", "z"}, Collation: utf8mb4},
+			{Name: "note", TypeCode: MT_BLOB, Collation: latin1, Flags: FF_MAYBE_NULL},
+			{Name: "j", TypeCode: MT_JSON},
+			{Name: "g", TypeCode: MT_GEOMETRY, SubTypeCode: 1},
+			tag,
+		}},
+		Keys: &Keys{Items: []*Key{
+			{Name: "PRIMARY", Parts: []*KeyPart{{Column: id, Length: 4}}, IsUnique: true},
+		}},
+	}
+	for i, c := range mt.Columns.Items {
+		c.Number = i
+	}
+	minimal := "0101" + "80" + // id is unsigned, price and f signed
+		"0203" + "2d" + "0208" + // utf8mb4, the third one latin1
+		"070101" // POINT
+	full := minimal +
+		"042d" + "026964" + "057072696365" + "0166" + "046e616d65" + "04636f6465" + "027473" +
+		"0164" + "0162" + "0165" + "0173" + "046e6f7465" + "016a" + "0167" + "03746167" +
+		"0a012d" +
+		"050703" + "0178" + "0179" + "017a" +
+		"060502" + "0161" + "0162" +
+		"080100" +
+		"0c02fffc"
+	for _, c := range []struct {
+		rowMetadata BinlogRowMetadata
+		want        string
+	}{
+		{BINLOG_ROW_METADATA_MINIMAL, minimal},
+		{BINLOG_ROW_METADATA_FULL, full},
+	} {
+		tm, err := mt.BinlogTableMap(c.rowMetadata)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, field := range []struct {
+			name string
+			got  []byte
+			want string
+		}{
+			{"types", tm.ColumnTypes, "03f6050ffe110a10fefefcf5ff0f"},
+			{"metadata", tm.ColumnMetadata, "0a02" + "08" + "b004" + "cefc" + "03" + "0201" + "f701" + "f801" +
+				"02" + "04" + "04" + "2000"},
+			{"null bitmap", tm.NullBitmap, "0204"},
+			{"optional metadata", tm.OptionalMetadata, c.want},
+		} {
+			if !bytes.Equal(field.got, mustDecodeHex(t, field.want)) {
+				t.Errorf("%d: %s %x, want %s", c.rowMetadata, field.name, field.got, field.want)
+			}
+		}
+	}
+
+	// a prefix of tag in the primary key
+	mt.Keys.Items[0].Parts = []*KeyPart{{Column: tag, Length: 16}, {Column: id, Length: 4}}
+	tm, err := mt.BinlogTableMap(BINLOG_ROW_METADATA_FULL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "09040d040000" + "0c02fffc"; !bytes.HasSuffix(tm.OptionalMetadata, mustDecodeHex(t, want)) {
+		t.Errorf("optional metadata %x, want the suffix %s", tm.OptionalMetadata, want)
+	}
+}
+
+func TestPrimaryKey(t *testing.T) {
+	id := &Column{Name: "id", TypeCode: MT_LONG, Length: 10}
+	email := &Column{Name: "email", TypeCode: MT_VARCHAR, Length: 100}
+	nullable := &Column{Name: "n", TypeCode: MT_LONG, Length: 10, Flags: FF_MAYBE_NULL}
+	for _, c := range []struct {
+		name string
+		key  *Key
+		want bool
+	}{
+		{"primary", &Key{Name: "PRIMARY", Parts: []*KeyPart{{Column: email, Length: 10}}, IsUnique: true}, true},
+		{"unique", &Key{Name: "uk", Parts: []*KeyPart{{Column: id, Length: 4}, {Column: email, Length: 100}}, IsUnique: true}, true},
+		{"nullable", &Key{Name: "uk", Parts: []*KeyPart{{Column: nullable, Length: 4}}, IsUnique: true}, false},
+		{"prefix", &Key{Name: "uk", Parts: []*KeyPart{{Column: email, Length: 10}}, IsUnique: true}, false},
+		{"functional", &Key{Name: "uk", Parts: []*KeyPart{{Expression: "(`id` + 1)"}}, IsUnique: true}, false},
+		{"not unique", &Key{Name: "k", Parts: []*KeyPart{{Column: id, Length: 4}}}, false},
+	} {
+		mt := &MySQLTable{Keys: &Keys{Items: []*Key{c.key}}}
+		if got := mt.PrimaryKey() != nil; got != c.want {
+			t.Errorf("%s: primary key %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -0,0 +1,150 @@
+package table
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/zing22845/go-frm-parser/frm/utils"
+)
+
+// canonicalTypeRegex drops what Canonical writes in its own place from the
+// type name
+var canonicalTypeRegex = regexp.MustCompile(` (CHARACTER SET|COLLATE) \S+| NOT NULL| AUTO_INCREMENT`)
+
+// CanonicalCharset returns the charset and collation names as MySQL 8.0
+// spells them, utf8 is utf8mb3
+func CanonicalCharset(c *Collation) (charsetName, collationName string) {
+	charsetName, collationName = c.CharsetName, c.Name
+	if charsetName == "utf8" {
+		charsetName = "utf8mb3"
+		collationName = "utf8mb3" + strings.TrimPrefix(collationName, "utf8")
+	}
+	return charsetName, collationName
+}
+
+// Canonical returns the normal form of the table definition, which doesn't
+// depend on whitespace, charset aliases, integer display widths, elided
+// default collations or the order of the table options. AUTO_INCREMENT is
+// left out as it changes with the data
+func (mt *MySQLTable) Canonical() string {
+	var lines []string
+	for _, c := range mt.Columns.Items {
+		lines = append(lines, "  "+c.Canonical())
+	}
+	for _, k := range mt.Keys.Items {
+		key := strings.Replace(k.String(), " USING BTREE", "", 1)
+		if key == "" {
+			continue
+		}
+		lines = append(lines, "  "+utils.CollapseWhitespace(key))
+	}
+	for _, fk := range mt.ForeignKeys {
+		lines = append(lines, "  "+fk.String())
+	}
+	return fmt.Sprintf("CREATE TABLE `%s` (\n%s\n) %s\n",
+		mt.Name, strings.Join(lines, ",\n"), mt.Options.Canonical())
+}
+
+// Fingerprint is the hex SHA-256 of the canonical form of the table
+func (mt *MySQLTable) Fingerprint() string {
+	sum := sha256.Sum256([]byte(mt.Canonical()))
+	return hex.EncodeToString(sum[:])
+}
+
+// Canonical returns the normal form of the column definition, the
+// charset and collation of text columns are always named
+func (c *Column) Canonical() string {
+	typeName := canonicalTypeRegex.ReplaceAllString(c.TypeName, "")
+	if !c.Flags.HasFlag(FF_ZEROFILL) {
+		if match := utils.DisplayWidthRegex.FindStringSubmatch(typeName); match != nil && match[0] != "tinyint(1)" {
+			typeName = match[1] + typeName[len(match[0]):]
+		}
+	}
+	if c.TypeCode == MT_YEAR {
+		typeName = strings.TrimSuffix(typeName, "(4)")
+	}
+	components := []string{fmt.Sprintf("`%s`", strings.ReplaceAll(c.Name, "`", "``")), typeName}
+	if c.Collation != nil && c.Collation.CharsetName != "binary" && HasCharset(c.TypeCode) {
+		charsetName, collationName := CanonicalCharset(c.Collation)
+		components = append(components, "CHARACTER SET "+charsetName, "COLLATE "+collationName)
+	}
+	if !c.Flags.HasFlag(FF_MAYBE_NULL) {
+		components = append(components, "NOT NULL")
+	}
+	if c.Default != "" {
+		components = append(components, "DEFAULT "+c.Default)
+	}
+	if c.Utype == UT_NEXT_NUMBER {
+		components = append(components, "AUTO_INCREMENT")
+	}
+	if c.Comment != "" {
+		components = append(components, "COMMENT "+QuoteString(c.Comment))
+	}
+	return strings.Join(components, " ")
+}
+
+// Canonical returns the table options sorted by name, without
+// AUTO_INCREMENT
+func (t *Options) Canonical() string {
+	options := make(map[string]string)
+	if t.Connection != "" {
+		options["CONNECTION"] = QuoteString(t.Connection)
+	}
+	if t.Engine != "" {
+		options["ENGINE"] = t.Engine
+	}
+	if t.Collation != nil && t.Collation.Name != "" {
+		options["DEFAULT CHARSET"], options["COLLATE"] = CanonicalCharset(t.Collation)
+	}
+	if t.MinRows != 0 {
+		options["MIN_ROWS"] = fmt.Sprint(t.MinRows)
+	}
+	if t.MaxRows != 0 {
+		options["MAX_ROWS"] = fmt.Sprint(t.MaxRows)
+	}
+	if t.AvgRowLength != 0 {
+		options["AVG_ROW_LENGTH"] = fmt.Sprint(t.AvgRowLength)
+	}
+	if t.RowFormat != RT_DEFAULT {
+		options["ROW_FORMAT"] = t.RowFormat.String()
+	}
+	if t.KeyBlockSize != 0 {
+		options["KEY_BLOCK_SIZE"] = fmt.Sprint(t.KeyBlockSize)
+	}
+	if t.Comment != "" {
+		options["COMMENT"] = QuoteString(t.Comment)
+	}
+	if t.DataDirectory != "" {
+		options["DATA DIRECTORY"] = QuoteString(t.DataDirectory)
+	}
+	if t.IndexDirectory != "" {
+		options["INDEX DIRECTORY"] = QuoteString(t.IndexDirectory)
+	}
+	if t.InsertMethod != "" {
+		options["INSERT_METHOD"] = t.InsertMethod
+	}
+	if len(t.Union) != 0 {
+		union := make([]string, 0, len(t.Union))
+		for _, child := range t.Union {
+			union = append(union, child.String())
+		}
+		options["UNION"] = "(" + strings.Join(union, ",") + ")"
+	}
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names)+1)
+	for _, name := range names {
+		parts = append(parts, name+"="+options[name])
+	}
+	if t.Partitions != "" {
+		parts = append(parts, NormalizePartitions(t.PartitionClause()))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,88 @@
+package table
+
+import "testing"
+
+func TestColumnCanonical(t *testing.T) {
+	utf8, err := GetCollationByName("utf8_general_ci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		column *Column
+		want   string
+	}{
+		{
+			column: &Column{Name: "id", TypeCode: MT_LONG, TypeName: "int(11) NOT NULL AUTO_INCREMENT", Utype: UT_NEXT_NUMBER},
+			want:   "`id` int NOT NULL AUTO_INCREMENT",
+		},
+		{
+			column: &Column{Name: "n", TypeCode: MT_LONG, TypeName: "int(10) unsigned zerofill", Flags: FF_ZEROFILL | FF_MAYBE_NULL, Default: "NULL"},
+			want:   "`n` int(10) unsigned zerofill DEFAULT NULL",
+		},
+		{
+			column: &Column{Name: "b", TypeCode: MT_TINY, TypeName: "tinyint(1) NOT NULL", Default: "'0'"},
+			want:   "`b` tinyint(1) NOT NULL DEFAULT '0'",
+		},
+		{
+			column: &Column{Name: "y", TypeCode: MT_YEAR, TypeName: "year(4)", Flags: FF_MAYBE_NULL, Default: "NULL"},
+			want:   "`y` year DEFAULT NULL",
+		},
+		{
+			column: &Column{Name: "s", TypeCode: MT_VARCHAR, TypeName: "varchar(10) CHARACTER SET utf8 NOT NULL", Collation: utf8, Comment: "it's"},
+			want:   "`s` varchar(10) CHARACTER SET utf8mb3 COLLATE utf8mb3_general_ci NOT NULL COMMENT 'it\\'s'",
+		},
+	} {
+		if got := c.column.Canonical(); got != c.want {
+			t.Errorf("got %s, want %s", got, c.want)
+		}
+	}
+}
+
+func TestOptionsCanonicalPartitions(t *testing.T) {
+	base := " PARTITION BY RANGE (id)\n(PARTITION p0 VALUES LESS THAN (10) TABLESPACE = ts1 ENGINE = InnoDB,\n" +
+		" PARTITION p1 VALUES LESS THAN MAXVALUE ENGINE = InnoDB)"
+	canonical := func(clause string) string {
+		o := &Options{Engine: "InnoDB", Partitions: clause}
+		_ = o.DecodePartitioning()
+		return o.Canonical()
+	}
+	want := "ENGINE=InnoDB PARTITION BY RANGE (`id`) (PARTITION `p0` VALUES LESS THAN (10) TABLESPACE = `ts1` ENGINE = InnoDB," +
+		"PARTITION `p1` VALUES LESS THAN (MAXVALUE) ENGINE = InnoDB)"
+	if got := canonical(base); got != want {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+	for _, c := range []struct {
+		name   string
+		clause string
+		same   bool
+	}{
+		{
+			name: "formatting",
+			clause: "PARTITION BY RANGE(`id`) ( PARTITION p0 VALUES LESS THAN (10) TABLESPACE=ts1 ENGINE=InnoDB," +
+				"  PARTITION p1 VALUES LESS THAN (MAXVALUE) ENGINE=InnoDB )",
+			same: true,
+		},
+		{
+			name: "tablespace",
+			clause: " PARTITION BY RANGE (id)\n(PARTITION p0 VALUES LESS THAN (10) TABLESPACE = ts2 ENGINE = InnoDB,\n" +
+				" PARTITION p1 VALUES LESS THAN MAXVALUE ENGINE = InnoDB)",
+		},
+		{
+			name: "nodegroup",
+			clause: " PARTITION BY RANGE (id)\n(PARTITION p0 VALUES LESS THAN (10) TABLESPACE = ts1 NODEGROUP = 1 ENGINE = InnoDB,\n" +
+				" PARTITION p1 VALUES LESS THAN MAXVALUE ENGINE = InnoDB)",
+		},
+		{
+			name: "expression",
+			clause: " PARTITION BY RANGE (id + 1)\n(PARTITION p0 VALUES LESS THAN (10) TABLESPACE = ts1 ENGINE = InnoDB,\n" +
+				" PARTITION p1 VALUES LESS THAN MAXVALUE ENGINE = InnoDB)",
+		},
+	} {
+		if got := canonical(c.clause) == want; got != c.same {
+			t.Errorf("%s: same canonical form %v, want %v", c.name, got, c.same)
+		}
+	}
+	if got := canonical(" PARTITION BY SOMETHING\n  (id)"); got != "ENGINE=InnoDB PARTITION BY SOMETHING (id)" {
+		t.Errorf("unparsable clause: got %s", got)
+	}
+}
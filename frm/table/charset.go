@@ -1,6 +1,10 @@
 package table
 
-import "github.com/pkg/errors"
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
 
 type Collation struct {
 	ID          int    `json:"id"`
@@ -230,6 +234,73 @@ var collationsIDMap = map[int]*Collation{
 	245: {245, "utf8mb4", "utf8mb4_croatian_ci", false, 4},
 	246: {246, "utf8mb4", "utf8mb4_unicode_520_ci", false, 4},
 	247: {247, "utf8mb4", "utf8mb4_vietnamese_ci", false, 4},
+	// MySQL 8.0 collations
+	248: {248, "gb18030", "gb18030_chinese_ci", true, 4},
+	249: {249, "gb18030", "gb18030_bin", false, 4},
+	250: {250, "gb18030", "gb18030_unicode_520_ci", false, 4},
+	255: {255, "utf8mb4", "utf8mb4_0900_ai_ci", false, 4},
+	256: {256, "utf8mb4", "utf8mb4_de_pb_0900_ai_ci", false, 4},
+	257: {257, "utf8mb4", "utf8mb4_is_0900_ai_ci", false, 4},
+	258: {258, "utf8mb4", "utf8mb4_lv_0900_ai_ci", false, 4},
+	259: {259, "utf8mb4", "utf8mb4_ro_0900_ai_ci", false, 4},
+	260: {260, "utf8mb4", "utf8mb4_sl_0900_ai_ci", false, 4},
+	261: {261, "utf8mb4", "utf8mb4_pl_0900_ai_ci", false, 4},
+	262: {262, "utf8mb4", "utf8mb4_et_0900_ai_ci", false, 4},
+	263: {263, "utf8mb4", "utf8mb4_es_0900_ai_ci", false, 4},
+	264: {264, "utf8mb4", "utf8mb4_sv_0900_ai_ci", false, 4},
+	265: {265, "utf8mb4", "utf8mb4_tr_0900_ai_ci", false, 4},
+	266: {266, "utf8mb4", "utf8mb4_cs_0900_ai_ci", false, 4},
+	267: {267, "utf8mb4", "utf8mb4_da_0900_ai_ci", false, 4},
+	268: {268, "utf8mb4", "utf8mb4_lt_0900_ai_ci", false, 4},
+	269: {269, "utf8mb4", "utf8mb4_sk_0900_ai_ci", false, 4},
+	270: {270, "utf8mb4", "utf8mb4_es_trad_0900_ai_ci", false, 4},
+	271: {271, "utf8mb4", "utf8mb4_la_0900_ai_ci", false, 4},
+	273: {273, "utf8mb4", "utf8mb4_eo_0900_ai_ci", false, 4},
+	274: {274, "utf8mb4", "utf8mb4_hu_0900_ai_ci", false, 4},
+	275: {275, "utf8mb4", "utf8mb4_hr_0900_ai_ci", false, 4},
+	277: {277, "utf8mb4", "utf8mb4_vi_0900_ai_ci", false, 4},
+	278: {278, "utf8mb4", "utf8mb4_0900_as_cs", false, 4},
+	279: {279, "utf8mb4", "utf8mb4_de_pb_0900_as_cs", false, 4},
+	280: {280, "utf8mb4", "utf8mb4_is_0900_as_cs", false, 4},
+	281: {281, "utf8mb4", "utf8mb4_lv_0900_as_cs", false, 4},
+	282: {282, "utf8mb4", "utf8mb4_ro_0900_as_cs", false, 4},
+	283: {283, "utf8mb4", "utf8mb4_sl_0900_as_cs", false, 4},
+	284: {284, "utf8mb4", "utf8mb4_pl_0900_as_cs", false, 4},
+	285: {285, "utf8mb4", "utf8mb4_et_0900_as_cs", false, 4},
+	286: {286, "utf8mb4", "utf8mb4_es_0900_as_cs", false, 4},
+	287: {287, "utf8mb4", "utf8mb4_sv_0900_as_cs", false, 4},
+	288: {288, "utf8mb4", "utf8mb4_tr_0900_as_cs", false, 4},
+	289: {289, "utf8mb4", "utf8mb4_cs_0900_as_cs", false, 4},
+	290: {290, "utf8mb4", "utf8mb4_da_0900_as_cs", false, 4},
+	291: {291, "utf8mb4", "utf8mb4_lt_0900_as_cs", false, 4},
+	292: {292, "utf8mb4", "utf8mb4_sk_0900_as_cs", false, 4},
+	293: {293, "utf8mb4", "utf8mb4_es_trad_0900_as_cs", false, 4},
+	294: {294, "utf8mb4", "utf8mb4_la_0900_as_cs", false, 4},
+	296: {296, "utf8mb4", "utf8mb4_eo_0900_as_cs", false, 4},
+	297: {297, "utf8mb4", "utf8mb4_hu_0900_as_cs", false, 4},
+	298: {298, "utf8mb4", "utf8mb4_hr_0900_as_cs", false, 4},
+	300: {300, "utf8mb4", "utf8mb4_vi_0900_as_cs", false, 4},
+	303: {303, "utf8mb4", "utf8mb4_ja_0900_as_cs", false, 4},
+	304: {304, "utf8mb4", "utf8mb4_ja_0900_as_cs_ks", false, 4},
+	305: {305, "utf8mb4", "utf8mb4_0900_as_ci", false, 4},
+	306: {306, "utf8mb4", "utf8mb4_ru_0900_ai_ci", false, 4},
+	307: {307, "utf8mb4", "utf8mb4_ru_0900_as_cs", false, 4},
+	308: {308, "utf8mb4", "utf8mb4_zh_0900_as_cs", false, 4},
+	309: {309, "utf8mb4", "utf8mb4_0900_bin", false, 4},
+	310: {310, "utf8mb4", "utf8mb4_nb_0900_ai_ci", false, 4},
+	311: {311, "utf8mb4", "utf8mb4_nb_0900_as_cs", false, 4},
+	312: {312, "utf8mb4", "utf8mb4_nn_0900_ai_ci", false, 4},
+	313: {313, "utf8mb4", "utf8mb4_nn_0900_as_cs", false, 4},
+	314: {314, "utf8mb4", "utf8mb4_sr_latn_0900_ai_ci", false, 4},
+	315: {315, "utf8mb4", "utf8mb4_sr_latn_0900_as_cs", false, 4},
+	316: {316, "utf8mb4", "utf8mb4_bs_0900_ai_ci", false, 4},
+	317: {317, "utf8mb4", "utf8mb4_bs_0900_as_cs", false, 4},
+	318: {318, "utf8mb4", "utf8mb4_bg_0900_ai_ci", false, 4},
+	319: {319, "utf8mb4", "utf8mb4_bg_0900_as_cs", false, 4},
+	320: {320, "utf8mb4", "utf8mb4_gl_0900_ai_ci", false, 4},
+	321: {321, "utf8mb4", "utf8mb4_gl_0900_as_cs", false, 4},
+	322: {322, "utf8mb4", "utf8mb4_mn_cyrl_0900_ai_ci", false, 4},
+	323: {323, "utf8mb4", "utf8mb4_mn_cyrl_0900_as_cs", false, 4},
 }
 
 // GetCollationByID returns collations by given id.
@@ -241,3 +312,32 @@ func GetCollationByID(id int) (*Collation, error) {
 
 	return collation, nil
 }
+
+// GetCollationByName returns the collation of the given name, utf8mb3 is
+// an alias of utf8
+func GetCollationByName(name string) (*Collation, error) {
+	name = strings.ToLower(name)
+	if strings.HasPrefix(name, "utf8mb3_") {
+		name = "utf8_" + strings.TrimPrefix(name, "utf8mb3_")
+	}
+	for _, collation := range collationsIDMap {
+		if collation.Name == name {
+			return collation, nil
+		}
+	}
+	return nil, errors.Errorf("Unknown collation %s", name)
+}
+
+// GetDefaultCollation returns the default collation of the charset
+func GetDefaultCollation(charsetName string) (*Collation, error) {
+	charsetName = strings.ToLower(charsetName)
+	if charsetName == "utf8mb3" {
+		charsetName = "utf8"
+	}
+	for _, collation := range collationsIDMap {
+		if collation.CharsetName == charsetName && collation.IsDefault {
+			return collation, nil
+		}
+	}
+	return nil, errors.Errorf("Unknown charset %s", charsetName)
+}
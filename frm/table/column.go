@@ -31,11 +31,17 @@ type Column struct {
 	Utype          Utype
 	NullBitMap     []byte
 	NullBit        *int
-	Metadata       *column.Metadata
-	Defaults       *Defaults
-	Labels         *column.Labels
-	Comments       *column.Comments
-	Scale          FieldFlag
+	// RecordOffset is where the column starts in a record, NullPos is its
+	// bit in the null bytes and BitPos the first of the bits BIT columns
+	// keep in the null bytes, -1 if the column has none
+	RecordOffset uint32
+	NullPos      int
+	BitPos       int
+	Metadata     *column.Metadata
+	Defaults     *Defaults
+	Labels       *column.Labels
+	Comments     *column.Comments
+	Scale        FieldFlag
 }
 
 func (c *Column) String() string {
@@ -68,7 +74,9 @@ func (c *Column) Decode() (err error) {
 			labelBytes = c.Labels.Items[labelID]
 		}
 	}
-	c.Defaults.CurrentOffset = uint32(utils.Uint24LE(c.Metadata.Data[c.Metadata.CurrentOffset+5:])) - 1
+	c.RecordOffset = uint32(utils.Uint24LE(c.Metadata.Data[c.Metadata.CurrentOffset+5:])) - 1
+	c.Defaults.CurrentOffset = c.RecordOffset
+	c.assignNullBits()
 	// decode comment length
 	commentLength := binary.LittleEndian.Uint16(c.Metadata.Data[c.Metadata.CurrentOffset+15:])
 
@@ -184,17 +192,30 @@ func (c *Column) DecodeTypes() (err error) {
 	return nil
 }
 
+// assignNullBits takes the next null bit for a nullable column and, for BIT
+// columns of engines which store them so (HA_CAN_BIT_FIELD), the bits that
+// don't fill a whole byte, in the order the server lays them out
+func (c *Column) assignNullBits() {
+	c.NullPos = -1
+	c.BitPos = -1
+	if c.Flags.HasFlag(FF_MAYBE_NULL) {
+		c.NullPos = *c.NullBit
+		*c.NullBit++
+	}
+	if c.TypeCode == MT_BIT && !c.Flags.HasFlag(FF_TREAT_BIT_AS_CHAR) && c.Length&7 != 0 {
+		c.BitPos = *c.NullBit
+		*c.NullBit += int(c.Length & 7)
+	}
+}
+
 func (c *Column) hasDefaults() bool {
 	isAutoIncrement := (c.Utype == UT_NEXT_NUMBER)
 	if c.Flags.HasFlag(FF_NO_DEFAULT) || isAutoIncrement {
 		return false
 	}
-	if c.Flags.HasFlag(FF_MAYBE_NULL) {
-		offset := *c.NullBit / 8
-		nullByte := c.NullBitMap[offset]
-		nullBit := *c.NullBit % 8
-		*c.NullBit++
-		if nullByte&(1<<(nullBit)) != 0 && c.Utype != UT_BLOB_FIELD {
+	if c.NullPos >= 0 {
+		nullByte := c.Defaults.Data[c.NullPos/8]
+		if nullByte&(1<<(c.NullPos%8)) != 0 && c.Utype != UT_BLOB_FIELD {
 			c.Default = "NULL"
 			return false
 		}
@@ -205,23 +226,31 @@ func (c *Column) hasDefaults() bool {
 
 func (c *Column) decodeTypeDecimal(hasDefaults bool) {
 	isSigned := c.Flags.HasFlag(FF_DECIMAL)
+	precision := c.Precision()
+	c.TypeName += fmt.Sprintf("(%d,%d)", precision, c.Scale)
+	if !isSigned {
+		c.TypeName += " unsigned"
+	}
+	if hasDefaults {
+		c.decodeDecimalDefault(precision)
+	}
+}
+
+// Precision is the precision of a DECIMAL column, the field length
+// counts the decimal point and the sign of signed columns, as in
+// my_decimal_length_to_precision
+func (c *Column) Precision() uint16 {
 	precision := c.Length
 	if c.Scale != 0 {
 		precision -= 1
 	}
-	if precision != 0 {
+	if precision != 0 && c.Flags.HasFlag(FF_DECIMAL) {
 		precision -= 1
 	}
 	if precision == 0 {
 		precision = 1
 	}
-	c.TypeName += fmt.Sprintf("(%d,%d)", precision, c.Scale)
-	if !isSigned {
-		c.TypeName += " unsigned"
-	}
-	if hasDefaults {
-		c.decodeDecimalDefault(precision)
-	}
+	return precision
 }
 
 func (c *Column) decodeDecimalDefault(precision uint16) {
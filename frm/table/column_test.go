@@ -0,0 +1,29 @@
+package table
+
+import "testing"
+
+func TestDecimalPrecision(t *testing.T) {
+	for _, c := range []struct {
+		length uint16
+		scale  FieldFlag
+		signed bool
+		want   uint16
+	}{
+		// DECIMAL(10,2): digits, point and sign
+		{12, 2, true, 10},
+		// DECIMAL(10,2) UNSIGNED has no sign
+		{11, 2, false, 10},
+		{11, 0, true, 10},
+		{10, 0, false, 10},
+		// DECIMAL(1,0) UNSIGNED
+		{1, 0, false, 1},
+	} {
+		col := &Column{TypeCode: MT_NEWDECIMAL, Length: c.length, Scale: c.scale}
+		if c.signed {
+			col.Flags = FF_DECIMAL
+		}
+		if got := col.Precision(); got != c.want {
+			t.Errorf("length %d scale %d signed %v: precision %d, want %d", c.length, c.scale, c.signed, got, c.want)
+		}
+	}
+}
@@ -257,6 +257,17 @@ func (mt MySQLType) KeyPrefix() (KeyPrefix, error) {
 	return prefix.IsKeyPrefix, nil
 }
 
+// HasCharset reports whether values of the type are text in a charset,
+// columns with the binary collation hold bytes all the same
+func HasCharset(typeCode MySQLType) bool {
+	switch typeCode {
+	case MT_STRING, MT_VAR_STRING, MT_VARCHAR, MT_ENUM, MT_SET,
+		MT_TINY_BLOB, MT_MEDIUM_BLOB, MT_LONG_BLOB, MT_BLOB:
+		return true
+	}
+	return false
+}
+
 // GeometryType represents the geometry types
 type GeometryType uint8
 
@@ -0,0 +1,358 @@
+package table
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zing22845/go-frm-parser/frm/utils"
+)
+
+// ResolveDataDirectory fills DATA DIRECTORY and INDEX DIRECTORY, which are
+// not recorded in the .frm, from the files next to it in dir:
+// InnoDB .isl link files and MyISAM .MYD/.MYI symbolic links,
+// for the table itself and for every (sub)partition. A link which can't
+// be read or doesn't belong to the table is added to the Warnings and
+// leaves its directory unresolved
+func (mt *MySQLTable) ResolveDataDirectory(dir string) {
+	stems := []string{utils.EncodeMySQLObject2File(mt.Name)}
+	if mt.Options.Partitioning != nil {
+		stems = append(stems, mt.Options.Partitioning.FileNames(mt.Name)...)
+	}
+	for _, stem := range stems {
+		islPath := filepath.Join(dir, stem+".isl")
+		data, err := os.ReadFile(islPath)
+		if err == nil {
+			err = mt.ApplyISL(islPath, data)
+		} else if os.IsNotExist(err) {
+			err = nil
+		}
+		if err != nil {
+			mt.Warnings = append(mt.Warnings, fmt.Sprintf("resolve DATA DIRECTORY: %v", err))
+		}
+		for _, ext := range []string{".MYD", ".MYI"} {
+			target, err := readSymlink(filepath.Join(dir, stem+ext))
+			if err != nil {
+				mt.Warnings = append(mt.Warnings, fmt.Sprintf("resolve %s symlink: %v", ext, err))
+				continue
+			}
+			if target == "" {
+				continue
+			}
+			if ext == ".MYD" {
+				mt.setDirectory(stem, filepath.Dir(target), "")
+			} else {
+				mt.setDirectory(stem, "", filepath.Dir(target))
+			}
+		}
+	}
+}
+
+// ApplyISL sets the DATA DIRECTORY of the table or partition from the
+// content of an InnoDB .isl file, path is the .isl file path and is used
+// to find the table or partition the link belongs to
+func (mt *MySQLTable) ApplyISL(path string, data []byte) error {
+	stem := strings.TrimSuffix(filepath.Base(path), ".isl")
+	ibdPath := strings.TrimSpace(string(data))
+	if ibdPath == "" {
+		return fmt.Errorf("%s is an empty .isl file", path)
+	}
+	// the .isl holds <DATA DIRECTORY>/<schema>/<table>.ibd
+	if !mt.setDirectory(stem, filepath.Dir(filepath.Dir(ibdPath)), "") {
+		return fmt.Errorf("%s does not belong to table %s", path, mt.Name)
+	}
+	return nil
+}
+
+func (mt *MySQLTable) setDirectory(stem, dataDirectory, indexDirectory string) bool {
+	if strings.EqualFold(stem, utils.EncodeMySQLObject2File(mt.Name)) {
+		if dataDirectory != "" {
+			mt.Options.DataDirectory = dataDirectory
+		}
+		if indexDirectory != "" {
+			mt.Options.IndexDirectory = indexDirectory
+		}
+		return true
+	}
+	if mt.Options.Partitioning == nil {
+		return false
+	}
+	def, sub := mt.Options.Partitioning.lookupFile(mt.Name, stem)
+	switch {
+	case sub != nil:
+		sub.setDirectory(dataDirectory, indexDirectory)
+	case def != nil:
+		def.setDirectory(dataDirectory, indexDirectory)
+	default:
+		return false
+	}
+	return true
+}
+
+// setDirectory sets the directories found from the files of the partition,
+// those the clause doesn't hold are marked to be added to it
+func (o *PartitionOptions) setDirectory(dataDirectory, indexDirectory string) {
+	if dataDirectory != "" {
+		o.dataResolved = o.dataResolved || o.DataDirectory == ""
+		o.DataDirectory = dataDirectory
+	}
+	if indexDirectory != "" {
+		o.indexResolved = o.indexResolved || o.IndexDirectory == ""
+		o.IndexDirectory = indexDirectory
+	}
+}
+
+// resolvedString renders the directories which were resolved from files
+func (o *PartitionOptions) resolvedString() string {
+	resolved := PartitionOptions{}
+	if o.dataResolved {
+		resolved.DataDirectory = o.DataDirectory
+	}
+	if o.indexResolved {
+		resolved.IndexDirectory = o.IndexDirectory
+	}
+	return resolved.String()
+}
+
+// readSymlink returns the absolute target of path if it is a symbolic link,
+// or an empty string if it is a regular file or does not exist
+func readSymlink(path string) (string, error) {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	if fi.Mode()&os.ModeSymlink == 0 {
+		return "", nil
+	}
+	target, err := os.Readlink(path)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(path), target)
+	}
+	return target, nil
+}
+
+// DataDirectoryOf returns the DATA DIRECTORY of the table or (sub)partition
+// stored in the file name stem, e.g. t1 or t1#P#p0, ok is false if the
+// file does not belong to the table
+func (mt *MySQLTable) DataDirectoryOf(stem string) (dataDirectory string, ok bool) {
+	if strings.EqualFold(stem, utils.EncodeMySQLObject2File(mt.Name)) {
+		return mt.Options.DataDirectory, true
+	}
+	if mt.Options.Partitioning == nil {
+		return "", false
+	}
+	def, sub := mt.Options.Partitioning.lookupFile(mt.Name, stem)
+	if def == nil {
+		return "", false
+	}
+	dataDirectory = mt.Options.DataDirectory
+	if def.DataDirectory != "" {
+		dataDirectory = def.DataDirectory
+	}
+	if sub != nil && sub.DataDirectory != "" {
+		dataDirectory = sub.DataDirectory
+	}
+	return dataDirectory, true
+}
+
+// PartitionClause returns the PARTITION BY clause as stored in the .frm,
+// with the DATA DIRECTORY and INDEX DIRECTORY found from the files of the
+// partitions added to it
+func (t *Options) PartitionClause() string {
+	if t.Partitioning == nil || !t.Partitioning.hasResolved(false) {
+		return t.Partitions
+	}
+	return t.Partitioning.withDirectories(t.Partitions)
+}
+
+// hasResolved reports whether a directory of a partition, or only of a
+// subpartition, was found from the files
+func (p *Partitioning) hasResolved(subPartitionsOnly bool) bool {
+	for _, def := range p.Definitions {
+		if !subPartitionsOnly && def.resolvedString() != "" {
+			return true
+		}
+		for _, sub := range def.SubPartitions {
+			if sub.resolvedString() != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// subPartitionList renders the subpartitions of def with their resolved
+// directories, for partitions whose subpartitions the clause doesn't list
+func (p *Partitioning) subPartitionList(def *PartitionDefinition) string {
+	if !p.hasResolved(true) || len(def.SubPartitions) == 0 {
+		return ""
+	}
+	subs := make([]string, len(def.SubPartitions))
+	for i, sub := range def.SubPartitions {
+		subs[i] = "SUBPARTITION " + quoteIdentifier(sub.Name) + sub.resolvedString()
+	}
+	return "\n (" + strings.Join(subs, ",\n  ") + ")"
+}
+
+// withDirectories inserts the resolved directories after the options of
+// their (sub)partition in the clause, leaving the rest of it as is. The
+// partitions of a clause with PARTITIONS n are listed
+func (p *Partitioning) withDirectories(clause string) string {
+	if p.DefaultDefinitions {
+		defs := make([]string, len(p.Definitions))
+		for i, def := range p.Definitions {
+			defs[i] = "PARTITION " + quoteIdentifier(def.Name) + def.resolvedString() + p.subPartitionList(def)
+		}
+		return strings.TrimRight(clause, " \t\r\n") + "\n(" + strings.Join(defs, ",\n ") + ")"
+	}
+
+	type insertion struct {
+		offset int
+		text   string
+	}
+	var insertions []insertion
+	insert := func(i int, text string) {
+		for i > 0 && strings.IndexByte(" \t\r\n", clause[i-1]) >= 0 {
+			i--
+		}
+		if text != "" {
+			insertions = append(insertions, insertion{i, text})
+		}
+	}
+	var def *PartitionDefinition
+	var sub *SubPartitionDefinition
+	// pending is set until the options of def are complete
+	pending := false
+	depth := 0
+	inList, inSubList := false, false
+	for i := 0; i < len(clause); i++ {
+		switch ch := clause[i]; {
+		case ch == '\'' || ch == '"' || ch == '`':
+			i = skipQuoted(clause, i)
+		case ch == '(':
+			switch {
+			case !inList && depth == 0 && nextWord(clause, i+1) == "PARTITION":
+				inList = true
+			case inList && depth == 1 && nextWord(clause, i+1) == "SUBPARTITION":
+				if pending && def != nil {
+					insert(i, def.resolvedString())
+				}
+				pending = false
+				inSubList = true
+			}
+			depth++
+		case ch == ')' || ch == ',':
+			if ch == ')' {
+				depth--
+			}
+			switch {
+			case inSubList && depth == 2 && ch == ',' || inSubList && depth == 1 && ch == ')':
+				if sub != nil {
+					insert(i, sub.resolvedString())
+				}
+				sub = nil
+				inSubList = ch == ','
+			case inList && depth == 1 && ch == ',' || inList && depth == 0 && ch == ')':
+				if pending && def != nil {
+					insert(i, def.resolvedString()+p.subPartitionList(def))
+				}
+				def, pending = nil, false
+				inList = ch == ','
+			}
+		case isWordByte(ch) && (i == 0 || !isWordByte(clause[i-1])):
+			word := readWord(clause, i)
+			switch {
+			case inList && !inSubList && depth == 1 && strings.EqualFold(word, "PARTITION"):
+				def, pending = p.definition(readName(clause, i+len(word))), true
+			case inSubList && depth == 2 && strings.EqualFold(word, "SUBPARTITION") && def != nil:
+				sub = def.subPartition(readName(clause, i+len(word)))
+			}
+			i += len(word) - 1
+		}
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, ins := range insertions {
+		sb.WriteString(clause[last:ins.offset])
+		sb.WriteString(ins.text)
+		last = ins.offset
+	}
+	sb.WriteString(clause[last:])
+	return sb.String()
+}
+
+func (p *Partitioning) definition(name string) *PartitionDefinition {
+	for _, def := range p.Definitions {
+		if strings.EqualFold(def.Name, name) {
+			return def
+		}
+	}
+	return nil
+}
+
+func (pd *PartitionDefinition) subPartition(name string) *SubPartitionDefinition {
+	for _, sub := range pd.SubPartitions {
+		if strings.EqualFold(sub.Name, name) {
+			return sub
+		}
+	}
+	return nil
+}
+
+// skipQuoted returns the offset of the quote closing the string or
+// identifier starting at i
+func skipQuoted(s string, i int) int {
+	quote := s[i]
+	for i++; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quote != '`':
+			i++
+		case s[i] == quote && i+1 < len(s) && s[i+1] == quote:
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return len(s)
+}
+
+func isWordByte(ch byte) bool {
+	return ch == '_' || ch == '$' || ch >= 0x80 ||
+		'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || '0' <= ch && ch <= '9'
+}
+
+func readWord(s string, i int) string {
+	end := i
+	for end < len(s) && isWordByte(s[end]) {
+		end++
+	}
+	return s[i:end]
+}
+
+// nextWord returns the upper cased word following the whitespace at i
+func nextWord(s string, i int) string {
+	for i < len(s) && strings.IndexByte(" \t\r\n", s[i]) >= 0 {
+		i++
+	}
+	return strings.ToUpper(readWord(s, i))
+}
+
+// readName returns the identifier following the whitespace at i
+func readName(s string, i int) string {
+	for i < len(s) && strings.IndexByte(" \t\r\n", s[i]) >= 0 {
+		i++
+	}
+	if i < len(s) && s[i] == '`' {
+		end := skipQuoted(s, i)
+		return strings.ReplaceAll(s[i+1:min(end, len(s))], "``", "`")
+	}
+	return readWord(s, i)
+}
@@ -0,0 +1,73 @@
+package table
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveDataDirectory(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	link := func(target, name string) {
+		if err := os.Symlink(target, filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("t1.isl", "/data/db/t1.ibd\n")
+	write("t1#P#p0.isl", "/ssd/db/t1#P#p0.ibd")
+	link("/mnt/data/t2.MYD", "t2.MYD")
+	link("../index/t2.MYI", "t2.MYI")
+	write("t3.isl", " \n")
+	write("t3.MYD", "")
+
+	mt := &MySQLTable{Name: "t1", Options: &Options{
+		Partitions: " PARTITION BY HASH (id)\nPARTITIONS 2",
+	}}
+	if err := mt.Options.DecodePartitioning(); err != nil {
+		t.Fatal(err)
+	}
+	mt.ResolveDataDirectory(dir)
+	if mt.Options.DataDirectory != "/data" || len(mt.Warnings) != 0 {
+		t.Errorf("t1: DATA DIRECTORY %q, warnings %q", mt.Options.DataDirectory, mt.Warnings)
+	}
+	if got, want := mt.Options.PartitionClause(), " PARTITION BY HASH (id)\nPARTITIONS 2\n"+
+		"(PARTITION `p0` DATA DIRECTORY = '/ssd',\n PARTITION `p1`)"; got != want {
+		t.Errorf("t1: partition clause\n%s\nwant\n%s", got, want)
+	}
+
+	mt = &MySQLTable{Name: "t2", Options: &Options{}}
+	mt.ResolveDataDirectory(dir)
+	if mt.Options.DataDirectory != "/mnt/data" || mt.Options.IndexDirectory != filepath.Join(filepath.Dir(dir), "index") {
+		t.Errorf("t2: DATA DIRECTORY %q, INDEX DIRECTORY %q", mt.Options.DataDirectory, mt.Options.IndexDirectory)
+	}
+
+	// an empty .isl is a warning and a regular .MYD no symlink
+	mt = &MySQLTable{Name: "t3", Options: &Options{}}
+	mt.ResolveDataDirectory(dir)
+	if mt.Options.DataDirectory != "" || len(mt.Warnings) != 1 || !strings.Contains(mt.Warnings[0], "empty .isl") {
+		t.Errorf("t3: DATA DIRECTORY %q, warnings %q", mt.Options.DataDirectory, mt.Warnings)
+	}
+
+	// files which can't be read leave the directories unresolved
+	mt = &MySQLTable{Name: "t1", Options: &Options{}}
+	mt.ResolveDataDirectory(filepath.Join(dir, "t1.isl"))
+	if mt.Options.DataDirectory != "" || len(mt.Warnings) != 3 {
+		t.Errorf("not a directory: DATA DIRECTORY %q, warnings %q", mt.Options.DataDirectory, mt.Warnings)
+	}
+}
+
+func TestApplyISL(t *testing.T) {
+	mt := &MySQLTable{Name: "t1", Options: &Options{}}
+	if err := mt.ApplyISL("/var/lib/mysql/db/t2.isl", []byte("/data/db/t2.ibd")); err == nil {
+		t.Error("the .isl of another table was applied")
+	}
+	if err := mt.ApplyISL("/var/lib/mysql/db/t1.isl", []byte("/data/db/t1.ibd")); err != nil || mt.Options.DataDirectory != "/data" {
+		t.Errorf("DATA DIRECTORY %q, %v", mt.Options.DataDirectory, err)
+	}
+}
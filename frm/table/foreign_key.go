@@ -0,0 +1,43 @@
+package table
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ForeignKey is a FOREIGN KEY constraint, the .frm doesn't record them
+// but the MySQL 8.0 data dictionary does
+type ForeignKey struct {
+	Name              string
+	Columns           []string
+	ReferencedSchema  string
+	ReferencedTable   string
+	ReferencedColumns []string
+	OnDelete          string
+	OnUpdate          string
+}
+
+func (fk *ForeignKey) String() string {
+	quote := func(names []string) string {
+		quoted := make([]string, len(names))
+		for i, name := range names {
+			quoted[i] = fmt.Sprintf("`%s`", strings.ReplaceAll(name, "`", "``"))
+		}
+		return strings.Join(quoted, ", ")
+	}
+	referenced := fmt.Sprintf("`%s`", fk.ReferencedTable)
+	if fk.ReferencedSchema != "" {
+		referenced = fmt.Sprintf("`%s`.%s", fk.ReferencedSchema, referenced)
+	}
+	components := []string{
+		fmt.Sprintf("CONSTRAINT `%s` FOREIGN KEY (%s) REFERENCES %s (%s)",
+			fk.Name, quote(fk.Columns), referenced, quote(fk.ReferencedColumns)),
+	}
+	if fk.OnDelete != "" {
+		components = append(components, "ON DELETE "+fk.OnDelete)
+	}
+	if fk.OnUpdate != "" {
+		components = append(components, "ON UPDATE "+fk.OnUpdate)
+	}
+	return strings.Join(components, " ")
+}
@@ -0,0 +1,78 @@
+package table
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sqlEscaper escapes a string literal the way mysqldump does
+var sqlEscaper = strings.NewReplacer(
+	"\\", "\\\\",
+	"'", "\\'",
+	"\x00", "\\0",
+	"\n", "\\n",
+	"\r", "\\r",
+	"\x1a", "\\Z",
+)
+
+// QuoteString returns s as a quoted SQL string literal
+func QuoteString(s string) string {
+	return "'" + sqlEscaper.Replace(s) + "'"
+}
+
+// QuoteValue returns value, as decoded by RecordDecoder, as an SQL literal.
+// A *BlobRef is a BLOB whose data was not read, it has no literal
+func (c *Column) QuoteValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "NULL", nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case []byte:
+		if len(v) == 0 {
+			return "''", nil
+		}
+		return "0x" + hex.EncodeToString(v), nil
+	case string:
+		if c.TypeCode == MT_DECIMAL || c.TypeCode == MT_NEWDECIMAL {
+			return v, nil
+		}
+		return QuoteString(v), nil
+	case *BlobRef:
+		return "", fmt.Errorf("the %d bytes of BLOB column %s were not read", v.Length, c.Name)
+	}
+	return fmt.Sprintf("'%v'", value), nil
+}
+
+// InsertStatement returns an INSERT statement of rows, the values of
+// every row in the order of the columns. It fails on a BLOB whose data
+// was not read rather than losing it
+func (mt *MySQLTable) InsertStatement(rows [][]interface{}) (string, error) {
+	columns := mt.Columns.Items
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = fmt.Sprintf("`%s`", strings.ReplaceAll(c.Name, "`", "``"))
+	}
+	values := make([]string, len(rows))
+	for i, row := range rows {
+		literals := make([]string, len(row))
+		for j, value := range row {
+			literal, err := columns[j].QuoteValue(value)
+			if err != nil {
+				return "", fmt.Errorf("row %d: %w", i, err)
+			}
+			literals[j] = literal
+		}
+		values[i] = "(" + strings.Join(literals, ",") + ")"
+	}
+	return fmt.Sprintf("INSERT INTO `%s` (%s) VALUES %s;",
+		strings.ReplaceAll(mt.Name, "`", "``"), strings.Join(names, ","), strings.Join(values, ",")), nil
+}
@@ -0,0 +1,37 @@
+package table
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInsertStatement(t *testing.T) {
+	mt := &MySQLTable{
+		Name: "t`1",
+		Columns: &Columns{Items: []*Column{
+			{Name: "id", TypeCode: MT_LONG},
+			{Name: "price", TypeCode: MT_NEWDECIMAL},
+			{Name: "name", TypeCode: MT_VARCHAR},
+			{Name: "data", TypeCode: MT_BLOB},
+		}},
+	}
+	got, err := mt.InsertStatement([][]interface{}{
+		{int64(1), "9.90", "it's\n", []byte{0xde, 0xad}},
+		{uint64(2), nil, "", []byte{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "INSERT INTO `t``1` (`id`,`price`,`name`,`data`) VALUES (1,9.90,'it\\'s\\n',0xdead),(2,NULL,'','');"
+	if got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	_, err = mt.InsertStatement([][]interface{}{
+		{int64(1), "9.90", "a", []byte{0x01}},
+		{int64(2), "9.90", "b", &BlobRef{Length: 70000, Pointer: 0x1000}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "row 1") || !strings.Contains(err.Error(), "data") {
+		t.Errorf("unread BLOB not reported: %v", err)
+	}
+}
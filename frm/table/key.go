@@ -21,13 +21,20 @@ type Key struct {
 	Comment    string
 	IndexType  string
 	IsUnique   bool
+	Invisible  bool
 	Keys       *Keys
 	Columns    *Columns
 }
 
 type KeyPart struct {
-	Column *Column
-	Length uint16
+	Column     *Column
+	Length     uint16
+	Descending bool
+	// Expression is set for functional key parts of MySQL 8.0
+	Expression string
+	// Cardinality is the estimated number of distinct values,
+	// 0 if the storage engine statistics were not read
+	Cardinality uint64
 }
 
 func (k *Key) String() string {
@@ -65,6 +72,9 @@ func (k *Key) String() string {
 	if k.Comment != "" {
 		components = append(components, fmt.Sprintf("COMMENT '%s'", k.Comment))
 	}
+	if k.Invisible {
+		components = append(components, "/*!80000 INVISIBLE */")
+	}
 	if k.Parser != "" && k.Parser != "True" { // Assuming 'True' is a placeholder for an undefined parser
 		components = append(components, fmt.Sprintf("/*!50100 WITH PARSER `%s` */ ", k.Parser))
 	}
@@ -74,6 +84,10 @@ func (k *Key) String() string {
 func (k *Key) FormatKeyPart(part *KeyPart) string {
 	// format the basic column name being indexed
 	value := part.String()
+	if part.Expression != "" {
+		// functional key parts never have an index prefix
+		return value
+	}
 
 	// Check if the index type is FULLTEXT or SPATIAL
 	if k.IndexType == "FULLTEXT" || k.IndexType == "SPATIAL" {
@@ -90,6 +104,9 @@ func (k *Key) FormatKeyPart(part *KeyPart) string {
 		prefixLength := part.Length / uint16(part.Column.Collation.Maxlen)
 		value += fmt.Sprintf("(%d)", prefixLength)
 	}
+	if part.Descending {
+		value += " DESC"
+	}
 	return value
 }
 
@@ -122,6 +139,9 @@ func (k *Key) Decode() {
 }
 
 func (kp *KeyPart) String() string {
+	if kp.Expression != "" {
+		return fmt.Sprintf("(%s)", kp.Expression)
+	}
 	return fmt.Sprintf("`%s`", kp.Column.Name)
 }
 
@@ -0,0 +1,87 @@
+package table
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zing22845/go-frm-parser/frm/utils"
+)
+
+// MERGE_INSERT_METHOD_PREFIX starts the INSERT_METHOD line of a .MRG file
+const MERGE_INSERT_METHOD_PREFIX = "#INSERT_METHOD="
+
+// MergeChild is a table in the UNION of a MERGE table,
+// Schema is empty if it is in the schema of the MERGE table
+type MergeChild struct {
+	Schema string
+	Name   string
+}
+
+func (c *MergeChild) String() string {
+	if c.Schema == "" {
+		return fmt.Sprintf("`%s`", c.Name)
+	}
+	return fmt.Sprintf("`%s`.`%s`", c.Schema, c.Name)
+}
+
+// ParseMergeFile parses the content of the .MRG file at path: one child
+// table per line, as a file name when it is in the same schema or as
+// ./schema/table otherwise, and an optional #INSERT_METHOD= line
+func ParseMergeFile(path string, data []byte) (union []*MergeChild, insertMethod string, err error) {
+	schemaDir := filepath.Base(filepath.Dir(path))
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if strings.HasPrefix(line, MERGE_INSERT_METHOD_PREFIX) {
+				insertMethod = strings.TrimPrefix(line, MERGE_INSERT_METHOD_PREFIX)
+			}
+			continue
+		}
+		child := &MergeChild{}
+		parts := strings.Split(filepath.ToSlash(line), "/")
+		child.Name, err = utils.DecodeMySQLFile2Object(parts[len(parts)-1])
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %w", path, err)
+		}
+		if len(parts) > 1 && parts[len(parts)-2] != schemaDir {
+			child.Schema, err = utils.DecodeMySQLFile2Object(parts[len(parts)-2])
+			if err != nil {
+				return nil, "", fmt.Errorf("%s: %w", path, err)
+			}
+		}
+		union = append(union, child)
+	}
+	err = scanner.Err()
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", path, err)
+	}
+	return union, insertMethod, nil
+}
+
+// ResolveMergeFile fills UNION and INSERT_METHOD of a MERGE table,
+// which are not recorded in the .frm, from its .MRG file in dir. A .MRG
+// file which can't be read or parsed is added to the Warnings
+func (mt *MySQLTable) ResolveMergeFile(dir string) {
+	path := filepath.Join(dir, utils.EncodeMySQLObject2File(mt.Name)+".MRG")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			mt.Warnings = append(mt.Warnings, fmt.Sprintf("read .MRG: %v", err))
+		}
+		return
+	}
+	union, insertMethod, err := ParseMergeFile(path, data)
+	if err != nil {
+		mt.Warnings = append(mt.Warnings, fmt.Sprintf("read .MRG: %v", err))
+		return
+	}
+	mt.Options.Union, mt.Options.InsertMethod = union, insertMethod
+}
@@ -0,0 +1,89 @@
+package table
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseMergeFile(t *testing.T) {
+	for _, c := range []struct {
+		name         string
+		data         string
+		union        string
+		insertMethod string
+		err          bool
+	}{
+		{
+			name:  "same schema",
+			data:  "t1\nt2\n",
+			union: "`t1`,`t2`",
+		},
+		{
+			name:         "other schemas and insert method",
+			data:         "t1\n./shop/t2\n./archive@002d2023/orders\n\n#INSERT_METHOD=LAST\n",
+			union:        "`t1`,`t2`,`archive-2023`.`orders`",
+			insertMethod: "LAST",
+		},
+		{
+			name:  "encoded names",
+			data:  "sales@0020q1\n./shop/@00e9t@00e9\n",
+			union: "`sales q1`,`été`",
+		},
+		{
+			name: "invalid encoding",
+			data: "t@00\n",
+			err:  true,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			union, insertMethod, err := ParseMergeFile("/var/lib/mysql/shop/m.MRG", []byte(c.data))
+			if c.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			children := make([]string, len(union))
+			for i, child := range union {
+				children[i] = child.String()
+			}
+			if got := strings.Join(children, ","); got != c.union || insertMethod != c.insertMethod {
+				t.Errorf("got UNION=(%s) INSERT_METHOD=%s, want UNION=(%s) INSERT_METHOD=%s",
+					got, insertMethod, c.union, c.insertMethod)
+			}
+		})
+	}
+}
+
+func TestResolveMergeFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "m@002d1.MRG"), []byte("t1\nt2\n#INSERT_METHOD=FIRST\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bad.MRG"), []byte("t@0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mt := &MySQLTable{Name: "m-1", Options: &Options{Engine: "MRG_MYISAM"}}
+	mt.ResolveMergeFile(dir)
+	if got := mt.Options.String(); !strings.Contains(got, "INSERT_METHOD=FIRST UNION=(`t1`,`t2`)") || len(mt.Warnings) != 0 {
+		t.Errorf("options %s, warnings %q", got, mt.Warnings)
+	}
+
+	mt = &MySQLTable{Name: "missing", Options: &Options{Engine: "MRG_MYISAM"}}
+	mt.ResolveMergeFile(dir)
+	if mt.Options.Union != nil || len(mt.Warnings) != 0 {
+		t.Errorf("missing .MRG: union %v, warnings %q", mt.Options.Union, mt.Warnings)
+	}
+
+	mt = &MySQLTable{Name: "bad", Options: &Options{Engine: "MRG_MYISAM"}}
+	mt.ResolveMergeFile(dir)
+	if mt.Options.Union != nil || len(mt.Warnings) != 1 || !strings.Contains(mt.Warnings[0], ".MRG") {
+		t.Errorf("invalid .MRG: union %v, warnings %q", mt.Options.Union, mt.Warnings)
+	}
+}
@@ -10,8 +10,12 @@ type MySQLVersion struct {
 }
 
 func NewMySQLVersion(data []byte) (mv *MySQLVersion) {
+	return NewMySQLVersionFromID(binary.LittleEndian.Uint32(data))
+}
+
+// NewMySQLVersionFromID creates a MySQLVersion from MYSQL_VERSION_ID, e.g. 50738
+func NewMySQLVersionFromID(versionID uint32) (mv *MySQLVersion) {
 	mv = new(MySQLVersion)
-	versionID := binary.LittleEndian.Uint32(data)
 	mv.Major = int(versionID / 10000)
 	mv.Minor = int(versionID % 1000 / 100)
 	mv.Release = int(versionID % 100)
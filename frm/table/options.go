@@ -8,6 +8,7 @@ import (
 type Options struct {
 	Connection     string
 	Engine         string
+	AutoIncrement  uint64
 	Collation      *Collation
 	MinRows        uint32
 	MaxRows        uint32
@@ -15,7 +16,13 @@ type Options struct {
 	RowFormat      RowType
 	KeyBlockSize   uint16
 	Comment        string
+	DataDirectory  string
+	IndexDirectory string
+	// InsertMethod and Union of MERGE tables
+	InsertMethod   string
+	Union          []*MergeChild
 	Partitions     string
+	Partitioning   *Partitioning
 	HandlerOptions HandlerOption
 }
 
@@ -27,6 +34,9 @@ func (t *Options) String() string {
 	if t.Engine != "" {
 		parts = append(parts, fmt.Sprintf("ENGINE=%s", t.Engine))
 	}
+	if t.AutoIncrement > 1 {
+		parts = append(parts, fmt.Sprintf("AUTO_INCREMENT=%d", t.AutoIncrement))
+	}
 	if t.Collation != nil && t.Collation.Name != "" {
 		parts = append(parts, fmt.Sprintf("DEFAULT CHARSET=%s", t.Collation.CharsetName))
 		if !t.Collation.IsDefault {
@@ -48,8 +58,24 @@ func (t *Options) String() string {
 	if t.Comment != "" {
 		parts = append(parts, fmt.Sprintf("COMMENT='%s'", t.Comment))
 	}
+	if t.DataDirectory != "" {
+		parts = append(parts, fmt.Sprintf("DATA DIRECTORY='%s'", t.DataDirectory))
+	}
+	if t.IndexDirectory != "" {
+		parts = append(parts, fmt.Sprintf("INDEX DIRECTORY='%s'", t.IndexDirectory))
+	}
+	if t.InsertMethod != "" {
+		parts = append(parts, fmt.Sprintf("INSERT_METHOD=%s", t.InsertMethod))
+	}
+	if len(t.Union) != 0 {
+		union := make([]string, 0, len(t.Union))
+		for _, child := range t.Union {
+			union = append(union, child.String())
+		}
+		parts = append(parts, fmt.Sprintf("UNION=(%s)", strings.Join(union, ",")))
+	}
 	if t.Partitions != "" {
-		parts = append(parts, fmt.Sprintf("/*!50100 %s */", t.Partitions))
+		parts = append(parts, fmt.Sprintf("/*!50100 %s */", t.PartitionClause()))
 	}
 	return strings.Join(parts, " ")
 }
@@ -64,3 +90,19 @@ func NewOptions(fileInfo *FileInfo) (o *Options) {
 	o.HandlerOptions = fileInfo._1E_HANDLER_OPTION
 	return o
 }
+
+// DecodePartitioning parses the raw PARTITION BY clause into Partitioning,
+// a clause the parser can't read leaves Partitioning nil and the raw
+// clause is still rendered
+func (t *Options) DecodePartitioning() (err error) {
+	t.Partitioning = nil
+	if t.Partitions == "" {
+		return nil
+	}
+	partitioning, err := ParsePartitioning(t.Partitions)
+	if err != nil {
+		return err
+	}
+	t.Partitioning = partitioning
+	return nil
+}
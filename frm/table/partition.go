@@ -0,0 +1,456 @@
+package table
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/tidb/pkg/parser"
+	"github.com/pingcap/tidb/pkg/parser/ast"
+	"github.com/pingcap/tidb/pkg/parser/format"
+	pmodel "github.com/pingcap/tidb/pkg/parser/model"
+	_ "github.com/pingcap/tidb/pkg/parser/test_driver"
+	"github.com/zing22845/go-frm-parser/frm/utils"
+)
+
+// PartitionMethod represents the partitioning function of a table
+type PartitionMethod string
+
+const (
+	PM_NONE  PartitionMethod = ""
+	PM_RANGE PartitionMethod = "RANGE"
+	PM_LIST  PartitionMethod = "LIST"
+	PM_HASH  PartitionMethod = "HASH"
+	PM_KEY   PartitionMethod = "KEY"
+)
+
+// Partitioning is the structured form of the PARTITION BY clause
+// stored in the extra section of the .frm
+type Partitioning struct {
+	Method       PartitionMethod
+	Linear       bool
+	UseColumns   bool // RANGE COLUMNS / LIST COLUMNS
+	Expression   string
+	Columns      []string
+	KeyAlgorithm uint64
+	Num          uint64
+	Sub          *SubPartitioning
+	Definitions  []*PartitionDefinition
+	// DefaultDefinitions is set when the partitions were not listed
+	// explicitly (PARTITIONS n) and have been generated
+	DefaultDefinitions bool
+	// DefaultSubPartitions is set when the subpartitions were not listed
+	// explicitly (SUBPARTITIONS n) and have been generated
+	DefaultSubPartitions bool
+}
+
+// SubPartitioning is the SUBPARTITION BY part of the clause,
+// only HASH and KEY are allowed by MySQL
+type SubPartitioning struct {
+	Method       PartitionMethod
+	Linear       bool
+	Expression   string
+	Columns      []string
+	KeyAlgorithm uint64
+	Num          uint64
+}
+
+// PartitionDefinition is a single PARTITION of the clause
+type PartitionDefinition struct {
+	Name string
+	// LessThan holds the VALUES LESS THAN items of RANGE partitions,
+	// MAXVALUE is kept as is
+	LessThan []string
+	// In holds the VALUES IN items of LIST partitions, each item has
+	// one value per column for LIST COLUMNS with multiple columns
+	In [][]string
+	PartitionOptions
+	SubPartitions []*SubPartitionDefinition
+}
+
+// SubPartitionDefinition is a single SUBPARTITION of a partition
+type SubPartitionDefinition struct {
+	Name string
+	PartitionOptions
+}
+
+// PartitionOptions are the options of a partition or subpartition
+type PartitionOptions struct {
+	Tablespace string
+	// Nodegroup is nil if NODEGROUP isn't given
+	Nodegroup      *uint64
+	MaxRows        uint64
+	MinRows        uint64
+	DataDirectory  string
+	IndexDirectory string
+	Comment        string
+	Engine         string
+	// dataResolved and indexResolved are set when the directory isn't in
+	// the clause but was found from the files of the partition
+	dataResolved  bool
+	indexResolved bool
+}
+
+// ParsePartitioning parses the PARTITION BY clause of a table
+func ParsePartitioning(clause string) (p *Partitioning, err error) {
+	stmt, err := parser.New().ParseOneStmt("CREATE TABLE t (c int) "+clause, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("parse partition clause failed: %w", err)
+	}
+	createStmt, ok := stmt.(*ast.CreateTableStmt)
+	if !ok || createStmt.Partition == nil {
+		return nil, fmt.Errorf("no partition clause found in: %s", clause)
+	}
+	po := createStmt.Partition
+	p = &Partitioning{
+		Linear: po.Linear,
+		Num:    po.Num,
+	}
+	p.Method, err = partitionMethod(po.Tp)
+	if err != nil {
+		return nil, err
+	}
+	p.Expression, err = restoreExpr(po.Expr)
+	if err != nil {
+		return nil, err
+	}
+	p.Columns = columnNames(po.ColumnNames)
+	p.UseColumns = (p.Method == PM_RANGE || p.Method == PM_LIST) && len(p.Columns) > 0
+	if po.KeyAlgorithm != nil {
+		p.KeyAlgorithm = po.KeyAlgorithm.Type
+	}
+	if po.Sub != nil {
+		p.Sub = &SubPartitioning{
+			Linear:  po.Sub.Linear,
+			Columns: columnNames(po.Sub.ColumnNames),
+			Num:     po.Sub.Num,
+		}
+		p.Sub.Method, err = partitionMethod(po.Sub.Tp)
+		if err != nil {
+			return nil, err
+		}
+		p.Sub.Expression, err = restoreExpr(po.Sub.Expr)
+		if err != nil {
+			return nil, err
+		}
+		if po.Sub.KeyAlgorithm != nil {
+			p.Sub.KeyAlgorithm = po.Sub.KeyAlgorithm.Type
+		}
+	}
+	p.Definitions = make([]*PartitionDefinition, len(po.Definitions))
+	for i, def := range po.Definitions {
+		p.Definitions[i], err = newPartitionDefinition(def)
+		if err != nil {
+			return nil, err
+		}
+	}
+	p.fillDefaultNames()
+	return p, nil
+}
+
+// NormalizePartitions returns the PARTITION BY clause as the parser restores
+// it, which keeps every option but not the formatting of the clause, or the
+// clause with its whitespace collapsed if it doesn't parse
+func NormalizePartitions(clause string) string {
+	stmt, err := parser.New().ParseOneStmt("CREATE TABLE t (c int) "+clause, "", "")
+	if err == nil {
+		if createStmt, ok := stmt.(*ast.CreateTableStmt); ok && createStmt.Partition != nil {
+			var sb strings.Builder
+			ctx := format.NewRestoreCtx(
+				format.RestoreStringSingleQuotes|format.RestoreStringWithoutCharset|
+					format.RestoreKeyWordUppercase|format.RestoreNameBackQuotes,
+				&sb)
+			if createStmt.Partition.Restore(ctx) == nil {
+				return sb.String()
+			}
+		}
+	}
+	return utils.CollapseWhitespace(clause)
+}
+
+// fillDefaultNames generates the (sub)partitions MySQL creates implicitly
+// for PARTITIONS n / SUBPARTITIONS n, named p0, p1... and p0sp0, p0sp1...
+func (p *Partitioning) fillDefaultNames() {
+	if len(p.Definitions) == 0 {
+		p.DefaultDefinitions = true
+		for i := uint64(0); i < p.Num; i++ {
+			p.Definitions = append(p.Definitions, &PartitionDefinition{Name: fmt.Sprintf("p%d", i)})
+		}
+	}
+	if p.Sub == nil {
+		return
+	}
+	for _, def := range p.Definitions {
+		if len(def.SubPartitions) != 0 {
+			continue
+		}
+		p.DefaultSubPartitions = true
+		for i := uint64(0); i < p.Sub.Num; i++ {
+			def.SubPartitions = append(def.SubPartitions,
+				&SubPartitionDefinition{Name: fmt.Sprintf("%ssp%d", def.Name, i)})
+		}
+	}
+}
+
+func newPartitionDefinition(def *ast.PartitionDefinition) (pd *PartitionDefinition, err error) {
+	pd = &PartitionDefinition{Name: def.Name.O}
+	switch clause := def.Clause.(type) {
+	case *ast.PartitionDefinitionClauseLessThan:
+		pd.LessThan = make([]string, len(clause.Exprs))
+		for i, expr := range clause.Exprs {
+			pd.LessThan[i], err = restoreExpr(expr)
+			if err != nil {
+				return nil, err
+			}
+		}
+	case *ast.PartitionDefinitionClauseIn:
+		pd.In = make([][]string, len(clause.Values))
+		for i, values := range clause.Values {
+			pd.In[i] = make([]string, len(values))
+			for j, expr := range values {
+				pd.In[i][j], err = restoreExpr(expr)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	pd.setOptions(def.Options)
+	pd.SubPartitions = make([]*SubPartitionDefinition, len(def.Sub))
+	for i, sub := range def.Sub {
+		pd.SubPartitions[i] = &SubPartitionDefinition{Name: sub.Name.O}
+		pd.SubPartitions[i].setOptions(sub.Options)
+	}
+	return pd, nil
+}
+
+func (o *PartitionOptions) setOptions(options []*ast.TableOption) {
+	for _, opt := range options {
+		switch opt.Tp {
+		case ast.TableOptionTablespace:
+			o.Tablespace = opt.StrValue
+		case ast.TableOptionNodegroup:
+			nodegroup := opt.UintValue
+			o.Nodegroup = &nodegroup
+		case ast.TableOptionMaxRows:
+			o.MaxRows = opt.UintValue
+		case ast.TableOptionMinRows:
+			o.MinRows = opt.UintValue
+		case ast.TableOptionDataDirectory:
+			o.DataDirectory = opt.StrValue
+		case ast.TableOptionIndexDirectory:
+			o.IndexDirectory = opt.StrValue
+		case ast.TableOptionComment:
+			o.Comment = opt.StrValue
+		case ast.TableOptionEngine:
+			o.Engine = opt.StrValue
+		}
+	}
+}
+
+func partitionMethod(tp pmodel.PartitionType) (PartitionMethod, error) {
+	switch tp {
+	case pmodel.PartitionTypeRange:
+		return PM_RANGE, nil
+	case pmodel.PartitionTypeList:
+		return PM_LIST, nil
+	case pmodel.PartitionTypeHash:
+		return PM_HASH, nil
+	case pmodel.PartitionTypeKey:
+		return PM_KEY, nil
+	default:
+		return PM_NONE, fmt.Errorf("unsupported partition type: %s", tp.String())
+	}
+}
+
+func restoreExpr(expr ast.ExprNode) (string, error) {
+	if expr == nil {
+		return "", nil
+	}
+	var sb strings.Builder
+	ctx := format.NewRestoreCtx(
+		format.RestoreStringSingleQuotes|format.RestoreStringWithoutCharset|
+			format.RestoreKeyWordUppercase|format.RestoreNameBackQuotes,
+		&sb)
+	err := expr.Restore(ctx)
+	if err != nil {
+		return "", fmt.Errorf("restore partition expression failed: %w", err)
+	}
+	return sb.String(), nil
+}
+
+func columnNames(names []*ast.ColumnName) []string {
+	if len(names) == 0 {
+		return nil
+	}
+	result := make([]string, len(names))
+	for i, name := range names {
+		result[i] = name.Name.O
+	}
+	return result
+}
+
+// IsRange reports whether the table is partitioned by RANGE or RANGE COLUMNS
+func (p *Partitioning) IsRange() bool {
+	return p.Method == PM_RANGE
+}
+
+// FileNames returns the tablespace file name stems of every (sub)partition
+// without extension, e.g. t1#P#p0 or t1#P#p0#SP#p0sp0
+func (p *Partitioning) FileNames(tableName string) []string {
+	base := utils.EncodeMySQLObject2File(tableName)
+	var names []string
+	for _, def := range p.Definitions {
+		partName := base + "#P#" + utils.EncodeMySQLObject2File(def.Name)
+		if len(def.SubPartitions) == 0 {
+			names = append(names, partName)
+			continue
+		}
+		for _, sub := range def.SubPartitions {
+			names = append(names, partName+"#SP#"+utils.EncodeMySQLObject2File(sub.Name))
+		}
+	}
+	return names
+}
+
+// lookupFile finds the (sub)partition stored in the file name stem,
+// e.g. t1#P#p0 or t1#P#p0#SP#p0sp0, 8.0 lower case #p#/#sp# is accepted
+func (p *Partitioning) lookupFile(tableName, stem string) (*PartitionDefinition, *SubPartitionDefinition) {
+	base := utils.EncodeMySQLObject2File(tableName)
+	for _, def := range p.Definitions {
+		partName := base + "#P#" + utils.EncodeMySQLObject2File(def.Name)
+		if strings.EqualFold(partName, stem) {
+			return def, nil
+		}
+		for _, sub := range def.SubPartitions {
+			if strings.EqualFold(partName+"#SP#"+utils.EncodeMySQLObject2File(sub.Name), stem) {
+				return def, sub
+			}
+		}
+	}
+	return nil, nil
+}
+
+// String renders the clause the way MySQL stores it in the .frm
+func (p *Partitioning) String() string {
+	var sb strings.Builder
+	sb.WriteString(" PARTITION BY ")
+	sb.WriteString(p.methodString(p.Method, p.Linear, p.UseColumns, p.KeyAlgorithm, p.Expression, p.Columns))
+	if p.DefaultDefinitions {
+		sb.WriteString(fmt.Sprintf("\nPARTITIONS %d", len(p.Definitions)))
+	}
+	if p.Sub != nil {
+		sb.WriteString("\nSUBPARTITION BY ")
+		sb.WriteString(p.methodString(p.Sub.Method, p.Sub.Linear, false, p.Sub.KeyAlgorithm, p.Sub.Expression, p.Sub.Columns))
+		if p.DefaultSubPartitions {
+			sb.WriteString(fmt.Sprintf("\nSUBPARTITIONS %d", p.Sub.Num))
+		}
+	}
+	if p.DefaultDefinitions {
+		return sb.String()
+	}
+	defs := make([]string, len(p.Definitions))
+	for i, def := range p.Definitions {
+		defs[i] = def.String(!p.DefaultSubPartitions)
+	}
+	sb.WriteString("\n(")
+	sb.WriteString(strings.Join(defs, ",\n "))
+	sb.WriteString(")")
+	return sb.String()
+}
+
+func (p *Partitioning) methodString(method PartitionMethod, linear, useColumns bool, algorithm uint64, expr string, columns []string) string {
+	var sb strings.Builder
+	if linear {
+		sb.WriteString("LINEAR ")
+	}
+	sb.WriteString(string(method))
+	if algorithm != 0 {
+		sb.WriteString(fmt.Sprintf(" ALGORITHM = %d", algorithm))
+	}
+	if useColumns {
+		sb.WriteString("  COLUMNS")
+	} else {
+		sb.WriteString(" ")
+	}
+	if expr != "" {
+		sb.WriteString("(" + expr + ")")
+	} else {
+		quoted := make([]string, len(columns))
+		for i, column := range columns {
+			quoted[i] = quoteIdentifier(column)
+		}
+		sb.WriteString("(" + strings.Join(quoted, ",") + ")")
+	}
+	return sb.String()
+}
+
+// String renders a single partition, subpartitions are only
+// listed if they were given explicitly
+func (pd *PartitionDefinition) String(withSubPartitions bool) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("PARTITION %s", pd.Name))
+	if pd.LessThan != nil {
+		if len(pd.LessThan) == 1 && pd.LessThan[0] == "MAXVALUE" {
+			sb.WriteString(" VALUES LESS THAN MAXVALUE")
+		} else {
+			sb.WriteString(fmt.Sprintf(" VALUES LESS THAN (%s)", strings.Join(pd.LessThan, ",")))
+		}
+	}
+	if pd.In != nil {
+		values := make([]string, len(pd.In))
+		for i, item := range pd.In {
+			if len(item) == 1 {
+				values[i] = item[0]
+			} else {
+				values[i] = "(" + strings.Join(item, ",") + ")"
+			}
+		}
+		sb.WriteString(fmt.Sprintf(" VALUES IN (%s)", strings.Join(values, ",")))
+	}
+	sb.WriteString(pd.PartitionOptions.String())
+	if withSubPartitions && len(pd.SubPartitions) > 0 {
+		subs := make([]string, len(pd.SubPartitions))
+		for i, sub := range pd.SubPartitions {
+			subs[i] = fmt.Sprintf("SUBPARTITION %s", sub.Name) + sub.PartitionOptions.String()
+		}
+		sb.WriteString("\n (")
+		sb.WriteString(strings.Join(subs, ",\n  "))
+		sb.WriteString(")")
+	}
+	return sb.String()
+}
+
+// String renders the options in the order MySQL writes them, each with a
+// leading space
+func (o *PartitionOptions) String() string {
+	var sb strings.Builder
+	if o.Tablespace != "" {
+		sb.WriteString(fmt.Sprintf(" TABLESPACE = %s", o.Tablespace))
+	}
+	if o.Nodegroup != nil {
+		sb.WriteString(fmt.Sprintf(" NODEGROUP = %d", *o.Nodegroup))
+	}
+	if o.MaxRows != 0 {
+		sb.WriteString(fmt.Sprintf(" MAX_ROWS = %d", o.MaxRows))
+	}
+	if o.MinRows != 0 {
+		sb.WriteString(fmt.Sprintf(" MIN_ROWS = %d", o.MinRows))
+	}
+	if o.DataDirectory != "" {
+		sb.WriteString(fmt.Sprintf(" DATA DIRECTORY = '%s'", o.DataDirectory))
+	}
+	if o.IndexDirectory != "" {
+		sb.WriteString(fmt.Sprintf(" INDEX DIRECTORY = '%s'", o.IndexDirectory))
+	}
+	if o.Comment != "" {
+		sb.WriteString(fmt.Sprintf(" COMMENT = '%s'", strings.ReplaceAll(o.Comment, "'", "''")))
+	}
+	if o.Engine != "" {
+		sb.WriteString(fmt.Sprintf(" ENGINE = %s", o.Engine))
+	}
+	return sb.String()
+}
+
+func quoteIdentifier(name string) string {
+	return fmt.Sprintf("`%s`", strings.ReplaceAll(name, "`", "``"))
+}
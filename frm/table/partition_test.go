@@ -0,0 +1,123 @@
+package table
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePartitioningOptions(t *testing.T) {
+	clause := " PARTITION BY RANGE (id)\n(PARTITION p0 VALUES LESS THAN (10) TABLESPACE = ts1 NODEGROUP = 0 ENGINE = InnoDB,\n" +
+		" PARTITION p1 VALUES LESS THAN MAXVALUE MAX_ROWS = 5 COMMENT = 'x' ENGINE = InnoDB)"
+	p, err := ParsePartitioning(clause)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Method != PM_RANGE || p.Expression != "`id`" || len(p.Definitions) != 2 {
+		t.Fatalf("unexpected partitioning %+v", p)
+	}
+	p0, p1 := p.Definitions[0], p.Definitions[1]
+	if p0.Tablespace != "ts1" || p0.Nodegroup == nil || *p0.Nodegroup != 0 || p0.Engine != "InnoDB" {
+		t.Errorf("p0 options %+v", p0.PartitionOptions)
+	}
+	if p1.Nodegroup != nil || p1.MaxRows != 5 || p1.Comment != "x" {
+		t.Errorf("p1 options %+v", p1.PartitionOptions)
+	}
+	want := " TABLESPACE = ts1 NODEGROUP = 0 ENGINE = InnoDB"
+	if got := p0.PartitionOptions.String(); got != want {
+		t.Errorf("p0 options rendered %q, want %q", got, want)
+	}
+}
+
+func TestDecodePartitioningKeepsUnparsableClause(t *testing.T) {
+	o := &Options{Partitions: " PARTITION BY SOMETHING (id)"}
+	if err := o.DecodePartitioning(); err == nil {
+		t.Fatal("expected a parse error")
+	}
+	if o.Partitioning != nil {
+		t.Error("Partitioning should stay nil")
+	}
+	if got := o.String(); !strings.Contains(got, "/*!50100  PARTITION BY SOMETHING (id) */") {
+		t.Errorf("raw clause not rendered: %s", got)
+	}
+}
+
+func TestPartitionClause(t *testing.T) {
+	for _, c := range []struct {
+		name    string
+		clause  string
+		skip    []string
+		want    string
+		resolve bool
+	}{
+		{
+			name: "no directory resolved",
+			clause: " PARTITION BY RANGE (to_seconds(d))\n" +
+				"(PARTITION p0 VALUES LESS THAN (10) TABLESPACE = ts1 NODEGROUP = 0 ENGINE = InnoDB)",
+		},
+		{
+			name: "explicit partitions",
+			clause: " PARTITION BY RANGE (id)\n" +
+				"(PARTITION p0 VALUES LESS THAN (10) TABLESPACE = ts1 NODEGROUP = 0 ENGINE = InnoDB,\n" +
+				" PARTITION `p,1` VALUES LESS THAN MAXVALUE COMMENT = 'a, (PARTITION p0' ENGINE = InnoDB)",
+			resolve: true,
+			want: " PARTITION BY RANGE (id)\n" +
+				"(PARTITION p0 VALUES LESS THAN (10) TABLESPACE = ts1 NODEGROUP = 0 ENGINE = InnoDB DATA DIRECTORY = '/d/t1#P#p0',\n" +
+				" PARTITION `p,1` VALUES LESS THAN MAXVALUE COMMENT = 'a, (PARTITION p0' ENGINE = InnoDB DATA DIRECTORY = '/d/t1#P#p@002c1')",
+		},
+		{
+			name:    "PARTITIONS n",
+			clause:  " PARTITION BY HASH (to_seconds(d))\nPARTITIONS 2",
+			resolve: true,
+			want: " PARTITION BY HASH (to_seconds(d))\nPARTITIONS 2\n" +
+				"(PARTITION `p0` DATA DIRECTORY = '/d/t1#P#p0',\n PARTITION `p1` DATA DIRECTORY = '/d/t1#P#p1')",
+		},
+		{
+			name: "SUBPARTITIONS n",
+			clause: " PARTITION BY RANGE (id)\nSUBPARTITION BY HASH (id)\nSUBPARTITIONS 2\n" +
+				"(PARTITION p0 VALUES LESS THAN (10) ENGINE = InnoDB,\n PARTITION p1 VALUES LESS THAN MAXVALUE ENGINE = InnoDB)",
+			skip:    []string{"t1#P#p1#SP#p1sp1"},
+			resolve: true,
+			want: " PARTITION BY RANGE (id)\nSUBPARTITION BY HASH (id)\nSUBPARTITIONS 2\n" +
+				"(PARTITION p0 VALUES LESS THAN (10) ENGINE = InnoDB\n" +
+				" (SUBPARTITION `p0sp0` DATA DIRECTORY = '/d/t1#P#p0#SP#p0sp0',\n" +
+				"  SUBPARTITION `p0sp1` DATA DIRECTORY = '/d/t1#P#p0#SP#p0sp1'),\n" +
+				" PARTITION p1 VALUES LESS THAN MAXVALUE ENGINE = InnoDB\n" +
+				" (SUBPARTITION `p1sp0` DATA DIRECTORY = '/d/t1#P#p1#SP#p1sp0',\n" +
+				"  SUBPARTITION `p1sp1`))",
+		},
+		{
+			name: "explicit subpartitions",
+			clause: " PARTITION BY LIST (id)\nSUBPARTITION BY HASH (id)\n" +
+				"(PARTITION p0 VALUES IN (1,2) DATA DIRECTORY = '/old'\n" +
+				" (SUBPARTITION s0 DATA DIRECTORY = '/old' ENGINE = InnoDB,\n  SUBPARTITION s1 ENGINE = InnoDB))",
+			resolve: true,
+			want: " PARTITION BY LIST (id)\nSUBPARTITION BY HASH (id)\n" +
+				"(PARTITION p0 VALUES IN (1,2) DATA DIRECTORY = '/old'\n" +
+				" (SUBPARTITION s0 DATA DIRECTORY = '/old' ENGINE = InnoDB,\n" +
+				"  SUBPARTITION s1 ENGINE = InnoDB DATA DIRECTORY = '/d/t1#P#p0#SP#s1'))",
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			mt := &MySQLTable{Name: "t1", Options: &Options{Partitions: c.clause}}
+			if err := mt.Options.DecodePartitioning(); err != nil {
+				t.Fatal(err)
+			}
+			want := c.clause
+			if c.resolve {
+				want = c.want
+			resolve:
+				for _, stem := range mt.Options.Partitioning.FileNames(mt.Name) {
+					for _, skip := range c.skip {
+						if stem == skip {
+							continue resolve
+						}
+					}
+					mt.setDirectory(stem, "/d/"+stem, "")
+				}
+			}
+			if got := mt.Options.PartitionClause(); got != want {
+				t.Errorf("got\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
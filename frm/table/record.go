@@ -0,0 +1,573 @@
+package table
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb/pkg/parser/charset"
+	"github.com/zing22845/go-frm-parser/frm/utils"
+)
+
+const (
+	// PORTABLE_SIZEOF_CHAR_PTR is the size of the pointer to the data
+	// which follows the length of BLOB fields in a record
+	PORTABLE_SIZEOF_CHAR_PTR = 8
+
+	TIMEF_INT_OFS     = 0x800000
+	TIMEF_OFS         = 0x800000000000
+	DATETIMEF_INT_OFS = 0x8000000000
+)
+
+// FieldLayout is where a column is stored in a record
+type FieldLayout struct {
+	Column     *Column
+	Offset     uint32
+	PackLength uint32
+	// NullPos is the bit of the column in the null bytes, -1 if NOT NULL
+	NullPos int
+	// BitPos and BitLength are the bits a BIT column keeps in the null
+	// bytes, BitPos is -1 if there are none
+	BitPos    int
+	BitLength int
+}
+
+// RecordLayout is the layout of a record in the server's internal row
+// format, as in the default values of the .frm or the MyISAM data file
+type RecordLayout struct {
+	NullBytes uint32
+	Length    uint32
+	Fields    []*FieldLayout
+}
+
+// BlobRef is a BLOB, TEXT, JSON or GEOMETRY field of a record, the record
+// only holds its length and a pointer to the data in memory
+type BlobRef struct {
+	Length  uint32
+	Pointer uint64
+}
+
+// PackLength is the number of bytes the column takes in a record
+func (c *Column) PackLength() (uint32, error) {
+	switch c.TypeCode {
+	case MT_DECIMAL, MT_STRING, MT_VAR_STRING:
+		return uint32(c.Length), nil
+	case MT_NEWDECIMAL:
+		intLength, fracLength := utils.CalculateDecimalLengths(int(c.Precision()), int(c.Scale))
+		return uint32(intLength + fracLength), nil
+	case MT_TINY, MT_YEAR:
+		return 1, nil
+	case MT_SHORT:
+		return 2, nil
+	case MT_INT24, MT_NEWDATE:
+		return 3, nil
+	case MT_LONG, MT_FLOAT, MT_DATE:
+		return 4, nil
+	case MT_LONGLONG, MT_DOUBLE, MT_DATETIME:
+		return 8, nil
+	case MT_NULL:
+		return 0, nil
+	case MT_TIME:
+		scale := c.temporalScale(MAX_TIME_WIDTH)
+		if scale > 0 {
+			if int(scale) >= len(TIME_HIRES_BYTES) {
+				return 0, fmt.Errorf("invalid scale %d for TIME(N)", scale)
+			}
+			return uint32(TIME_HIRES_BYTES[scale]), nil
+		}
+		return 3, nil
+	case MT_TIME2:
+		return 3 + uint32(utils.DigitsToBytes[c.temporalScale(MAX_TIME_WIDTH)]), nil
+	case MT_TIMESTAMP, MT_TIMESTAMP2:
+		return 4 + uint32(utils.DigitsToBytes[c.temporalScale(MAX_DATETIME_WIDTH)]), nil
+	case MT_DATETIME2:
+		return 5 + uint32(utils.DigitsToBytes[c.temporalScale(MAX_DATETIME_WIDTH)]), nil
+	case MT_VARCHAR:
+		if c.Length < 256 {
+			return uint32(c.Length) + 1, nil
+		}
+		return uint32(c.Length) + 2, nil
+	case MT_ENUM:
+		if len(c.LabelStrs) < 256 {
+			return 1, nil
+		}
+		return 2, nil
+	case MT_SET:
+		n := (len(c.LabelStrs) + 7) / 8
+		if n > 4 {
+			n = 8
+		}
+		return uint32(n), nil
+	case MT_BIT:
+		if c.Flags.HasFlag(FF_TREAT_BIT_AS_CHAR) {
+			return uint32(c.Length+7) / 8, nil
+		}
+		return uint32(c.Length) / 8, nil
+	case MT_TINY_BLOB, MT_MEDIUM_BLOB, MT_LONG_BLOB, MT_BLOB, MT_JSON, MT_GEOMETRY:
+		return c.blobLengthBytes() + PORTABLE_SIZEOF_CHAR_PTR, nil
+	}
+	return 0, fmt.Errorf("column %s: unknown pack length of type %d", c.Name, c.TypeCode)
+}
+
+// blobLengthBytes is the size of the length of a BLOB field,
+// recorded in the pack flag of the column
+func (c *Column) blobLengthBytes() uint32 {
+	n := uint32((c.Flags & FF_PACK) >> FF_PACK_SHIFT)
+	if n != 0 {
+		return n
+	}
+	switch c.TypeCode {
+	case MT_TINY_BLOB:
+		return 1
+	case MT_BLOB:
+		return 2
+	case MT_MEDIUM_BLOB:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// temporalScale is the fractional seconds precision of a temporal column
+func (c *Column) temporalScale(width int32) int32 {
+	scale := int32(c.Length) - width - 1
+	if scale < 0 {
+		return 0
+	}
+	return scale
+}
+
+// RecordLayout computes the layout of the records of the table, the
+// offsets of a table parsed from a .frm are the ones recorded in it
+func (mt *MySQLTable) RecordLayout() (*RecordLayout, error) {
+	layout := &RecordLayout{
+		Fields: make([]*FieldLayout, 0, len(mt.Columns.Items)),
+	}
+	fromFRM := mt.FileInfo != nil
+	nullBit := 1
+	if mt.Options.HandlerOptions.HasOption(HO_PACK_RECORD) {
+		nullBit = 0
+	}
+	if !fromFRM {
+		// count the null bits first, the fields follow the null bytes
+		counter := nullBit
+		for _, c := range mt.Columns.Items {
+			c.NullBit = &counter
+			c.assignNullBits()
+		}
+		layout.NullBytes = uint32(counter+7) / 8
+	}
+	offset := layout.NullBytes
+	for _, c := range mt.Columns.Items {
+		packLength, err := c.PackLength()
+		if err != nil {
+			return nil, err
+		}
+		f := &FieldLayout{
+			Column:     c,
+			Offset:     offset,
+			PackLength: packLength,
+			NullPos:    c.NullPos,
+			BitPos:     c.BitPos,
+		}
+		if fromFRM {
+			f.Offset = c.RecordOffset
+		}
+		if f.BitPos >= 0 {
+			f.BitLength = int(c.Length & 7)
+		}
+		offset += packLength
+		layout.Fields = append(layout.Fields, f)
+	}
+	if fromFRM {
+		layout.Length = uint32(mt.FileInfo._10_RECORD_LENGTH)
+		layout.NullBytes = layout.Length
+		for _, f := range layout.Fields {
+			if f.Offset < layout.NullBytes {
+				layout.NullBytes = f.Offset
+			}
+		}
+	} else {
+		layout.Length = offset
+	}
+	return layout, nil
+}
+
+// IsNull reports whether the field is NULL in record
+func (f *FieldLayout) IsNull(record []byte) bool {
+	if f.NullPos < 0 {
+		return false
+	}
+	return record[f.NullPos/8]&(1<<(f.NullPos%8)) != 0
+}
+
+// RecordDecoder decodes records in the server's internal row format
+// into typed values: int64 or uint64 for integers and BIT, float32,
+// float64, string for DECIMAL, temporal, ENUM, SET and character columns,
+// []byte for binary columns and *BlobRef for BLOB fields unless BlobData
+// is set. NULL is nil
+type RecordDecoder struct {
+	Table  *MySQLTable
+	Layout *RecordLayout
+	// BlobData returns the data referenced by a BLOB field, it depends
+	// on where the record comes from
+	BlobData func(f *FieldLayout, ref *BlobRef) ([]byte, error)
+}
+
+func NewRecordDecoder(mt *MySQLTable) (*RecordDecoder, error) {
+	layout, err := mt.RecordLayout()
+	if err != nil {
+		return nil, err
+	}
+	return &RecordDecoder{Table: mt, Layout: layout}, nil
+}
+
+// Decode returns the values of all the columns of record
+func (d *RecordDecoder) Decode(record []byte) ([]interface{}, error) {
+	if uint32(len(record)) < d.Layout.Length {
+		return nil, fmt.Errorf("record length %d is less than %d", len(record), d.Layout.Length)
+	}
+	values := make([]interface{}, len(d.Layout.Fields))
+	for i, f := range d.Layout.Fields {
+		value, err := d.DecodeField(f, record)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", f.Column.Name, err)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// DecodeField returns the value of the field f of record
+func (d *RecordDecoder) DecodeField(f *FieldLayout, record []byte) (interface{}, error) {
+	if f.IsNull(record) {
+		return nil, nil
+	}
+	c := f.Column
+	data := record[f.Offset : f.Offset+f.PackLength]
+	isSigned := c.Flags.HasFlag(FF_DECIMAL)
+	switch c.TypeCode {
+	case MT_TINY, MT_SHORT, MT_INT24, MT_LONG, MT_LONGLONG:
+		return decodeInteger(data, isSigned), nil
+	case MT_FLOAT:
+		return math.Float32frombits(binary.LittleEndian.Uint32(data)), nil
+	case MT_DOUBLE:
+		return math.Float64frombits(binary.LittleEndian.Uint64(data)), nil
+	case MT_DECIMAL:
+		return strings.TrimSpace(string(data)), nil
+	case MT_NEWDECIMAL:
+		return DecodeDecimal(data, int(c.Precision()), int(c.Scale)), nil
+	case MT_NULL:
+		return nil, nil
+	case MT_YEAR:
+		if data[0] == 0 {
+			return uint64(0), nil
+		}
+		return uint64(data[0]) + 1900, nil
+	case MT_DATE:
+		v := binary.LittleEndian.Uint32(data)
+		return fmt.Sprintf("%04d-%02d-%02d", v/10000, v/100%100, v%100), nil
+	case MT_NEWDATE:
+		v := utils.Uint24LE(data)
+		return fmt.Sprintf("%04d-%02d-%02d", v>>9, (v>>5)&0xF, v&0x1F), nil
+	case MT_TIME:
+		return decodeTime(data, c.temporalScale(MAX_TIME_WIDTH))
+	case MT_TIME2:
+		return decodeTime2(data, c.temporalScale(MAX_TIME_WIDTH)), nil
+	case MT_DATETIME:
+		v := binary.LittleEndian.Uint64(data)
+		return fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d",
+			v/10000000000, v/100000000%100, v/1000000%100,
+			v/10000%100, v/100%100, v%100), nil
+	case MT_DATETIME2:
+		return decodeDatetime2(data, c.temporalScale(MAX_DATETIME_WIDTH)), nil
+	case MT_TIMESTAMP, MT_TIMESTAMP2:
+		return decodeTimestamp(c.TypeCode, data, c.temporalScale(MAX_DATETIME_WIDTH)), nil
+	case MT_BIT:
+		// the bits kept in the null bytes are the high ones, as in
+		// Field_bit::val_int
+		var value uint64
+		if f.BitPos >= 0 {
+			value = uint64(recordBits(record, f.BitPos, f.BitLength))
+		}
+		for _, b := range data {
+			value = value<<8 | uint64(b)
+		}
+		return value, nil
+	case MT_ENUM:
+		index := decodeInteger(data, false).(uint64)
+		if index == 0 || index > uint64(len(c.LabelStrs)) {
+			return "", nil
+		}
+		return c.LabelStrs[index-1], nil
+	case MT_SET:
+		bits := decodeInteger(data, false).(uint64)
+		var labels []string
+		for i, label := range c.LabelStrs {
+			if bits&(1<<uint(i)) != 0 {
+				labels = append(labels, label)
+			}
+		}
+		return strings.Join(labels, ","), nil
+	case MT_STRING:
+		if c.Collation.CharsetName == charset.CharsetBin {
+			return append([]byte(nil), data...), nil
+		}
+		return c.decodeString(bytes.TrimRight(data, " "))
+	case MT_VAR_STRING:
+		return c.decodeString(bytes.TrimRight(data, " "))
+	case MT_VARCHAR:
+		lengthBytes := f.PackLength - uint32(c.Length)
+		length := uint32(data[0])
+		if lengthBytes == 2 {
+			length = uint32(binary.LittleEndian.Uint16(data))
+		}
+		if lengthBytes+length > f.PackLength {
+			return nil, fmt.Errorf("varchar length %d exceeds %d", length, c.Length)
+		}
+		return c.decodeString(data[lengthBytes : lengthBytes+length])
+	case MT_TINY_BLOB, MT_MEDIUM_BLOB, MT_LONG_BLOB, MT_BLOB, MT_JSON, MT_GEOMETRY:
+		lengthBytes := f.PackLength - PORTABLE_SIZEOF_CHAR_PTR
+		ref := &BlobRef{
+			Length:  uint32(decodeInteger(data[:lengthBytes], false).(uint64)),
+			Pointer: binary.LittleEndian.Uint64(data[lengthBytes:]),
+		}
+		if d.BlobData == nil {
+			return ref, nil
+		}
+		blob, err := d.BlobData(f, ref)
+		if err != nil {
+			return nil, err
+		}
+		if c.TypeCode == MT_JSON || c.TypeCode == MT_GEOMETRY {
+			return blob, nil
+		}
+		return c.decodeString(blob)
+	}
+	return nil, fmt.Errorf("unsupported type %d", c.TypeCode)
+}
+
+// decodeString returns data converted to UTF-8, binary strings are
+// returned as []byte
+func (c *Column) decodeString(data []byte) (interface{}, error) {
+	if c.Collation == nil || c.Collation.CharsetName == charset.CharsetBin {
+		return append([]byte(nil), data...), nil
+	}
+	return utils.UTF8Decoder(data, c.Collation.CharsetName)
+}
+
+// decodeInteger decodes a little-endian integer of 1 to 8 bytes
+func decodeInteger(data []byte, isSigned bool) interface{} {
+	var value uint64
+	for i := len(data) - 1; i >= 0; i-- {
+		value = value<<8 | uint64(data[i])
+	}
+	if !isSigned {
+		return value
+	}
+	shift := uint(64 - 8*len(data))
+	return int64(value<<shift) >> shift
+}
+
+// recordBits returns length bits starting at bit pos of the null bytes
+func recordBits(record []byte, pos, length int) uint16 {
+	value := uint16(record[pos/8])
+	if pos%8+length > 8 {
+		value |= uint16(record[pos/8+1]) << 8
+	}
+	return (value >> (pos % 8)) & (1<<length - 1)
+}
+
+// bigEndianInt decodes a big-endian signed integer of 1 to 8 bytes
+func bigEndianInt(data []byte) int64 {
+	var value uint64
+	for _, b := range data {
+		value = value<<8 | uint64(b)
+	}
+	shift := uint(64 - 8*len(data))
+	return int64(value<<shift) >> shift
+}
+
+// fracMicroseconds decodes the fractional seconds part of the binary
+// temporal types into microseconds
+func fracMicroseconds(data []byte, scale int32) int64 {
+	switch utils.DigitsToBytes[scale] {
+	case 1:
+		return bigEndianInt(data[:1]) * 10000
+	case 2:
+		return bigEndianInt(data[:2]) * 100
+	case 3:
+		return bigEndianInt(data[:3])
+	}
+	return 0
+}
+
+func formatFrac(usec int64, scale int32) string {
+	if scale <= 0 {
+		return ""
+	}
+	return "." + fmt.Sprintf("%06d", usec)[:scale]
+}
+
+// decodeTime decodes the old TIME type, including the MariaDB TIME(N)
+func decodeTime(data []byte, scale int32) (string, error) {
+	if scale == 0 {
+		value := bigEndianInt([]byte{data[2], data[1], data[0]})
+		sign := ""
+		if value < 0 {
+			sign = "-"
+			value = -value
+		}
+		return fmt.Sprintf("%s%02d:%02d:%02d", sign, value/10000, value/100%100, value%100), nil
+	}
+	var value uint64
+	for _, b := range data {
+		value = value<<8 | uint64(b)
+	}
+	zeroPoint := secPartShift((TIME_MAX_VALUE_SECONDS+1)*TIME_SECOND_PART_FACTOR, int(scale))
+	usec := int64(secPartUnshift(int(value)-zeroPoint, int(scale)))
+	sign := ""
+	if usec < 0 {
+		sign = "-"
+		usec = -usec
+	}
+	seconds := usec / TIME_SECOND_PART_FACTOR
+	return fmt.Sprintf("%s%02d:%02d:%02d%s", sign, seconds/3600, seconds/60%60, seconds%60,
+		formatFrac(usec%TIME_SECOND_PART_FACTOR, scale)), nil
+}
+
+// decodeTime2 follows my_time_packed_from_binary, the fractional part of
+// a negative value is stored as an unsigned offset below the integer part
+func decodeTime2(data []byte, scale int32) string {
+	var packed int64
+	switch fracBytes := utils.DigitsToBytes[scale]; fracBytes {
+	case 1, 2:
+		intPart := int64(utils.Uint24BE(data)) - TIMEF_INT_OFS
+		frac := int64(data[3])
+		if fracBytes == 2 {
+			frac = int64(binary.BigEndian.Uint16(data[3:]))
+		}
+		if intPart < 0 && frac != 0 {
+			intPart++
+			frac -= 1 << (8 * fracBytes)
+		}
+		// hundredths or ten thousandths of a second
+		factor := int64(10000)
+		if fracBytes == 2 {
+			factor = 100
+		}
+		packed = intPart<<24 + frac*factor
+	case 3:
+		packed = int64(utils.Uint48BE(data)) - TIMEF_OFS
+	default:
+		packed = (int64(utils.Uint24BE(data)) - TIMEF_INT_OFS) << 24
+	}
+	sign := ""
+	if packed < 0 {
+		sign = "-"
+		packed = -packed
+	}
+	hms := packed >> 24
+	return fmt.Sprintf("%s%02d:%02d:%02d%s", sign,
+		(hms>>12)%(1<<10), (hms>>6)%(1<<6), hms%(1<<6),
+		formatFrac(packed%(1<<24), scale))
+}
+
+// decodeDatetime2 follows my_datetime_packed_from_binary
+func decodeDatetime2(data []byte, scale int32) string {
+	ymdhms := int64(utils.Uint40BE(data)) - DATETIMEF_INT_OFS
+	ymd := ymdhms >> 17
+	ym := ymd >> 5
+	hms := ymdhms % (1 << 17)
+	return fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d%s",
+		ym/13, ym%13, ymd%(1<<5), hms>>12, (hms>>6)%(1<<6), hms%(1<<6),
+		formatFrac(fracMicroseconds(data[5:], scale), scale))
+}
+
+// decodeTimestamp returns the timestamp in UTC, the old TIMESTAMP is
+// stored in the byte order of the host and the new one in big-endian
+func decodeTimestamp(typeCode MySQLType, data []byte, scale int32) string {
+	var seconds uint32
+	if typeCode == MT_TIMESTAMP && scale == 0 {
+		seconds = binary.LittleEndian.Uint32(data)
+	} else {
+		seconds = binary.BigEndian.Uint32(data)
+	}
+	if seconds == 0 {
+		return "0000-00-00 00:00:00" + formatFrac(0, scale)
+	}
+	var usec int64
+	if scale > 0 {
+		var frac uint64
+		for _, b := range data[4:] {
+			frac = frac<<8 | uint64(b)
+		}
+		usec = int64(frac)
+		switch utils.DigitsToBytes[scale] {
+		case 1:
+			usec *= 10000
+		case 2:
+			usec *= 100
+		}
+	}
+	return time.Unix(int64(seconds), 0).UTC().Format("2006-01-02 15:04:05") +
+		formatFrac(usec, scale)
+}
+
+// DecodeDecimal decodes a DECIMAL(precision,scale) in the binary format
+// of decimal2bin
+func DecodeDecimal(data []byte, precision, scale int) string {
+	buf := append([]byte(nil), data...)
+	isNegative := buf[0]&0x80 == 0
+	buf[0] ^= 0x80
+	if isNegative {
+		for i := range buf {
+			buf[i] = ^buf[i]
+		}
+	}
+	intDigits := precision - scale
+	var sb strings.Builder
+	pos := 0
+	readDigits := func(digits int) string {
+		n := utils.DigitsToBytes[digits]
+		if digits == 9 {
+			n = 4
+		}
+		var value uint64
+		for _, b := range buf[pos : pos+n] {
+			value = value<<8 | uint64(b)
+		}
+		pos += n
+		return strconv.FormatUint(value, 10)
+	}
+	// leading integer digits, then groups of 9 digits
+	if lead := intDigits % 9; lead > 0 {
+		sb.WriteString(readDigits(lead))
+	}
+	for i := 0; i < intDigits/9; i++ {
+		sb.WriteString(utils.Zfill(readDigits(9), 9))
+	}
+	integerPart := strings.TrimLeft(sb.String(), "0")
+	if integerPart == "" {
+		integerPart = "0"
+	}
+	result := integerPart
+	if scale > 0 {
+		sb.Reset()
+		for i := 0; i < scale/9; i++ {
+			sb.WriteString(utils.Zfill(readDigits(9), 9))
+		}
+		if trail := scale % 9; trail > 0 {
+			sb.WriteString(utils.Zfill(readDigits(trail), trail))
+		}
+		result += "." + sb.String()
+	}
+	if isNegative {
+		result = "-" + result
+	}
+	return result
+}
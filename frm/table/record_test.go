@@ -0,0 +1,145 @@
+package table
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeTime2(t *testing.T) {
+	for _, c := range []struct {
+		data  []byte
+		scale int32
+		want  string
+	}{
+		{[]byte{0x80, 0x10, 0x83}, 0, "01:02:03"},
+		{[]byte{0x7f, 0xef, 0x7d}, 0, "-01:02:03"},
+		{[]byte{0x80, 0x00, 0x01, 0x32}, 1, "00:00:01.5"},
+		{[]byte{0x7f, 0xff, 0xfe, 0xce}, 1, "-00:00:01.5"},
+		{[]byte{0x7f, 0xff, 0xff, 0x00}, 2, "-00:00:01.00"},
+		{[]byte{0x7f, 0xff, 0xfe, 0xf4}, 2, "-00:00:01.12"},
+		{[]byte{0x80, 0x00, 0x01, 0x13, 0x88}, 3, "00:00:01.500"},
+		{[]byte{0x7f, 0xff, 0xfe, 0xec, 0x78}, 3, "-00:00:01.500"},
+		{[]byte{0x7f, 0xff, 0xfe, 0xfb, 0x2e}, 4, "-00:00:01.1234"},
+		{[]byte{0x80, 0x00, 0x01, 0x07, 0xa1, 0x20}, 6, "00:00:01.500000"},
+		{[]byte{0x7f, 0xff, 0xfe, 0xf8, 0x5e, 0xe0}, 6, "-00:00:01.500000"},
+		{[]byte{0x7f, 0xff, 0xfe, 0xfe, 0x1d, 0xc6}, 5, "-00:00:01.12345"},
+		{[]byte{0x4b, 0x91, 0x05, 0x00}, 1, "-838:59:59.0"},
+	} {
+		if got := decodeTime2(c.data, c.scale); got != c.want {
+			t.Errorf("decodeTime2(% x, %d) = %s, want %s", c.data, c.scale, got, c.want)
+		}
+	}
+}
+
+func TestRecordDecoder(t *testing.T) {
+	collation := func(name string) *Collation {
+		c, err := GetCollationByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	latin1, utf8mb4, binary := collation("latin1_swedish_ci"), collation("utf8mb4_general_ci"), collation("binary")
+	// each column with the bytes of its field and the decoded value
+	columns := []struct {
+		column *Column
+		data   []byte
+		// bits are the bits a BIT column keeps in the null bytes
+		bits uint16
+		null bool
+		want interface{}
+	}{
+		{column: &Column{Name: "id", TypeCode: MT_LONG, Length: 11, Flags: FF_DECIMAL},
+			data: []byte{0xfb, 0xff, 0xff, 0xff}, want: int64(-5)},
+		{column: &Column{Name: "u", TypeCode: MT_LONGLONG, Length: 20},
+			data: bytes.Repeat([]byte{0xff}, 8), want: uint64(math.MaxUint64)},
+		{column: &Column{Name: "m", TypeCode: MT_INT24, Length: 9, Flags: FF_DECIMAL},
+			data: []byte{0x00, 0x00, 0x80}, want: int64(-8388608)},
+		{column: &Column{Name: "price", TypeCode: MT_NEWDECIMAL, Length: 12, Scale: 2, Flags: FF_DECIMAL | FF_MAYBE_NULL},
+			data: []byte{0x7f, 0xff, 0xff, 0x84, 0xd2}, want: "-123.45"},
+		{column: &Column{Name: "big", TypeCode: MT_NEWDECIMAL, Length: 21, Scale: 10},
+			data: []byte{0x80, 0x00, 0x00, 0x00, 0x0c, 0x07, 0x5b, 0xcd, 0x15, 0x00},
+			want: "12.1234567890"},
+		{column: &Column{Name: "f", TypeCode: MT_FLOAT, Length: 12, Flags: FF_DECIMAL},
+			data: []byte{0x00, 0x00, 0xc0, 0x3f}, want: float32(1.5)},
+		{column: &Column{Name: "g", TypeCode: MT_DOUBLE, Length: 22, Flags: FF_DECIMAL},
+			data: []byte{0, 0, 0, 0, 0, 0, 0x02, 0xc0}, want: float64(-2.25)},
+		{column: &Column{Name: "d", TypeCode: MT_NEWDATE, Length: 10},
+			data: []byte{0x8a, 0xd0, 0x0f}, want: "2024-04-10"},
+		{column: &Column{Name: "dt", TypeCode: MT_DATETIME2, Length: 23},
+			data: []byte{0x99, 0xb3, 0x14, 0x94, 0x09, 0x04, 0xce}, want: "2024-04-10 09:16:09.123"},
+		{column: &Column{Name: "old_dt", TypeCode: MT_DATETIME, Length: 19},
+			data: []byte{0x59, 0x20, 0x74, 0x96, 0x68, 0x12, 0x00, 0x00}, want: "2024-04-10 09:16:09"},
+		{column: &Column{Name: "ts", TypeCode: MT_TIMESTAMP2, Length: 19},
+			data: []byte{0x66, 0x16, 0x58, 0xd9}, want: "2024-04-10 09:16:09"},
+		{column: &Column{Name: "ts6", TypeCode: MT_TIMESTAMP2, Length: 26},
+			data: []byte{0x66, 0x16, 0x58, 0xd9, 0x07, 0xa1, 0x20}, want: "2024-04-10 09:16:09.500000"},
+		{column: &Column{Name: "tm", TypeCode: MT_TIME2, Length: 10},
+			data: []byte{0x7f, 0xef, 0x7d}, want: "-01:02:03"},
+		{column: &Column{Name: "y", TypeCode: MT_YEAR, Length: 4},
+			data: []byte{124}, want: uint64(2024)},
+		{column: &Column{Name: "b", TypeCode: MT_BIT, Length: 10},
+			data: []byte{0xce}, bits: 0b10, want: uint64(0b1011001110)},
+		{column: &Column{Name: "e", TypeCode: MT_ENUM, LabelStrs: []string{"new", "paid"}},
+			data: []byte{2}, want: "paid"},
+		{column: &Column{Name: "s", TypeCode: MT_SET, LabelStrs: []string{"a", "b", "c"}},
+			data: []byte{5}, want: "a,c"},
+		{column: &Column{Name: "c", TypeCode: MT_STRING, Length: 4, Collation: latin1},
+			data: []byte("ab  "), want: "ab"},
+		{column: &Column{Name: "v", TypeCode: MT_VARCHAR, Length: 300, Collation: utf8mb4},
+			data: append([]byte{6, 0}, "héllo"...), want: "héllo"},
+		{column: &Column{Name: "bin", TypeCode: MT_STRING, Length: 3, Collation: binary},
+			data: []byte{0, 'a', ' '}, want: []byte{0, 'a', ' '}},
+		{column: &Column{Name: "note", TypeCode: MT_BLOB, Collation: utf8mb4, Flags: FF_MAYBE_NULL},
+			data: []byte{9, 0, 1, 0, 0, 0, 0, 0, 0, 0}, want: "blob text"},
+		{column: &Column{Name: "n", TypeCode: MT_LONG, Length: 11, Flags: FF_DECIMAL | FF_MAYBE_NULL},
+			null: true, want: nil},
+	}
+	mt := &MySQLTable{Options: &Options{}, Columns: &Columns{}}
+	for _, c := range columns {
+		mt.Columns.Items = append(mt.Columns.Items, c.column)
+	}
+	d, err := NewRecordDecoder(mt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the null bits start after the bit of deleted records, then come the
+	// 2 bits of the BIT column, in column order
+	if d.Layout.NullBytes != 1 || d.Layout.Length != 402 {
+		t.Fatalf("null bytes %d, length %d", d.Layout.NullBytes, d.Layout.Length)
+	}
+	record := make([]byte, d.Layout.Length)
+	for i, c := range columns {
+		f := d.Layout.Fields[i]
+		if uint32(len(c.data)) != f.PackLength && !c.null && c.column.TypeCode != MT_VARCHAR {
+			t.Fatalf("%s: pack length %d, test data %d", c.column.Name, f.PackLength, len(c.data))
+		}
+		copy(record[f.Offset:], c.data)
+		if c.null {
+			record[f.NullPos/8] |= 1 << (f.NullPos % 8)
+		}
+		if f.BitPos >= 0 {
+			record[f.BitPos/8] |= byte(c.bits << (f.BitPos % 8))
+		}
+	}
+	d.BlobData = func(f *FieldLayout, ref *BlobRef) ([]byte, error) {
+		if ref.Length != 9 || ref.Pointer != 1 {
+			t.Errorf("blob reference %+v", ref)
+		}
+		return []byte("blob text"), nil
+	}
+	values, err := d.Decode(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range columns {
+		if !reflect.DeepEqual(values[i], c.want) {
+			t.Errorf("%s: got %#v, want %#v", c.column.Name, values[i], c.want)
+		}
+	}
+	if _, err := d.Decode(record[:10]); err == nil {
+		t.Error("no error for a short record")
+	}
+}
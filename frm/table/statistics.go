@@ -0,0 +1,16 @@
+package table
+
+import "time"
+
+// Statistics are the table figures the storage engine keeps outside
+// of the .frm, as reported by SHOW TABLE STATUS
+type Statistics struct {
+	Rows        uint64
+	DeletedRows uint64
+	DataLength  uint64
+	IndexLength uint64
+	DataFree    uint64
+	Checksum    uint64
+	CreateTime  time.Time
+	CheckTime   time.Time
+}
@@ -20,6 +20,11 @@ type MySQLTable struct {
 	Columns      *Columns
 	Collation    *Collation
 	Options      *Options
+	ForeignKeys  []*ForeignKey
+	Statistics   *Statistics
+	// Warnings are the optional files next to the .frm which could not be
+	// read
+	Warnings []string
 }
 
 func NewMySQLTable(path string, data []byte, fi *FileInfo) (mt *MySQLTable, err error) {
@@ -81,6 +86,9 @@ func (mt *MySQLTable) String() string {
 	if mt.Keys.Combined != "" {
 		columnKeys += ",\n" + mt.Keys.Combined
 	}
+	for _, fk := range mt.ForeignKeys {
+		columnKeys += ",\n  " + fk.String()
+	}
 	parts := []string{
 		"",
 		fmt.Sprintf("CREATE TABLE `%s` (", mt.Name),
@@ -97,14 +105,18 @@ func (mt *MySQLTable) StringWithHeader() string {
 		"--",
 		fmt.Sprintf("-- Table structure for table `%s`", mt.Name),
 		fmt.Sprintf("-- Created with MySQL Version %s", mt.MySQLVersion.String()),
-		"--",
-		mt.String(),
 	}
+	for _, warning := range mt.Warnings {
+		parts = append(parts, "-- Warning: "+warning)
+	}
+	parts = append(parts, "--", mt.String())
 	return strings.Join(parts, "\n")
 }
 
 func (mt *MySQLTable) Decode(data []byte) error {
 	mt.DecodeOptions()
+	// the raw clause is kept when the model can't be parsed
+	_ = mt.Options.DecodePartitioning()
 	err := mt.Columns.Decode(mt)
 	if err != nil {
 		return err
@@ -0,0 +1,176 @@
+package trigger
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zing22845/go-frm-parser/frm/utils"
+)
+
+const (
+	// TRG_FILE_TYPE is the first line of .TRG files
+	TRG_FILE_TYPE = "TYPE=TRIGGERS"
+)
+
+var (
+	// identifierPattern matches a plain or back-quoted identifier
+	identifierPattern = "(?:`(?:[^`]|``)*`|[\\w$]+)"
+	// createTriggerRegex splits a CREATE TRIGGER statement
+	createTriggerRegex = regexp.MustCompile(`(?is)^\s*CREATE\s+(?:DEFINER\s*=\s*(\S+)\s+)?TRIGGER\s+` +
+		`(` + identifierPattern + `(?:\.` + identifierPattern + `)?)\s+(BEFORE|AFTER)\s+(INSERT|UPDATE|DELETE)\s+` +
+		`ON\s+` + identifierPattern + `(?:\.` + identifierPattern + `)?\s+FOR\s+EACH\s+ROW\s+` +
+		`(?:((?:FOLLOWS|PRECEDES)\s+` + identifierPattern + `)\s+)?(.*)$`)
+)
+
+// Parse parses the triggers of a .TRG file, the table is named after the
+// file
+func Parse(path string, data string) (triggers []*MySQLTrigger, err error) {
+	if !strings.HasPrefix(data, TRG_FILE_TYPE) {
+		return nil, fmt.Errorf("%s is not a .TRG file", path)
+	}
+	tableName, err := utils.DecodeMySQLFile2Object(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string][]string)
+	for _, line := range strings.Split(data, "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		values[key], err = splitValues(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, key, err)
+		}
+	}
+	for i, statement := range values["triggers"] {
+		t := &MySQLTrigger{Table: tableName, Statement: statement}
+		match := createTriggerRegex.FindStringSubmatch(statement)
+		if match == nil {
+			return nil, fmt.Errorf("%s: can't parse trigger %q", path, statement)
+		}
+		t.Definer = match[1]
+		t.Name = unquoteIdentifier(match[2])
+		t.Timing = strings.ToUpper(match[3])
+		t.Event = strings.ToUpper(match[4])
+		t.Order = match[5]
+		t.Body = match[6]
+		if t.Definer == "" {
+			t.Definer = definer(item(values["definers"], i))
+		}
+		t.SQLMode, _ = strconv.ParseUint(item(values["sql_modes"], i), 10, 64)
+		t.ClientCharset = item(values["client_cs_names"], i)
+		t.ConnectionCollation = item(values["connection_cl_names"], i)
+		t.DatabaseCollation = item(values["db_cl_names"], i)
+		if created, err := strconv.ParseInt(item(values["created"], i), 10, 64); err == nil && created != 0 {
+			// hundredths of a second since the epoch
+			t.Created = time.UnixMilli(created * 10).UTC()
+		}
+		triggers = append(triggers, t)
+	}
+	return triggers, nil
+}
+
+func item(values []string, i int) string {
+	if i < len(values) {
+		return values[i]
+	}
+	return ""
+}
+
+// definer quotes the user@host of the definers list
+func definer(s string) string {
+	if s == "" {
+		return ""
+	}
+	user, host := s, ""
+	if i := strings.LastIndex(s, "@"); i >= 0 {
+		user, host = s[:i], s[i+1:]
+	}
+	return quoteIdentifier(user) + "@" + quoteIdentifier(host)
+}
+
+// unquoteIdentifier returns the name of a trigger, without its schema
+func unquoteIdentifier(s string) string {
+	var parts []string
+	for len(s) != 0 {
+		if s[0] == '`' {
+			end := 1
+			for end < len(s) {
+				if s[end] == '`' {
+					if end+1 < len(s) && s[end+1] == '`' {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			parts = append(parts, strings.ReplaceAll(s[1:end], "``", "`"))
+			s = s[min(end+1, len(s)):]
+		} else {
+			end := strings.IndexByte(s, '.')
+			if end < 0 {
+				end = len(s)
+			}
+			parts = append(parts, s[:end])
+			s = s[end:]
+		}
+		s = strings.TrimPrefix(s, ".")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return parts[len(parts)-1]
+}
+
+// splitValues splits the space separated values of a list parameter,
+// strings are quoted and escaped like read_escaped_string does
+func splitValues(s string) (values []string, err error) {
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case ' ':
+			i++
+		case '\'':
+			var b strings.Builder
+			i++
+			for ; i < len(s) && s[i] != '\''; i++ {
+				if s[i] != '\\' {
+					b.WriteByte(s[i])
+					continue
+				}
+				i++
+				if i == len(s) {
+					return nil, fmt.Errorf("unterminated escape")
+				}
+				switch s[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case '0':
+					b.WriteByte(0)
+				case 'z':
+					b.WriteByte(26)
+				default:
+					b.WriteByte(s[i])
+				}
+			}
+			if i == len(s) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			values = append(values, b.String())
+			i++
+		default:
+			end := strings.IndexByte(s[i:], ' ')
+			if end < 0 {
+				end = len(s) - i
+			}
+			values = append(values, s[i:i+end])
+			i += end
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,77 @@
+package trigger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zing22845/go-frm-parser/frm/utils"
+)
+
+// MySQLTrigger is a trigger of a table, MySQL 5.x keeps the triggers of
+// a table in its .TRG file
+type MySQLTrigger struct {
+	Name    string
+	Table   string
+	Definer string
+	// Timing is BEFORE or AFTER, Event INSERT, UPDATE or DELETE
+	Timing string
+	Event  string
+	// Order is the FOLLOWS or PRECEDES clause, if any
+	Order string
+	Body  string
+	// Statement is the CREATE TRIGGER statement as stored
+	Statement           string
+	SQLMode             uint64
+	ClientCharset       string
+	ConnectionCollation string
+	DatabaseCollation   string
+	// Created is zero for triggers created before MySQL 5.7.2
+	Created time.Time
+}
+
+func (t *MySQLTrigger) GetName() string {
+	return t.Name
+}
+
+func (t *MySQLTrigger) String() string {
+	return t.Statement + ";\n"
+}
+
+func (t *MySQLTrigger) StringWithHeader() string {
+	return strings.Join([]string{
+		"--",
+		fmt.Sprintf("-- Trigger: %s on table %s", t.Name, t.Table),
+		"--",
+		"",
+		t.String(),
+	}, "\n")
+}
+
+// Canonical returns the normal form of the trigger, whitespace outside
+// of strings and quoted identifiers is collapsed
+func (t *MySQLTrigger) Canonical() string {
+	parts := []string{"CREATE"}
+	if t.Definer != "" {
+		parts = append(parts, "DEFINER="+t.Definer)
+	}
+	parts = append(parts, "TRIGGER", quoteIdentifier(t.Name), t.Timing, t.Event,
+		"ON", quoteIdentifier(t.Table), "FOR EACH ROW")
+	if t.Order != "" {
+		parts = append(parts, t.Order)
+	}
+	parts = append(parts, utils.CollapseWhitespace(t.Body))
+	return strings.Join(parts, " ") + "\n"
+}
+
+// Fingerprint is the hex SHA-256 of the canonical form of the trigger
+func (t *MySQLTrigger) Fingerprint() string {
+	sum := sha256.Sum256([]byte(t.Canonical()))
+	return hex.EncodeToString(sum[:])
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
@@ -0,0 +1,51 @@
+package trigger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	data := "TYPE=TRIGGERS\n" +
+		"triggers='CREATE DEFINER=`root`@`localhost` TRIGGER t1_bi BEFORE INSERT ON `t1` FOR EACH ROW SET NEW.note = \\'a  b\\'' " +
+		"'CREATE TRIGGER `test`.`t1_bi2` before insert ON t1 FOR EACH ROW FOLLOWS t1_bi\\nBEGIN\\n  SET NEW.id =  NEW.id + 1;\\nEND'\n" +
+		"sql_modes=1436549152 1436549152\n" +
+		"definers='root@localhost' 'app@%'\n" +
+		"client_cs_names='utf8mb4' 'utf8mb4'\n" +
+		"connection_cl_names='utf8mb4_general_ci' 'utf8mb4_general_ci'\n" +
+		"db_cl_names='utf8mb4_0900_ai_ci' 'utf8mb4_0900_ai_ci'\n" +
+		"created=171274056912 171274057000\n"
+	triggers, err := Parse("/data/test/t1.TRG", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		name      string
+		canonical string
+		created   time.Time
+	}{
+		{"t1_bi", "CREATE DEFINER=`root`@`localhost` TRIGGER `t1_bi` BEFORE INSERT ON `t1` FOR EACH ROW " +
+			"SET NEW.note = 'a  b'\n", time.UnixMilli(1712740569120).UTC()},
+		{"t1_bi2", "CREATE DEFINER=`app`@`%` TRIGGER `t1_bi2` BEFORE INSERT ON `t1` FOR EACH ROW FOLLOWS t1_bi " +
+			"BEGIN SET NEW.id = NEW.id + 1; END\n", time.UnixMilli(1712740570000).UTC()},
+	}
+	if len(triggers) != len(want) {
+		t.Fatalf("%d triggers, want %d", len(triggers), len(want))
+	}
+	for i, w := range want {
+		tr := triggers[i]
+		if tr.Name != w.name || tr.Table != "t1" || tr.SQLMode != 1436549152 || !tr.Created.Equal(w.created) {
+			t.Errorf("trigger %d: %+v", i, tr)
+		}
+		if got := tr.Canonical(); got != w.canonical {
+			t.Errorf("%s: canonical\n%s\nwant\n%s", tr.Name, got, w.canonical)
+		}
+	}
+
+	if _, err := Parse("t1.TRG", "TYPE=VIEW\n"); err == nil {
+		t.Error("no error for a view file")
+	}
+	if _, err := Parse("t1.TRG", "TYPE=TRIGGERS\ntriggers='DROP TRIGGER t1_bi'\n"); err == nil {
+		t.Error("no error for a statement which is not CREATE TRIGGER")
+	}
+}
@@ -0,0 +1,204 @@
+package upgrade
+
+import (
+	"strings"
+
+	"github.com/zing22845/go-frm-parser/frm/lint"
+	"github.com/zing22845/go-frm-parser/frm/table"
+	"github.com/zing22845/go-frm-parser/frm/view"
+)
+
+// nativePartitioningEngines are the engines which partition tables
+// themselves in 8.0, the generic partition handler is gone
+var nativePartitioningEngines = map[string]bool{
+	"INNODB":     true,
+	"NDBCLUSTER": true,
+	"NDB":        true,
+}
+
+// CheckTable checks a table of the schema
+func (r *Report) CheckTable(schema string, mt *table.MySQLTable) {
+	r.checkIdentifier(schema, mt.Name, "", "table", mt.Name)
+	if strings.Contains(mt.Name, "FTS") {
+		r.add(CHECK_FTS_TABLE_NAME, lint.SEVERITY_ERROR, schema, mt.Name, "",
+			"table name contains FTS, which InnoDB can mistake for a full-text auxiliary table, rename the table")
+	}
+	if mt.Options.Partitions != "" && !nativePartitioningEngines[strings.ToUpper(mt.Options.Engine)] {
+		r.add(CHECK_PARTITIONED_ENGINE, lint.SEVERITY_ERROR, schema, mt.Name, "",
+			"partitioned table uses the %s engine, which has no native partitioning in 8.0, "+
+				"convert it to InnoDB or remove the partitioning", mt.Options.Engine)
+	}
+	if mt.Collation != nil && isUTF8MB3(mt.Collation.CharsetName) {
+		r.add(CHECK_UTF8MB3, lint.SEVERITY_WARNING, schema, mt.Name, "",
+			"table default character set is utf8mb3, consider utf8mb4")
+	}
+	for _, c := range mt.Columns.Items {
+		r.checkIdentifier(schema, mt.Name, c.Name, "column", c.Name)
+		switch c.TypeCode {
+		case table.MT_TIME, table.MT_DATETIME, table.MT_TIMESTAMP:
+			name, _ := c.TypeCode.Name()
+			r.add(CHECK_OLD_TEMPORAL, lint.SEVERITY_ERROR, schema, mt.Name, c.Name,
+				"%s column uses the pre-5.6.4 storage format, rebuild the table with ALTER TABLE ... FORCE",
+				strings.ToUpper(name))
+		case table.MT_TINY, table.MT_SHORT, table.MT_INT24, table.MT_LONG, table.MT_LONGLONG:
+			r.checkDisplayWidth(schema, mt.Name, c)
+		}
+		if c.Collation != nil && c.Collation != mt.Collation && isUTF8MB3(c.Collation.CharsetName) && table.HasCharset(c.TypeCode) {
+			r.add(CHECK_UTF8MB3, lint.SEVERITY_WARNING, schema, mt.Name, c.Name,
+				"column character set is utf8mb3, consider utf8mb4")
+		}
+	}
+	for _, k := range mt.Keys.Items {
+		if k.Name != "PRIMARY" {
+			r.checkIdentifier(schema, mt.Name, "", "index", k.Name)
+		}
+	}
+}
+
+// checkDisplayWidth reports ZEROFILL and display widths, which 8.0.17
+// deprecates, but for TINYINT(1)
+func (r *Report) checkDisplayWidth(schema, tableName string, c *table.Column) {
+	name, _ := c.TypeCode.Name()
+	if c.Flags.HasFlag(table.FF_ZEROFILL) {
+		r.add(CHECK_ZEROFILL, lint.SEVERITY_NOTE, schema, tableName, c.Name,
+			"%s(%d) ZEROFILL is deprecated, the padding should be done by the application", name, c.Length)
+		return
+	}
+	if c.TypeCode == table.MT_TINY && c.Length == 1 {
+		return
+	}
+	widths := defaultDisplayWidths[name]
+	width := widths[0]
+	if !c.Flags.HasFlag(table.FF_DECIMAL) {
+		width = widths[1]
+	}
+	if c.Length != width {
+		r.add(CHECK_ZEROFILL, lint.SEVERITY_NOTE, schema, tableName, c.Name,
+			"display width %s(%d) is deprecated and ignored in 8.0", name, c.Length)
+	}
+}
+
+// CheckView checks a view of the schema
+func (r *Report) CheckView(schema string, v *view.MySQLView) {
+	r.checkIdentifier(schema, v.Name, "", "view", v.Name)
+	tokens := tokenize(v.Body)
+	reported := make(map[string]bool)
+	for i, token := range tokens {
+		word := strings.ToUpper(token)
+		if i+1 < len(tokens) && tokens[i+1] == "(" && !reported[word] {
+			// a function call, the names of qualified columns are quoted
+			replacement, removed := removedFunctions[word]
+			if removed && (i == 0 || tokens[i-1] != ".") {
+				reported[word] = true
+				if replacement != "" {
+					r.add(CHECK_REMOVED_SYNTAX, lint.SEVERITY_ERROR, schema, v.Name, "",
+						"function %s() was removed in 8.0, use %s()", word, replacement)
+				} else {
+					r.add(CHECK_REMOVED_SYNTAX, lint.SEVERITY_ERROR, schema, v.Name, "",
+						"function %s() was removed in 8.0", word)
+				}
+			}
+		}
+		switch word {
+		case "SQL_CACHE":
+			if !reported[word] {
+				reported[word] = true
+				r.add(CHECK_REMOVED_SYNTAX, lint.SEVERITY_ERROR, schema, v.Name, "",
+					"SQL_CACHE was removed in 8.0 with the query cache")
+			}
+		case "GROUP":
+			if i+1 < len(tokens) && strings.EqualFold(tokens[i+1], "BY") && groupByHasOrder(tokens[i+2:]) && !reported[word] {
+				reported[word] = true
+				r.add(CHECK_REMOVED_SYNTAX, lint.SEVERITY_ERROR, schema, v.Name, "",
+					"GROUP BY ... ASC/DESC was removed in 8.0.13, use ORDER BY")
+			}
+		}
+	}
+}
+
+// checkIdentifier reports an identifier which 8.0 reserves
+func (r *Report) checkIdentifier(schema, object, column, kind, name string) {
+	if !reservedKeywords[strings.ToUpper(name)] {
+		return
+	}
+	if kind == "schema" {
+		object = ""
+	}
+	r.add(CHECK_RESERVED_KEYWORDS, lint.SEVERITY_WARNING, schema, object, column,
+		"%s name %s is a reserved keyword in 8.0, it has to be quoted", kind, name)
+}
+
+// groupByHasOrder reports whether the GROUP BY list starting at tokens
+// sorts with ASC or DESC
+func groupByHasOrder(tokens []string) bool {
+	depth := 0
+	for _, token := range tokens {
+		switch strings.ToUpper(token) {
+		case "(":
+			depth++
+		case ")":
+			if depth == 0 {
+				return false
+			}
+			depth--
+		case "ASC", "DESC":
+			if depth == 0 {
+				return true
+			}
+		case "HAVING", "ORDER", "LIMIT", "UNION", "WINDOW", "PROCEDURE", "INTO", "FOR", "LOCK":
+			if depth == 0 {
+				return false
+			}
+		}
+	}
+	return false
+}
+
+// tokenize splits the SQL into words and punctuation, strings and quoted
+// identifiers are dropped
+func tokenize(sql string) (tokens []string) {
+	for i := 0; i < len(sql); {
+		ch := sql[i]
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`':
+			i = skipQuoted(sql, i)
+			tokens = append(tokens, "''")
+		case isWordByte(ch):
+			start := i
+			for i < len(sql) && isWordByte(sql[i]) {
+				i++
+			}
+			tokens = append(tokens, sql[start:i])
+		case ch == ' ' || ch == '\t' || ch == '\r' || ch == '\n':
+			i++
+		default:
+			tokens = append(tokens, sql[i:i+1])
+			i++
+		}
+	}
+	return tokens
+}
+
+// skipQuoted returns the end of the string or identifier quoted at i
+func skipQuoted(sql string, i int) int {
+	quote := sql[i]
+	for i++; i < len(sql); i++ {
+		switch sql[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return i
+}
+
+func isWordByte(ch byte) bool {
+	return ch == '_' || ch == '$' || ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= 0x80
+}
@@ -0,0 +1,170 @@
+package upgrade
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zing22845/go-frm-parser/frm/table"
+	"github.com/zing22845/go-frm-parser/frm/view"
+)
+
+func issues(r *Report) (lines []string) {
+	for _, issue := range r.Issues {
+		lines = append(lines, issue.String())
+	}
+	return lines
+}
+
+func collation(t *testing.T, name string) *table.Collation {
+	t.Helper()
+	c, err := table.GetCollationByName(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestCheckTable(t *testing.T) {
+	utf8mb3 := collation(t, "utf8_general_ci")
+	utf8mb4 := collation(t, "utf8mb4_general_ci")
+	for _, c := range []struct {
+		name string
+		mt   *table.MySQLTable
+		want []string
+	}{
+		{
+			name: "clean",
+			mt: &table.MySQLTable{
+				Name:      "orders",
+				Collation: utf8mb4,
+				Options:   &table.Options{Engine: "InnoDB", Partitions: " PARTITION BY HASH (id)"},
+				Columns: &table.Columns{Items: []*table.Column{
+					{Name: "id", TypeCode: table.MT_LONG, Length: 11, Flags: table.FF_DECIMAL},
+					{Name: "created", TypeCode: table.MT_DATETIME2},
+					{Name: "note", TypeCode: table.MT_VARCHAR, Length: 400, Collation: utf8mb4},
+				}},
+				Keys: &table.Keys{Items: []*table.Key{{Name: "PRIMARY"}}},
+			},
+		},
+		{
+			name: "issues",
+			mt: &table.MySQLTable{
+				Name:      "FTS_docs",
+				Collation: utf8mb3,
+				Options:   &table.Options{Engine: "MyISAM", Partitions: " PARTITION BY HASH (id)"},
+				Columns: &table.Columns{Items: []*table.Column{
+					{Name: "rank", TypeCode: table.MT_LONG, Length: 10, Flags: table.FF_DECIMAL},
+					{Name: "created", TypeCode: table.MT_DATETIME},
+					{Name: "t", TypeCode: table.MT_TIME},
+					// columns of the table default are not reported again
+					{Name: "note", TypeCode: table.MT_VARCHAR, Length: 300, Collation: utf8mb3},
+					{Name: "code", TypeCode: table.MT_STRING, Length: 30, Collation: collation(t, "utf8_bin")},
+				}},
+				Keys: &table.Keys{Items: []*table.Key{{Name: "PRIMARY"}, {Name: "groups"}}},
+			},
+			want: []string{
+				"error: db.FTS_docs - table name contains FTS, which InnoDB can mistake for a full-text auxiliary table, rename the table",
+				"error: db.FTS_docs - partitioned table uses the MyISAM engine, which has no native partitioning in 8.0, convert it to InnoDB or remove the partitioning",
+				"warning: db.FTS_docs - table default character set is utf8mb3, consider utf8mb4",
+				"warning: db.FTS_docs.rank - column name rank is a reserved keyword in 8.0, it has to be quoted",
+				"note: db.FTS_docs.rank - display width int(10) is deprecated and ignored in 8.0",
+				"error: db.FTS_docs.created - DATETIME column uses the pre-5.6.4 storage format, rebuild the table with ALTER TABLE ... FORCE",
+				"error: db.FTS_docs.t - TIME column uses the pre-5.6.4 storage format, rebuild the table with ALTER TABLE ... FORCE",
+				"warning: db.FTS_docs.code - column character set is utf8mb3, consider utf8mb4",
+				"warning: db.FTS_docs - index name groups is a reserved keyword in 8.0, it has to be quoted",
+			},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			r := &Report{}
+			r.CheckTable("db", c.mt)
+			if got := issues(r); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestCheckDisplayWidth(t *testing.T) {
+	for _, c := range []struct {
+		typeCode table.MySQLType
+		length   uint16
+		flags    table.FieldFlag
+		want     string
+	}{
+		// FF_DECIMAL is set on the signed integer columns
+		{table.MT_LONG, 11, table.FF_DECIMAL, ""},
+		{table.MT_LONG, 10, 0, ""},
+		{table.MT_LONG, 10, table.FF_DECIMAL, "display width int(10) is deprecated and ignored in 8.0"},
+		{table.MT_LONG, 11, 0, "display width int(11) is deprecated and ignored in 8.0"},
+		{table.MT_TINY, 4, table.FF_DECIMAL, ""},
+		{table.MT_TINY, 3, 0, ""},
+		{table.MT_TINY, 1, table.FF_DECIMAL, ""},
+		{table.MT_TINY, 1, 0, ""},
+		{table.MT_TINY, 2, table.FF_DECIMAL, "display width tinyint(2) is deprecated and ignored in 8.0"},
+		{table.MT_SHORT, 6, table.FF_DECIMAL, ""},
+		{table.MT_INT24, 8, 0, ""},
+		{table.MT_LONGLONG, 20, table.FF_DECIMAL, ""},
+		{table.MT_LONGLONG, 20, 0, ""},
+		{table.MT_LONG, 10, table.FF_ZEROFILL, "int(10) ZEROFILL is deprecated, the padding should be done by the application"},
+		{table.MT_TINY, 1, table.FF_ZEROFILL, "tinyint(1) ZEROFILL is deprecated, the padding should be done by the application"},
+	} {
+		r := &Report{}
+		r.checkDisplayWidth("db", "t1", &table.Column{Name: "c", TypeCode: c.typeCode, Length: c.length, Flags: c.flags})
+		var got string
+		if len(r.Issues) != 0 {
+			got = r.Issues[0].Message
+		}
+		if got != c.want || len(r.Issues) > 1 {
+			t.Errorf("type %d(%d) flags %d: got %q, want %q", c.typeCode, c.length, c.flags, got, c.want)
+		}
+	}
+}
+
+func TestCheckView(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		body string
+		want []string
+	}{
+		{
+			name: "removed functions",
+			body: "select password(`t1`.`a`) AS `p`,encode(`t1`.`b`,'k') AS `e`,PASSWORD(`t1`.`c`) AS `p2` from `test`.`t1`",
+			want: []string{
+				"error: db.v1 - function PASSWORD() was removed in 8.0",
+				"error: db.v1 - function ENCODE() was removed in 8.0, use AES_ENCRYPT()",
+			},
+		},
+		{
+			name: "qualified names",
+			body: "select `test`.`area`(`t1`.`g`) AS `a`,test.glength(`t1`.`g`) AS `l`,`t1`.`x` AS `x`," +
+				"'password(' AS `s` from `test`.`t1`",
+		},
+		{
+			name: "GROUP BY DESC",
+			body: "select `t1`.`a` AS `a`,count(0) AS `n` from `test`.`t1` group by `t1`.`a` desc",
+			want: []string{"error: db.v1 - GROUP BY ... ASC/DESC was removed in 8.0.13, use ORDER BY"},
+		},
+		{
+			name: "GROUP BY ORDER BY DESC",
+			body: "select `t1`.`a` AS `a` from `test`.`t1` group by `t1`.`a` having (count(0) > 1) order by `t1`.`a` desc",
+		},
+		{
+			name: "GROUP BY in a subquery",
+			body: "select `s`.`a` AS `a` from (select `t1`.`a` AS `a` from `test`.`t1` group by `t1`.`a`) `s` order by `s`.`a` desc",
+		},
+		{
+			name: "SQL_CACHE",
+			body: "select sql_cache `t1`.`a` AS `a` from `test`.`t1`",
+			want: []string{"error: db.v1 - SQL_CACHE was removed in 8.0 with the query cache"},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			r := &Report{}
+			r.CheckView("db", &view.MySQLView{Name: "v1", Body: c.body})
+			if got := issues(r); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package upgrade
+
+// reservedKeywords are the words MySQL 8.0 reserves which 5.7 doesn't,
+// identifiers named so have to be quoted after the upgrade
+var reservedKeywords = map[string]bool{
+	"ARRAY":        true,
+	"CUBE":         true,
+	"CUME_DIST":    true,
+	"DENSE_RANK":   true,
+	"EMPTY":        true,
+	"EXCEPT":       true,
+	"FIRST_VALUE":  true,
+	"FUNCTION":     true,
+	"GROUPING":     true,
+	"GROUPS":       true,
+	"INTERSECT":    true,
+	"JSON_TABLE":   true,
+	"LAG":          true,
+	"LAST_VALUE":   true,
+	"LATERAL":      true,
+	"LEAD":         true,
+	"MEMBER":       true,
+	"NTH_VALUE":    true,
+	"NTILE":        true,
+	"OF":           true,
+	"OVER":         true,
+	"PERCENT_RANK": true,
+	"RANK":         true,
+	"RECURSIVE":    true,
+	"ROW":          true,
+	"ROWS":         true,
+	"ROW_NUMBER":   true,
+	"SYSTEM":       true,
+	"WINDOW":       true,
+}
+
+// removedFunctions are the functions MySQL 8.0 removed, with their
+// replacement if there is one
+var removedFunctions = map[string]string{
+	"ENCODE":                     "AES_ENCRYPT",
+	"DECODE":                     "AES_DECRYPT",
+	"ENCRYPT":                    "SHA2",
+	"DES_ENCRYPT":                "AES_ENCRYPT",
+	"DES_DECRYPT":                "AES_DECRYPT",
+	"PASSWORD":                   "",
+	"AREA":                       "ST_AREA",
+	"ASBINARY":                   "ST_ASBINARY",
+	"ASTEXT":                     "ST_ASTEXT",
+	"ASWKB":                      "ST_ASWKB",
+	"ASWKT":                      "ST_ASWKT",
+	"BUFFER":                     "ST_BUFFER",
+	"CENTROID":                   "ST_CENTROID",
+	"CONTAINS":                   "MBRCONTAINS",
+	"CONVEXHULL":                 "ST_CONVEXHULL",
+	"CROSSES":                    "ST_CROSSES",
+	"DIMENSION":                  "ST_DIMENSION",
+	"DISJOINT":                   "MBRDISJOINT",
+	"DISTANCE":                   "ST_DISTANCE",
+	"ENDPOINT":                   "ST_ENDPOINT",
+	"ENVELOPE":                   "ST_ENVELOPE",
+	"EQUALS":                     "MBREQUALS",
+	"EXTERIORRING":               "ST_EXTERIORRING",
+	"GEOMCOLLFROMTEXT":           "ST_GEOMCOLLFROMTEXT",
+	"GEOMCOLLFROMWKB":            "ST_GEOMCOLLFROMWKB",
+	"GEOMETRYCOLLECTIONFROMTEXT": "ST_GEOMETRYCOLLECTIONFROMTEXT",
+	"GEOMETRYCOLLECTIONFROMWKB":  "ST_GEOMETRYCOLLECTIONFROMWKB",
+	"GEOMETRYFROMTEXT":           "ST_GEOMETRYFROMTEXT",
+	"GEOMETRYFROMWKB":            "ST_GEOMETRYFROMWKB",
+	"GEOMETRYN":                  "ST_GEOMETRYN",
+	"GEOMETRYTYPE":               "ST_GEOMETRYTYPE",
+	"GEOMFROMTEXT":               "ST_GEOMFROMTEXT",
+	"GEOMFROMWKB":                "ST_GEOMFROMWKB",
+	"GLENGTH":                    "ST_LENGTH",
+	"INTERIORRINGN":              "ST_INTERIORRINGN",
+	"INTERSECTS":                 "MBRINTERSECTS",
+	"ISCLOSED":                   "ST_ISCLOSED",
+	"ISEMPTY":                    "ST_ISEMPTY",
+	"ISSIMPLE":                   "ST_ISSIMPLE",
+	"LINEFROMTEXT":               "ST_LINEFROMTEXT",
+	"LINEFROMWKB":                "ST_LINEFROMWKB",
+	"LINESTRINGFROMTEXT":         "ST_LINESTRINGFROMTEXT",
+	"LINESTRINGFROMWKB":          "ST_LINESTRINGFROMWKB",
+	"MLINEFROMTEXT":              "ST_MLINEFROMTEXT",
+	"MLINEFROMWKB":               "ST_MLINEFROMWKB",
+	"MPOINTFROMTEXT":             "ST_MPOINTFROMTEXT",
+	"MPOINTFROMWKB":              "ST_MPOINTFROMWKB",
+	"MPOLYFROMTEXT":              "ST_MPOLYFROMTEXT",
+	"MPOLYFROMWKB":               "ST_MPOLYFROMWKB",
+	"MULTILINESTRINGFROMTEXT":    "ST_MULTILINESTRINGFROMTEXT",
+	"MULTILINESTRINGFROMWKB":     "ST_MULTILINESTRINGFROMWKB",
+	"MULTIPOINTFROMTEXT":         "ST_MULTIPOINTFROMTEXT",
+	"MULTIPOINTFROMWKB":          "ST_MULTIPOINTFROMWKB",
+	"MULTIPOLYGONFROMTEXT":       "ST_MULTIPOLYGONFROMTEXT",
+	"MULTIPOLYGONFROMWKB":        "ST_MULTIPOLYGONFROMWKB",
+	"NUMGEOMETRIES":              "ST_NUMGEOMETRIES",
+	"NUMINTERIORRINGS":           "ST_NUMINTERIORRINGS",
+	"NUMPOINTS":                  "ST_NUMPOINTS",
+	"OVERLAPS":                   "MBROVERLAPS",
+	"POINTFROMTEXT":              "ST_POINTFROMTEXT",
+	"POINTFROMWKB":               "ST_POINTFROMWKB",
+	"POINTN":                     "ST_POINTN",
+	"POLYFROMTEXT":               "ST_POLYFROMTEXT",
+	"POLYFROMWKB":                "ST_POLYFROMWKB",
+	"POLYGONFROMTEXT":            "ST_POLYGONFROMTEXT",
+	"POLYGONFROMWKB":             "ST_POLYGONFROMWKB",
+	"SRID":                       "ST_SRID",
+	"STARTPOINT":                 "ST_STARTPOINT",
+	"TOUCHES":                    "ST_TOUCHES",
+	"WITHIN":                     "MBRWITHIN",
+	"X":                          "ST_X",
+	"Y":                          "ST_Y",
+}
+
+// defaultDisplayWidths are the display widths MySQL 5.7 gives the integer
+// types, signed and unsigned
+var defaultDisplayWidths = map[string][2]uint16{
+	"tinyint":   {4, 3},
+	"smallint":  {6, 5},
+	"mediumint": {9, 8},
+	"int":       {11, 10},
+	"bigint":    {20, 20},
+}
@@ -0,0 +1,261 @@
+package upgrade
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zing22845/go-frm-parser/frm"
+	"github.com/zing22845/go-frm-parser/frm/lint"
+	"github.com/zing22845/go-frm-parser/frm/table"
+	"github.com/zing22845/go-frm-parser/frm/view"
+)
+
+// Check identifies a check of the upgrade to MySQL 8.0
+type Check string
+
+const (
+	CHECK_RESERVED_KEYWORDS  Check = "reservedKeywords"
+	CHECK_OLD_TEMPORAL       Check = "oldTemporal"
+	CHECK_PARTITIONED_ENGINE Check = "nonNativePartitioning"
+	CHECK_UTF8MB3            Check = "utf8mb3"
+	CHECK_ZEROFILL           Check = "zerofill"
+	CHECK_FTS_TABLE_NAME     Check = "ftsTablename"
+	CHECK_ORPHANED_SQL_FILES Check = "orphanedObjects"
+	CHECK_REMOVED_SYNTAX     Check = "removedViewSyntax"
+	CHECK_PARSE              Check = "parse"
+)
+
+// Checks lists the checks in the order of the report
+var Checks = []Check{
+	CHECK_RESERVED_KEYWORDS,
+	CHECK_OLD_TEMPORAL,
+	CHECK_PARTITIONED_ENGINE,
+	CHECK_UTF8MB3,
+	CHECK_ZEROFILL,
+	CHECK_FTS_TABLE_NAME,
+	CHECK_ORPHANED_SQL_FILES,
+	CHECK_REMOVED_SYNTAX,
+	CHECK_PARSE,
+}
+
+var checkTitles = map[Check]string{
+	CHECK_RESERVED_KEYWORDS:  "Usage of db objects with names conflicting with new reserved keywords",
+	CHECK_OLD_TEMPORAL:       "Usage of old temporal type",
+	CHECK_PARTITIONED_ENGINE: "Usage of partitioned tables on engines without native partitioning",
+	CHECK_UTF8MB3:            "Usage of utf8mb3 charset",
+	CHECK_ZEROFILL:           "Usage of ZEROFILL and display width of integer types",
+	CHECK_FTS_TABLE_NAME:     "Table names in conflict with InnoDB FTS auxiliary tables",
+	CHECK_ORPHANED_SQL_FILES: "Orphaned #sql files of interrupted DDL",
+	CHECK_REMOVED_SYNTAX:     "Usage of syntax removed in 8.0 in view definitions",
+	CHECK_PARSE:              "Files which could not be parsed",
+}
+
+// Title describes the check
+func (c Check) Title() string {
+	return checkTitles[c]
+}
+
+// systemSchemas are not checked, the upgrade rebuilds them
+var systemSchemas = map[string]bool{
+	"mysql":              true,
+	"information_schema": true,
+	"performance_schema": true,
+	"sys":                true,
+}
+
+// Issue is a problem of an object for the upgrade
+type Issue struct {
+	Check    Check         `json:"check"`
+	Severity lint.Severity `json:"severity"`
+	Schema   string        `json:"schema,omitempty"`
+	Object   string        `json:"object,omitempty"`
+	Column   string        `json:"column,omitempty"`
+	Message  string        `json:"message"`
+}
+
+func (i *Issue) String() string {
+	var name string
+	if i.Schema != "" {
+		name = i.Schema
+	}
+	if i.Object != "" {
+		if name != "" {
+			name += "."
+		}
+		name += i.Object
+	}
+	if i.Column != "" {
+		name += "." + i.Column
+	}
+	if name == "" {
+		return fmt.Sprintf("%s: %s", i.Severity, i.Message)
+	}
+	return fmt.Sprintf("%s: %s - %s", i.Severity, name, i.Message)
+}
+
+// Report is the result of the checks of a datadir
+type Report struct {
+	Datadir string   `json:"datadir"`
+	Schemas int      `json:"schemas"`
+	Tables  int      `json:"tables"`
+	Views   int      `json:"views"`
+	Issues  []*Issue `json:"issues"`
+}
+
+// Count returns the number of issues of each severity
+func (r *Report) Count() map[lint.Severity]int {
+	counts := make(map[lint.Severity]int)
+	for _, issue := range r.Issues {
+		counts[issue.Severity]++
+	}
+	return counts
+}
+
+// Ready reports whether no issue blocks the upgrade
+func (r *Report) Ready() bool {
+	return r.Count()[lint.SEVERITY_ERROR] == 0
+}
+
+// String formats the report like util.checkForServerUpgrade
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Checked %d schemas, %d tables and %d views in %s\n\n", r.Schemas, r.Tables, r.Views, r.Datadir)
+	for i, check := range Checks {
+		fmt.Fprintf(&b, "%d) %s\n", i+1, check.Title())
+		found := false
+		for _, issue := range r.Issues {
+			if issue.Check == check {
+				fmt.Fprintf(&b, "  %s\n", issue.String())
+				found = true
+			}
+		}
+		if !found {
+			b.WriteString("  No issues found\n")
+		}
+		b.WriteString("\n")
+	}
+	counts := r.Count()
+	fmt.Fprintf(&b, "Errors:   %d\nWarnings: %d\nNotices:  %d\n",
+		counts[lint.SEVERITY_ERROR], counts[lint.SEVERITY_WARNING], counts[lint.SEVERITY_NOTE])
+	if r.Ready() {
+		b.WriteString("\nNo fatal errors were found that would prevent an upgrade, but some potential issues were detected.\n")
+	} else {
+		b.WriteString("\nErrors were found, fix them before the upgrade.\n")
+	}
+	return b.String()
+}
+
+func (r *Report) add(check Check, severity lint.Severity, schema, object, column, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, &Issue{
+		Check:    check,
+		Severity: severity,
+		Schema:   schema,
+		Object:   object,
+		Column:   column,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// CheckDatadir checks the schemas of a MySQL 5.7 datadir, or a backup of
+// it, for the upgrade to MySQL 8.0
+func CheckDatadir(datadir string) (*Report, error) {
+	r := &Report{Datadir: datadir}
+	d, err := frm.ReadDatadir(datadir)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range d.Files {
+		r.checkOrphan("", file.Name())
+	}
+	for _, sd := range d.Schemas {
+		if systemSchemas[sd.Schema] || !sd.IsSchema() {
+			continue
+		}
+		err = r.checkSchema(sd)
+		if err != nil {
+			return nil, err
+		}
+	}
+	r.sort()
+	return r, nil
+}
+
+// checkSchema checks the database directory of a schema
+func (r *Report) checkSchema(sd *frm.SchemaDir) error {
+	schema := sd.Schema
+	r.Schemas++
+	r.checkIdentifier(schema, "", "", "schema", schema)
+	err := r.checkSchemaCharset(sd.Path, schema)
+	if err != nil {
+		return err
+	}
+	for _, file := range sd.Temporary {
+		r.checkOrphan(schema, file.Name())
+	}
+	return sd.Walk(func(path string, parsed frm.MySQLSchema, err error) error {
+		if err != nil {
+			r.add(CHECK_PARSE, lint.SEVERITY_WARNING, schema, filepath.Base(path), "", "%v", err)
+			return nil
+		}
+		switch t := parsed.(type) {
+		case *table.MySQLTable:
+			r.Tables++
+			r.CheckTable(schema, t)
+		case *view.MySQLView:
+			r.Views++
+			r.CheckView(schema, t)
+		}
+		return nil
+	})
+}
+
+// checkSchemaCharset checks the default character set of db.opt
+func (r *Report) checkSchemaCharset(dir, schema string) error {
+	file, err := os.Open(filepath.Join(dir, "db.opt"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if ok && key == "default-character-set" && isUTF8MB3(value) {
+			r.add(CHECK_UTF8MB3, lint.SEVERITY_WARNING, schema, "", "",
+				"schema default character set is %s, which is utf8mb3 in 8.0 and will become utf8mb4", value)
+		}
+	}
+	return scanner.Err()
+}
+
+// checkOrphan reports the files left by an interrupted ALTER TABLE,
+// #sql-*.frm, #sql-ib*.ibd and the like
+func (r *Report) checkOrphan(schema, name string) bool {
+	if !strings.HasPrefix(name, "#sql") {
+		return false
+	}
+	r.add(CHECK_ORPHANED_SQL_FILES, lint.SEVERITY_WARNING, schema, name, "",
+		"temporary file of an interrupted DDL, the 8.0 data dictionary can't import it, drop or remove it")
+	return true
+}
+
+func (r *Report) sort() {
+	order := make(map[Check]int, len(Checks))
+	for i, check := range Checks {
+		order[check] = i
+	}
+	sort.SliceStable(r.Issues, func(i, j int) bool {
+		return order[r.Issues[i].Check] < order[r.Issues[j].Check]
+	})
+}
+
+func isUTF8MB3(charset string) bool {
+	return charset == "utf8" || charset == "utf8mb3"
+}
@@ -0,0 +1,105 @@
+package upgrade
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zing22845/go-frm-parser/frm/lint"
+)
+
+// writeFile writes data, or copies the file of test_frms named by data
+// when it starts with @
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	content := []byte(data)
+	if name, ok := strings.CutPrefix(data, "@"); ok {
+		var err error
+		content, err = os.ReadFile(filepath.Join("../../test_frms", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckDatadir(t *testing.T) {
+	datadir := t.TempDir()
+	for path, data := range map[string]string{
+		"ibdata1":                 "",
+		"#sql-ib12-3456.ibd":      "",
+		"mysql/db.opt":            "default-character-set=utf8\ndefault-collation=utf8_general_ci\n",
+		"mysql/user.frm":          "@table_simple.frm",
+		"shop/db.opt":             "default-character-set=utf8\ndefault-collation=utf8_general_ci\n",
+		"shop/rank.frm":           "@table_simple.frm",
+		"shop/v1.frm":             "@view_md5_success.frm",
+		"shop/#sql-1a2b_3.frm":    "@table_simple.frm",
+		"shop/broken.frm":         "not a definition",
+		"shop/orders.ibd":         "",
+		"rank/db.opt":             "default-character-set=utf8mb4\ndefault-collation=utf8mb4_general_ci\n",
+		"legacy/t1.frm":           "@table_simple.frm",
+		"legacy/t1.MYD":           "",
+		"shop@002d2023/t2.frm":    "@table_simple.frm",
+		"shop@002d2023/t2.MYI":    "",
+		"shop@002d2023/sub/x.db":  "",
+		"#innodb_temp/temp_1.ibt": "",
+		"lost+found/#12345":       "",
+	} {
+		writeFile(t, filepath.Join(datadir, path), data)
+	}
+	r, err := CheckDatadir(datadir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the system schemas and the directories without definitions are
+	// skipped, broken.frm isn't counted
+	if r.Schemas != 4 || r.Tables != 3 || r.Views != 1 {
+		t.Errorf("checked %d schemas, %d tables and %d views", r.Schemas, r.Tables, r.Views)
+	}
+	want := []string{
+		"warning: rank - schema name rank is a reserved keyword in 8.0, it has to be quoted",
+		"warning: shop.rank - table name rank is a reserved keyword in 8.0, it has to be quoted",
+		"warning: legacy.t1 - table default character set is utf8mb3, consider utf8mb4",
+		"warning: shop - schema default character set is utf8, which is utf8mb3 in 8.0 and will become utf8mb4",
+		"warning: shop.rank - table default character set is utf8mb3, consider utf8mb4",
+		"warning: shop-2023.t2 - table default character set is utf8mb3, consider utf8mb4",
+		"warning: #sql-ib12-3456.ibd - temporary file of an interrupted DDL, the 8.0 data dictionary can't import it, drop or remove it",
+		"warning: shop.#sql-1a2b_3.frm - temporary file of an interrupted DDL, the 8.0 data dictionary can't import it, drop or remove it",
+		"warning: shop.broken.frm - invalid input format",
+	}
+	if got := issues(r); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestReportString(t *testing.T) {
+	r := &Report{Datadir: "/var/lib/mysql", Schemas: 1, Tables: 2}
+	r.checkOrphan("shop", "#sql-1a2b_3.frm")
+	r.checkIdentifier("shop", "", "", "schema", "rank")
+	r.sort()
+	got := r.String()
+	for _, want := range []string{
+		"Checked 1 schemas, 2 tables and 0 views in /var/lib/mysql\n\n" +
+			"1) Usage of db objects with names conflicting with new reserved keywords\n" +
+			"  warning: shop - schema name rank is a reserved keyword in 8.0, it has to be quoted\n\n" +
+			"2) Usage of old temporal type\n  No issues found\n",
+		"7) Orphaned #sql files of interrupted DDL\n" +
+			"  warning: shop.#sql-1a2b_3.frm - temporary file of an interrupted DDL",
+		"Errors:   0\nWarnings: 2\nNotices:  0\n\nNo fatal errors were found",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report without %q:\n%s", want, got)
+		}
+	}
+	r.add(CHECK_OLD_TEMPORAL, lint.SEVERITY_ERROR, "shop", "t1", "c", "old")
+	if r.Ready() || !strings.HasSuffix(r.String(), "Errors were found, fix them before the upgrade.\n") {
+		t.Error("an error doesn't block the upgrade")
+	}
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DisplayWidthRegex matches the display width of integer types
+var DisplayWidthRegex = regexp.MustCompile(`^(tinyint|smallint|mediumint|int|bigint)\(\d+\)`)
+
+// CollapseWhitespace replaces the runs of whitespace of the SQL outside of
+// strings and quoted identifiers by a space and trims the ends
+func CollapseWhitespace(sql string) string {
+	var b strings.Builder
+	var quote byte
+	space := false
+	for i := 0; i < len(sql); i++ {
+		ch := sql[i]
+		if quote != 0 {
+			b.WriteByte(ch)
+			if ch == '\\' && quote != '`' && i+1 < len(sql) {
+				i++
+				b.WriteByte(sql[i])
+			} else if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case ' ', '\t', '\r', '\n':
+			space = true
+			continue
+		case '\'', '"', '`':
+			quote = ch
+		}
+		if space && b.Len() != 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteByte(ch)
+	}
+	return b.String()
+}
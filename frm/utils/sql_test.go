@@ -0,0 +1,21 @@
+package utils
+
+import "testing"
+
+func TestCollapseWhitespace(t *testing.T) {
+	for _, c := range []struct {
+		sql  string
+		want string
+	}{
+		{"  select\t1\n", "select 1"},
+		{"select  'a  b',\n  \"c\r\nd\"", "select 'a  b', \"c\r\nd\""},
+		{"select `x  y`  from\tt", "select `x  y` from t"},
+		{"select 'it\\'s  ok'  ,  1", "select 'it\\'s  ok' , 1"},
+		{"select 'a''  b'", "select 'a''  b'"},
+		{"select `a\\`  ,  b", "select `a\\` , b"},
+	} {
+		if got := CollapseWhitespace(c.sql); got != c.want {
+			t.Errorf("CollapseWhitespace(%q) = %q, want %q", c.sql, got, c.want)
+		}
+	}
+}
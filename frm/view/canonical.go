@@ -0,0 +1,24 @@
+package view
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/zing22845/go-frm-parser/frm/utils"
+)
+
+// Canonical returns the normal form of the view definition, whitespace
+// outside of strings and quoted identifiers is collapsed and the body
+// doesn't end with a semicolon
+func (v *MySQLView) Canonical() string {
+	canonical := *v
+	canonical.Body = strings.TrimRight(utils.CollapseWhitespace(v.Body), "; ")
+	return canonical.String()
+}
+
+// Fingerprint is the hex SHA-256 of the canonical form of the view
+func (v *MySQLView) Fingerprint() string {
+	sum := sha256.Sum256([]byte(v.Canonical()))
+	return hex.EncodeToString(sum[:])
+}
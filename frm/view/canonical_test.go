@@ -0,0 +1,37 @@
+package view
+
+import (
+	"os"
+	"testing"
+)
+
+func TestCanonical(t *testing.T) {
+	data, err := os.ReadFile("../../test_frms/view_md5_success.frm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := Parse("v1.frm", string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "CREATE ALGORITHM=UNDEFINED DEFINER=`root`@`localhost` SQL SECURITY DEFINER VIEW `v1` AS " +
+		"select `test`.`t1`.`id` AS `id` from `test`.`t1`;\n"
+	if got := v.Canonical(); got != want {
+		t.Errorf("canonical\n%s\nwant\n%s", got, want)
+	}
+	fingerprint := v.Fingerprint()
+	for _, c := range []struct {
+		body string
+		same bool
+	}{
+		{"select `test`.`t1`.`id`  AS `id`\nfrom `test`.`t1`;\n", true},
+		{"select `test`.`t1`.`id` AS `id  ` from `test`.`t1`", false},
+		{"select `test`.`t1`.`id` AS `id` from `test`.`t2`", false},
+	} {
+		changed := *v
+		changed.Body = c.body
+		if same := changed.Fingerprint() == fingerprint; same != c.same {
+			t.Errorf("%q: same fingerprint %v, want %v", c.body, same, c.same)
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/zing22845/go-frm-parser/frm/utils"
 )
 
 type MySQLView struct {
@@ -17,6 +19,25 @@ type MySQLView struct {
 	StoredMD5   string
 	ComputedMD5 string
 	Timestamp   time.Time
+	Updatable   bool
+	// Source is the view query as the client wrote it, with its comments
+	// and formatting, BodyUTF8 the query in utf8
+	Source              string
+	BodyUTF8            string
+	ClientCharset       string
+	ConnectionCollation string
+	CreateVersion       int
+	// Revision is set by servers before MySQL 5.1
+	Revision int
+	// MariaDBVersion is the version of the MariaDB server which wrote the
+	// file, as 100428 for 10.4.28
+	MariaDBVersion string
+	// UseSource makes StringWithHeader render Source instead of Body, in a
+	// plain CREATE VIEW statement rather than in version comments
+	UseSource bool
+	// Warnings are the problems found by parsing with MD5_WARN or
+	// MD5_REPAIR
+	Warnings []string
 }
 
 func (v *MySQLView) GetName() string {
@@ -25,6 +46,9 @@ func (v *MySQLView) GetName() string {
 
 func (v *MySQLView) ParseName(path string) {
 	v.Name = strings.TrimSuffix(filepath.Base(path), ".frm")
+	if name, err := utils.DecodeMySQLFile2Object(v.Name); err == nil {
+		v.Name = name
+	}
 }
 
 func (v *MySQLView) String() string {
@@ -53,16 +77,62 @@ func (v *MySQLView) String() string {
 }
 
 func (v *MySQLView) StringWithHeader() string {
-	header := strings.Join([]string{
+	lines := []string{
 		"--",
 		fmt.Sprintf("-- View: %s", v.Name),
 		fmt.Sprintf("-- Timestamp: %s", v.Timestamp.Format("2006-01-02 15:04:05")),
 		fmt.Sprintf("-- Stored MD5: %s", v.StoredMD5),
 		fmt.Sprintf("-- Computed MD5: %s", v.ComputedMD5),
-		"--",
-		"",
-		"",
-	}, "\n")
+	}
+	for _, warning := range v.Warnings {
+		lines = append(lines, "-- Warning: "+warning)
+	}
+	header := strings.Join(append(lines, "--", "", ""), "\n")
+
+	return header + v.dumpString()
+}
 
-	return header + v.String()
+// dumpString renders the view the way mysqldump does, in version comments
+// and with the charset and collation of the client which created it
+func (v *MySQLView) dumpString() string {
+	var b strings.Builder
+	restore := v.ClientCharset != "" && v.ConnectionCollation != ""
+	if restore {
+		b.WriteString("/*!50001 SET @saved_cs_client          = @@character_set_client */;\n")
+		b.WriteString("/*!50001 SET @saved_cs_results         = @@character_set_results */;\n")
+		b.WriteString("/*!50001 SET @saved_col_connection     = @@collation_connection */;\n")
+		fmt.Fprintf(&b, "/*!50001 SET character_set_client      = %s */;\n", v.ClientCharset)
+		fmt.Fprintf(&b, "/*!50001 SET character_set_results     = %s */;\n", v.ClientCharset)
+		fmt.Fprintf(&b, "/*!50001 SET collation_connection      = %s */;\n", v.ConnectionCollation)
+	}
+
+	security := "DEFINER"
+	if v.SUID != Default {
+		security = v.SUID.String()
+	}
+	name := strings.ReplaceAll(v.Name, "`", "``")
+	if v.UseSource && v.Source != "" {
+		// the comments of the source would end a version comment, and a
+		// line comment at its end would hide what follows on the line
+		fmt.Fprintf(&b, "CREATE ALGORITHM=%s DEFINER=%s SQL SECURITY %s VIEW `%s` AS %s\n",
+			v.Algorithm, v.Definer.String(), security, name, strings.TrimRight(v.Source, " \t\r\n;"))
+		if v.CheckOption != None {
+			fmt.Fprintf(&b, "WITH %s CHECK OPTION", v.CheckOption)
+		}
+	} else {
+		fmt.Fprintf(&b, "/*!50001 CREATE ALGORITHM=%s */\n", v.Algorithm)
+		fmt.Fprintf(&b, "/*!50013 DEFINER=%s SQL SECURITY %s */\n", v.Definer.String(), security)
+		fmt.Fprintf(&b, "/*!50001 VIEW `%s` AS %s */", name, v.Body)
+		if v.CheckOption != None {
+			fmt.Fprintf(&b, "\n/*!50002 WITH %s CHECK OPTION */", v.CheckOption)
+		}
+	}
+	b.WriteString(";\n")
+
+	if restore {
+		b.WriteString("/*!50001 SET character_set_client      = @saved_cs_client */;\n")
+		b.WriteString("/*!50001 SET character_set_results     = @saved_cs_results */;\n")
+		b.WriteString("/*!50001 SET collation_connection      = @saved_col_connection */;\n")
+	}
+	return b.String()
 }
@@ -0,0 +1,102 @@
+package view
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDumpString(t *testing.T) {
+	const (
+		saveCharset = "/*!50001 SET @saved_cs_client          = @@character_set_client */;\n" +
+			"/*!50001 SET @saved_cs_results         = @@character_set_results */;\n" +
+			"/*!50001 SET @saved_col_connection     = @@collation_connection */;\n" +
+			"/*!50001 SET character_set_client      = utf8mb4 */;\n" +
+			"/*!50001 SET character_set_results     = utf8mb4 */;\n" +
+			"/*!50001 SET collation_connection      = utf8mb4_general_ci */;\n"
+		restoreCharset = "/*!50001 SET character_set_client      = @saved_cs_client */;\n" +
+			"/*!50001 SET character_set_results     = @saved_cs_results */;\n" +
+			"/*!50001 SET collation_connection      = @saved_col_connection */;\n"
+		body   = "select `shop`.`t`.`id` AS `id` from `shop`.`t`"
+		source = "SELECT id /* the key */ FROM t -- all rows\n"
+	)
+	for _, c := range []struct {
+		name string
+		view MySQLView
+		want string
+	}{
+		{
+			name: "version comments",
+			view: MySQLView{Name: "v", Algorithm: Merge, SUID: Default, Body: body},
+			want: "/*!50001 CREATE ALGORITHM=MERGE */\n" +
+				"/*!50013 DEFINER=`root`@`localhost` SQL SECURITY DEFINER */\n" +
+				"/*!50001 VIEW `v` AS " + body + " */;\n",
+		},
+		{
+			name: "charset and check option",
+			view: MySQLView{
+				Name: "v`1", SUID: Invoker, Body: body, CheckOption: Cascaded,
+				ClientCharset: "utf8mb4", ConnectionCollation: "utf8mb4_general_ci",
+			},
+			want: saveCharset +
+				"/*!50001 CREATE ALGORITHM=UNDEFINED */\n" +
+				"/*!50013 DEFINER=`root`@`localhost` SQL SECURITY INVOKER */\n" +
+				"/*!50001 VIEW `v``1` AS " + body + " */\n" +
+				"/*!50002 WITH CASCADED CHECK OPTION */;\n" +
+				restoreCharset,
+		},
+		{
+			name: "source",
+			view: MySQLView{
+				Name: "v", SUID: Definer, Body: body, Source: source, UseSource: true,
+				ClientCharset: "utf8mb4", ConnectionCollation: "utf8mb4_general_ci",
+			},
+			want: saveCharset +
+				"CREATE ALGORITHM=UNDEFINED DEFINER=`root`@`localhost` SQL SECURITY DEFINER VIEW `v` AS " +
+				"SELECT id /* the key */ FROM t -- all rows\n;\n" +
+				restoreCharset,
+		},
+		{
+			name: "source and check option",
+			view: MySQLView{Name: "v", SUID: Definer, Source: source, UseSource: true, CheckOption: Local},
+			want: "CREATE ALGORITHM=UNDEFINED DEFINER=`root`@`localhost` SQL SECURITY DEFINER VIEW `v` AS " +
+				"SELECT id /* the key */ FROM t -- all rows\nWITH LOCAL CHECK OPTION;\n",
+		},
+		{
+			name: "no source",
+			view: MySQLView{Name: "v", SUID: Definer, Body: body, UseSource: true},
+			want: "/*!50001 CREATE ALGORITHM=UNDEFINED */\n" +
+				"/*!50013 DEFINER=`root`@`localhost` SQL SECURITY DEFINER */\n" +
+				"/*!50001 VIEW `v` AS " + body + " */;\n",
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			c.view.Definer = MySQLDefiner{User: "root", Host: "localhost"}
+			if got := c.view.dumpString(); got != c.want {
+				t.Errorf("got\n%s\nwant\n%s", got, c.want)
+			}
+		})
+	}
+}
+
+func TestStringWithHeader(t *testing.T) {
+	data, err := os.ReadFile("../../test_frms/view_md5_success.frm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := Parse("v1.frm", string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.Warnings = []string{"stored md5 repaired"}
+	got := v.StringWithHeader()
+	header := "--\n-- View: v1\n-- Timestamp: " + v.Timestamp.Format("2006-01-02 15:04:05") + "\n" +
+		"-- Stored MD5: " + v.StoredMD5 + "\n-- Computed MD5: " + v.ComputedMD5 + "\n" +
+		"-- Warning: stored md5 repaired\n--\n\n"
+	if !strings.HasPrefix(got, header) {
+		t.Fatalf("got\n%s\nwant header\n%s", got, header)
+	}
+	if body := strings.TrimPrefix(got, header); body != v.dumpString() {
+		t.Errorf("got\n%s\nwant\n%s", body, v.dumpString())
+	}
+}
@@ -1,15 +1,56 @@
 package view
 
 import (
+	"bytes"
 	"crypto/md5"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"io"
-	"regexp"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// MD5Mode is what parsing does when the md5 stored in a view file doesn't
+// match its query
+type MD5Mode int
+
+const (
+	// MD5_IGNORE only computes the md5
+	MD5_IGNORE MD5Mode = iota
+	// MD5_WARN records the mismatch in the view's Warnings
+	MD5_WARN
+	// MD5_STRICT fails with ErrMD5Mismatch
+	MD5_STRICT
+	// MD5_REPAIR records the mismatch and, in ParseFile, rewrites the md5
+	// of the file
+	MD5_REPAIR
+)
+
+// ErrMD5Mismatch is the error of MD5_STRICT parsing of a view whose query
+// doesn't match its md5
+var ErrMD5Mismatch = errors.New("md5 mismatch")
+
+// ParseOptions are the options of ParseWithOptions
+type ParseOptions struct {
+	MD5Mode MD5Mode
+	// UseSource sets UseSource of the view
+	UseSource bool
+}
+
+// Parse parses the view file data without checking its md5
 func Parse(path string, data string) (view *MySQLView, err error) {
+	return ParseWithOptions(path, data, nil)
+}
+
+// ParseWithOptions parses the view file data, checking its md5 as opts say
+func ParseWithOptions(path string, data string, opts *ParseOptions) (view *MySQLView, err error) {
+	if opts == nil {
+		opts = &ParseOptions{}
+	}
 	view = &MySQLView{}
 
 	lines := strings.Split(data, "\n")
@@ -26,7 +67,7 @@ func Parse(path string, data string) (view *MySQLView, err error) {
 		case "md5":
 			view.StoredMD5 = value
 		case "updatable":
-			// Ignore this field
+			view.Updatable = value == "1"
 		case "algorithm":
 			view.Algorithm = parseAlgorithm(value)
 		case "definer_user":
@@ -43,47 +84,129 @@ func Parse(path string, data string) (view *MySQLView, err error) {
 				return view, err
 			}
 		case "create-version":
-			// Ignore this field
+			view.CreateVersion, err = strconv.Atoi(value)
+			if err != nil {
+				return view, err
+			}
+		case "revision":
+			view.Revision, err = strconv.Atoi(value)
+			if err != nil {
+				return view, err
+			}
 		case "source":
-			// Ignore this field
+			view.Source = unescape(value)
 		case "client_cs_name":
-			// Ignore this field
+			view.ClientCharset = value
 		case "connection_cl_name":
-			// Ignore this field
+			view.ConnectionCollation = value
 		case "view_body_utf8":
-			// Ignore this field
+			view.BodyUTF8 = unescape(value)
+		case "mariadb-version":
+			view.MariaDBVersion = value
 		}
 	}
 
+	view.UseSource = opts.UseSource
 	view.ParseName(path)
 	view.ComputedMD5 = computeMD5(view.Body)
-	/*
-		if view.StoredMD5 != view.ComputedMD5 {
-			return view, fmt.Errorf(
-				"md5 not match, stored: %s, computed: %s",
-				view.StoredMD5, view.ComputedMD5)
-		}
-	*/
+	if view.MD5Matches() || opts.MD5Mode == MD5_IGNORE {
+		return view, nil
+	}
+	mismatch := fmt.Errorf("%w, stored: %s, computed: %s", ErrMD5Mismatch, view.StoredMD5, view.ComputedMD5)
+	if opts.MD5Mode == MD5_STRICT {
+		return view, mismatch
+	}
+	view.Warnings = append(view.Warnings, mismatch.Error())
 
 	return view, nil
 }
 
+// MD5Matches reports whether the stored md5 is the md5 of the query
+func (v *MySQLView) MD5Matches() bool {
+	return v.StoredMD5 == v.ComputedMD5
+}
+
+// RepairMD5 rewrites the md5 of the view file at path to the md5 of its
+// query and returns the repaired view parsed with opts but for MD5Mode,
+// the rest of the file is kept as is. The file is replaced by renaming a
+// copy, so a failure leaves it intact
+func RepairMD5(path string, opts *ParseOptions) (*MySQLView, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	parseOpts := ParseOptions{}
+	if opts != nil {
+		parseOpts = *opts
+	}
+	parseOpts.MD5Mode = MD5_IGNORE
+	view, err := ParseWithOptions(path, string(data), &parseOpts)
+	if err != nil {
+		return nil, err
+	}
+	if view.MD5Matches() {
+		return view, nil
+	}
+	lines := bytes.Split(data, []byte("\n"))
+	for i, line := range lines {
+		if bytes.HasPrefix(line, []byte("md5=")) {
+			lines[i] = []byte("md5=" + view.ComputedMD5)
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(bytes.Join(lines, []byte("\n")))
+	if err == nil {
+		err = tmp.Chmod(info.Mode().Perm())
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("repair md5 of %s: %w", path, err)
+	}
+	view.StoredMD5 = view.ComputedMD5
+	return view, nil
+}
+
+// unescape decodes a value of a view file the way read_escaped_string of
+// the server does, the escapes are those of write_escaped_string
 func unescape(value string) string {
-	metaMapping := map[string]string{
-		"b":  "\\b",
-		"t":  "\\t",
-		"n":  "\\n",
-		"r":  "\\r",
-		"\\": "\\\\",
-		"s":  " ",
-		"\"": "\"",
-		"'":  "'",
-	}
-
-	regex := regexp.MustCompile(`\\(\['"btnr\\s])`)
-	return regex.ReplaceAllStringFunc(value, func(match string) string {
-		return metaMapping[match[1:2]]
-	})
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case '\\':
+			b.WriteByte('\\')
+		case 'n':
+			b.WriteByte('\n')
+		case '0':
+			b.WriteByte(0)
+		case 'z':
+			b.WriteByte(26)
+		case '\'':
+			b.WriteByte('\'')
+		default:
+			// not written by the server, kept as is
+			b.WriteByte('\\')
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
 }
 
 func parseAlgorithm(input string) Algorithm {
@@ -125,6 +248,8 @@ func parseCheckOption(input string) CheckOption {
 	}
 }
 
+// computeMD5 is the md5 the server stores for a view, of its query as
+// written before escaping
 func computeMD5(input string) string {
 	hash := md5.New()
 	_, _ = io.WriteString(hash, input)
@@ -4,12 +4,15 @@ go 1.21.5
 
 require (
 	github.com/pingcap/tidb/pkg/parser v0.0.0-20240415074806-224ae1547850
-	github.com/pkg/errors v0.8.1
+	github.com/pkg/errors v0.9.1
 )
 
 require (
+	github.com/cznic/mathutil v0.0.0-20181122101859-297441e03548 // indirect
 	github.com/pingcap/errors v0.11.5-0.20240311024730-e056997136bb // indirect
+	github.com/pingcap/failpoint v0.0.0-20220801062533-2eaa32854a6c // indirect
 	github.com/pingcap/log v1.1.0 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
 	go.uber.org/atomic v1.11.0 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
 	go.uber.org/zap v1.26.0 // indirect